@@ -13,49 +13,90 @@ import (
 	"time"
 
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/config"
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/logging"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/metrics"
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/server"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/tracing"
 	_ "github.com/joho/godotenv/autoload"
 )
 
 func main() {
 	ctx := context.Background()
 
-	// Initialize Firebase/GCIP
-	log.Println("Initializing Firebase/GCIP...")
-	if err := auth.InitFirebase(ctx); err != nil {
-		log.Fatalf("Unable to initialize Firebase: %v\n", err)
+	// Every setting, validated up front (environment over CONFIG_FILE)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+
+	// JSON logs with PII redaction; log.Printf goes through the same handler
+	logging.Setup(logging.Options{Level: cfg.Log.Level, Format: cfg.Log.Format})
+
+	// OpenTelemetry tracing (off unless OTEL_TRACES_EXPORTER is otlp or stdout);
+	// set up before the database so its queries are traced
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{Exporter: cfg.Tracing.Exporter})
+	if err != nil {
+		log.Fatalf("Unable to initialize tracing: %v\n", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("failed to flush traces: %v", err)
+		}
+	}()
+
+	// Initialize the token verifier (Firebase/GCIP unless AUTH_MODE says otherwise)
+	log.Println("Initializing token verifier...")
+	verifier, err := auth.NewTokenVerifier(ctx, cfg.Auth)
+	if err != nil {
+		log.Fatalf("Unable to initialize token verifier: %v\n", err)
 	}
 
 	// Initialize the database connection
-	databaseService, dbError := database.InitDB()
+	databaseService, dbError := database.InitDB(cfg.Database)
 	if dbError != nil {
 		log.Fatalf("Unable to connect to database: %v\n", dbError)
 	}
 	defer databaseService.CloseDB()
+	metrics.RegisterDB(databaseService.Pool())
+
+	// Metrics on their own listener, kept off the public port
+	if addr := cfg.Metrics.Addr; addr != "" {
+		go func() {
+			if err := metrics.Serve(addr); err != nil {
+				log.Printf("metrics listener stopped: %v", err)
+			}
+		}()
+	}
 
-	// Initialize the HTTP server
-	server := server.NewServer(databaseService.Pool())
+	// Initialize the HTTP server; background jobs stop with jobsCtx
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+	server, drain := server.NewServer(jobsCtx, cfg, databaseService.Pool(), verifier)
 
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
 
 	// Run graceful shutdown in a separate goroutine
-	go gracefulShutdown(server, done)
+	go gracefulShutdown(server, drain, done)
 
 	// Start the HTTP server
-	err := server.ListenAndServe()
+	err = server.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
 		panic(fmt.Sprintf("http server error: %s", err))
 	}
 
 	// Wait for the graceful shutdown to complete
 	<-done
+	stopJobs()
 	log.Println("Graceful shutdown complete.")
 
 }
 
-func gracefulShutdown(srv *http.Server, done chan bool) {
+func gracefulShutdown(srv *http.Server, drain func(context.Context) error, done chan bool) {
 	// Create context that listens for the interrupt signal from the OS.
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	// Ensure the stop function is called to release resources
@@ -74,6 +115,11 @@ func gracefulShutdown(srv *http.Server, done chan bool) {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown with error: %v", err)
 	}
+	// Running imports stop at their next row and save their state while the
+	// database is still open
+	if err := drain(ctx); err != nil {
+		log.Printf("Background work did not stop in time: %v", err)
+	}
 
 	log.Println("Server exiting")
 
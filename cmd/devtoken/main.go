@@ -0,0 +1,139 @@
+// Command devtoken mints ID tokens for running the API with AUTH_MODE=local.
+//
+// Generate a signing key and the JWKS file the API reads (once):
+//
+//	go run ./cmd/devtoken -init
+//
+// Then start the API with AUTH_MODE=local and AUTH_JWKS_FILE=.dev/auth/jwks.json,
+// and mint a token for a user whose external_id is UID:
+//
+//	go run ./cmd/devtoken -uid UID [-tv 1] [-ttl 1h]
+//	curl -H "Authorization: Bearer $(go run ./cmd/devtoken -uid UID)" localhost:8080/me
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+)
+
+func main() {
+	var (
+		dir      = flag.String("dir", ".dev/auth", "directory holding key.pem and jwks.json")
+		initKeys = flag.Bool("init", false, "generate a new signing key and JWKS file, then exit")
+		uid      = flag.String("uid", "", "user external_id to put in the sub claim")
+		email    = flag.String("email", "", "optional email claim")
+		tv       = flag.Int("tv", 0, "token version claim (0 leaves it out)")
+		ttl      = flag.Duration("ttl", time.Hour, "token lifetime")
+		issuer   = flag.String("iss", envOr("AUTH_LOCAL_ISSUER", auth.DefaultLocalIssuer), "issuer claim")
+		audience = flag.String("aud", envOr("AUTH_LOCAL_AUDIENCE", auth.DefaultLocalAudience), "audience claim")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+
+	keyPath := filepath.Join(*dir, "key.pem")
+	jwksPath := filepath.Join(*dir, "jwks.json")
+
+	if *initKeys {
+		if err := generate(keyPath, jwksPath); err != nil {
+			log.Fatalf("devtoken: %v", err)
+		}
+		log.Printf("wrote %s and %s", keyPath, jwksPath)
+		log.Printf("start the API with AUTH_MODE=local AUTH_JWKS_FILE=%s", jwksPath)
+		return
+	}
+
+	if *uid == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	key, err := loadKey(keyPath)
+	if err != nil {
+		log.Fatalf("devtoken: %v (run with -init first)", err)
+	}
+	_, kid, err := auth.LocalJWKS(key)
+	if err != nil {
+		log.Fatalf("devtoken: %v", err)
+	}
+
+	claims := map[string]interface{}{}
+	if *email != "" {
+		claims["email"] = *email
+	}
+	if *tv > 0 {
+		claims[auth.TokenVersionClaim] = *tv
+	}
+	token, err := auth.SignLocalToken(key, kid, auth.LocalToken{
+		UID:      *uid,
+		Issuer:   *issuer,
+		Audience: *audience,
+		TTL:      *ttl,
+		Claims:   claims,
+	})
+	if err != nil {
+		log.Fatalf("devtoken: %v", err)
+	}
+	fmt.Println(token)
+}
+
+func generate(keyPath, jwksPath string) error {
+	if _, err := os.Stat(keyPath); err == nil {
+		return fmt.Errorf("%s already exists; delete it to rotate the key", keyPath)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+	jwks, _, err := auth.LocalJWKS(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0o700); err != nil {
+		return err
+	}
+	der := x509.MarshalPKCS1PrivateKey(key)
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(jwksPath, jwks, 0o644)
+}
+
+func loadKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New(path + ": no PEM block")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New(path + ": not an RSA key")
+	}
+	return key, nil
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
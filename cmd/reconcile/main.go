@@ -0,0 +1,79 @@
+// Command reconcile compares Firebase/GCIP accounts with app.users and reports
+// the differences, optionally repairing them.
+//
+//	go run ./cmd/reconcile           # report only
+//	go run ./cmd/reconcile -repair   # also fix what can be fixed safely
+//	go run ./cmd/reconcile -json     # print the full report as JSON
+//
+// It exits with status 1 when drift remains unresolved, so it can gate a CI or
+// cron job.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/config"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/reconcile"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/user"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	var (
+		repair = flag.Bool("repair", false, "repair drift instead of only reporting it")
+		asJSON = flag.Bool("json", false, "print the report as JSON")
+	)
+	flag.Parse()
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := auth.InitFirebase(ctx, cfg.Auth); err != nil {
+		log.Fatalf("Unable to initialize Firebase: %v", err)
+	}
+	databaseService, err := database.InitDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("Unable to connect to database: %v", err)
+	}
+	defer databaseService.CloseDB()
+
+	r := reconcile.New(user.NewUserRepository(databaseService.Pool()), user.NewGCIPIdentityProvider())
+	report, err := r.Run(ctx, *repair)
+	if err != nil {
+		log.Fatalf("reconcile: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("encode report: %v", err)
+		}
+	} else {
+		for _, d := range report.Drifts {
+			status := "unresolved"
+			switch {
+			case d.Repaired:
+				status = "repaired"
+			case d.RepairError != "":
+				status = "repair failed: " + d.RepairError
+			}
+			fmt.Printf("%-24s %-30s %-32s %s [%s]\n", d.Kind, d.ExternalID, d.Email, d.Detail, status)
+		}
+		fmt.Println(report.Summary())
+	}
+
+	if report.Unresolved() > 0 {
+		databaseService.CloseDB()
+		os.Exit(1)
+	}
+}
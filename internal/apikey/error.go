@@ -0,0 +1,42 @@
+package apikey
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain-level errors for API key operations
+var (
+	ErrNotFound     = errors.New("api key not found")
+	ErrConflict     = errors.New("api key conflict")
+	ErrInvalidInput = errors.New("invalid api key input")
+	ErrForbidden    = errors.New("forbidden: insufficient permissions")
+
+	ErrAlreadyRevoked = errors.New("api key already revoked")
+
+	// Authentication failures; the middleware reports all of them as an invalid token
+	ErrInvalidKey = errors.New("invalid api key")
+	ErrKeyRevoked = errors.New("api key revoked")
+	ErrKeyExpired = errors.New("api key expired")
+)
+
+// ValidationError represents validation errors with specific field information
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap allows errors.Is to work with ValidationError
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// NewValidationError creates a new ValidationError
+func NewValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
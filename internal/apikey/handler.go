@@ -0,0 +1,153 @@
+package apikey
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+/* -------------------- Handler Struct -------------------- */
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// RegisterRoutes mounts the API key management routes (under /api-keys).
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.list)
+	r.Post("/", h.create)
+	r.Get("/{id}", h.getByID)
+	r.Post("/{id}/revoke", h.revoke)
+}
+
+/* -------------------- Handlers -------------------- */
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	list, err := h.svc.List(r.Context(), actor)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// create responds with the new key; this is the only time it is returned.
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var in CreateKeyInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), actor, &in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	k, err := h.svc.Get(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, k)
+}
+
+func (h *Handler) revoke(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.svc.Revoke(r.Context(), actor, id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/* -------------------- Helpers -------------------- */
+
+func parseID(r *http.Request, param string) (uuid.UUID, error) {
+	id, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, param)))
+	if err != nil {
+		return uuid.Nil, NewValidationError(param, "must be a valid UUID")
+	}
+	return id, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// writeError classifies known domain errors and delegates to httpError.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
+
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		httpError(w, http.StatusBadRequest, err.Error())
+
+	case errors.Is(err, ErrNotFound):
+		httpError(w, http.StatusNotFound, err.Error())
+
+	case errors.Is(err, ErrConflict), errors.Is(err, ErrAlreadyRevoked):
+		httpError(w, http.StatusConflict, err.Error())
+
+	case errors.Is(err, ErrForbidden):
+		httpError(w, http.StatusForbidden, err.Error())
+
+	default:
+		httpError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
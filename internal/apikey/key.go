@@ -0,0 +1,54 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+)
+
+// Keys look like hz_<prefix>_<secret>. Both parts use lowercase base32, so
+// neither contains the "_" separator.
+const (
+	prefixBytes = 5  // 8 characters
+	secretBytes = 32 // 52 characters
+)
+
+var keyEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// generateKey returns a new key together with its prefix and hash.
+func generateKey() (key, prefix string, hash []byte, err error) {
+	buf := make([]byte, prefixBytes+secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", nil, err
+	}
+	prefix = keyEncoding.EncodeToString(buf[:prefixBytes])
+	key = auth.APIKeyPrefix + prefix + "_" + keyEncoding.EncodeToString(buf[prefixBytes:])
+	return key, prefix, hashKey(key), nil
+}
+
+// parseKey returns the prefix of a well-formed key.
+func parseKey(key string) (prefix string, ok bool) {
+	rest, ok := strings.CutPrefix(key, auth.APIKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != keyEncoding.EncodedLen(prefixBytes) || len(secret) != keyEncoding.EncodedLen(secretBytes) {
+		return "", false
+	}
+	return prefix, true
+}
+
+func hashKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// matches compares key against a stored hash in constant time.
+func matches(key string, hash []byte) bool {
+	return subtle.ConstantTimeCompare(hashKey(key), hash) == 1
+}
@@ -0,0 +1,46 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey ↔ app.api_keys. The key itself is never stored, only its hash.
+type APIKey struct {
+	ID              uuid.UUID  `json:"id"`
+	Name            string     `json:"name"`
+	Prefix          string     `json:"prefix"` // identifies the key in logs and lists
+	KeyHash         []byte     `json:"-"`
+	Scopes          []string   `json:"scopes"`
+	ShopID          *uuid.UUID `json:"shopId,omitempty"` // nil = system-wide
+	CreatedByUserID *uuid.UUID `json:"createdByUserId,omitempty"`
+	ExpiresAt       *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt      *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt       *time.Time `json:"revokedAt,omitempty"`
+	RevokedByUserID *uuid.UUID `json:"revokedByUserId,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// IsRevoked reports whether the key has been revoked.
+func (k *APIKey) IsRevoked() bool { return k.RevokedAt != nil }
+
+// IsExpired reports whether the key's expiry has passed at now.
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// CreateKeyInput is the body of POST /api-keys.
+type CreateKeyInput struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ShopCode  *string    `json:"shopCode,omitempty"` // omit for a system-wide key
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// CreatedKey is returned once, at creation; Key cannot be retrieved again.
+type CreatedKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,143 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository defines the persistence contract for API keys.
+type Repository interface {
+	Create(ctx context.Context, k *APIKey) (*APIKey, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	List(ctx context.Context) ([]*APIKey, error)
+	// Revoke returns ErrAlreadyRevoked for a key that is already revoked.
+	Revoke(ctx context.Context, id uuid.UUID, byUserID uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+}
+
+type pgRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository constructs a Postgres-backed API key repository.
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgRepo{db: db}
+}
+
+/* ---------- error mapping ---------- */
+
+func mapPgError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.UniqueViolation:
+			return ErrConflict
+		case pgerrcode.ForeignKeyViolation, pgerrcode.CheckViolation, pgerrcode.NotNullViolation:
+			return ErrInvalidInput
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+/* ---------- queries ---------- */
+
+const keySelect = `
+SELECT id, name, prefix, key_hash, scopes, shop_id, created_by_user_id,
+       expires_at, last_used_at, revoked_at, revoked_by_user_id, created_at, updated_at
+FROM app.api_keys
+`
+
+func scanKey(row pgx.Row) (*APIKey, error) {
+	var k APIKey
+	if err := row.Scan(
+		&k.ID, &k.Name, &k.Prefix, &k.KeyHash, &k.Scopes, &k.ShopID, &k.CreatedByUserID,
+		&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.RevokedByUserID, &k.CreatedAt, &k.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *pgRepo) Create(ctx context.Context, k *APIKey) (*APIKey, error) {
+	created, err := scanKey(r.db.QueryRow(ctx, `
+INSERT INTO app.api_keys (name, prefix, key_hash, scopes, shop_id, created_by_user_id, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, name, prefix, key_hash, scopes, shop_id, created_by_user_id,
+          expires_at, last_used_at, revoked_at, revoked_by_user_id, created_at, updated_at
+`, k.Name, k.Prefix, k.KeyHash, k.Scopes, k.ShopID, k.CreatedByUserID, k.ExpiresAt))
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	return created, nil
+}
+
+func (r *pgRepo) GetByID(ctx context.Context, id uuid.UUID) (*APIKey, error) {
+	k, err := scanKey(r.db.QueryRow(ctx, keySelect+` WHERE id = $1`, id))
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	return k, nil
+}
+
+func (r *pgRepo) GetByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	k, err := scanKey(r.db.QueryRow(ctx, keySelect+` WHERE prefix = $1`, prefix))
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	return k, nil
+}
+
+func (r *pgRepo) List(ctx context.Context) ([]*APIKey, error) {
+	rows, err := r.db.Query(ctx, keySelect+` ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	defer rows.Close()
+
+	list := make([]*APIKey, 0)
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, k)
+	}
+	return list, rows.Err()
+}
+
+func (r *pgRepo) Revoke(ctx context.Context, id uuid.UUID, byUserID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+UPDATE app.api_keys
+SET revoked_at = now(), revoked_by_user_id = $2
+WHERE id = $1 AND revoked_at IS NULL
+`, id, byUserID)
+	if err != nil {
+		return mapPgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		// Either the key doesn't exist or it was revoked already
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrAlreadyRevoked
+	}
+	return nil
+}
+
+func (r *pgRepo) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE app.api_keys SET last_used_at = now() WHERE id = $1`, id)
+	return mapPgError(err)
+}
@@ -0,0 +1,237 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/cache"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/shop"
+	"github.com/google/uuid"
+)
+
+// Service manages API keys and authenticates requests that carry one.
+type Service interface {
+	List(ctx context.Context, actor *auth.AuthUser) ([]*APIKey, error)
+	Get(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*APIKey, error)
+	Create(ctx context.Context, actor *auth.AuthUser, in *CreateKeyInput) (*CreatedKey, error)
+	Revoke(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) error
+
+	// Authenticate resolves a bearer token of the form hz_<prefix>_<secret> to
+	// the key's principal, and records that the key was used.
+	Authenticate(ctx context.Context, key string) (*auth.AuthUser, error)
+}
+
+const (
+	maxNameLength = 100
+
+	// keyCacheTTL bounds how long another instance may keep accepting a key
+	// after it is revoked (this instance forgets it immediately).
+	keyCacheTTL  = 30 * time.Second
+	keyCacheSize = 1000
+	// lastUsedInterval is the minimum time between last_used_at writes per key.
+	lastUsedInterval = time.Minute
+
+	// createAttempts covers the (unlikely) case of a prefix collision.
+	createAttempts = 3
+)
+
+type service struct {
+	repo        Repository
+	shopService shop.ShopService
+	audit       audit.Recorder
+	now         func() time.Time
+
+	keys     *cache.LRU[string, *APIKey] // by prefix
+	lastUsed *cache.LRU[uuid.UUID, struct{}]
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates an API key service. Creations and revocations are
+// recorded to rec; nil records nothing.
+func NewService(repo Repository, shopSvc shop.ShopService, rec audit.Recorder) Service {
+	return &service{
+		repo:        repo,
+		shopService: shopSvc,
+		audit:       audit.OrNop(rec),
+		now:         time.Now,
+		keys:        cache.New[string, *APIKey](keyCacheSize, keyCacheTTL),
+		lastUsed:    cache.New[uuid.UUID, struct{}](keyCacheSize, lastUsedInterval),
+	}
+}
+
+/* ---------- management (apikeys.manage) ---------- */
+
+func (s *service) List(ctx context.Context, actor *auth.AuthUser) ([]*APIKey, error) {
+	if !actor.Can(auth.PermAPIKeysManage) {
+		return nil, ErrForbidden
+	}
+	return s.repo.List(ctx)
+}
+
+func (s *service) Get(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*APIKey, error) {
+	if !actor.Can(auth.PermAPIKeysManage) {
+		return nil, ErrForbidden
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) Create(ctx context.Context, actor *auth.AuthUser, in *CreateKeyInput) (*CreatedKey, error) {
+	if !actor.Can(auth.PermAPIKeysManage) {
+		return nil, ErrForbidden
+	}
+	k, err := s.validateCreate(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	createdBy := actor.ID
+	k.CreatedByUserID = &createdBy
+
+	for attempt := 1; ; attempt++ {
+		key, prefix, hash, err := generateKey()
+		if err != nil {
+			return nil, fmt.Errorf("generate api key: %w", err)
+		}
+		k.Prefix, k.KeyHash = prefix, hash
+
+		created, err := s.repo.Create(ctx, k)
+		if errors.Is(err, ErrConflict) && attempt < createAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		slog.InfoContext(ctx, "api key created", "prefix", created.Prefix, "name", created.Name, "scopes", created.Scopes)
+		// the entry holds the key's metadata, never the key itself
+		s.audit.Record(ctx, audit.Entry{
+			Action: "apikey.create", EntityType: audit.EntityAPIKey, EntityID: created.ID.String(),
+			ShopID: created.ShopID, After: created,
+		})
+		return &CreatedKey{APIKey: created, Key: key}, nil
+	}
+}
+
+func (s *service) validateCreate(ctx context.Context, in *CreateKeyInput) (*APIKey, error) {
+	k := &APIKey{Name: strings.TrimSpace(in.Name)}
+	if k.Name == "" {
+		return nil, NewValidationError("name", "is required")
+	}
+	if len(k.Name) > maxNameLength {
+		return nil, NewValidationError("name", fmt.Sprintf("must be at most %d characters", maxNameLength))
+	}
+
+	for _, scope := range in.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !auth.IsValidScope(scope) {
+			return nil, NewValidationError("scopes", fmt.Sprintf("unknown scope %q", scope))
+		}
+		if !slices.Contains(k.Scopes, scope) {
+			k.Scopes = append(k.Scopes, scope)
+		}
+	}
+	if len(k.Scopes) == 0 {
+		return nil, NewValidationError("scopes", "at least one scope is required")
+	}
+
+	if in.ShopCode != nil && strings.TrimSpace(*in.ShopCode) != "" {
+		shopID, err := s.shopService.GetShopIDByCode(ctx, strings.TrimSpace(*in.ShopCode))
+		if err != nil {
+			if errors.Is(err, shop.ErrNotFound) {
+				return nil, NewValidationError("shopCode", "invalid shop code")
+			}
+			return nil, fmt.Errorf("lookup shopCode: %w", err)
+		}
+		k.ShopID = &shopID
+	}
+
+	if in.ExpiresAt != nil {
+		if !in.ExpiresAt.After(s.now()) {
+			return nil, NewValidationError("expiresAt", "must be in the future")
+		}
+		k.ExpiresAt = in.ExpiresAt
+	}
+	return k, nil
+}
+
+func (s *service) Revoke(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) error {
+	if !actor.Can(auth.PermAPIKeysManage) {
+		return ErrForbidden
+	}
+	k, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Revoke(ctx, id, actor.ID); err != nil {
+		return err
+	}
+	s.keys.Delete(k.Prefix)
+	slog.InfoContext(ctx, "api key revoked", "prefix", k.Prefix, "name", k.Name)
+	revoked := *k
+	now := s.now()
+	revoked.RevokedAt, revoked.RevokedByUserID = &now, &actor.ID
+	s.audit.Record(ctx, audit.Entry{
+		Action: "apikey.revoke", EntityType: audit.EntityAPIKey, EntityID: k.ID.String(),
+		ShopID: k.ShopID, Before: k, After: &revoked,
+	})
+	return nil
+}
+
+/* ---------- authentication ---------- */
+
+func (s *service) Authenticate(ctx context.Context, key string) (*auth.AuthUser, error) {
+	prefix, ok := parseKey(key)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	k, ok := s.keys.Get(prefix)
+	if !ok {
+		var err error
+		k, err = s.repo.GetByPrefix(ctx, prefix)
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrInvalidKey
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.keys.Set(prefix, k)
+	}
+
+	if !matches(key, k.KeyHash) {
+		return nil, ErrInvalidKey
+	}
+	if k.IsRevoked() {
+		return nil, ErrKeyRevoked
+	}
+	if k.IsExpired(s.now()) {
+		return nil, ErrKeyExpired
+	}
+
+	// Record use (asynchronous, at most once per lastUsedInterval per key)
+	if _, recent := s.lastUsed.Get(k.ID); !recent {
+		s.lastUsed.Set(k.ID, struct{}{})
+		go func(ctx context.Context, id uuid.UUID) {
+			if err := s.repo.TouchLastUsed(ctx, id); err != nil {
+				slog.ErrorContext(ctx, "failed to record api key use", "api_key_id", id, "error", err)
+			}
+		}(context.WithoutCancel(ctx), k.ID)
+	}
+
+	id := k.ID
+	return &auth.AuthUser{
+		ID:         k.ID,
+		Email:      "apikey:" + k.Prefix, // shows up where actors are logged
+		ExternalID: "apikey:" + k.Prefix,
+		ShopID:     k.ShopID,
+		IsActive:   true,
+		APIKeyID:   &id,
+		Scopes:     slices.Clone(k.Scopes),
+	}, nil
+}
@@ -0,0 +1,302 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/shop"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* ---------- fakes ---------- */
+
+type fakeRepo struct {
+	mu      sync.Mutex
+	keys    map[uuid.UUID]*APIKey
+	lookups int
+	touched chan uuid.UUID
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{keys: map[uuid.UUID]*APIKey{}, touched: make(chan uuid.UUID, 10)}
+}
+
+func (f *fakeRepo) Create(_ context.Context, k *APIKey) (*APIKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.keys {
+		if existing.Prefix == k.Prefix {
+			return nil, ErrConflict
+		}
+	}
+	c := *k
+	c.ID = uuid.New()
+	c.CreatedAt = time.Now()
+	f.keys[c.ID] = &c
+	out := c
+	return &out, nil
+}
+
+func (f *fakeRepo) GetByID(_ context.Context, id uuid.UUID) (*APIKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k, ok := f.keys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := *k
+	return &out, nil
+}
+
+func (f *fakeRepo) GetByPrefix(_ context.Context, prefix string) (*APIKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lookups++
+	for _, k := range f.keys {
+		if k.Prefix == prefix {
+			out := *k
+			return &out, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (f *fakeRepo) List(context.Context) ([]*APIKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	list := make([]*APIKey, 0, len(f.keys))
+	for _, k := range f.keys {
+		out := *k
+		list = append(list, &out)
+	}
+	return list, nil
+}
+
+func (f *fakeRepo) Revoke(_ context.Context, id uuid.UUID, by uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k, ok := f.keys[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if k.RevokedAt != nil {
+		return ErrAlreadyRevoked
+	}
+	now := time.Now()
+	k.RevokedAt, k.RevokedByUserID = &now, &by
+	return nil
+}
+
+func (f *fakeRepo) TouchLastUsed(_ context.Context, id uuid.UUID) error {
+	f.touched <- id
+	return nil
+}
+
+type fakeShops struct {
+	shop.ShopService
+	codes map[string]uuid.UUID
+}
+
+func (f *fakeShops) GetShopIDByCode(_ context.Context, code string) (uuid.UUID, error) {
+	if id, ok := f.codes[code]; ok {
+		return id, nil
+	}
+	return uuid.Nil, shop.ErrNotFound
+}
+
+/* ---------- helpers ---------- */
+
+var (
+	superAdmin = &auth.AuthUser{ID: uuid.New(), Email: "root@example.com", RoleCode: auth.RoleSuperAdmin, IsActive: true}
+	admin      = &auth.AuthUser{ID: uuid.New(), Email: "admin@example.com", RoleCode: auth.RoleAdmin, IsActive: true}
+)
+
+func newTestService() (*service, *fakeRepo, uuid.UUID) {
+	repo := newFakeRepo()
+	shopID := uuid.New()
+	svc := NewService(repo, &fakeShops{codes: map[string]uuid.UUID{"S001": shopID}}, nil).(*service)
+	return svc, repo, shopID
+}
+
+/* ---------- tests ---------- */
+
+func TestCreate(t *testing.T) {
+	svc, repo, shopID := newTestService()
+	code := "S001"
+
+	created, err := svc.Create(context.Background(), superAdmin, &CreateKeyInput{
+		Name:     " scan worker ",
+		Scopes:   []string{auth.ScopeScansCallback, auth.ScopeImagesWrite, auth.ScopeScansCallback},
+		ShopCode: &code,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(created.Key, "hz_"+created.Prefix+"_"))
+	assert.Equal(t, "scan worker", created.Name)
+	assert.Equal(t, []string{auth.ScopeScansCallback, auth.ScopeImagesWrite}, created.Scopes)
+	assert.Equal(t, &shopID, created.ShopID)
+	assert.Equal(t, &superAdmin.ID, created.CreatedByUserID)
+
+	// Only the hash is stored
+	stored := repo.keys[created.ID]
+	assert.Equal(t, hashKey(created.Key), stored.KeyHash)
+	assert.NotContains(t, string(stored.KeyHash), created.Key)
+}
+
+func TestCreateValidation(t *testing.T) {
+	svc, _, _ := newTestService()
+	past := time.Now().Add(-time.Hour)
+	unknown := "NOPE"
+
+	tests := map[string]struct {
+		in    CreateKeyInput
+		field string
+	}{
+		"blank name":     {CreateKeyInput{Name: " ", Scopes: []string{auth.ScopeWorkOrdersRead}}, "name"},
+		"long name":      {CreateKeyInput{Name: strings.Repeat("x", 101), Scopes: []string{auth.ScopeWorkOrdersRead}}, "name"},
+		"no scopes":      {CreateKeyInput{Name: "k"}, "scopes"},
+		"unknown scope":  {CreateKeyInput{Name: "k", Scopes: []string{"workorders:delete"}}, "scopes"},
+		"unknown shop":   {CreateKeyInput{Name: "k", Scopes: []string{auth.ScopeWorkOrdersRead}, ShopCode: &unknown}, "shopCode"},
+		"expiry in past": {CreateKeyInput{Name: "k", Scopes: []string{auth.ScopeWorkOrdersRead}, ExpiresAt: &past}, "expiresAt"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.Create(context.Background(), superAdmin, &tt.in)
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			assert.Equal(t, tt.field, verr.Field)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
+
+func TestManagementRequiresSuperAdmin(t *testing.T) {
+	svc, _, _ := newTestService()
+	ctx := context.Background()
+
+	_, err := svc.List(ctx, admin)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.Get(ctx, admin, uuid.New())
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.Create(ctx, admin, &CreateKeyInput{Name: "k", Scopes: []string{auth.ScopeWorkOrdersRead}})
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, svc.Revoke(ctx, admin, uuid.New()), ErrForbidden)
+}
+
+func TestAuthenticate(t *testing.T) {
+	svc, repo, shopID := newTestService()
+	ctx := context.Background()
+	code := "S001"
+	created, err := svc.Create(ctx, superAdmin, &CreateKeyInput{Name: "reports", Scopes: []string{auth.ScopeWorkOrdersRead}, ShopCode: &code})
+	require.NoError(t, err)
+
+	p, err := svc.Authenticate(ctx, created.Key)
+	require.NoError(t, err)
+	assert.True(t, p.IsAPIKey())
+	assert.Equal(t, created.ID, *p.APIKeyID)
+	assert.Equal(t, &shopID, p.ShopID)
+	assert.Empty(t, p.RoleCode)
+	assert.True(t, p.HasScope(auth.ScopeWorkOrdersRead))
+	assert.False(t, p.HasScope(auth.ScopeImagesWrite))
+
+	select {
+	case id := <-repo.touched:
+		assert.Equal(t, created.ID, id)
+	case <-time.After(time.Second):
+		t.Fatal("last_used_at not recorded")
+	}
+
+	// Cached, and last use is coalesced
+	_, err = svc.Authenticate(ctx, created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.lookups)
+	assert.Empty(t, repo.touched)
+}
+
+func TestAuthenticateRejects(t *testing.T) {
+	svc, repo, _ := newTestService()
+	ctx := context.Background()
+	created, err := svc.Create(ctx, superAdmin, &CreateKeyInput{Name: "k", Scopes: []string{auth.ScopeWorkOrdersRead}})
+	require.NoError(t, err)
+
+	// Same prefix, wrong secret
+	forged := created.Key[:len(created.Key)-4] + "aaaa"
+	if forged == created.Key {
+		forged = created.Key[:len(created.Key)-4] + "bbbb"
+	}
+	_, err = svc.Authenticate(ctx, forged)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = svc.Authenticate(ctx, "hz_short_key")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	unknown, _, _, err := generateKey()
+	require.NoError(t, err)
+	_, err = svc.Authenticate(ctx, unknown)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	// Expired
+	expired := time.Now().Add(time.Hour)
+	repo.keys[created.ID].ExpiresAt = &expired
+	svc.keys.Purge()
+	svc.now = func() time.Time { return expired.Add(time.Second) }
+	_, err = svc.Authenticate(ctx, created.Key)
+	assert.ErrorIs(t, err, ErrKeyExpired)
+	svc.now = time.Now
+
+	// Revocation takes effect immediately on this instance, despite the cache
+	require.NoError(t, svc.Revoke(ctx, superAdmin, created.ID))
+	_, err = svc.Authenticate(ctx, created.Key)
+	assert.ErrorIs(t, err, ErrKeyRevoked)
+	assert.ErrorIs(t, svc.Revoke(ctx, superAdmin, created.ID), ErrAlreadyRevoked)
+}
+
+func TestParseKey(t *testing.T) {
+	key, prefix, hash, err := generateKey()
+	require.NoError(t, err)
+	assert.Len(t, prefix, 8)
+	assert.True(t, matches(key, hash))
+
+	got, ok := parseKey(key)
+	assert.True(t, ok)
+	assert.Equal(t, prefix, got)
+
+	for _, bad := range []string{"", "hz_", "hz_abc", "abc_" + key[3:], key + "x", strings.Replace(key, "_", "-", 2)} {
+		_, ok := parseKey(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+// auditLog collects audit entries in memory.
+type auditLog struct{ entries []audit.Entry }
+
+func (l *auditLog) Record(_ context.Context, e audit.Entry) { l.entries = append(l.entries, e) }
+
+// Test: creating and revoking keys is audited, without the key itself
+func TestKeyChangesAreAudited(t *testing.T) {
+	log := &auditLog{}
+	svc := NewService(newFakeRepo(), &fakeShops{}, log)
+	ctx := context.Background()
+
+	created, err := svc.Create(ctx, superAdmin, &CreateKeyInput{Name: "exporter", Scopes: []string{auth.ScopeWorkOrdersRead}})
+	require.NoError(t, err)
+	require.NoError(t, svc.Revoke(ctx, superAdmin, created.ID))
+	assert.ErrorIs(t, svc.Revoke(ctx, superAdmin, created.ID), ErrAlreadyRevoked)
+
+	require.Len(t, log.entries, 2)
+	create, revoke := log.entries[0], log.entries[1]
+	assert.Equal(t, "apikey.create", create.Action)
+	assert.Equal(t, created.ID.String(), create.EntityID)
+	assert.IsType(t, &APIKey{}, create.After, "the plaintext key is not recorded")
+
+	assert.Equal(t, "apikey.revoke", revoke.Action)
+	assert.Nil(t, revoke.Before.(*APIKey).RevokedAt)
+	assert.Equal(t, &superAdmin.ID, revoke.After.(*APIKey).RevokedByUserID)
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// snapshot marshals v to JSON, or returns nil for a nil v.
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// diff compares two JSON objects field by field (top level only). Timestamps
+// maintained by the database (updatedAt) are left out: they change on every
+// write and say nothing about what the caller changed.
+func diff(before, after json.RawMessage) map[string]Change {
+	var b, a map[string]any
+	_ = json.Unmarshal(before, &b)
+	_ = json.Unmarshal(after, &a)
+
+	changes := make(map[string]Change)
+	for k, av := range a {
+		if bv, ok := b[k]; !ok || !reflect.DeepEqual(bv, av) {
+			changes[k] = Change{From: b[k], To: av}
+		}
+	}
+	for k, bv := range b {
+		if _, ok := a[k]; !ok {
+			changes[k] = Change{From: bv, To: nil}
+		}
+	}
+	delete(changes, "updatedAt")
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
@@ -0,0 +1,33 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain-level errors for audit queries
+var (
+	ErrInvalidInput = errors.New("invalid audit query")
+	ErrForbidden    = errors.New("forbidden: insufficient permissions")
+)
+
+// ValidationError represents validation errors with specific field information
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap allows errors.Is to work with ValidationError
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// NewValidationError creates a new ValidationError
+func NewValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
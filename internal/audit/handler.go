@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+/* -------------------- Handler Struct -------------------- */
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// RegisterRoutes mounts the audit routes (under /audit).
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/", h.list)
+	r.Get("/{entityType}/{entityID}", h.history)
+}
+
+/* -------------------- Handlers -------------------- */
+
+// list handles GET /audit?actorId=&action=&entityType=&entityId=&from=&to=&limit=&offset=
+// from and to are RFC 3339 timestamps.
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	f := Filter{
+		Action:     q.Get("action"),
+		EntityType: q.Get("entityType"),
+		EntityID:   q.Get("entityId"),
+		Limit:      atoiDefault(q.Get("limit"), defaultLimit),
+		Offset:     atoiDefault(q.Get("offset"), 0),
+	}
+	if v := q.Get("actorId"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			writeError(w, r, NewValidationError("actorId", "invalid user ID"))
+			return
+		}
+		f.ActorUserID = &id
+	}
+	for name, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(w, r, NewValidationError(name, "must be an RFC 3339 timestamp"))
+				return
+			}
+			*dst = &t
+		}
+	}
+
+	page, err := h.svc.List(r.Context(), actor, f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// history handles GET /audit/{entityType}/{entityID}?limit=&offset=
+func (h *Handler) history(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	page, err := h.svc.History(r.Context(), actor,
+		chi.URLParam(r, "entityType"), chi.URLParam(r, "entityID"),
+		atoiDefault(q.Get("limit"), defaultLimit), atoiDefault(q.Get("offset"), 0))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+/* -------------------- Helpers -------------------- */
+
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// writeError classifies known domain errors and delegates to httpError.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
+
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		httpError(w, http.StatusBadRequest, err.Error())
+
+	case errors.Is(err, ErrForbidden):
+		httpError(w, http.StatusForbidden, err.Error())
+
+	default:
+		httpError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
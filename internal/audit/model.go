@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity types
+const (
+	EntityUser         = "user"
+	EntityShop         = "shop"
+	EntityWorkOrder    = "workorder"
+	EntityEstimate     = "estimate"
+	EntityInvoice      = "invoice"
+	EntityRole         = "role"
+	EntityAPIKey       = "apikey"
+	EntityOrganization = "organization"
+)
+
+// Entry is what a service records about one mutation. Before and After are
+// the entity as the API returns it (nil for creates and deletes); the
+// recorder adds the actor and request details from ctx.
+type Entry struct {
+	Action     string // "<entity>.<verb>", e.g. "user.deactivate"
+	EntityType string
+	EntityID   string
+	// ShopID and OrganizationID scope who may read the entry. The
+	// organization defaults to the shop's.
+	ShopID         *uuid.UUID
+	OrganizationID *uuid.UUID
+	Before         any
+	After          any
+}
+
+// Change is one changed top-level field.
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Record ↔ app.audit_log
+type Record struct {
+	ID             int64             `json:"id"`
+	OccurredAt     time.Time         `json:"occurredAt"`
+	ActorUserID    *uuid.UUID        `json:"actorUserId,omitempty"`
+	ActorAPIKeyID  *uuid.UUID        `json:"actorApiKeyId,omitempty"`
+	ActorEmail     string            `json:"actorEmail,omitempty"`
+	ActorRole      string            `json:"actorRole,omitempty"`
+	Action         string            `json:"action"`
+	EntityType     string            `json:"entityType"`
+	EntityID       string            `json:"entityId"`
+	ShopID         *uuid.UUID        `json:"shopId,omitempty"`
+	OrganizationID *uuid.UUID        `json:"organizationId,omitempty"`
+	Before         json.RawMessage   `json:"before,omitempty"`
+	After          json.RawMessage   `json:"after,omitempty"`
+	Changes        map[string]Change `json:"changes,omitempty"`
+	IP             string            `json:"ip,omitempty"`
+	UserAgent      string            `json:"userAgent,omitempty"`
+	RequestID      string            `json:"requestId,omitempty"`
+}
+
+// Filter narrows GET /audit and the per-entity history. Zero values mean "no
+// restriction".
+type Filter struct {
+	// Set by the service from the caller's scope
+	ShopID         *uuid.UUID
+	OrganizationID *uuid.UUID
+
+	ActorUserID *uuid.UUID
+	Action      string
+	EntityType  string
+	EntityID    string
+	From        *time.Time // occurred_at >= From
+	To          *time.Time // occurred_at < To
+	Limit       int
+	Offset      int
+}
+
+// Page is one page of audit records, newest first.
+type Page struct {
+	Items  []*Record `json:"items"`
+	Total  int       `json:"total"`
+	Limit  int       `json:"limit"`
+	Offset int       `json:"offset"`
+}
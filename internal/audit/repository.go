@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository defines the persistence contract for the audit log. There is no
+// update or delete: the table is append-only.
+type Repository interface {
+	Insert(ctx context.Context, rec *Record) error
+	List(ctx context.Context, f Filter) ([]*Record, int, error)
+}
+
+type pgRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository constructs a Postgres-backed audit repository.
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgRepo{db: db}
+}
+
+// Insert appends rec. A nil OrganizationID is filled from the shop.
+func (r *pgRepo) Insert(ctx context.Context, rec *Record) error {
+	var changes []byte
+	if rec.Changes != nil {
+		var err error
+		if changes, err = json.Marshal(rec.Changes); err != nil {
+			return err
+		}
+	}
+
+	const q = `
+INSERT INTO app.audit_log (
+    actor_user_id, actor_api_key_id, actor_email, actor_role,
+    action, entity_type, entity_id, shop_id, organization_id,
+    before, after, changes, ip, user_agent, request_id
+) VALUES (
+    $1, $2, NULLIF($3, ''), NULLIF($4, ''),
+    $5, $6, $7, $8, COALESCE($9, (SELECT organization_id FROM app.shop WHERE id = $8)),
+    $10, $11, $12, NULLIF($13, '')::inet, NULLIF($14, ''), NULLIF($15, '')
+)`
+	_, err := r.db.Exec(ctx, q,
+		rec.ActorUserID, rec.ActorAPIKeyID, rec.ActorEmail, rec.ActorRole,
+		rec.Action, rec.EntityType, rec.EntityID, rec.ShopID, rec.OrganizationID,
+		nullJSON(rec.Before), nullJSON(rec.After), nullJSON(changes),
+		rec.IP, rec.UserAgent, rec.RequestID,
+	)
+	return err
+}
+
+// nullJSON keeps an absent snapshot NULL instead of an empty jsonb value.
+func nullJSON(b []byte) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
+
+func (r *pgRepo) List(ctx context.Context, f Filter) ([]*Record, int, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.ShopID != nil {
+		add("shop_id = $%d", *f.ShopID)
+	}
+	if f.OrganizationID != nil {
+		add("organization_id = $%d", *f.OrganizationID)
+	}
+	if f.ActorUserID != nil {
+		add("actor_user_id = $%d", *f.ActorUserID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.EntityType != "" {
+		add("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("entity_id = $%d", f.EntityID)
+	}
+	if f.From != nil {
+		add("occurred_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("occurred_at < $%d", *f.To)
+	}
+	clause := ""
+	if len(where) > 0 {
+		clause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT count(*) FROM app.audit_log`+clause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	q := `
+SELECT id, occurred_at, actor_user_id, actor_api_key_id,
+       COALESCE(actor_email, ''), COALESCE(actor_role, ''),
+       action, entity_type, entity_id, shop_id, organization_id,
+       before, after, changes,
+       COALESCE(host(ip), ''), COALESCE(user_agent, ''), COALESCE(request_id, '')
+FROM app.audit_log` + clause + fmt.Sprintf(`
+ORDER BY occurred_at DESC, id DESC
+LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := make([]*Record, 0)
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, rec)
+	}
+	return list, total, rows.Err()
+}
+
+func scanRecord(row pgx.Row) (*Record, error) {
+	var (
+		rec                   Record
+		before, after, change []byte // NULL stays nil
+	)
+	if err := row.Scan(
+		&rec.ID, &rec.OccurredAt, &rec.ActorUserID, &rec.ActorAPIKeyID,
+		&rec.ActorEmail, &rec.ActorRole,
+		&rec.Action, &rec.EntityType, &rec.EntityID, &rec.ShopID, &rec.OrganizationID,
+		&before, &after, &change,
+		&rec.IP, &rec.UserAgent, &rec.RequestID,
+	); err != nil {
+		return nil, err
+	}
+	rec.Before, rec.After = before, after
+	if len(change) > 0 {
+		if err := json.Unmarshal(change, &rec.Changes); err != nil {
+			return nil, err
+		}
+	}
+	return &rec, nil
+}
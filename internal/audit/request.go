@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestInfo is where a mutation came from.
+type RequestInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type ctxKey int
+
+const requestInfoKey ctxKey = iota
+
+// WithRequestInfo stores info in ctx for the recorder.
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey, info)
+}
+
+// RequestInfoFrom returns the info stored by WithRequestInfo (zero outside a
+// request, e.g. in background jobs).
+func RequestInfoFrom(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey).(RequestInfo)
+	return info
+}
+
+// Middleware captures the client IP, user agent and request ID (from chi's
+// RequestID middleware, which must run first) for every request. The IP is
+// the connection's peer address; put chi's RealIP in front of it when the
+// service runs behind a trusted proxy.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+		ctx := WithRequestInfo(r.Context(), RequestInfo{
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+			RequestID: middleware.GetReqID(r.Context()),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,179 @@
+// Package audit keeps the append-only change history in app.audit_log. The
+// services that change users, shops, work orders, billing, roles, API keys and
+// organizations report their mutations through a Recorder; Service answers the
+// history queries behind GET /audit.
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+)
+
+// Recorder is what mutating services call after a change succeeds.
+type Recorder interface {
+	Record(ctx context.Context, e Entry)
+}
+
+// Nop records nothing. Services fall back to it when constructed without a
+// recorder.
+type Nop struct{}
+
+func (Nop) Record(context.Context, Entry) {}
+
+// OrNop returns rec, or Nop when rec is nil.
+func OrNop(rec Recorder) Recorder {
+	if rec == nil {
+		return Nop{}
+	}
+	return rec
+}
+
+// Service defines the audit operations.
+type Service interface {
+	Recorder
+	// List returns entries across every entity. SuperAdmin only.
+	List(ctx context.Context, actor *auth.AuthUser, f Filter) (*Page, error)
+	// History returns the entries of one entity, limited to the caller's
+	// shop (or organization for org-wide callers).
+	History(ctx context.Context, actor *auth.AuthUser, entityType, entityID string, limit, offset int) (*Page, error)
+}
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+var entityTypes = map[string]bool{
+	EntityUser: true, EntityShop: true, EntityWorkOrder: true, EntityEstimate: true, EntityInvoice: true,
+	EntityRole: true, EntityAPIKey: true, EntityOrganization: true,
+}
+
+const entityTypesMessage = "must be one of user, shop, workorder, estimate, invoice, role, apikey, organization"
+
+type service struct {
+	repo Repository
+	now  func() time.Time
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates an audit service.
+func NewService(repo Repository) Service {
+	return &service{repo: repo, now: time.Now}
+}
+
+// Record writes e with the caller and request details from ctx. Auditing is
+// best-effort: the change it describes has already been committed, so a
+// failed write is logged rather than returned.
+func (s *service) Record(ctx context.Context, e Entry) {
+	rec, err := s.build(ctx, e)
+	if err == nil {
+		err = s.repo.Insert(ctx, rec)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "audit write failed", "action", e.Action, "entity_type", e.EntityType, "entity_id", e.EntityID, "error", err)
+	}
+}
+
+func (s *service) build(ctx context.Context, e Entry) (*Record, error) {
+	before, err := snapshot(e.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := snapshot(e.After)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &Record{
+		OccurredAt:     s.now(),
+		Action:         e.Action,
+		EntityType:     e.EntityType,
+		EntityID:       e.EntityID,
+		ShopID:         e.ShopID,
+		OrganizationID: e.OrganizationID,
+		Before:         before,
+		After:          after,
+	}
+	if before != nil && after != nil {
+		rec.Changes = diff(before, after)
+	}
+	if u, err := auth.GetAuthUser(ctx); err == nil {
+		if u.IsAPIKey() {
+			rec.ActorAPIKeyID = u.APIKeyID
+		} else {
+			id := u.ID
+			rec.ActorUserID = &id
+			rec.ActorEmail = u.Email
+			rec.ActorRole = u.RoleCode
+		}
+	}
+	info := RequestInfoFrom(ctx)
+	rec.IP, rec.UserAgent, rec.RequestID = info.IP, info.UserAgent, info.RequestID
+	return rec, nil
+}
+
+func (s *service) List(ctx context.Context, actor *auth.AuthUser, f Filter) (*Page, error) {
+	if actor == nil || !actor.IsSuperAdmin() {
+		return nil, ErrForbidden
+	}
+	if f.EntityType != "" && !entityTypes[f.EntityType] {
+		return nil, NewValidationError("entityType", entityTypesMessage)
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return nil, NewValidationError("to", "must be after from")
+	}
+	// the organization header narrows a SuperAdmin's view like everywhere else
+	f.ShopID = nil
+	f.OrganizationID = auth.OrganizationScope(ctx)
+	return s.list(ctx, f)
+}
+
+func (s *service) History(ctx context.Context, actor *auth.AuthUser, entityType, entityID string, limit, offset int) (*Page, error) {
+	if actor == nil || !actor.Can(auth.PermAuditRead) {
+		return nil, ErrForbidden
+	}
+	if !entityTypes[entityType] {
+		return nil, NewValidationError("entityType", entityTypesMessage)
+	}
+	if entityID == "" {
+		return nil, NewValidationError("entityId", "is required")
+	}
+
+	f := Filter{EntityType: entityType, EntityID: entityID, Limit: limit, Offset: offset}
+	switch {
+	case actor.IsSuperAdmin():
+		f.OrganizationID = auth.OrganizationScope(ctx)
+	case actor.IsOrgWide():
+		if actor.OrganizationID == nil {
+			return nil, ErrForbidden
+		}
+		f.OrganizationID = actor.OrganizationID
+	default:
+		if actor.ShopID == nil {
+			return nil, ErrForbidden
+		}
+		f.ShopID = actor.ShopID
+	}
+	return s.list(ctx, f)
+}
+
+func (s *service) list(ctx context.Context, f Filter) (*Page, error) {
+	if f.Limit <= 0 {
+		f.Limit = defaultLimit
+	}
+	if f.Limit > maxLimit {
+		f.Limit = maxLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	items, total, err := s.repo.List(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	return &Page{Items: items, Total: total, Limit: f.Limit, Offset: f.Offset}, nil
+}
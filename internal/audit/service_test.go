@@ -0,0 +1,183 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* ---------- fakes ---------- */
+
+type fakeRepo struct {
+	records   []*Record
+	lastQuery Filter
+	err       error
+}
+
+func (r *fakeRepo) Insert(_ context.Context, rec *Record) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.records = append(r.records, rec)
+	return nil
+}
+
+func (r *fakeRepo) List(_ context.Context, f Filter) ([]*Record, int, error) {
+	r.lastQuery = f
+	return r.records, len(r.records), nil
+}
+
+type widget struct {
+	Name      string `json:"name"`
+	Size      int    `json:"size"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+/* ---------- Record ---------- */
+
+func TestRecord(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo)
+	shopID := uuid.New()
+	actor := &auth.AuthUser{ID: uuid.New(), Email: "admin@example.com", RoleCode: auth.RoleAdmin, ShopID: &shopID}
+	ctx := auth.SetAuthUser(context.Background(), actor)
+	ctx = WithRequestInfo(ctx, RequestInfo{IP: "203.0.113.7", UserAgent: "test", RequestID: "req-1"})
+
+	svc.Record(ctx, Entry{
+		Action: "shop.update", EntityType: EntityShop, EntityID: shopID.String(), ShopID: &shopID,
+		Before: widget{Name: "a", Size: 1, UpdatedAt: "t1"},
+		After:  &widget{Name: "b", Size: 1, UpdatedAt: "t2"},
+	})
+
+	require.Len(t, repo.records, 1)
+	rec := repo.records[0]
+	assert.Equal(t, &actor.ID, rec.ActorUserID)
+	assert.Nil(t, rec.ActorAPIKeyID)
+	assert.Equal(t, "admin@example.com", rec.ActorEmail)
+	assert.Equal(t, auth.RoleAdmin, rec.ActorRole)
+	assert.Equal(t, RequestInfo{IP: "203.0.113.7", UserAgent: "test", RequestID: "req-1"},
+		RequestInfo{IP: rec.IP, UserAgent: rec.UserAgent, RequestID: rec.RequestID})
+	assert.JSONEq(t, `{"name":"a","size":1,"updatedAt":"t1"}`, string(rec.Before))
+	assert.Equal(t, map[string]Change{"name": {From: "a", To: "b"}}, rec.Changes, "unchanged fields and updatedAt are left out")
+}
+
+func TestRecordCreateHasNoDiff(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo)
+	var none *widget
+
+	svc.Record(context.Background(), Entry{Action: "shop.create", EntityType: EntityShop, EntityID: "x", Before: none, After: widget{Name: "a"}})
+
+	require.Len(t, repo.records, 1)
+	assert.Nil(t, repo.records[0].Before, "a typed nil is no snapshot")
+	assert.Nil(t, repo.records[0].Changes)
+	assert.Nil(t, repo.records[0].ActorUserID, "no caller in background jobs")
+}
+
+func TestRecordAPIKeyActor(t *testing.T) {
+	repo := &fakeRepo{}
+	keyID := uuid.New()
+	ctx := auth.SetAuthUser(context.Background(), &auth.AuthUser{ID: uuid.New(), APIKeyID: &keyID})
+
+	NewService(repo).Record(ctx, Entry{Action: "workorder.create", EntityType: EntityWorkOrder, EntityID: "x"})
+
+	require.Len(t, repo.records, 1)
+	assert.Equal(t, &keyID, repo.records[0].ActorAPIKeyID)
+	assert.Nil(t, repo.records[0].ActorUserID)
+}
+
+func TestRecordFailureIsNotFatal(t *testing.T) {
+	svc := NewService(&fakeRepo{err: errors.New("db down")})
+	assert.NotPanics(t, func() {
+		svc.Record(context.Background(), Entry{Action: "user.update", EntityType: EntityUser, EntityID: "x"})
+	})
+}
+
+/* ---------- queries ---------- */
+
+func TestList(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo)
+	shopID := uuid.New()
+
+	admin := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleAdmin, ShopID: &shopID, Permissions: []string{auth.PermAuditRead}}
+	_, err := svc.List(context.Background(), admin, Filter{})
+	assert.ErrorIs(t, err, ErrForbidden, "the full log is superadmin only")
+
+	super := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleSuperAdmin}
+	_, err = svc.List(context.Background(), super, Filter{EntityType: "widget"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	org := uuid.New()
+	page, err := svc.List(auth.WithOrganization(context.Background(), org), super, Filter{ShopID: &shopID, Limit: 10000})
+	require.NoError(t, err)
+	assert.Equal(t, maxLimit, page.Limit)
+	assert.Nil(t, repo.lastQuery.ShopID, "callers cannot set the scope")
+	assert.Equal(t, &org, repo.lastQuery.OrganizationID)
+}
+
+func TestHistoryScope(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo)
+	ctx := context.Background()
+	shopID, orgID := uuid.New(), uuid.New()
+	entity := uuid.NewString()
+
+	t.Run("shop admin sees their shop", func(t *testing.T) {
+		admin := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleAdmin, ShopID: &shopID, Permissions: []string{auth.PermAuditRead}}
+		page, err := svc.History(ctx, admin, EntityUser, entity, 0, -5)
+		require.NoError(t, err)
+		assert.Equal(t, defaultLimit, page.Limit)
+		assert.Equal(t, 0, page.Offset)
+		assert.Equal(t, &shopID, repo.lastQuery.ShopID)
+		assert.Nil(t, repo.lastQuery.OrganizationID)
+		assert.Equal(t, entity, repo.lastQuery.EntityID)
+	})
+
+	t.Run("org admin sees their organization", func(t *testing.T) {
+		orgAdmin := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleOrgAdmin, OrganizationID: &orgID,
+			Permissions: []string{auth.PermAuditRead, auth.PermOrgShops}}
+		_, err := svc.History(ctx, orgAdmin, EntityShop, entity, 0, 0)
+		require.NoError(t, err)
+		assert.Nil(t, repo.lastQuery.ShopID)
+		assert.Equal(t, &orgID, repo.lastQuery.OrganizationID)
+	})
+
+	t.Run("needs audit.read", func(t *testing.T) {
+		adjuster := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleAdjuster, ShopID: &shopID}
+		_, err := svc.History(ctx, adjuster, EntityWorkOrder, entity, 0, 0)
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("unknown entity type", func(t *testing.T) {
+		super := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleSuperAdmin}
+		_, err := svc.History(ctx, super, "widget", entity, 0, 0)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
+
+/* ---------- middleware ---------- */
+
+func TestMiddleware(t *testing.T) {
+	var got RequestInfo
+	h := middleware.RequestID(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = RequestInfoFrom(r.Context())
+	})))
+
+	req := httptest.NewRequest(http.MethodPut, "/shops/x", nil)
+	req.RemoteAddr = "198.51.100.4:51234"
+	req.Header.Set("User-Agent", "dashboard/1.0")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "198.51.100.4", got.IP)
+	assert.Equal(t, "dashboard/1.0", got.UserAgent)
+	assert.NotEmpty(t, got.RequestID)
+}
@@ -0,0 +1,610 @@
+package bms
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/estimate"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/google/uuid"
+)
+
+/* ---------- export ---------- */
+
+// FromWorkOrder builds a BMS estimate document for the work order.
+// The estimate is optional; without it only the claim data is exported.
+func FromWorkOrder(d dto.WorkOrderDetail, est *estimate.Estimate, now time.Time) *Document {
+	doc := &Document{
+		XMLName: xml.Name{Local: RootEstimate},
+		Xmlns:   Namespace,
+		RqUID:   uuid.NewString(),
+		DocumentInfo: DocumentInfo{
+			BMSVer:         Version,
+			DocumentType:   "E",
+			DocumentID:     d.Code,
+			CreateDateTime: now.UTC().Format(time.RFC3339),
+		},
+	}
+
+	// Customer names are stored split but only the full name is on the detail;
+	// the first word is taken as the first name.
+	first, last, _ := strings.Cut(strings.TrimSpace(d.Customer.FullName), " ")
+	owner := &PartyInfo{Party: Party{
+		PersonInfo: &PersonInfo{
+			PersonName: PersonName{FirstName: first, LastName: strings.TrimSpace(last)},
+			Communications: []Communications{{
+				CommQualifier: CommAddress,
+				Address: &Address{
+					Address1:      d.Customer.Address,
+					City:          d.Customer.City,
+					StateProvince: d.Customer.Province,
+					PostalCode:    d.Customer.PostalCode,
+					CountryCode:   "CA",
+				},
+			}},
+		},
+		ContactInfo: contactInfo(d.Customer.Phone, d.Customer.Email),
+	}}
+	doc.AdminInfo.Owner = owner
+
+	doc.AdminInfo.RepairFacility = &PartyInfo{Party: Party{OrgInfo: &OrgInfo{
+		CompanyName: d.Shop.ShopName,
+		IDInfo:      &IDInfo{IDQualifierCode: "ShopCode", IDNum: d.Shop.ShopCode},
+	}}}
+
+	if ins := d.Insurance; ins != nil {
+		doc.RefClaimNum = ins.ClaimNumber
+		doc.AdminInfo.InsuranceCompany = &PartyInfo{Party: Party{OrgInfo: &OrgInfo{CompanyName: ins.InsuranceCompany}}}
+		if strings.TrimSpace(ins.AgentFullName) != "" || ins.AgentPhone != "" {
+			af, al, _ := strings.Cut(strings.TrimSpace(ins.AgentFullName), " ")
+			doc.AdminInfo.InsuranceAgent = &PartyInfo{Party: Party{
+				PersonInfo:  &PersonInfo{PersonName: PersonName{FirstName: af, LastName: strings.TrimSpace(al)}},
+				ContactInfo: contactInfo(ins.AgentPhone, ""),
+			}}
+		}
+		doc.ClaimInfo = &ClaimInfo{ClaimNum: ins.ClaimNumber}
+		if ins.PolicyNumber != "" {
+			doc.ClaimInfo.PolicyInfo = &PolicyInfo{PolicyNum: ins.PolicyNumber}
+		}
+	}
+
+	v := d.Vehicle
+	doc.VehicleInfo = VehicleInfo{
+		VehicleDesc: VehicleDesc{ModelYear: strconv.Itoa(v.ModelYear), MakeDesc: v.Make, ModelName: v.Model, BodyStyle: v.BodyStyle},
+	}
+	if v.VIN != "" {
+		doc.VehicleInfo.VINInfo = &VINInfo{VINNum: v.VIN}
+	}
+	if v.PlateNo != "" {
+		doc.VehicleInfo.License = &License{LicensePlateNum: v.PlateNo, LicensePlateStateProvince: d.Customer.Province}
+	}
+	if v.Color != "" {
+		doc.VehicleInfo.Paint = &Paint{ColorName: v.Color}
+	}
+
+	if est != nil {
+		addEstimate(doc, est)
+	}
+	return doc
+}
+
+func addEstimate(doc *Document, est *estimate.Estimate) {
+	for _, l := range est.Lines {
+		line := DamageLineInfo{LineNum: l.LineNo, LineDesc: l.Description}
+		taxable := boolInd(l.Taxable)
+		qty := formatDecimal(l.Quantity)
+		switch l.Category {
+		case estimate.CategoryPDR, estimate.CategoryRAndI, estimate.CategoryPaint:
+			li := &LaborInfo{LaborType: LaborBody, LaborHours: qty, LaborAmt: formatMoney(l.AmountCents), TaxableInd: taxable}
+			switch l.Category {
+			case estimate.CategoryPDR:
+				li.LaborOperation = OperationPDR
+			case estimate.CategoryRAndI:
+				li.LaborOperation = OperationRemoveInstl
+			case estimate.CategoryPaint:
+				li.LaborType = LaborRefinish
+			}
+			line.LaborInfo = li
+		case estimate.CategoryParts:
+			line.PartInfo = &PartInfo{PartType: PartTypeOEM, Quantity: qty, PartPrice: formatMoney(l.UnitPriceCents), TaxableInd: taxable}
+		case estimate.CategorySublet:
+			line.OtherChargesInfo = &OtherChargesInfo{OtherChargesType: OtherChargeSublet, Quantity: qty, Price: formatMoney(l.UnitPriceCents), TaxableInd: taxable}
+		default:
+			line.OtherChargesInfo = &OtherChargesInfo{OtherChargesType: OtherChargeOther, Quantity: qty, Price: formatMoney(l.UnitPriceCents), TaxableInd: taxable}
+		}
+		doc.DamageLines = append(doc.DamageLines, line)
+	}
+
+	if est.DeductibleCents > 0 {
+		if doc.ClaimInfo == nil {
+			doc.ClaimInfo = &ClaimInfo{}
+		}
+		if doc.ClaimInfo.PolicyInfo == nil {
+			doc.ClaimInfo.PolicyInfo = &PolicyInfo{}
+		}
+		doc.ClaimInfo.PolicyInfo.CoverageInfo = &CoverageInfo{Coverage: []Coverage{{
+			CoverageCategory: "C", // collision
+			DeductibleInfo:   &DeductibleInfo{DeductibleAmt: formatMoney(est.DeductibleCents)},
+		}}}
+	}
+
+	if est.Tax != nil {
+		doc.RepairTotals = &RepairTotalsInfo{SummaryTotalsInfo: []SummaryTotalsInfo{
+			{TotalType: TotalSubtotal, TotalTypeDesc: "Subtotal", TotalAmt: formatMoney(est.Tax.SubtotalCents)},
+			{TotalType: TotalTax, TotalTypeDesc: "Sales tax (" + est.Tax.Province + ")", TotalAmt: formatMoney(est.Tax.TaxCents)},
+			{TotalType: TotalGross, TotalTypeDesc: "Gross total", TotalAmt: formatMoney(est.Tax.TotalCents)},
+		}}
+		if est.DeductibleCents > 0 {
+			doc.RepairTotals.SummaryTotalsInfo = append(doc.RepairTotals.SummaryTotalsInfo,
+				SummaryTotalsInfo{TotalType: TotalDeductible, TotalTypeDesc: "Deductible", TotalAmt: formatMoney(est.DeductibleCents)})
+		}
+	}
+}
+
+func contactInfo(phone, email string) *ContactInfo {
+	var comms []Communications
+	if phone != "" {
+		comms = append(comms, Communications{CommQualifier: CommCellPhone, CommPhone: phone})
+	}
+	if email != "" {
+		comms = append(comms, Communications{CommQualifier: CommEmail, CommEmail: email})
+	}
+	if len(comms) == 0 {
+		return nil
+	}
+	return &ContactInfo{Communications: comms}
+}
+
+// Marshal encodes the document with an XML declaration.
+func Marshal(doc *Document) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encode BMS: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+/* ---------- import ---------- */
+
+// Parse decodes a BMS estimate or assignment document.
+func Parse(r io.Reader) (*Document, error) {
+	var doc Document
+	dec := xml.NewDecoder(r)
+	// Insurer exports are usually UTF-8 but some older systems still send ISO-8859-1.
+	dec.CharsetReader = charsetReader
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	switch doc.XMLName.Local {
+	case RootAssignment, RootEstimate:
+	default:
+		return nil, fmt.Errorf("%w: unsupported root element %q", ErrInvalidDocument, doc.XMLName.Local)
+	}
+	return &doc, nil
+}
+
+// Intake is what an incoming BMS document maps to.
+type Intake struct {
+	Payload         dto.IntakePayload
+	Lines           []estimate.LineInput
+	DeductibleCents int64
+	// ShopCode is the repair facility ID from the document, if any.
+	ShopCode string
+	Warnings []string
+}
+
+// FieldError describes one unusable field in an incoming document.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string { return e.Field + ": " + e.Message }
+
+// ValidationErrors collects every problem found in a document.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, len(v))
+	for i, e := range v {
+		msgs[i] = e.Error()
+	}
+	return "invalid BMS document: " + strings.Join(msgs, "; ")
+}
+
+func (v ValidationErrors) Unwrap() error { return ErrInvalidDocument }
+
+// ToIntake maps a parsed document onto an intake payload and estimate lines.
+// Every missing required field is reported, not just the first.
+func ToIntake(doc *Document) (*Intake, error) {
+	var errs ValidationErrors
+	require := func(field, value string) string {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			errs = append(errs, FieldError{Field: field, Message: "is required"})
+		}
+		return value
+	}
+
+	in := &Intake{}
+
+	// --- owner / customer ---
+	owner := doc.AdminInfo.Owner
+	if owner == nil || owner.Party.PersonInfo == nil {
+		errs = append(errs, FieldError{Field: "AdminInfo/Owner", Message: "owner person is required"})
+		owner = &PartyInfo{Party: Party{PersonInfo: &PersonInfo{}}}
+	}
+	person := owner.Party.PersonInfo
+	addr := findAddress(person.Communications)
+	phone, email := findContact(owner.Party.ContactInfo, person.Communications)
+	in.Payload.Customer = dto.CustomerIntake{
+		FirstName:  require("Owner/PersonName/FirstName", person.PersonName.FirstName),
+		LastName:   require("Owner/PersonName/LastName", person.PersonName.LastName),
+		Address:    require("Owner/Address/Address1", addr.Address1),
+		City:       require("Owner/Address/City", addr.City),
+		PostalCode: strings.ToUpper(require("Owner/Address/PostalCode", addr.PostalCode)),
+		Province:   strings.ToUpper(require("Owner/Address/StateProvince", addr.StateProvince)),
+		Email:      email,
+		Phone:      digitsOnly(phone),
+	}
+
+	// --- vehicle ---
+	vi := doc.VehicleInfo
+	year, err := strconv.Atoi(strings.TrimSpace(vi.VehicleDesc.ModelYear))
+	if err != nil {
+		errs = append(errs, FieldError{Field: "VehicleDesc/ModelYear", Message: "must be a year"})
+	}
+	var vin, plate, color string
+	if vi.VINInfo != nil {
+		vin = vi.VINInfo.VINNum
+	}
+	if vi.License != nil {
+		plate = strings.TrimSpace(vi.License.LicensePlateNum)
+	}
+	if vi.Paint != nil {
+		color = strings.TrimSpace(vi.Paint.ColorName)
+	}
+	in.Payload.Vehicle = dto.VehicleIntake{
+		PlateNo:   plate,
+		Make:      require("VehicleDesc/MakeDesc", vi.VehicleDesc.MakeDesc),
+		Model:     require("VehicleDesc/ModelName", vi.VehicleDesc.ModelName),
+		BodyStyle: strings.TrimSpace(vi.VehicleDesc.BodyStyle),
+		ModelYear: year,
+		VIN:       strings.ToUpper(require("VINInfo/VIN/VINNum", vin)),
+		Color:     color,
+	}
+
+	checkFormats(in.Payload, &errs)
+
+	// --- insurance ---
+	ins := &dto.InsuranceIntake{}
+	if ic := doc.AdminInfo.InsuranceCompany; ic != nil && ic.Party.OrgInfo != nil {
+		ins.InsuranceCompany = strings.TrimSpace(ic.Party.OrgInfo.CompanyName)
+	}
+	if ag := doc.AdminInfo.InsuranceAgent; ag != nil {
+		if ag.Party.PersonInfo != nil {
+			ins.AgentFirstName = strings.TrimSpace(ag.Party.PersonInfo.PersonName.FirstName)
+			ins.AgentLastName = strings.TrimSpace(ag.Party.PersonInfo.PersonName.LastName)
+		}
+		var comms []Communications
+		if ag.Party.PersonInfo != nil {
+			comms = ag.Party.PersonInfo.Communications
+		}
+		agentPhone, _ := findContact(ag.Party.ContactInfo, comms)
+		ins.AgentPhone = digitsOnly(agentPhone)
+	}
+	ins.ClaimNumber = strings.TrimSpace(doc.RefClaimNum)
+	if ci := doc.ClaimInfo; ci != nil {
+		if c := strings.TrimSpace(ci.ClaimNum); c != "" {
+			ins.ClaimNumber = c
+		}
+		if pi := ci.PolicyInfo; pi != nil {
+			ins.PolicyNumber = strings.TrimSpace(pi.PolicyNum)
+			in.DeductibleCents = deductible(pi, &errs)
+		}
+	}
+	if !ins.IsEmpty() {
+		if ins.InsuranceCompany == "" {
+			errs = append(errs, FieldError{Field: "AdminInfo/InsuranceCompany", Message: "is required when claim details are present"})
+		}
+		in.Payload.Insurance = ins
+	}
+
+	// --- repair facility ---
+	if rf := doc.AdminInfo.RepairFacility; rf != nil && rf.Party.OrgInfo != nil && rf.Party.OrgInfo.IDInfo != nil {
+		in.ShopCode = strings.TrimSpace(rf.Party.OrgInfo.IDInfo.IDNum)
+	}
+
+	// --- damage lines ---
+	for i, dl := range doc.DamageLines {
+		line, warn, err := lineInput(dl)
+		if err != nil {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("DamageLineInfo[%d]", i+1), Message: err.Error()})
+			continue
+		}
+		if warn != "" {
+			in.Warnings = append(in.Warnings, fmt.Sprintf("line %d: %s", i+1, warn))
+		}
+		if line != nil {
+			in.Lines = append(in.Lines, *line)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return in, nil
+}
+
+// Formats mirror the CHECK constraints on app.customers and app.vehicles, so a bad
+// document is reported field by field instead of as a failed insert.
+var (
+	vinPattern    = regexp.MustCompile(`^[A-HJ-NPR-Z0-9]{17}$`)
+	postalPattern = regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY][0-9][ABCEGHJ-NPRSTV-Z] ?[0-9][ABCEGHJ-NPRSTV-Z][0-9]$`)
+	provinces     = map[string]bool{
+		"AB": true, "BC": true, "MB": true, "NB": true, "NL": true, "NT": true, "NS": true,
+		"NU": true, "ON": true, "PE": true, "QC": true, "SK": true, "YT": true,
+	}
+)
+
+func checkFormats(p dto.IntakePayload, errs *ValidationErrors) {
+	if v := p.Vehicle.VIN; v != "" && !vinPattern.MatchString(v) {
+		*errs = append(*errs, FieldError{Field: "VINInfo/VIN/VINNum", Message: "must be a 17-character VIN"})
+	}
+	if pc := p.Customer.PostalCode; pc != "" && !postalPattern.MatchString(pc) {
+		*errs = append(*errs, FieldError{Field: "Owner/Address/PostalCode", Message: "must be a Canadian postal code"})
+	}
+	if pr := p.Customer.Province; pr != "" && !provinces[pr] {
+		*errs = append(*errs, FieldError{Field: "Owner/Address/StateProvince", Message: "must be a Canadian province code"})
+	}
+	if ph := p.Customer.Phone; ph != "" && (len(ph) < 10 || len(ph) > 11) {
+		*errs = append(*errs, FieldError{Field: "Owner/CommPhone", Message: "must have 10 or 11 digits"})
+	}
+	if y := p.Vehicle.ModelYear; y != 0 && (y < 1900 || y > time.Now().Year()+1) {
+		*errs = append(*errs, FieldError{Field: "VehicleDesc/ModelYear", Message: "is out of range"})
+	}
+}
+
+func deductible(pi *PolicyInfo, errs *ValidationErrors) int64 {
+	if pi.CoverageInfo == nil {
+		return 0
+	}
+	for _, c := range pi.CoverageInfo.Coverage {
+		if c.DeductibleInfo == nil || strings.TrimSpace(c.DeductibleInfo.DeductibleAmt) == "" {
+			continue
+		}
+		cents, err := parseMoney(c.DeductibleInfo.DeductibleAmt)
+		if err != nil {
+			*errs = append(*errs, FieldError{Field: "DeductibleInfo/DeductibleAmt", Message: err.Error()})
+			return 0
+		}
+		return cents
+	}
+	return 0
+}
+
+// lineInput maps one damage line. Lines with nothing billable (e.g. notes) are skipped with a warning.
+func lineInput(dl DamageLineInfo) (*estimate.LineInput, string, error) {
+	desc := strings.TrimSpace(dl.LineDesc)
+	if desc == "" {
+		return nil, "", errors.New("LineDesc is required")
+	}
+
+	switch {
+	case dl.LaborInfo != nil:
+		li := dl.LaborInfo
+		hours, err := parseQuantity(li.LaborHours)
+		if err != nil {
+			return nil, "", fmt.Errorf("LaborHours: %w", err)
+		}
+		if hours == 0 {
+			return nil, "no labour hours, skipped", nil
+		}
+		var amount int64
+		if strings.TrimSpace(li.LaborAmt) != "" {
+			if amount, err = parseMoney(li.LaborAmt); err != nil {
+				return nil, "", fmt.Errorf("LaborAmt: %w", err)
+			}
+		}
+		cat := estimate.CategoryPDR
+		switch {
+		case strings.EqualFold(li.LaborType, LaborRefinish):
+			cat = estimate.CategoryPaint
+		case strings.EqualFold(li.LaborOperation, OperationRemoveInstl):
+			cat = estimate.CategoryRAndI
+		}
+		var warn string
+		if amount == 0 {
+			warn = "no labour amount, unit price left at 0"
+		}
+		return &estimate.LineInput{
+			Category:       cat,
+			Description:    desc,
+			Quantity:       hours,
+			UnitPriceCents: int64(math.Round(float64(amount) / hours)),
+			Taxable:        taxableInd(li.TaxableInd),
+		}, warn, nil
+
+	case dl.PartInfo != nil:
+		return chargeLine(estimate.CategoryParts, desc, dl.PartInfo.Quantity, dl.PartInfo.PartPrice, dl.PartInfo.TaxableInd)
+
+	case dl.OtherChargesInfo != nil:
+		cat := estimate.CategoryOther
+		if strings.EqualFold(dl.OtherChargesInfo.OtherChargesType, OtherChargeSublet) {
+			cat = estimate.CategorySublet
+		}
+		oc := dl.OtherChargesInfo
+		return chargeLine(cat, desc, oc.Quantity, oc.Price, oc.TaxableInd)
+	}
+	return nil, "no labour, part or charge details, skipped", nil
+}
+
+func chargeLine(cat estimate.Category, desc, quantity, price, taxable string) (*estimate.LineInput, string, error) {
+	qty := 1.0
+	if strings.TrimSpace(quantity) != "" {
+		q, err := parseQuantity(quantity)
+		if err != nil {
+			return nil, "", fmt.Errorf("Quantity: %w", err)
+		}
+		qty = q
+	}
+	if qty == 0 {
+		return nil, "zero quantity, skipped", nil
+	}
+	cents, err := parseMoney(price)
+	if err != nil {
+		return nil, "", fmt.Errorf("price: %w", err)
+	}
+	return &estimate.LineInput{Category: cat, Description: desc, Quantity: qty, UnitPriceCents: cents, Taxable: taxableInd(taxable)}, "", nil
+}
+
+func findAddress(comms []Communications) Address {
+	for _, c := range comms {
+		if c.Address != nil {
+			return *c.Address
+		}
+	}
+	return Address{}
+}
+
+// findContact returns the first phone and email from ContactInfo, falling back to
+// the person's own Communications (both layouts appear in the wild).
+func findContact(ci *ContactInfo, personComms []Communications) (phone, email string) {
+	all := personComms
+	if ci != nil {
+		all = append(append([]Communications{}, ci.Communications...), personComms...)
+	}
+	for _, c := range all {
+		if phone == "" && strings.TrimSpace(c.CommPhone) != "" {
+			phone = strings.TrimSpace(c.CommPhone)
+		}
+		if email == "" && strings.TrimSpace(c.CommEmail) != "" {
+			email = strings.TrimSpace(c.CommEmail)
+		}
+	}
+	return phone, email
+}
+
+/* ---------- value helpers ---------- */
+
+// formatMoney renders cents as a BMS decimal amount, e.g. 12345 -> "123.45".
+func formatMoney(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// parseMoney parses a decimal dollar amount into cents without going through float.
+func parseMoney(s string) (int64, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, ",", ""))
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	if s == "" || s == "." {
+		return 0, errors.New("amount is required")
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("%q has more than 2 decimal places", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an amount", s)
+	}
+	f, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an amount", s)
+	}
+	cents := w*100 + f
+	if neg {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+func parseQuantity(s string) (float64, error) {
+	q, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || q < 0 || math.IsNaN(q) || math.IsInf(q, 0) {
+		return 0, fmt.Errorf("%q is not a quantity", s)
+	}
+	return q, nil
+}
+
+func formatDecimal(q float64) string {
+	return strconv.FormatFloat(q, 'f', -1, 64)
+}
+
+func boolInd(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// taxableInd reads a BMS boolean; absent means taxable.
+func taxableInd(s string) *bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	v := s == "1" || strings.EqualFold(s, "true") || strings.EqualFold(s, "Y")
+	return &v
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// charsetReader accepts UTF-8 (handled by encoding/xml) plus ISO-8859-1.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "latin-1":
+		return &latin1Reader{r: input}, nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
+
+type latin1Reader struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	// Each Latin-1 byte becomes at most 2 UTF-8 bytes.
+	n := len(p) / 2
+	if n == 0 {
+		n = 1
+	}
+	if cap(l.buf) < n {
+		l.buf = make([]byte, n)
+	}
+	m, err := l.r.Read(l.buf[:n])
+	out := p[:0]
+	for _, c := range l.buf[:m] {
+		out = append(out, string(rune(c))...)
+	}
+	return len(out), err
+}
@@ -0,0 +1,203 @@
+package bms
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/estimate"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/tax"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var exportedAt = time.Date(2025, time.June, 3, 9, 0, 0, 0, time.UTC)
+
+func parseFixture(t *testing.T) *Document {
+	t.Helper()
+	f, err := os.Open("testdata/assignment.xml")
+	require.NoError(t, err)
+	defer f.Close()
+	doc, err := Parse(f)
+	require.NoError(t, err)
+	return doc
+}
+
+// Test: an insurer assignment maps onto an intake payload and estimate lines
+func TestToIntakeAssignment(t *testing.T) {
+	in, err := ToIntake(parseFixture(t))
+	require.NoError(t, err)
+
+	c := in.Payload.Customer
+	assert.Equal(t, "Dana", c.FirstName)
+	assert.Equal(t, "McAllister", c.LastName)
+	assert.Equal(t, "AB", c.Province)
+	assert.Equal(t, "T3E 0B2", c.PostalCode)
+	assert.Equal(t, "4035550142", c.Phone)
+	assert.Equal(t, "dana@example.com", c.Email)
+
+	v := in.Payload.Vehicle
+	assert.Equal(t, "2HGFC2F59MH512345", v.VIN)
+	assert.Equal(t, 2021, v.ModelYear)
+	assert.Equal(t, "CBX 4471", v.PlateNo)
+	assert.Equal(t, "Aegean Blue", v.Color)
+
+	require.NotNil(t, in.Payload.Insurance)
+	assert.Equal(t, "Prairie Mutual Insurance", in.Payload.Insurance.InsuranceCompany)
+	assert.Equal(t, "CLM-2025-00117", in.Payload.Insurance.ClaimNumber)
+	assert.Equal(t, "AB-449120", in.Payload.Insurance.PolicyNumber)
+	assert.Equal(t, "15875550199", in.Payload.Insurance.AgentPhone)
+	assert.Equal(t, "Priya", in.Payload.Insurance.AgentFirstName)
+
+	assert.Equal(t, "CAL01", in.ShopCode)
+	assert.Equal(t, int64(50000), in.DeductibleCents)
+
+	require.Len(t, in.Lines, 4)
+	assert.Equal(t, estimate.CategoryPDR, in.Lines[0].Category)
+	assert.Equal(t, 4.5, in.Lines[0].Quantity)
+	assert.Equal(t, int64(8500), in.Lines[0].UnitPriceCents)
+	assert.Nil(t, in.Lines[0].Taxable)
+	assert.Equal(t, estimate.CategoryRAndI, in.Lines[1].Category)
+	assert.Equal(t, estimate.CategoryParts, in.Lines[2].Category)
+	assert.Equal(t, 2.0, in.Lines[2].Quantity)
+	assert.Equal(t, int64(6499), in.Lines[2].UnitPriceCents)
+	assert.Equal(t, estimate.CategorySublet, in.Lines[3].Category)
+	assert.Equal(t, 1.0, in.Lines[3].Quantity)
+	require.NotNil(t, in.Lines[3].Taxable)
+	assert.False(t, *in.Lines[3].Taxable)
+
+	// The note-only line is skipped, not rejected.
+	require.Len(t, in.Warnings, 1)
+	assert.Contains(t, in.Warnings[0], "line 5")
+}
+
+// Test: every missing or malformed field is reported at once
+func TestToIntakeValidation(t *testing.T) {
+	doc := parseFixture(t)
+	doc.AdminInfo.Owner.Party.PersonInfo.PersonName.LastName = ""
+	doc.VehicleInfo.VINInfo.VINNum = "NOT-A-VIN"
+	doc.VehicleInfo.VehicleDesc.MakeDesc = " "
+	doc.DamageLines[2].PartInfo.PartPrice = "12.345"
+
+	_, err := ToIntake(doc)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidDocument))
+
+	var verrs ValidationErrors
+	require.True(t, errors.As(err, &verrs))
+	fields := make([]string, len(verrs))
+	for i, e := range verrs {
+		fields[i] = e.Field
+	}
+	assert.ElementsMatch(t, []string{
+		"Owner/PersonName/LastName",
+		"VehicleDesc/MakeDesc",
+		"VINInfo/VIN/VINNum",
+		"DamageLineInfo[3]",
+	}, fields)
+}
+
+// Test: documents that aren't BMS are rejected
+func TestParseRejectsOtherDocuments(t *testing.T) {
+	_, err := Parse(strings.NewReader(`<?xml version="1.0"?><Invoice/>`))
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+
+	_, err = Parse(strings.NewReader(`not xml`))
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+}
+
+// Test: ISO-8859-1 documents from older systems decode correctly
+func TestParseLatin1(t *testing.T) {
+	raw, err := os.ReadFile("testdata/assignment.xml")
+	require.NoError(t, err)
+	raw = bytes.Replace(raw, []byte(`encoding="UTF-8"`), []byte(`encoding="ISO-8859-1"`), 1)
+	raw = bytes.Replace(raw, []byte("<FirstName>Dana</FirstName>"), []byte("<FirstName>Ren\xe9e</FirstName>"), 1)
+
+	doc, err := Parse(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "Renée", doc.AdminInfo.Owner.Party.PersonInfo.PersonName.FirstName)
+}
+
+// Test: an exported work order imports back to the same data
+func TestExportRoundTrip(t *testing.T) {
+	detail := dto.WorkOrderDetail{
+		ID:   uuid.New(),
+		Code: "WO-0042",
+		Customer: dto.CustomerDetail{
+			FullName: "Zoë Lévesque", Address: "9 Rue Saint-Jean", City: "Québec",
+			Province: "QC", PostalCode: "G1R1N8", Email: "zoe@example.com", Phone: "4185550000",
+		},
+		Vehicle: dto.VehicleDetail{PlateNo: "ABC123", Make: "Honda", Model: "Civic", ModelYear: 2021, VIN: "2HGFC2F59MH000000", Color: "Blue"},
+		Shop:    dto.ShopSummary{ShopID: uuid.New(), ShopCode: "QC01", ShopName: "Haven Québec"},
+		Insurance: &dto.InsuranceDetail{
+			InsuranceCompany: "Prairie Mutual", AgentFullName: "Sam Roy", AgentPhone: "5145550100",
+			ClaimNumber: "CLM-1", PolicyNumber: "POL-1",
+		},
+	}
+	est := &estimate.Estimate{
+		Version: 1, Status: estimate.StatusApproved, DeductibleCents: 25000,
+		Lines: []estimate.Line{
+			{LineNo: 1, Category: estimate.CategoryPDR, Description: "Roof dents", Quantity: 3, UnitPriceCents: 9000, AmountCents: 27000, Taxable: true},
+			{LineNo: 2, Category: estimate.CategoryPaint, Description: "Blend fender", Quantity: 1.5, UnitPriceCents: 8000, AmountCents: 12000, Taxable: true},
+			{LineNo: 3, Category: estimate.CategoryParts, Description: "Clip kit", Quantity: 4, UnitPriceCents: 250, AmountCents: 1000, Taxable: true},
+			{LineNo: 4, Category: estimate.CategoryOther, Description: "Storage", Quantity: 1, UnitPriceCents: 5000, AmountCents: 5000, Taxable: false},
+		},
+	}
+	b, err := tax.Calculate("QC", exportedAt, est.TaxLines())
+	require.NoError(t, err)
+	est.Tax = b
+
+	out, err := Marshal(FromWorkOrder(detail, est, exportedAt))
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(out, []byte("<?xml")))
+	assert.Contains(t, string(out), `<VehicleDamageEstimateAddRq xmlns="http://www.cieca.com/BMS">`)
+	assert.Contains(t, string(out), "<TotalType>TOT</TotalType>")
+
+	doc, err := Parse(bytes.NewReader(out))
+	require.NoError(t, err)
+	in, err := ToIntake(doc)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Zoë", in.Payload.Customer.FirstName)
+	assert.Equal(t, "Lévesque", in.Payload.Customer.LastName)
+	assert.Equal(t, "G1R1N8", in.Payload.Customer.PostalCode)
+	assert.Equal(t, detail.Vehicle.VIN, in.Payload.Vehicle.VIN)
+	assert.Equal(t, "QC01", in.ShopCode)
+	assert.Equal(t, "Sam", in.Payload.Insurance.AgentFirstName)
+	assert.Equal(t, "Roy", in.Payload.Insurance.AgentLastName)
+	assert.Equal(t, est.DeductibleCents, in.DeductibleCents)
+	assert.Empty(t, in.Warnings)
+
+	require.Len(t, in.Lines, len(est.Lines))
+	for i, l := range est.Lines {
+		got := in.Lines[i]
+		assert.Equal(t, l.Category, got.Category, "line %d", i+1)
+		assert.Equal(t, l.Description, got.Description)
+		assert.Equal(t, l.Quantity, got.Quantity)
+		assert.Equal(t, l.UnitPriceCents, got.UnitPriceCents)
+		require.NotNil(t, got.Taxable)
+		assert.Equal(t, l.Taxable, *got.Taxable)
+	}
+}
+
+// Test: money conversion is exact in both directions
+func TestMoney(t *testing.T) {
+	assert.Equal(t, "0.00", formatMoney(0))
+	assert.Equal(t, "1234.05", formatMoney(123405))
+	assert.Equal(t, "-0.50", formatMoney(-50))
+
+	for in, want := range map[string]int64{"0": 0, "12": 1200, "12.3": 1230, "1,234.56": 123456, ".99": 99, "-4.10": -410} {
+		got, err := parseMoney(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "abc", "1.234", "1.x"} {
+		_, err := parseMoney(in)
+		assert.Error(t, err, in)
+	}
+}
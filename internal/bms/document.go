@@ -0,0 +1,227 @@
+// Package bms reads and writes CIECA BMS (Business Message Specification) XML,
+// the format Canadian insurers' estimating platforms use to exchange claims.
+//
+// Only the parts of BMS we map to our own data are modelled: AdminInfo parties
+// (owner, insurer, agent, repair facility), ClaimInfo, VehicleInfo, DamageLineInfo
+// and RepairTotalsInfo. Unknown elements in incoming documents are ignored.
+package bms
+
+import "encoding/xml"
+
+const (
+	// Namespace is the BMS XML namespace.
+	Namespace = "http://www.cieca.com/BMS"
+	// Version is the BMS version we write.
+	Version = "5.2.0"
+
+	// RootEstimate is the root element of an estimate document.
+	RootEstimate = "VehicleDamageEstimateAddRq"
+	// RootAssignment is the root element of an insurer assignment.
+	RootAssignment = "AssignmentAddRq"
+)
+
+// Document is a BMS estimate or assignment message.
+type Document struct {
+	XMLName      xml.Name
+	Xmlns        string            `xml:"xmlns,attr,omitempty"`
+	RqUID        string            `xml:"RqUID"`
+	RefClaimNum  string            `xml:"RefClaimNum,omitempty"`
+	DocumentInfo DocumentInfo      `xml:"DocumentInfo"`
+	AdminInfo    AdminInfo         `xml:"AdminInfo"`
+	ClaimInfo    *ClaimInfo        `xml:"ClaimInfo,omitempty"`
+	VehicleInfo  VehicleInfo       `xml:"VehicleInfo"`
+	DamageLines  []DamageLineInfo  `xml:"DamageLineInfo"`
+	RepairTotals *RepairTotalsInfo `xml:"RepairTotalsInfo,omitempty"`
+}
+
+type DocumentInfo struct {
+	BMSVer         string `xml:"BMSVer"`
+	DocumentType   string `xml:"DocumentType"` // E = estimate, A = assignment
+	DocumentID     string `xml:"DocumentID,omitempty"`
+	CreateDateTime string `xml:"CreateDateTime,omitempty"`
+}
+
+type AdminInfo struct {
+	InsuranceCompany *PartyInfo `xml:"InsuranceCompany,omitempty"`
+	Owner            *PartyInfo `xml:"Owner,omitempty"`
+	InsuranceAgent   *PartyInfo `xml:"InsuranceAgent,omitempty"`
+	RepairFacility   *PartyInfo `xml:"RepairFacility,omitempty"`
+}
+
+// PartyInfo wraps a Party, as every AdminInfo role does in BMS.
+type PartyInfo struct {
+	Party Party `xml:"Party"`
+}
+
+type Party struct {
+	PersonInfo  *PersonInfo  `xml:"PersonInfo,omitempty"`
+	OrgInfo     *OrgInfo     `xml:"OrgInfo,omitempty"`
+	ContactInfo *ContactInfo `xml:"ContactInfo,omitempty"`
+}
+
+type PersonInfo struct {
+	PersonName     PersonName       `xml:"PersonName"`
+	Communications []Communications `xml:"Communications,omitempty"`
+}
+
+type PersonName struct {
+	FirstName string `xml:"FirstName,omitempty"`
+	LastName  string `xml:"LastName,omitempty"`
+}
+
+type OrgInfo struct {
+	CompanyName    string           `xml:"CompanyName"`
+	IDInfo         *IDInfo          `xml:"IDInfo,omitempty"`
+	Communications []Communications `xml:"Communications,omitempty"`
+}
+
+type IDInfo struct {
+	IDQualifierCode string `xml:"IDQualifierCode"`
+	IDNum           string `xml:"IDNum"`
+}
+
+type ContactInfo struct {
+	Communications []Communications `xml:"Communications"`
+}
+
+// Communication qualifiers used in Communications.CommQualifier.
+const (
+	CommAddress   = "AL" // address line
+	CommCellPhone = "CP"
+	CommHomePhone = "HP"
+	CommWorkPhone = "WP"
+	CommEmail     = "EM"
+)
+
+type Communications struct {
+	CommQualifier string   `xml:"CommQualifier"`
+	Address       *Address `xml:"Address,omitempty"`
+	CommPhone     string   `xml:"CommPhone,omitempty"`
+	CommEmail     string   `xml:"CommEmail,omitempty"`
+}
+
+type Address struct {
+	Address1      string `xml:"Address1,omitempty"`
+	City          string `xml:"City,omitempty"`
+	StateProvince string `xml:"StateProvince,omitempty"`
+	PostalCode    string `xml:"PostalCode,omitempty"`
+	CountryCode   string `xml:"CountryCode,omitempty"`
+}
+
+type ClaimInfo struct {
+	ClaimNum   string      `xml:"ClaimNum,omitempty"`
+	PolicyInfo *PolicyInfo `xml:"PolicyInfo,omitempty"`
+}
+
+type PolicyInfo struct {
+	PolicyNum    string        `xml:"PolicyNum,omitempty"`
+	CoverageInfo *CoverageInfo `xml:"CoverageInfo,omitempty"`
+}
+
+type CoverageInfo struct {
+	Coverage []Coverage `xml:"Coverage"`
+}
+
+type Coverage struct {
+	CoverageCategory string          `xml:"CoverageCategory,omitempty"`
+	DeductibleInfo   *DeductibleInfo `xml:"DeductibleInfo,omitempty"`
+}
+
+type DeductibleInfo struct {
+	DeductibleAmt string `xml:"DeductibleAmt"`
+}
+
+type VehicleInfo struct {
+	VINInfo     *VINInfo    `xml:"VINInfo,omitempty"`
+	License     *License    `xml:"License,omitempty"`
+	VehicleDesc VehicleDesc `xml:"VehicleDesc"`
+	Paint       *Paint      `xml:"Paint,omitempty"`
+}
+
+type VINInfo struct {
+	VINNum string `xml:"VIN>VINNum"`
+}
+
+type License struct {
+	LicensePlateNum           string `xml:"LicensePlateNum"`
+	LicensePlateStateProvince string `xml:"LicensePlateStateProvince,omitempty"`
+}
+
+type VehicleDesc struct {
+	ModelYear string `xml:"ModelYear"`
+	MakeDesc  string `xml:"MakeDesc"`
+	ModelName string `xml:"ModelName"`
+	BodyStyle string `xml:"BodyStyle,omitempty"`
+}
+
+type Paint struct {
+	ColorName string `xml:"Exterior>Color>ColorName"`
+}
+
+// Labor types used in LaborInfo.LaborType.
+const (
+	LaborBody     = "LAB"
+	LaborRefinish = "LAR"
+)
+
+// Labor operations we write to tell our labour categories apart on re-import.
+// Other systems read these lines as plain body labour (LAB).
+const (
+	OperationPDR         = "PDR"
+	OperationRemoveInstl = "R&I"
+)
+
+// Part and other charge types.
+const (
+	PartTypeOEM       = "PAN"
+	OtherChargeSublet = "SUB"
+	OtherChargeOther  = "OTH"
+)
+
+type DamageLineInfo struct {
+	LineNum          int               `xml:"LineNum"`
+	LineDesc         string            `xml:"LineDesc"`
+	LaborInfo        *LaborInfo        `xml:"LaborInfo,omitempty"`
+	PartInfo         *PartInfo         `xml:"PartInfo,omitempty"`
+	OtherChargesInfo *OtherChargesInfo `xml:"OtherChargesInfo,omitempty"`
+}
+
+type LaborInfo struct {
+	LaborType      string `xml:"LaborType"`
+	LaborOperation string `xml:"LaborOperation,omitempty"`
+	LaborHours     string `xml:"LaborHours"`
+	LaborAmt       string `xml:"LaborAmt,omitempty"`
+	TaxableInd     string `xml:"TaxableInd,omitempty"`
+}
+
+type PartInfo struct {
+	PartType   string `xml:"PartType"`
+	Quantity   string `xml:"Quantity,omitempty"`
+	PartPrice  string `xml:"PartPrice"`
+	TaxableInd string `xml:"TaxableInd,omitempty"`
+}
+
+type OtherChargesInfo struct {
+	OtherChargesType string `xml:"OtherChargesType"`
+	Quantity         string `xml:"Quantity,omitempty"`
+	Price            string `xml:"Price"`
+	TaxableInd       string `xml:"TaxableInd,omitempty"`
+}
+
+type RepairTotalsInfo struct {
+	SummaryTotalsInfo []SummaryTotalsInfo `xml:"SummaryTotalsInfo"`
+}
+
+// Summary total types used in SummaryTotalsInfo.TotalType.
+const (
+	TotalSubtotal   = "SUB"
+	TotalTax        = "TAX"
+	TotalGross      = "TOT"
+	TotalDeductible = "DED"
+)
+
+type SummaryTotalsInfo struct {
+	TotalType     string `xml:"TotalType"`
+	TotalTypeDesc string `xml:"TotalTypeDesc,omitempty"`
+	TotalAmt      string `xml:"TotalAmt"`
+}
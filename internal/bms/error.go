@@ -0,0 +1,10 @@
+package bms
+
+import "errors"
+
+// Domain-level errors for BMS exchange
+var (
+	ErrNotFound        = errors.New("work order not found")
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrInvalidDocument = errors.New("invalid BMS document")
+)
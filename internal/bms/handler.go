@@ -0,0 +1,135 @@
+package bms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxUploadBytes caps an assignment upload; real BMS files are well under 1 MB.
+const maxUploadBytes = 5 << 20
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+/* -------------------- Handlers -------------------- */
+
+// GET /workorders/{id}/bms.xml?estimateId=<id>
+func (h *Handler) ExportWorkOrder(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, r, fmt.Errorf("%w: invalid work order id", ErrInvalidInput))
+		return
+	}
+	var estimateID *uuid.UUID
+	if raw := strings.TrimSpace(r.URL.Query().Get("estimateId")); raw != "" {
+		eid, err := uuid.Parse(raw)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("%w: invalid estimateId", ErrInvalidInput))
+			return
+		}
+		estimateID = &eid
+	}
+
+	out, filename, err := h.svc.ExportWorkOrder(r.Context(), actor, id, estimateID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(out)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(out)
+}
+
+// POST /workorders/import/bms?shopCode=<code>
+// Accepts the XML as the request body, or as the "file" field of a multipart form.
+func (h *Handler) ImportAssignment(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+
+	var body io.Reader = r.Body
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			writeError(w, r, fmt.Errorf("%w: multipart upload must include a \"file\" field", ErrInvalidInput))
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	res, err := h.svc.ImportAssignment(r.Context(), actor, body, r.URL.Query().Get("shopCode"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, res)
+}
+
+/* -------------------- Helpers -------------------- */
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// writeError classifies known domain errors and delegates to httpError.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
+
+	var verrs ValidationErrors
+	var intakeErrs dto.ValidationErrors
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &verrs):
+		// Report every field so the document can be fixed in one pass.
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": ErrInvalidDocument.Error(), "fields": []FieldError(verrs)})
+	case errors.As(err, &intakeErrs):
+		// The document parsed but the work order it describes fails intake validation.
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": "invalid intake", "fields": intakeErrs})
+	case errors.As(err, &tooLarge):
+		httpError(w, http.StatusRequestEntityTooLarge, "file too large")
+	case errors.Is(err, ErrInvalidDocument), errors.Is(err, ErrInvalidInput):
+		httpError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotFound):
+		httpError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, workorder.ErrShopInactive):
+		httpError(w, http.StatusConflict, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
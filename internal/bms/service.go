@@ -0,0 +1,159 @@
+package bms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/estimate"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/google/uuid"
+)
+
+// ImportResult is what an assignment import created.
+type ImportResult struct {
+	WorkOrder dto.WorkOrderDetail `json:"workOrder"`
+	Estimate  *estimate.Estimate  `json:"estimate,omitempty"`
+	Warnings  []string            `json:"warnings,omitempty"`
+}
+
+// Service exchanges work orders with insurer systems as BMS XML.
+type Service interface {
+	// ExportWorkOrder returns the work order as a BMS document and a suggested
+	// file name. A nil estimateID exports the approved estimate, or the latest
+	// version when none is approved.
+	ExportWorkOrder(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID, estimateID *uuid.UUID) ([]byte, string, error)
+	// ImportAssignment creates a work order, and a draft estimate when the
+	// document has damage lines. shopCode is only honoured for superadmins.
+	ImportAssignment(ctx context.Context, actor *auth.AuthUser, r io.Reader, shopCode string) (*ImportResult, error)
+}
+
+type service struct {
+	workorders workorder.Service
+	estimates  estimate.Service
+	now        func() time.Time
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates a BMS service. Access checks on estimates are delegated to
+// the estimate service.
+func NewService(workorders workorder.Service, estimates estimate.Service) Service {
+	return &service{workorders: workorders, estimates: estimates, now: time.Now}
+}
+
+func (s *service) ExportWorkOrder(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID, estimateID *uuid.UUID) ([]byte, string, error) {
+	detail, err := s.workorders.GetWorkOrderByID(ctx, workOrderID)
+	if err != nil {
+		if errors.Is(err, workorder.ErrNotFound) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", err
+	}
+	if !actor.CanAccessShop(detail.Shop.ShopID) {
+		return nil, "", ErrNotFound
+	}
+
+	est, err := s.exportEstimate(ctx, actor, workOrderID, estimateID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	out, err := Marshal(FromWorkOrder(detail, est, s.now()))
+	if err != nil {
+		return nil, "", err
+	}
+	return out, detail.Code + ".xml", nil
+}
+
+func (s *service) exportEstimate(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID, estimateID *uuid.UUID) (*estimate.Estimate, error) {
+	if estimateID != nil {
+		e, err := s.estimates.GetByID(ctx, actor, *estimateID)
+		if err != nil {
+			if errors.Is(err, estimate.ErrNotFound) || errors.Is(err, estimate.ErrWorkOrderNotFound) {
+				return nil, fmt.Errorf("%w: estimate not found", ErrInvalidInput)
+			}
+			return nil, err
+		}
+		if e.WorkOrderID != workOrderID {
+			return nil, fmt.Errorf("%w: estimate belongs to another work order", ErrInvalidInput)
+		}
+		return e, nil
+	}
+
+	list, err := s.estimates.ListByWorkOrder(ctx, actor, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	var latest *estimate.Estimate
+	for _, e := range list {
+		if e.Status == estimate.StatusApproved {
+			return e, nil
+		}
+		if latest == nil || e.Version > latest.Version {
+			latest = e
+		}
+	}
+	return latest, nil
+}
+
+func (s *service) ImportAssignment(ctx context.Context, actor *auth.AuthUser, r io.Reader, shopCode string) (*ImportResult, error) {
+	doc, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	in, err := ToIntake(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Staff always import into their own shop; the facility ID in the document
+	// is only a hint, and only superadmins may pick a shop.
+	switch {
+	case !actor.IsSuperAdmin():
+		if !actor.HasShop() {
+			return nil, fmt.Errorf("%w: user is not assigned to a shop", ErrInvalidInput)
+		}
+		in.Payload.Shop = dto.ShopRef{ShopID: *actor.ShopID}
+	case strings.TrimSpace(shopCode) != "":
+		in.Payload.Shop = dto.ShopRef{ShopCode: strings.ToUpper(strings.TrimSpace(shopCode))}
+	case in.ShopCode != "":
+		in.Payload.Shop = dto.ShopRef{ShopCode: strings.ToUpper(in.ShopCode)}
+	default:
+		return nil, fmt.Errorf("%w: shopCode is required", ErrInvalidInput)
+	}
+
+	wo, err := s.workorders.CreateWorkOrder(ctx, in.Payload)
+	if err != nil {
+		return nil, err
+	}
+	res := &ImportResult{WorkOrder: wo, Warnings: in.Warnings}
+
+	if len(in.Lines) == 0 {
+		return res, nil
+	}
+	// The work order is already saved, so a rejected estimate is reported
+	// rather than failing the whole import.
+	note := "Imported from insurer assignment"
+	if claim := doc.RefClaimNum; claim != "" {
+		note += " (claim " + claim + ")"
+	}
+	est, err := s.estimates.Create(ctx, actor, wo.ID, &estimate.CreateEstimateInput{
+		DeductibleCents: in.DeductibleCents,
+		Notes:           &note,
+		Lines:           in.Lines,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "bms: work order imported without estimate", "work_order", wo.Code, "error", err)
+		res.Warnings = append(res.Warnings, "estimate not created: "+err.Error())
+		return res, nil
+	}
+	res.Estimate = est
+	return res, nil
+}
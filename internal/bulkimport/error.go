@@ -0,0 +1,12 @@
+package bulkimport
+
+import "errors"
+
+// Domain-level errors for bulk imports
+var (
+	ErrNotFound     = errors.New("import job not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrInvalidFile  = errors.New("invalid spreadsheet")
+	ErrNotReady     = errors.New("import job is still running")
+)
@@ -0,0 +1,199 @@
+package bulkimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxUploadBytes caps a spreadsheet upload.
+const maxUploadBytes = 10 << 20
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// RegisterRoutes mounts the import routes (under /workorders/import).
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Post("/", h.upload)
+	r.Get("/jobs/{jobId}", h.getJob)
+	r.Get("/jobs/{jobId}/errors.csv", h.errorsCSV)
+}
+
+/* -------------------- Handlers -------------------- */
+
+// POST /workorders/import?commit=true&shopCode=<code>
+// Without commit=true the upload is only validated and a dry-run report is returned.
+// The file is the "file" field of a multipart form, or the raw request body with
+// a text/csv or XLSX content type.
+func (h *Handler) upload(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	filename, data, err := readUpload(w, r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	sheet, err := ReadSheet(data, filename)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	commit, _ := strconv.ParseBool(q.Get("commit"))
+	if !commit {
+		report, err := h.svc.DryRun(r.Context(), actor, sheet, q.Get("shopCode"))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+
+	job, err := h.svc.Start(r.Context(), actor, filename, sheet, q.Get("shopCode"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/workorders/import/jobs/"+job.ID.String())
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// GET /workorders/import/jobs/{jobId}
+func (h *Handler) getJob(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseJobID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	job, err := h.svc.GetJob(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// GET /workorders/import/jobs/{jobId}/errors.csv
+func (h *Handler) errorsCSV(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseJobID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	filename, err := h.svc.WriteErrorsCSV(r.Context(), actor, id, &buf)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+}
+
+/* -------------------- Helpers -------------------- */
+
+func readUpload(w http.ResponseWriter, r *http.Request) (string, []byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt == "multipart/form-data" {
+		f, hdr, err := r.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return "", nil, err
+			}
+			return "", nil, fmt.Errorf("%w: multipart upload must include a \"file\" field", ErrInvalidInput)
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		return hdr.Filename, data, err
+	}
+
+	// Raw body: the content type stands in for the file extension.
+	var filename string
+	switch mt {
+	case "text/csv", "application/csv", "text/plain":
+		filename = "import.csv"
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		filename = "import.xlsx"
+	default:
+		return "", nil, fmt.Errorf("%w: upload a .csv or .xlsx file", ErrInvalidInput)
+	}
+	data, err := io.ReadAll(r.Body)
+	return filename, data, err
+}
+
+func parseJobID(r *http.Request) (uuid.UUID, error) {
+	id, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "jobId")))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid job id", ErrInvalidInput)
+	}
+	return id, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// writeError classifies known domain errors and delegates to httpError.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		httpError(w, http.StatusRequestEntityTooLarge, "file too large")
+	case errors.Is(err, ErrInvalidFile), errors.Is(err, ErrInvalidInput):
+		httpError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrForbidden):
+		httpError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, ErrNotFound):
+		httpError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrNotReady):
+		httpError(w, http.StatusConflict, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
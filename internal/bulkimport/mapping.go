@@ -0,0 +1,215 @@
+package bulkimport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+)
+
+// Intake fields a column can map to. Names match dto.FieldError.Field so row
+// errors point at the same field names as POST /workorders.
+const (
+	fieldFirstName      = "customer.firstName"
+	fieldLastName       = "customer.lastName"
+	fieldFullName       = "customer.fullName" // split into first/last
+	fieldAddress        = "customer.address"
+	fieldCity           = "customer.city"
+	fieldPostalCode     = "customer.postalCode"
+	fieldProvince       = "customer.province"
+	fieldEmail          = "customer.email"
+	fieldPhone          = "customer.phone"
+	fieldPlateNo        = "vehicle.plateNo"
+	fieldMake           = "vehicle.make"
+	fieldModel          = "vehicle.model"
+	fieldBodyStyle      = "vehicle.bodyStyle"
+	fieldModelYear      = "vehicle.modelYear"
+	fieldVIN            = "vehicle.vin"
+	fieldColor          = "vehicle.color"
+	fieldInsurer        = "insurance.insuranceCompany"
+	fieldAgentFirstName = "insurance.agentFirstName"
+	fieldAgentLastName  = "insurance.agentLastName"
+	fieldAgentPhone     = "insurance.agentPhone"
+	fieldPolicyNumber   = "insurance.policyNumber"
+	fieldClaimNumber    = "insurance.claimNumber"
+	fieldShopCode       = "shop.shopCode"
+)
+
+// headerAliases maps normalised header text (lower case, letters and digits only)
+// to an intake field. It covers our own export and the layouts insurers send.
+var headerAliases = map[string]string{
+	"firstname": fieldFirstName, "customerfirstname": fieldFirstName, "givenname": fieldFirstName, "insuredfirstname": fieldFirstName,
+	"lastname": fieldLastName, "customerlastname": fieldLastName, "surname": fieldLastName, "familyname": fieldLastName, "insuredlastname": fieldLastName,
+	"name": fieldFullName, "fullname": fieldFullName, "customername": fieldFullName, "customer": fieldFullName, "claimant": fieldFullName, "claimantname": fieldFullName, "insured": fieldFullName, "insuredname": fieldFullName,
+	"address": fieldAddress, "customeraddress": fieldAddress, "streetaddress": fieldAddress, "address1": fieldAddress, "street": fieldAddress,
+	"city": fieldCity, "customercity": fieldCity, "town": fieldCity,
+	"postalcode": fieldPostalCode, "customerpostalcode": fieldPostalCode, "postal": fieldPostalCode, "zip": fieldPostalCode, "zipcode": fieldPostalCode,
+	"province": fieldProvince, "customerprovince": fieldProvince, "prov": fieldProvince, "state": fieldProvince,
+	"email": fieldEmail, "customeremail": fieldEmail, "emailaddress": fieldEmail,
+	"phone": fieldPhone, "customerphone": fieldPhone, "phonenumber": fieldPhone, "mobile": fieldPhone, "cell": fieldPhone, "telephone": fieldPhone,
+	"plate": fieldPlateNo, "plateno": fieldPlateNo, "platenumber": fieldPlateNo, "licenseplate": fieldPlateNo, "licenceplate": fieldPlateNo, "vehicleplate": fieldPlateNo,
+	"make": fieldMake, "vehiclemake": fieldMake,
+	"model": fieldModel, "vehiclemodel": fieldModel,
+	"bodystyle": fieldBodyStyle, "vehiclebodystyle": fieldBodyStyle, "body": fieldBodyStyle,
+	"year": fieldModelYear, "modelyear": fieldModelYear, "vehicleyear": fieldModelYear,
+	"vin": fieldVIN, "vehiclevin": fieldVIN, "serialnumber": fieldVIN,
+	"color": fieldColor, "colour": fieldColor, "vehiclecolor": fieldColor, "vehiclecolour": fieldColor,
+	"insurancecompany": fieldInsurer, "insurer": fieldInsurer, "insurance": fieldInsurer, "carrier": fieldInsurer,
+	"agentfirstname": fieldAgentFirstName, "adjusterfirstname": fieldAgentFirstName,
+	"agentlastname": fieldAgentLastName, "adjusterlastname": fieldAgentLastName,
+	"agentphone": fieldAgentPhone, "adjusterphone": fieldAgentPhone, "insuranceagentphone": fieldAgentPhone,
+	"policy": fieldPolicyNumber, "policynumber": fieldPolicyNumber, "policyno": fieldPolicyNumber,
+	"claim": fieldClaimNumber, "claimnumber": fieldClaimNumber, "claimno": fieldClaimNumber,
+	"shopcode": fieldShopCode, "shop": fieldShopCode,
+}
+
+// columnMap records which intake field each spreadsheet column feeds.
+type columnMap struct {
+	fields []string // by column index; "" for unmapped columns
+}
+
+// mapColumns matches the header against the known aliases. A field mapped by
+// more than one column is an error, since it's unclear which one to trust.
+func mapColumns(header []string) (*columnMap, map[string]string, []string, error) {
+	cm := &columnMap{fields: make([]string, len(header))}
+	mapped := make(map[string]string)
+	var unmapped []string
+	byField := make(map[string]string)
+
+	for i, h := range header {
+		field, ok := headerAliases[normaliseHeader(h)]
+		if !ok {
+			if h != "" {
+				unmapped = append(unmapped, h)
+			}
+			continue
+		}
+		if prev, dup := byField[field]; dup {
+			return nil, nil, nil, fmt.Errorf("%w: columns %q and %q both map to %s", ErrInvalidFile, prev, h, field)
+		}
+		byField[field] = h
+		cm.fields[i] = field
+		mapped[h] = field
+	}
+
+	_, first := byField[fieldFirstName]
+	_, full := byField[fieldFullName]
+	if first && full {
+		return nil, nil, nil, fmt.Errorf("%w: use either a full name column or first/last name columns, not both", ErrInvalidFile)
+	}
+	if len(mapped) == 0 {
+		return nil, nil, nil, fmt.Errorf("%w: no recognised columns; is the first row a header?", ErrInvalidFile)
+	}
+	return cm, mapped, unmapped, nil
+}
+
+func (cm *columnMap) has(field string) bool {
+	for _, f := range cm.fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// payload builds the intake payload for one row. Problems that stop a cell
+// from being read at all (a model year that isn't a number) are returned;
+// everything else is left to dto.IntakePayload.Validate.
+func (cm *columnMap) payload(values []string) (dto.IntakePayload, []dto.FieldError) {
+	var (
+		p    dto.IntakePayload
+		ins  dto.InsuranceIntake
+		errs []dto.FieldError
+	)
+	for i, field := range cm.fields {
+		if field == "" || i >= len(values) {
+			continue
+		}
+		v := strings.TrimSpace(values[i])
+		switch field {
+		case fieldFirstName:
+			p.Customer.FirstName = v
+		case fieldLastName:
+			p.Customer.LastName = v
+		case fieldFullName:
+			p.Customer.FirstName, p.Customer.LastName = splitName(v)
+		case fieldAddress:
+			p.Customer.Address = v
+		case fieldCity:
+			p.Customer.City = v
+		case fieldPostalCode:
+			p.Customer.PostalCode = v
+		case fieldProvince:
+			p.Customer.Province = v
+		case fieldEmail:
+			p.Customer.Email = v
+		case fieldPhone:
+			p.Customer.Phone = v
+		case fieldPlateNo:
+			p.Vehicle.PlateNo = v
+		case fieldMake:
+			p.Vehicle.Make = v
+		case fieldModel:
+			p.Vehicle.Model = v
+		case fieldBodyStyle:
+			p.Vehicle.BodyStyle = v
+		case fieldModelYear:
+			if v == "" {
+				continue
+			}
+			year, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, dto.FieldError{Field: field, Message: fmt.Sprintf("%q is not a year", v)})
+				continue
+			}
+			p.Vehicle.ModelYear = year
+		case fieldVIN:
+			p.Vehicle.VIN = v
+		case fieldColor:
+			p.Vehicle.Color = v
+		case fieldInsurer:
+			ins.InsuranceCompany = v
+		case fieldAgentFirstName:
+			ins.AgentFirstName = v
+		case fieldAgentLastName:
+			ins.AgentLastName = v
+		case fieldAgentPhone:
+			ins.AgentPhone = v
+		case fieldPolicyNumber:
+			ins.PolicyNumber = v
+		case fieldClaimNumber:
+			ins.ClaimNumber = v
+		case fieldShopCode:
+			p.Shop.ShopCode = v
+		}
+	}
+	if !ins.IsEmpty() {
+		p.Insurance = &ins
+	}
+	return p, errs
+}
+
+// splitName takes the last word as the last name ("Mary Anne Smith" -> "Mary Anne", "Smith"),
+// or splits "Smith, Mary Anne" on the comma.
+func splitName(s string) (first, last string) {
+	if l, f, ok := strings.Cut(s, ","); ok {
+		return strings.TrimSpace(f), strings.TrimSpace(l)
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if i := strings.LastIndexByte(s, ' '); i > 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+func normaliseHeader(h string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(h) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
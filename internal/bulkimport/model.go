@@ -0,0 +1,77 @@
+// Package bulkimport creates work orders in bulk from CSV or XLSX spreadsheets,
+// such as the claimant lists insurers send after a storm.
+package bulkimport
+
+import (
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/google/uuid"
+)
+
+// JobStatus mirrors the SQL enum app.import_job_status
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// RowError lists the problems with one spreadsheet row.
+// Row is the 1-based row number as shown in a spreadsheet app, header included.
+type RowError struct {
+	Row    int              `json:"row"`
+	Errors []dto.FieldError `json:"errors"`
+}
+
+// RejectedRow is a row that was not imported, with its original cells.
+type RejectedRow struct {
+	RowError
+	Values []string `json:"values"`
+}
+
+// CreatedRow links a spreadsheet row to the work order it created.
+type CreatedRow struct {
+	Row         int       `json:"row"`
+	WorkOrderID uuid.UUID `json:"workOrderId"`
+	Code        string    `json:"code"`
+}
+
+// Report is the dry-run result for an uploaded file.
+type Report struct {
+	TotalRows   int               `json:"totalRows"`
+	ValidRows   int               `json:"validRows"`
+	InvalidRows int               `json:"invalidRows"`
+	Columns     map[string]string `json:"columns"` // spreadsheet header -> intake field
+	Unmapped    []string          `json:"unmappedColumns,omitempty"`
+	Errors      []RowError        `json:"errors,omitempty"`
+}
+
+// Job is a committed import, polled via GET /workorders/import/jobs/{id}.
+type Job struct {
+	ID              uuid.UUID     `json:"id"`
+	ShopID          *uuid.UUID    `json:"shopId,omitempty"`
+	CreatedByUserID *uuid.UUID    `json:"createdByUserId,omitempty"`
+	Filename        string        `json:"filename"`
+	Status          JobStatus     `json:"status"`
+	TotalRows       int           `json:"totalRows"`
+	ProcessedRows   int           `json:"processedRows"`
+	CreatedRows     int           `json:"createdRows"`
+	FailedRows      int           `json:"failedRows"`
+	Header          []string      `json:"-"`
+	Rejected        []RejectedRow `json:"-"`
+	Errors          []RowError    `json:"errors,omitempty"`
+	Created         []CreatedRow  `json:"workOrders,omitempty"`
+	Error           *string       `json:"error,omitempty"`
+	StartedAt       *time.Time    `json:"startedAt,omitempty"`
+	FinishedAt      *time.Time    `json:"finishedAt,omitempty"`
+	CreatedAt       time.Time     `json:"createdAt"`
+	UpdatedAt       time.Time     `json:"updatedAt"`
+}
+
+// Done reports whether the job has stopped processing rows.
+func (j *Job) Done() bool {
+	return j.Status == JobCompleted || j.Status == JobFailed
+}
@@ -0,0 +1,226 @@
+package bulkimport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository defines the persistence contract for import jobs, plus the
+// lookups a dry run needs.
+type Repository interface {
+	CreateJob(ctx context.Context, job *Job) (*Job, error)
+	// SaveProgress writes the job's status, counters and row results.
+	SaveProgress(ctx context.Context, job *Job) error
+	GetJob(ctx context.Context, id uuid.UUID) (*Job, error)
+	// FailStaleJobs marks the queued and running jobs last updated before
+	// cutoff as failed with msg, and returns how many it marked.
+	FailStaleJobs(ctx context.Context, cutoff time.Time, msg string) (int64, error)
+
+	// ShopIDsByCode resolves shop codes within the request's organization;
+	// unknown codes are absent from the result. Codes are only unique per
+	// organization, so a code that matches shops in several organizations (an
+	// unscoped SuperAdmin) maps to uuid.Nil rather than to one of them.
+	ShopIDsByCode(ctx context.Context, codes []string) (map[string]uuid.UUID, error)
+	// ExistingVINs and ExistingEmails return the values already on file, upper-
+	// and lower-cased respectively. They only see the caller's shops; a clash
+	// with another shop's row is left to the unique constraint at creation.
+	ExistingVINs(ctx context.Context, vins []string) (map[string]bool, error)
+	ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error)
+}
+
+type pgRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository constructs a Postgres-backed import job repository.
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgRepo{db: db}
+}
+
+const jobSelect = `
+SELECT id, shop_id, created_by_user_id, filename, status,
+       total_rows, processed_rows, created_rows, failed_rows,
+       header, rejected_rows, created_work_orders, error,
+       started_at, finished_at, created_at, updated_at
+FROM app.import_jobs
+`
+
+func scanJob(row pgx.Row) (*Job, error) {
+	var (
+		j                         Job
+		header, rejected, created []byte
+	)
+	if err := row.Scan(
+		&j.ID, &j.ShopID, &j.CreatedByUserID, &j.Filename, &j.Status,
+		&j.TotalRows, &j.ProcessedRows, &j.CreatedRows, &j.FailedRows,
+		&header, &rejected, &created, &j.Error,
+		&j.StartedAt, &j.FinishedAt, &j.CreatedAt, &j.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(header, &j.Header); err != nil {
+		return nil, fmt.Errorf("decode header: %w", err)
+	}
+	if err := json.Unmarshal(rejected, &j.Rejected); err != nil {
+		return nil, fmt.Errorf("decode rejected_rows: %w", err)
+	}
+	if err := json.Unmarshal(created, &j.Created); err != nil {
+		return nil, fmt.Errorf("decode created_work_orders: %w", err)
+	}
+	return &j, nil
+}
+
+func (r *pgRepo) CreateJob(ctx context.Context, job *Job) (*Job, error) {
+	header, err := json.Marshal(job.Header)
+	if err != nil {
+		return nil, err
+	}
+	rejected, err := json.Marshal(nonNil(job.Rejected))
+	if err != nil {
+		return nil, err
+	}
+
+	var id uuid.UUID
+	err = r.db.QueryRow(ctx, `
+		INSERT INTO app.import_jobs
+			(shop_id, created_by_user_id, filename, status, total_rows, processed_rows, failed_rows, header, rejected_rows)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, job.ShopID, job.CreatedByUserID, job.Filename, job.Status,
+		job.TotalRows, job.ProcessedRows, job.FailedRows, header, rejected,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetJob(ctx, id)
+}
+
+func (r *pgRepo) SaveProgress(ctx context.Context, job *Job) error {
+	rejected, err := json.Marshal(nonNil(job.Rejected))
+	if err != nil {
+		return err
+	}
+	created, err := json.Marshal(nonNil(job.Created))
+	if err != nil {
+		return err
+	}
+	tag, err := r.db.Exec(ctx, `
+		UPDATE app.import_jobs SET
+			status = $2,
+			processed_rows = $3,
+			created_rows = $4,
+			failed_rows = $5,
+			rejected_rows = $6,
+			created_work_orders = $7,
+			error = $8,
+			started_at = $9,
+			finished_at = $10
+		WHERE id = $1
+	`, job.ID, job.Status, job.ProcessedRows, job.CreatedRows, job.FailedRows,
+		rejected, created, job.Error, job.StartedAt, job.FinishedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *pgRepo) GetJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	return scanJob(r.db.QueryRow(ctx, jobSelect+` WHERE id = $1`, id))
+}
+
+func (r *pgRepo) FailStaleJobs(ctx context.Context, cutoff time.Time, msg string) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE app.import_jobs SET
+			status = 'failed',
+			error = $2,
+			finished_at = now()
+		WHERE status IN ('queued', 'running') AND updated_at < $1
+	`, cutoff, msg)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *pgRepo) ShopIDsByCode(ctx context.Context, codes []string) (map[string]uuid.UUID, error) {
+	out := make(map[string]uuid.UUID, len(codes))
+	if len(codes) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.Query(ctx, `SELECT code, id FROM app.shop WHERE code = ANY($1) AND ($2::uuid IS NULL OR organization_id = $2)`,
+		codes, auth.OrganizationScope(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			code string
+			id   uuid.UUID
+		)
+		if err := rows.Scan(&code, &id); err != nil {
+			return nil, err
+		}
+		code = strings.ToUpper(code)
+		if _, seen := out[code]; seen {
+			id = uuid.Nil
+		}
+		out[code] = id
+	}
+	return out, rows.Err()
+}
+
+func (r *pgRepo) ExistingVINs(ctx context.Context, vins []string) (map[string]bool, error) {
+	return r.existing(ctx, `SELECT upper(vin::text) FROM app.vehicles WHERE vin = ANY($1::citext[])`, vins)
+}
+
+func (r *pgRepo) ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	return r.existing(ctx, `SELECT lower(email::text) FROM app.customers WHERE email = ANY($1::citext[])`, emails)
+}
+
+func (r *pgRepo) existing(ctx context.Context, query string, values []string) (map[string]bool, error) {
+	out := make(map[string]bool)
+	if len(values) == 0 {
+		return out, nil
+	}
+	err := database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, values)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var v string
+			if err := rows.Scan(&v); err != nil {
+				return err
+			}
+			out[v] = true
+		}
+		return rows.Err()
+	})
+	return out, err
+}
+
+// nonNil keeps empty lists as [] rather than null in jsonb columns.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,522 @@
+package bulkimport
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/csvsafe"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/metrics"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// progressEvery is how many rows are processed between progress updates. Each
+// work order is still created in its own transaction, so one bad row never
+// takes others down with it.
+const progressEvery = 50
+
+// abandonedAfter is how long a queued or running job may go without saving
+// progress before FailAbandonedJobs fails it: the instance running it stopped
+// without finishing it. Running jobs save every progressEvery rows, far more
+// often than that.
+const abandonedAfter = 10 * time.Minute
+
+var (
+	errShutdown  = errors.New("interrupted by a server shutdown; the rows not imported are in the errors file")
+	errAbandoned = errors.New("interrupted: the server running the import stopped; check the created work orders before importing the rest again")
+)
+
+// WorkOrderCreator creates a single work order; workorder.Service satisfies it.
+type WorkOrderCreator interface {
+	CreateWorkOrder(ctx context.Context, payload dto.IntakePayload) (dto.WorkOrderDetail, error)
+}
+
+// Service imports work orders from spreadsheets. Imports are for admins and
+// above; staff always import into their own shop, while superadmins choose a
+// shop per row (shop code column) or for the whole file.
+type Service interface {
+	// DryRun validates every row without creating anything.
+	DryRun(ctx context.Context, actor *auth.AuthUser, sheet *Sheet, shopCode string) (*Report, error)
+	// Start validates the file, records a job and creates the valid rows in
+	// the background. Poll GetJob for progress.
+	Start(ctx context.Context, actor *auth.AuthUser, filename string, sheet *Sheet, shopCode string) (*Job, error)
+	GetJob(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Job, error)
+	// WriteErrorsCSV writes the rejected rows of a finished job in the uploaded
+	// column layout, with an extra errors column, and returns a file name.
+	WriteErrorsCSV(ctx context.Context, actor *auth.AuthUser, id uuid.UUID, w io.Writer) (string, error)
+
+	// Shutdown stops the running jobs at their next row, marking them failed
+	// with the rows left over rejected, and waits for them to save, or for ctx
+	// to be done. Call it once the server no longer takes requests.
+	Shutdown(ctx context.Context) error
+	// FailAbandonedJobs fails the jobs left queued or running by an instance
+	// that stopped without finishing them: once at startup, then periodically
+	// until ctx is cancelled.
+	FailAbandonedJobs(ctx context.Context)
+}
+
+type service struct {
+	repo       Repository
+	workorders WorkOrderCreator
+	spawn      func(func())
+	now        func() time.Time
+
+	jobs     sync.WaitGroup
+	stop     chan struct{} // closed by Shutdown
+	stopOnce sync.Once
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates a bulk import service.
+func NewService(repo Repository, workorders WorkOrderCreator) Service {
+	return &service{
+		repo:       repo,
+		workorders: workorders,
+		spawn:      func(f func()) { go f() },
+		now:        time.Now,
+		stop:       make(chan struct{}),
+	}
+}
+
+func (s *service) DryRun(ctx context.Context, actor *auth.AuthUser, sheet *Sheet, shopCode string) (*Report, error) {
+	p, err := s.prepare(ctx, actor, sheet, shopCode)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{
+		TotalRows:   len(sheet.Rows),
+		ValidRows:   len(p.valid),
+		InvalidRows: len(p.rejected),
+		Columns:     p.mapped,
+		Unmapped:    p.unmapped,
+	}
+	for _, r := range p.rejected {
+		report.Errors = append(report.Errors, r.RowError)
+	}
+	return report, nil
+}
+
+func (s *service) Start(ctx context.Context, actor *auth.AuthUser, filename string, sheet *Sheet, shopCode string) (*Job, error) {
+	p, err := s.prepare(ctx, actor, sheet, shopCode)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.valid) == 0 {
+		return nil, fmt.Errorf("%w: no valid rows to import; run a dry run to see the errors", ErrInvalidInput)
+	}
+
+	job, err := s.repo.CreateJob(ctx, &Job{
+		ShopID:          p.shopID,
+		CreatedByUserID: &actor.ID,
+		Filename:        filename,
+		Status:          JobQueued,
+		TotalRows:       len(sheet.Rows),
+		ProcessedRows:   len(p.rejected),
+		FailedRows:      len(p.rejected),
+		Header:          sheet.Header,
+		Rejected:        p.rejected,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The job outlives the request, so it must not inherit its cancellation.
+	runCtx := context.WithoutCancel(ctx)
+	running := *job
+	s.jobs.Add(1)
+	s.spawn(func() {
+		defer s.jobs.Done()
+		s.run(runCtx, &running, p.valid)
+	})
+
+	job.fillErrors()
+	return job, nil
+}
+
+func (s *service) GetJob(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Job, error) {
+	job, err := s.repo.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canSeeJob(actor, job) {
+		return nil, ErrNotFound
+	}
+	job.fillErrors()
+	return job, nil
+}
+
+func (s *service) WriteErrorsCSV(ctx context.Context, actor *auth.AuthUser, id uuid.UUID, w io.Writer) (string, error) {
+	job, err := s.GetJob(ctx, actor, id)
+	if err != nil {
+		return "", err
+	}
+	if !job.Done() {
+		return "", ErrNotReady
+	}
+
+	cw := csv.NewWriter(w)
+	// the values are the uploaded file's, so formulas are defused like in exports
+	header := append(append([]string{}, job.Header...), "row", "errors")
+	if err := cw.Write(csvsafe.Record(header)); err != nil {
+		return "", err
+	}
+	for _, r := range sortedRejected(job.Rejected) {
+		rec := make([]string, len(job.Header), len(header))
+		copy(rec, r.Values)
+		msgs := make([]string, len(r.Errors))
+		for i, e := range r.Errors {
+			msgs[i] = strings.TrimPrefix(e.Field+": "+e.Message, ": ")
+		}
+		rec = append(rec, fmt.Sprint(r.Row), strings.Join(msgs, "; "))
+		if err := cw.Write(csvsafe.Record(rec)); err != nil {
+			return "", err
+		}
+	}
+	cw.Flush()
+
+	base := strings.TrimSuffix(filepath.Base(job.Filename), filepath.Ext(job.Filename))
+	return base + "-errors.csv", cw.Error()
+}
+
+func (s *service) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	done := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *service) FailAbandonedJobs(ctx context.Context) {
+	ticker := time.NewTicker(abandonedAfter)
+	defer ticker.Stop()
+	for {
+		s.failAbandoned(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *service) failAbandoned(ctx context.Context) {
+	n, err := s.repo.FailStaleJobs(ctx, s.now().Add(-abandonedAfter), errAbandoned.Error())
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.WarnContext(ctx, "import jobs: failing abandoned jobs failed", "error", err)
+		}
+		return
+	}
+	if n > 0 {
+		slog.WarnContext(ctx, "import jobs: failed abandoned jobs", "count", n)
+	}
+}
+
+/* ---------- validation ---------- */
+
+type plannedRow struct {
+	number  int
+	values  []string
+	payload dto.IntakePayload
+}
+
+type plan struct {
+	mapped   map[string]string
+	unmapped []string
+	valid    []plannedRow
+	rejected []RejectedRow
+	shopID   *uuid.UUID // set when every valid row goes to the same shop
+}
+
+// prepare maps and validates every row: intake validation, shop resolution and
+// duplicate VINs/emails both within the file and against existing records.
+func (s *service) prepare(ctx context.Context, actor *auth.AuthUser, sheet *Sheet, shopCode string) (*plan, error) {
+	if !actor.Can(auth.PermWorkOrdersImport) {
+		return nil, ErrForbidden
+	}
+	// SuperAdmin and org admins name the shop per row (or via shopCode);
+	// codes resolve within the request's organization scope.
+	byCode := actor.IsSuperAdmin() || actor.IsOrgWide()
+	if !byCode && !actor.HasShop() {
+		return nil, fmt.Errorf("%w: user is not assigned to a shop", ErrInvalidInput)
+	}
+	cm, mapped, unmapped, err := mapColumns(sheet.Header)
+	if err != nil {
+		return nil, err
+	}
+	defaultShop := strings.ToUpper(strings.TrimSpace(shopCode))
+
+	type candidate struct {
+		plannedRow
+		errs []dto.FieldError
+	}
+	rows := make([]*candidate, len(sheet.Rows))
+	var codes []string
+	for i, sr := range sheet.Rows {
+		payload, errs := cm.payload(sr.Values)
+		if byCode {
+			if payload.Shop.ShopCode == "" {
+				payload.Shop.ShopCode = defaultShop
+			}
+		} else {
+			payload.Shop = dto.ShopRef{ShopID: *actor.ShopID}
+		}
+		payload.Normalize()
+
+		if err := payload.Validate(); err != nil {
+			var verrs dto.ValidationErrors
+			if !errors.As(err, &verrs) {
+				return nil, err
+			}
+			errs = append(errs, verrs...)
+		}
+		if byCode {
+			if payload.Shop.ShopCode == "" {
+				errs = append(errs, dto.FieldError{Field: fieldShopCode, Message: "is required (add a shop code column or ?shopCode=)"})
+			} else {
+				codes = append(codes, payload.Shop.ShopCode)
+			}
+		}
+		rows[i] = &candidate{plannedRow{number: sr.Number, values: sr.Values, payload: payload}, errs}
+	}
+
+	if byCode {
+		shops, err := s.repo.ShopIDsByCode(ctx, uniq(codes))
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range rows {
+			code := c.payload.Shop.ShopCode
+			if code == "" {
+				continue
+			}
+			switch id, ok := shops[code]; {
+			case !ok:
+				c.errs = append(c.errs, dto.FieldError{Field: fieldShopCode, Message: fmt.Sprintf("no shop with code %q", code)})
+			case id == uuid.Nil:
+				c.errs = append(c.errs, dto.FieldError{Field: fieldShopCode,
+					Message: fmt.Sprintf("shop code %q exists in several organizations; choose one with the %s header", code, auth.OrganizationHeader)})
+			default:
+				c.payload.Shop.ShopID = id
+			}
+		}
+	}
+
+	// VINs and customer emails are unique in the database; catch clashes now
+	// rather than as failed inserts half-way through the job.
+	var vins, emails []string
+	firstVIN, firstEmail := map[string]int{}, map[string]int{}
+	for _, c := range rows {
+		if vin := c.payload.Vehicle.VIN; vin != "" {
+			if n, dup := firstVIN[vin]; dup {
+				c.errs = append(c.errs, dto.FieldError{Field: fieldVIN, Message: fmt.Sprintf("same VIN as row %d", n)})
+			} else {
+				firstVIN[vin] = c.number
+				vins = append(vins, vin)
+			}
+		}
+		if email := strings.ToLower(c.payload.Customer.Email); email != "" {
+			if n, dup := firstEmail[email]; dup {
+				c.errs = append(c.errs, dto.FieldError{Field: fieldEmail, Message: fmt.Sprintf("same email as row %d", n)})
+			} else {
+				firstEmail[email] = c.number
+				emails = append(emails, email)
+			}
+		}
+	}
+	takenVINs, err := s.repo.ExistingVINs(ctx, vins)
+	if err != nil {
+		return nil, err
+	}
+	takenEmails, err := s.repo.ExistingEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &plan{mapped: mapped, unmapped: unmapped}
+	shops := map[uuid.UUID]bool{}
+	for _, c := range rows {
+		if takenVINs[c.payload.Vehicle.VIN] {
+			c.errs = append(c.errs, dto.FieldError{Field: fieldVIN, Message: "a vehicle with this VIN already exists"})
+		}
+		if takenEmails[strings.ToLower(c.payload.Customer.Email)] {
+			c.errs = append(c.errs, dto.FieldError{Field: fieldEmail, Message: "a customer with this email already exists"})
+		}
+		if len(c.errs) > 0 {
+			p.rejected = append(p.rejected, RejectedRow{RowError: RowError{Row: c.number, Errors: c.errs}, Values: c.values})
+			continue
+		}
+		p.valid = append(p.valid, c.plannedRow)
+		shops[c.payload.Shop.ShopID] = true
+	}
+	if len(shops) == 1 {
+		for id := range shops {
+			p.shopID = &id
+		}
+	}
+	return p, nil
+}
+
+/* ---------- background run ---------- */
+
+// run creates the valid rows one by one, saving progress every progressEvery
+// rows and once more at the end.
+func (s *service) run(ctx context.Context, job *Job, rows []plannedRow) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			slog.ErrorContext(ctx, "import job panicked", "job_id", job.ID, "panic", rec)
+			s.finish(ctx, job, fmt.Errorf("internal error"))
+		}
+	}()
+
+	started := s.now()
+	job.Status = JobRunning
+	job.StartedAt = &started
+	s.save(ctx, job)
+
+	for start := 0; start < len(rows); start += progressEvery {
+		for i, r := range rows[start:min(start+progressEvery, len(rows))] {
+			if s.stopping() {
+				s.reject(job, rows[start+i:], dto.FieldError{Message: "not imported: the server shut down; import this row again"})
+				s.finish(ctx, job, errShutdown)
+				return
+			}
+			wo, err := s.workorders.CreateWorkOrder(ctx, r.payload)
+			job.ProcessedRows++
+			if err != nil {
+				job.FailedRows++
+				job.Rejected = append(job.Rejected, RejectedRow{
+					RowError: RowError{Row: r.number, Errors: createErrors(ctx, job.ID, r.number, err)},
+					Values:   r.values,
+				})
+				continue
+			}
+			job.CreatedRows++
+			job.Created = append(job.Created, CreatedRow{Row: r.number, WorkOrderID: wo.ID, Code: wo.Code})
+		}
+		s.save(ctx, job)
+	}
+	s.finish(ctx, job, nil)
+}
+
+func (s *service) stopping() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// reject records rows as failed with the same error.
+func (s *service) reject(job *Job, rows []plannedRow, fe dto.FieldError) {
+	for _, r := range rows {
+		job.ProcessedRows++
+		job.FailedRows++
+		job.Rejected = append(job.Rejected, RejectedRow{RowError: RowError{Row: r.number, Errors: []dto.FieldError{fe}}, Values: r.values})
+	}
+}
+
+func (s *service) finish(ctx context.Context, job *Job, err error) {
+	finished := s.now()
+	job.FinishedAt = &finished
+	job.Status = JobCompleted
+	if err != nil {
+		msg := err.Error()
+		job.Status = JobFailed
+		job.Error = &msg
+	}
+	s.save(ctx, job)
+
+	var took time.Duration
+	if job.StartedAt != nil {
+		took = finished.Sub(*job.StartedAt)
+	}
+	metrics.ImportJobFinished(string(job.Status), took)
+}
+
+func (s *service) save(ctx context.Context, job *Job) {
+	if err := s.repo.SaveProgress(ctx, job); err != nil {
+		slog.ErrorContext(ctx, "import job: saving progress failed", "job_id", job.ID, "error", err)
+	}
+}
+
+// createErrors explains why a row that passed validation still failed, e.g.
+// a VIN registered by someone else since the upload.
+func createErrors(ctx context.Context, jobID uuid.UUID, row int, err error) []dto.FieldError {
+	var verrs dto.ValidationErrors
+	if errors.As(err, &verrs) {
+		return verrs
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		switch pgErr.ConstraintName {
+		case "uq_vehicles_vin":
+			return []dto.FieldError{{Field: fieldVIN, Message: "a vehicle with this VIN already exists"}}
+		case "uq_customers_email":
+			return []dto.FieldError{{Field: fieldEmail, Message: "a customer with this email already exists"}}
+		case "uq_vehicles_plate_province":
+			return []dto.FieldError{{Field: fieldPlateNo, Message: "a vehicle with this plate already exists"}}
+		}
+	}
+	slog.ErrorContext(ctx, "import job: row failed", "job_id", jobID, "row", row, "error", err)
+	return []dto.FieldError{{Message: "could not create work order"}}
+}
+
+/* ---------- helpers ---------- */
+
+func canSeeJob(actor *auth.AuthUser, job *Job) bool {
+	if actor.IsSuperAdmin() {
+		return true
+	}
+	// jobs are not scoped by organization, so org admins only see their own
+	if actor.IsOrgWide() {
+		return job.CreatedByUserID != nil && *job.CreatedByUserID == actor.ID
+	}
+	return job.ShopID != nil && actor.CanAccessShop(*job.ShopID)
+}
+
+func (j *Job) fillErrors() {
+	j.Errors = nil
+	for _, r := range sortedRejected(j.Rejected) {
+		j.Errors = append(j.Errors, r.RowError)
+	}
+}
+
+func sortedRejected(in []RejectedRow) []RejectedRow {
+	out := append([]RejectedRow(nil), in...)
+	sort.SliceStable(out, func(a, b int) bool { return out[a].Row < out[b].Row })
+	return out
+}
+
+func uniq(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := in[:0:0]
+	for _, v := range in {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,405 @@
+package bulkimport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+/* ---------- fakes ---------- */
+
+type fakeRepo struct {
+	mu     sync.Mutex
+	jobs   map[uuid.UUID]*Job
+	shops  map[string]uuid.UUID
+	vins   map[string]bool
+	emails map[string]bool
+	saves  int
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{jobs: map[uuid.UUID]*Job{}, shops: map[string]uuid.UUID{}, vins: map[string]bool{}, emails: map[string]bool{}}
+}
+
+func (f *fakeRepo) CreateJob(_ context.Context, job *Job) (*Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	j := *job
+	j.ID = uuid.New()
+	f.jobs[j.ID] = &j
+	out := j
+	return &out, nil
+}
+
+func (f *fakeRepo) SaveProgress(_ context.Context, job *Job) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.saves++
+	j := *job
+	j.Rejected = append([]RejectedRow(nil), job.Rejected...)
+	j.Created = append([]CreatedRow(nil), job.Created...)
+	f.jobs[j.ID] = &j
+	return nil
+}
+
+func (f *fakeRepo) GetJob(_ context.Context, id uuid.UUID) (*Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	j, ok := f.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := *j
+	return &out, nil
+}
+
+func (f *fakeRepo) FailStaleJobs(_ context.Context, cutoff time.Time, msg string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var n int64
+	for _, j := range f.jobs {
+		if !j.Done() && j.UpdatedAt.Before(cutoff) {
+			j.Status, j.Error = JobFailed, &msg
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeRepo) ShopIDsByCode(_ context.Context, codes []string) (map[string]uuid.UUID, error) {
+	out := map[string]uuid.UUID{}
+	for _, c := range codes {
+		if id, ok := f.shops[c]; ok {
+			out[c] = id
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepo) ExistingVINs(_ context.Context, vins []string) (map[string]bool, error) {
+	return pick(f.vins, vins), nil
+}
+
+func (f *fakeRepo) ExistingEmails(_ context.Context, emails []string) (map[string]bool, error) {
+	return pick(f.emails, emails), nil
+}
+
+func pick(set map[string]bool, values []string) map[string]bool {
+	out := map[string]bool{}
+	for _, v := range values {
+		if set[v] {
+			out[v] = true
+		}
+	}
+	return out
+}
+
+type fakeCreator struct {
+	created []dto.IntakePayload
+	failVIN map[string]error
+	before  func() // called before each creation
+}
+
+func (f *fakeCreator) CreateWorkOrder(_ context.Context, p dto.IntakePayload) (dto.WorkOrderDetail, error) {
+	if f.before != nil {
+		f.before()
+	}
+	if err := f.failVIN[p.Vehicle.VIN]; err != nil {
+		return dto.WorkOrderDetail{}, err
+	}
+	f.created = append(f.created, p)
+	return dto.WorkOrderDetail{ID: uuid.New(), Code: fmt.Sprintf("WO-%04d", len(f.created)), Shop: dto.ShopSummary{ShopID: p.Shop.ShopID}}, nil
+}
+
+func newTestService(repo *fakeRepo, creator *fakeCreator) *service {
+	svc := NewService(repo, creator).(*service)
+	svc.spawn = func(f func()) { f() } // run jobs inline
+	return svc
+}
+
+/* ---------- fixtures ---------- */
+
+var shopA = uuid.New()
+
+func adminOf(shop uuid.UUID) *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleAdmin, Permissions: []string{auth.PermWorkOrdersImport}, ShopID: &shop}
+}
+
+func superAdmin() *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleSuperAdmin}
+}
+
+const header = "Claimant,Address,City,Province,Postal Code,Phone,Email,Year,Make,Model,VIN,Plate,Insurer,Claim #\n"
+
+func row(name, email, vin string) string {
+	return fmt.Sprintf("%s,1 Main St,Calgary,AB,T2P 2B5,(403) 555-0101,%s,2020,Honda,Civic,%s,ABC123,Prairie Mutual,CLM-%s\n", name, email, vin, vin[len(vin)-3:])
+}
+
+func sheetOf(t *testing.T, body string) *Sheet {
+	t.Helper()
+	s, err := ReadSheet([]byte(body), "claims.csv")
+	require.NoError(t, err)
+	return s
+}
+
+/* ---------- tests ---------- */
+
+// Test: a dry run reports every invalid row with intake field names and creates nothing
+func TestDryRun(t *testing.T) {
+	repo, creator := newFakeRepo(), &fakeCreator{}
+	repo.vins["2HGFC2F59MH000003"] = true
+	svc := newTestService(repo, creator)
+
+	body := header +
+		row("Dana McAllister", "dana@example.com", "2HGFC2F59MH000001") +
+		row("Sam Roy", "DANA@example.com", "2HGFC2F59MH000002") + // duplicate email in file
+		row("Lee Chan", "lee@example.com", "2HGFC2F59MH000003") + // VIN already on file
+		",1 Main St,Calgary,ZZ,123,555,not-an-email,next year,Honda,,BAD,,,\n"
+
+	report, err := svc.DryRun(context.Background(), adminOf(shopA), sheetOf(t, body), "")
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.TotalRows)
+	assert.Equal(t, 1, report.ValidRows)
+	assert.Equal(t, 3, report.InvalidRows)
+	assert.Equal(t, fieldFullName, report.Columns["Claimant"])
+	assert.Equal(t, fieldClaimNumber, report.Columns["Claim #"])
+	assert.Empty(t, creator.created)
+
+	require.Len(t, report.Errors, 3)
+	assert.Equal(t, 3, report.Errors[0].Row)
+	assert.Equal(t, "same email as row 2", report.Errors[0].Errors[0].Message)
+	assert.Equal(t, 4, report.Errors[1].Row)
+	assert.Equal(t, fieldVIN, report.Errors[1].Errors[0].Field)
+
+	fields := map[string]bool{}
+	for _, e := range report.Errors[2].Errors {
+		fields[e.Field] = true
+	}
+	for _, f := range []string{"customer.firstName", "customer.province", "customer.postalCode", "customer.phone",
+		"customer.email", "vehicle.modelYear", "vehicle.model", "vehicle.vin"} {
+		assert.True(t, fields[f], f)
+	}
+}
+
+// Test: committing creates valid rows in the actor's shop and keeps failures for the error file
+func TestStartAndErrorsCSV(t *testing.T) {
+	repo := newFakeRepo()
+	creator := &fakeCreator{failVIN: map[string]error{
+		"2HGFC2F59MH000052": &pgconn.PgError{Code: "23505", ConstraintName: "uq_vehicles_vin"},
+	}}
+	svc := newTestService(repo, creator)
+	actor := adminOf(shopA)
+
+	var b strings.Builder
+	b.WriteString(header)
+	for i := 1; i <= 120; i++ {
+		b.WriteString(row(fmt.Sprintf("Person %d", i), fmt.Sprintf("p%d@example.com", i), fmt.Sprintf("2HGFC2F59MH%06d", i)))
+	}
+	b.WriteString(row("=No Email", "", "2HGFC2F59MH999999"))
+
+	job, err := svc.Start(context.Background(), actor, "storm-claims.csv", sheetOf(t, b.String()), "OTHER")
+	require.NoError(t, err)
+	assert.Equal(t, &shopA, job.ShopID)
+	require.Len(t, job.Errors, 1, "validation failures are known before the job runs")
+
+	done, err := svc.GetJob(context.Background(), actor, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, JobCompleted, done.Status)
+	assert.Equal(t, 121, done.TotalRows)
+	assert.Equal(t, 121, done.ProcessedRows)
+	assert.Equal(t, 119, done.CreatedRows)
+	assert.Equal(t, 2, done.FailedRows)
+	assert.Len(t, done.Created, 119)
+	assert.GreaterOrEqual(t, repo.saves, 4, "progress is saved every progressEvery rows")
+	for _, p := range creator.created {
+		assert.Equal(t, shopA, p.Shop.ShopID, "staff always import into their own shop")
+	}
+
+	var buf bytes.Buffer
+	filename, err := svc.WriteErrorsCSV(context.Background(), actor, job.ID, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "storm-claims-errors.csv", filename)
+
+	recs, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, recs, 3)
+	assert.Equal(t, "errors", recs[0][len(recs[0])-1])
+	assert.Equal(t, "Person 52", recs[1][0], "rows are in sheet order")
+	assert.Contains(t, recs[1][len(recs[1])-1], "VIN already exists")
+	assert.Equal(t, "'=No Email", recs[2][0], "formulas are defused")
+	assert.Contains(t, recs[2][len(recs[2])-1], "customer.email: is required")
+}
+
+// Test: shutting down stops a running job at its next row; the rows left over
+// are rejected so they can be imported again from the errors file
+func TestShutdownStopsRunningJobs(t *testing.T) {
+	repo := newFakeRepo()
+	entered, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	creator := &fakeCreator{before: func() {
+		once.Do(func() {
+			close(entered)
+			<-release
+		})
+	}}
+	svc := NewService(repo, creator).(*service) // jobs run in the background
+	actor := adminOf(shopA)
+
+	body := header +
+		row("Person 1", "p1@example.com", "2HGFC2F59MH000001") +
+		row("Person 2", "p2@example.com", "2HGFC2F59MH000002") +
+		row("Person 3", "p3@example.com", "2HGFC2F59MH000003")
+	job, err := svc.Start(context.Background(), actor, "claims.csv", sheetOf(t, body), "")
+	require.NoError(t, err)
+
+	<-entered
+	stopped := make(chan error, 1)
+	go func() { stopped <- svc.Shutdown(context.Background()) }()
+	<-svc.stop
+	close(release)
+	require.NoError(t, <-stopped)
+
+	done, err := svc.GetJob(context.Background(), actor, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, JobFailed, done.Status)
+	assert.Equal(t, errShutdown.Error(), *done.Error)
+	assert.Equal(t, 3, done.ProcessedRows)
+	assert.Equal(t, 1, done.CreatedRows, "the row in flight is finished")
+	assert.Equal(t, 2, done.FailedRows)
+	require.Len(t, done.Errors, 2)
+	assert.Equal(t, 3, done.Errors[0].Row)
+	assert.Contains(t, done.Errors[0].Errors[0].Message, "server shut down")
+}
+
+// Test: jobs left queued or running by a stopped instance are failed once they
+// haven't saved progress for a while; live and finished jobs are left alone
+func TestFailAbandonedJobs(t *testing.T) {
+	repo := newFakeRepo()
+	svc := newTestService(repo, &fakeCreator{})
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	jobs := map[string]*Job{
+		"abandoned": {ID: uuid.New(), Status: JobRunning, UpdatedAt: now.Add(-time.Hour)},
+		"queued":    {ID: uuid.New(), Status: JobQueued, UpdatedAt: now.Add(-time.Hour)},
+		"live":      {ID: uuid.New(), Status: JobRunning, UpdatedAt: now.Add(-time.Minute)},
+		"finished":  {ID: uuid.New(), Status: JobCompleted, UpdatedAt: now.Add(-time.Hour)},
+	}
+	for _, j := range jobs {
+		repo.jobs[j.ID] = j
+	}
+
+	svc.failAbandoned(context.Background())
+	assert.Equal(t, JobFailed, jobs["abandoned"].Status)
+	assert.Equal(t, errAbandoned.Error(), *jobs["abandoned"].Error)
+	assert.Equal(t, JobFailed, jobs["queued"].Status)
+	assert.Equal(t, JobRunning, jobs["live"].Status)
+	assert.Equal(t, JobCompleted, jobs["finished"].Status)
+}
+
+// Test: superadmins pick shops per row or per file; unknown and ambiguous
+// codes are row errors
+func TestSuperAdminShopResolution(t *testing.T) {
+	repo, creator := newFakeRepo(), &fakeCreator{}
+	shopB := uuid.New()
+	repo.shops["CAL01"] = shopA
+	repo.shops["EDM01"] = shopB
+	repo.shops["RED01"] = uuid.Nil // in two organizations
+	svc := newTestService(repo, creator)
+
+	body := strings.Replace(header, "\n", ",Shop Code\n", 1) +
+		strings.Replace(row("A One", "a@example.com", "2HGFC2F59MH000001"), "\n", ",edm01\n", 1) +
+		strings.Replace(row("B Two", "b@example.com", "2HGFC2F59MH000002"), "\n", ",\n", 1) +
+		strings.Replace(row("C Three", "c@example.com", "2HGFC2F59MH000003"), "\n", ",NOPE\n", 1) +
+		strings.Replace(row("D Four", "d@example.com", "2HGFC2F59MH000004"), "\n", ",RED01\n", 1)
+
+	report, err := svc.DryRun(context.Background(), superAdmin(), sheetOf(t, body), "")
+	require.NoError(t, err)
+	require.Len(t, report.Errors, 3)
+	assert.Contains(t, report.Errors[0].Errors[0].Message, "is required")
+	assert.Contains(t, report.Errors[1].Errors[0].Message, `no shop with code "NOPE"`)
+	assert.Contains(t, report.Errors[2].Errors[0].Message, `shop code "RED01" exists in several organizations`)
+
+	job, err := svc.Start(context.Background(), superAdmin(), "x.csv", sheetOf(t, body), "cal01")
+	require.NoError(t, err)
+	assert.Nil(t, job.ShopID, "rows went to two shops")
+	require.Len(t, creator.created, 2)
+	assert.Equal(t, shopB, creator.created[0].Shop.ShopID)
+	assert.Equal(t, shopA, creator.created[1].Shop.ShopID)
+
+	// Another shop's admin can't see it.
+	_, err = svc.GetJob(context.Background(), adminOf(shopA), job.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// Test: only admins import, and a file with nothing valid isn't committed
+func TestImportGuards(t *testing.T) {
+	svc := newTestService(newFakeRepo(), &fakeCreator{})
+	bodyman := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleBodyman, ShopID: &shopA}
+
+	_, err := svc.DryRun(context.Background(), bodyman, sheetOf(t, header+row("A B", "a@example.com", "2HGFC2F59MH000001")), "")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = svc.Start(context.Background(), adminOf(shopA), "x.csv", sheetOf(t, header+"only,a,name\n"), "")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = svc.DryRun(context.Background(), adminOf(shopA), sheetOf(t, "foo,bar\n1,2\n"), "")
+	assert.ErrorIs(t, err, ErrInvalidFile)
+}
+
+// Test: XLSX uploads read the first sheet, with blank rows skipped but counted
+func TestReadSheetXLSX(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]any{"First Name", "Last Name", "Year"}))
+	require.NoError(t, f.SetSheetRow("Sheet1", "A2", &[]any{"Dana", "McAllister", 2021}))
+	require.NoError(t, f.SetSheetRow("Sheet1", "A4", &[]any{"Sam", "Roy", 2019}))
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+
+	s, err := ReadSheet(buf.Bytes(), "claims.xlsx")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"First Name", "Last Name", "Year"}, s.Header)
+	require.Len(t, s.Rows, 2)
+	assert.Equal(t, 2, s.Rows[0].Number)
+	assert.Equal(t, 4, s.Rows[1].Number)
+	assert.Equal(t, []string{"Sam", "Roy", "2019"}, s.Rows[1].Values)
+
+	// Sniffed without an extension, too.
+	_, err = ReadSheet(buf.Bytes(), "")
+	require.NoError(t, err)
+}
+
+// Test: CSV quirks from spreadsheet apps
+func TestReadSheetCSV(t *testing.T) {
+	s, err := ReadSheet([]byte("\uFEFFNom;Ville\r\n\r\nLévesque, Zoë;Québec\r\n"), "claims.csv")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Nom", "Ville"}, s.Header)
+	require.Len(t, s.Rows, 1)
+	assert.Equal(t, 3, s.Rows[0].Number)
+
+	_, err = ReadSheet([]byte("a,b\n"), "claims.csv")
+	assert.ErrorIs(t, err, ErrInvalidFile)
+	_, err = ReadSheet([]byte("a,b\n1,2\n"), "claims.pdf")
+	assert.ErrorIs(t, err, ErrInvalidFile)
+
+	first, last := splitName("Lévesque, Zoë")
+	assert.Equal(t, "Zoë", first)
+	assert.Equal(t, "Lévesque", last)
+	first, last = splitName("Mary  Anne Smith")
+	assert.Equal(t, "Mary Anne", first)
+	assert.Equal(t, "Smith", last)
+}
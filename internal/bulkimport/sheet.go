@@ -0,0 +1,165 @@
+package bulkimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// MaxRows is the most data rows a single upload may contain.
+const MaxRows = 5000
+
+// Sheet is an uploaded spreadsheet: a header row and the data rows under it.
+type Sheet struct {
+	Header []string
+	Rows   []SheetRow
+}
+
+// SheetRow is one non-blank data row.
+type SheetRow struct {
+	Number int // as shown in a spreadsheet app; the header is row 1
+	Values []string
+}
+
+// ReadSheet reads a CSV file or the first worksheet of an XLSX file.
+// The format is taken from the file name, falling back to sniffing the content.
+func ReadSheet(data []byte, filename string) (*Sheet, error) {
+	var (
+		records [][]string
+		lines   []int
+		err     error
+	)
+	switch ext := strings.ToLower(filepath.Ext(filename)); {
+	case ext == ".xlsx", ext == "" && bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		records, lines, err = readXLSX(data)
+	case ext == ".csv", ext == ".txt", ext == "":
+		records, lines, err = readCSV(data)
+	default:
+		return nil, fmt.Errorf("%w: unsupported file type %q (use .csv or .xlsx)", ErrInvalidFile, ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newSheet(records, lines)
+}
+
+// newSheet takes the records with the row number each starts on.
+func newSheet(records [][]string, lines []int) (*Sheet, error) {
+	// Skip any blank lines above the header.
+	start := 0
+	for start < len(records) && isBlank(records[start]) {
+		start++
+	}
+	if start == len(records) {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidFile)
+	}
+
+	sheet := &Sheet{Header: trimAll(records[start])}
+	for i := start + 1; i < len(records); i++ {
+		if isBlank(records[i]) {
+			continue
+		}
+		if len(sheet.Rows) == MaxRows {
+			return nil, fmt.Errorf("%w: more than %d rows; split the file", ErrInvalidFile, MaxRows)
+		}
+		sheet.Rows = append(sheet.Rows, SheetRow{Number: lines[i], Values: records[i]})
+	}
+	if len(sheet.Rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows below the header", ErrInvalidFile)
+	}
+	return sheet, nil
+}
+
+// readCSV numbers records by the line they start on; encoding/csv skips blank
+// lines, which a spreadsheet app would still count as rows.
+func readCSV(data []byte) ([][]string, []int, error) {
+	data = bytes.TrimPrefix(data, []byte("\uFEFF")) // Excel's UTF-8 BOM
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	// Spreadsheets saved with a French-Canadian locale use semicolons.
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	var (
+		records [][]string
+		lines   []int
+	)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, lines, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		line, _ := r.FieldPos(0)
+		records = append(records, rec)
+		lines = append(lines, line)
+		if len(records) > MaxRows+1 {
+			// newSheet reports the limit; no need to read the rest.
+			return records, lines, nil
+		}
+	}
+}
+
+func readXLSX(data []byte) ([][]string, []int, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil, fmt.Errorf("%w: workbook has no sheets", ErrInvalidFile)
+	}
+	rows, err := f.Rows(sheets[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer rows.Close()
+
+	var (
+		records [][]string
+		lines   []int
+	)
+	for rows.Next() {
+		// Columns returns formatted cell values, so dates and numbers read as displayed.
+		cols, err := rows.Columns()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		records = append(records, cols)
+		lines = append(lines, len(records)) // Rows yields empty rows too
+		if len(records) > MaxRows+1 {
+			break
+		}
+	}
+	return records, lines, rows.Error()
+}
+
+func isBlank(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func trimAll(rec []string) []string {
+	out := make([]string, len(rec))
+	for i, v := range rec {
+		out[i] = strings.TrimSpace(v)
+	}
+	return out
+}
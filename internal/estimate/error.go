@@ -0,0 +1,38 @@
+package estimate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain-level errors for estimate operations
+var (
+	ErrNotFound     = errors.New("estimate not found")
+	ErrConflict     = errors.New("estimate conflict")
+	ErrInvalidInput = errors.New("invalid estimate input")
+	ErrForbidden    = errors.New("forbidden: insufficient permissions")
+
+	ErrWorkOrderNotFound = errors.New("work order not found")
+	ErrNotEditable       = errors.New("only draft estimates can be changed")
+)
+
+// ValidationError represents validation errors with specific field information
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap allows errors.Is to work with ValidationError
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// NewValidationError creates a new ValidationError
+func NewValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
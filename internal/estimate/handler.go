@@ -0,0 +1,194 @@
+package estimate
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+/* -------------------- Handler Struct -------------------- */
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// RegisterRoutes mounts the estimate routes (under /estimates).
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/{id}", h.getByID)
+	r.Put("/{id}", h.update)
+	r.Post("/{id}/approve", h.approve)
+}
+
+// RegisterWorkOrderRoutes mounts the per-work-order routes (under /workorders/{id}/estimates).
+func (h *Handler) RegisterWorkOrderRoutes(r chi.Router) {
+	r.Get("/", h.listByWorkOrder)
+	r.Post("/", h.create)
+}
+
+/* -------------------- Handlers -------------------- */
+
+func (h *Handler) listByWorkOrder(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	workOrderID, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	list, err := h.svc.ListByWorkOrder(r.Context(), actor, workOrderID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	workOrderID, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var in CreateEstimateInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	e, err := h.svc.Create(r.Context(), actor, workOrderID, &in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, e)
+}
+
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	e, err := h.svc.GetByID(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var in UpdateEstimateInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	e, err := h.svc.Update(r.Context(), actor, id, &in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (h *Handler) approve(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	e, err := h.svc.Approve(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+/* -------------------- Helpers -------------------- */
+
+func parseID(r *http.Request, param string) (uuid.UUID, error) {
+	id, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, param)))
+	if err != nil {
+		return uuid.Nil, NewValidationError(param, "must be a valid UUID")
+	}
+	return id, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// writeError classifies known domain errors and delegates to httpError.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
+
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		httpError(w, http.StatusBadRequest, err.Error())
+
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrWorkOrderNotFound):
+		httpError(w, http.StatusNotFound, err.Error())
+
+	case errors.Is(err, ErrConflict), errors.Is(err, ErrNotEditable):
+		httpError(w, http.StatusConflict, err.Error())
+
+	case errors.Is(err, ErrForbidden):
+		httpError(w, http.StatusForbidden, err.Error())
+
+	default:
+		httpError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
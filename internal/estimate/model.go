@@ -0,0 +1,123 @@
+package estimate
+
+import (
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/tax"
+	"github.com/google/uuid"
+)
+
+// Status mirrors the SQL enum app.estimate_status
+type Status string
+
+const (
+	StatusDraft    Status = "draft"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+// Category mirrors the SQL enum app.billing_line_category
+type Category string
+
+const (
+	CategoryPDR    Category = "pdr"     // paintless dent repair labour
+	CategoryRAndI  Category = "r_and_i" // remove & install labour
+	CategoryPaint  Category = "paint"   // paint labour / materials
+	CategoryParts  Category = "parts"
+	CategorySublet Category = "sublet"
+	CategoryOther  Category = "other"
+)
+
+var validCategories = map[Category]bool{
+	CategoryPDR: true, CategoryRAndI: true, CategoryPaint: true,
+	CategoryParts: true, CategorySublet: true, CategoryOther: true,
+}
+
+// IsValid reports whether c is a known line category.
+func (c Category) IsValid() bool { return validCategories[c] }
+
+// Line ↔ app.estimate_lines
+type Line struct {
+	ID             uuid.UUID `json:"id"`
+	LineNo         int       `json:"lineNo"`
+	Category       Category  `json:"category"`
+	Description    string    `json:"description"`
+	Quantity       float64   `json:"quantity"` // hours for labour, units for parts
+	UnitPriceCents int64     `json:"unitPriceCents"`
+	AmountCents    int64     `json:"amountCents"`
+	Taxable        bool      `json:"taxable"`
+}
+
+// Estimate ↔ app.estimates (with its lines)
+type Estimate struct {
+	ID               uuid.UUID  `json:"id"`
+	WorkOrderID      uuid.UUID  `json:"workOrderId"`
+	Version          int        `json:"version"`
+	Status           Status     `json:"status"`
+	DeductibleCents  int64      `json:"deductibleCents"`
+	Notes            *string    `json:"notes,omitempty"`
+	CreatedByUserID  *uuid.UUID `json:"createdByUserId,omitempty"`
+	ApprovedByUserID *uuid.UUID `json:"approvedByUserId,omitempty"`
+	ApprovedAt       *time.Time `json:"approvedAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+	Lines            []Line     `json:"lines"`
+
+	// Tax is computed by the service from the shop's province; it is not stored.
+	Tax *tax.Breakdown `json:"tax,omitempty"`
+}
+
+// TaxLines converts the estimate lines to tax engine input.
+func (e *Estimate) TaxLines() []tax.Line {
+	out := make([]tax.Line, 0, len(e.Lines))
+	for _, l := range e.Lines {
+		out = append(out, tax.Line{
+			ID:          l.ID.String(),
+			Description: l.Description,
+			AmountCents: l.AmountCents,
+			Exempt:      !l.Taxable,
+		})
+	}
+	return out
+}
+
+// WorkOrderRef is the slice of work order + shop data the billing services need.
+type WorkOrderRef struct {
+	ID     uuid.UUID
+	ShopID uuid.UUID
+	Status string
+	// ShopProvince is the province sales tax is charged in: the shop settings'
+	// tax province if set, otherwise the shop's address.
+	ShopProvince string
+	HasInsurance bool
+	// LabourRates holds the shop's default hourly rates (cents) by category;
+	// categories without a configured rate are absent.
+	LabourRates map[Category]int64
+}
+
+// LineInput is a single line as sent by the frontend.
+type LineInput struct {
+	Category       Category `json:"category"`
+	Description    string   `json:"description"`
+	Quantity       float64  `json:"quantity"`
+	UnitPriceCents int64    `json:"unitPriceCents"`
+	Taxable        *bool    `json:"taxable,omitempty"` // defaults to true
+	// UseShopRate prices a labour line at the shop's configured hourly rate,
+	// ignoring UnitPriceCents.
+	UseShopRate bool `json:"useShopRate,omitempty"`
+}
+
+// CreateEstimateInput represents the payload for POST /workorders/{id}/estimates
+type CreateEstimateInput struct {
+	DeductibleCents int64       `json:"deductibleCents"`
+	Notes           *string     `json:"notes,omitempty"`
+	Lines           []LineInput `json:"lines"`
+}
+
+// UpdateEstimateInput represents the payload for PUT /estimates/{id}.
+// Nil fields are left unchanged; a non-nil Lines replaces all lines.
+type UpdateEstimateInput struct {
+	DeductibleCents *int64      `json:"deductibleCents,omitempty"`
+	Notes           *string     `json:"notes,omitempty"`
+	Lines           []LineInput `json:"lines,omitempty"`
+}
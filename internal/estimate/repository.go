@@ -0,0 +1,293 @@
+package estimate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository defines the persistence contract for estimates.
+type Repository interface {
+	GetWorkOrderRef(ctx context.Context, workOrderID uuid.UUID) (*WorkOrderRef, error)
+	Create(ctx context.Context, e *Estimate) (*Estimate, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*Estimate, error)
+	ListByWorkOrder(ctx context.Context, workOrderID uuid.UUID) ([]*Estimate, error)
+	GetApprovedByWorkOrder(ctx context.Context, workOrderID uuid.UUID) (*Estimate, error)
+	Update(ctx context.Context, e *Estimate, replaceLines bool) (*Estimate, error)
+	Approve(ctx context.Context, id uuid.UUID, byUserID uuid.UUID) (*Estimate, error)
+}
+
+type pgRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository constructs a Postgres-backed estimate repository.
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgRepo{db: db}
+}
+
+/* ---------- error mapping ---------- */
+
+func mapPgError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.UniqueViolation:
+			return ErrConflict
+		case pgerrcode.ForeignKeyViolation, pgerrcode.CheckViolation, pgerrcode.NotNullViolation,
+			pgerrcode.InvalidTextRepresentation:
+			return ErrInvalidInput
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+/* ---------- queries ---------- */
+
+const estimateSelect = `
+SELECT id, work_order_id, version, status, deductible_cents, notes,
+       created_by_user_id, approved_by_user_id, approved_at, created_at, updated_at
+FROM app.estimates
+`
+
+func scanEstimate(row pgx.Row) (*Estimate, error) {
+	var e Estimate
+	if err := row.Scan(
+		&e.ID, &e.WorkOrderID, &e.Version, &e.Status, &e.DeductibleCents, &e.Notes,
+		&e.CreatedByUserID, &e.ApprovedByUserID, &e.ApprovedAt, &e.CreatedAt, &e.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	e.Lines = make([]Line, 0)
+	return &e, nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func (r *pgRepo) loadLines(ctx context.Context, q querier, estimates ...*Estimate) error {
+	if len(estimates) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(estimates))
+	byID := make(map[uuid.UUID]*Estimate, len(estimates))
+	for _, e := range estimates {
+		ids = append(ids, e.ID)
+		byID[e.ID] = e
+	}
+
+	rows, err := q.Query(ctx, `
+SELECT estimate_id, id, line_no, category, description, quantity::float8, unit_price_cents, amount_cents, taxable
+FROM app.estimate_lines
+WHERE estimate_id = ANY($1)
+ORDER BY estimate_id, line_no
+`, ids)
+	if err != nil {
+		return fmt.Errorf("load estimate lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var estimateID uuid.UUID
+		var l Line
+		if err := rows.Scan(&estimateID, &l.ID, &l.LineNo, &l.Category, &l.Description,
+			&l.Quantity, &l.UnitPriceCents, &l.AmountCents, &l.Taxable); err != nil {
+			return fmt.Errorf("scan estimate line: %w", err)
+		}
+		if e := byID[estimateID]; e != nil {
+			e.Lines = append(e.Lines, l)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *pgRepo) GetWorkOrderRef(ctx context.Context, workOrderID uuid.UUID) (*WorkOrderRef, error) {
+	var ref WorkOrderRef
+	var pdr, rAndI, paint *int64
+	// under RLS: the work order is only found if it is in the caller's shops
+	err := database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, `
+SELECT wo.id, wo.shop_id, wo.status::text, COALESCE(ss.tax_province, s.province),
+       EXISTS (SELECT 1 FROM app.insurance i WHERE i.work_order_id = wo.id),
+       ss.pdr_rate_cents, ss.r_and_i_rate_cents, ss.paint_rate_cents
+FROM app.work_orders wo
+JOIN app.shop s ON s.id = wo.shop_id
+LEFT JOIN app.shop_settings ss ON ss.shop_id = s.id
+WHERE wo.id = $1
+  AND ($2::uuid IS NULL OR s.organization_id = $2)
+`, workOrderID, auth.OrganizationScope(ctx)).Scan(&ref.ID, &ref.ShopID, &ref.Status, &ref.ShopProvince, &ref.HasInsurance,
+			&pdr, &rAndI, &paint)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWorkOrderNotFound
+		}
+		return nil, fmt.Errorf("get work order ref: %w", err)
+	}
+
+	ref.LabourRates = make(map[Category]int64)
+	for c, rate := range map[Category]*int64{CategoryPDR: pdr, CategoryRAndI: rAndI, CategoryPaint: paint} {
+		if rate != nil {
+			ref.LabourRates[c] = *rate
+		}
+	}
+	return &ref, nil
+}
+
+func (r *pgRepo) Create(ctx context.Context, e *Estimate) (*Estimate, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Version numbers are per work order; a concurrent insert that picks the same
+	// number trips uq_estimates_work_order_version and surfaces as ErrConflict.
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+INSERT INTO app.estimates (work_order_id, version, status, deductible_cents, notes, created_by_user_id)
+VALUES ($1,
+        (SELECT COALESCE(MAX(version), 0) + 1 FROM app.estimates WHERE work_order_id = $1),
+        $2, $3, $4, $5)
+RETURNING id
+`, e.WorkOrderID, StatusDraft, e.DeductibleCents, e.Notes, e.CreatedByUserID).Scan(&id)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+
+	if err := insertLines(ctx, tx, id, e.Lines); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func insertLines(ctx context.Context, tx pgx.Tx, estimateID uuid.UUID, lines []Line) error {
+	for i, l := range lines {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO app.estimate_lines
+(estimate_id, line_no, category, description, quantity, unit_price_cents, amount_cents, taxable)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`, estimateID, i+1, l.Category, l.Description, l.Quantity, l.UnitPriceCents, l.AmountCents, l.Taxable); err != nil {
+			return mapPgError(err)
+		}
+	}
+	return nil
+}
+
+func (r *pgRepo) GetByID(ctx context.Context, id uuid.UUID) (*Estimate, error) {
+	e, err := scanEstimate(r.db.QueryRow(ctx, estimateSelect+` WHERE id = $1`, id))
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	if err := r.loadLines(ctx, r.db, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *pgRepo) ListByWorkOrder(ctx context.Context, workOrderID uuid.UUID) ([]*Estimate, error) {
+	rows, err := r.db.Query(ctx, estimateSelect+` WHERE work_order_id = $1 ORDER BY version DESC`, workOrderID)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	defer rows.Close()
+
+	list := make([]*Estimate, 0)
+	for rows.Next() {
+		e, err := scanEstimate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan estimate: %w", err)
+		}
+		list = append(list, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadLines(ctx, r.db, list...); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *pgRepo) GetApprovedByWorkOrder(ctx context.Context, workOrderID uuid.UUID) (*Estimate, error) {
+	e, err := scanEstimate(r.db.QueryRow(ctx,
+		estimateSelect+` WHERE work_order_id = $1 AND status = 'approved'`, workOrderID))
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	if err := r.loadLines(ctx, r.db, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *pgRepo) Update(ctx context.Context, e *Estimate, replaceLines bool) (*Estimate, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Only drafts are editable; the status predicate makes this safe against a concurrent approval.
+	ct, err := tx.Exec(ctx, `
+UPDATE app.estimates
+SET deductible_cents = $2, notes = $3
+WHERE id = $1 AND status = 'draft'
+`, e.ID, e.DeductibleCents, e.Notes)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, ErrNotEditable
+	}
+
+	if replaceLines {
+		if _, err := tx.Exec(ctx, `DELETE FROM app.estimate_lines WHERE estimate_id = $1`, e.ID); err != nil {
+			return nil, mapPgError(err)
+		}
+		if err := insertLines(ctx, tx, e.ID, e.Lines); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, e.ID)
+}
+
+func (r *pgRepo) Approve(ctx context.Context, id uuid.UUID, byUserID uuid.UUID) (*Estimate, error) {
+	ct, err := r.db.Exec(ctx, `
+UPDATE app.estimates
+SET status = 'approved', approved_by_user_id = $2, approved_at = $3
+WHERE id = $1 AND status = 'draft'
+`, id, byUserID, time.Now())
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, ErrNotEditable
+	}
+	return r.GetByID(ctx, id)
+}
@@ -0,0 +1,253 @@
+package estimate
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/tax"
+	"github.com/google/uuid"
+)
+
+// Service defines business operations for estimates.
+type Service interface {
+	ListByWorkOrder(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID) ([]*Estimate, error)
+	Create(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID, in *CreateEstimateInput) (*Estimate, error)
+	GetByID(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Estimate, error)
+	Update(ctx context.Context, actor *auth.AuthUser, id uuid.UUID, in *UpdateEstimateInput) (*Estimate, error)
+	Approve(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Estimate, error)
+}
+
+type service struct {
+	repo  Repository
+	audit audit.Recorder
+	now   func() time.Time
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates an estimate service. Approvals are recorded to rec; nil
+// records nothing.
+func NewService(repo Repository, rec audit.Recorder) Service {
+	return &service{repo: repo, audit: audit.OrNop(rec), now: time.Now}
+}
+
+func (s *service) ListByWorkOrder(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID) ([]*Estimate, error) {
+	ref, err := s.workOrderFor(ctx, actor, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.repo.ListByWorkOrder(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range list {
+		s.applyTax(ctx, ref, e)
+	}
+	return list, nil
+}
+
+func (s *service) Create(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID, in *CreateEstimateInput) (*Estimate, error) {
+	if !canWrite(actor) {
+		return nil, ErrForbidden
+	}
+	ref, err := s.workOrderFor(ctx, actor, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.DeductibleCents < 0 {
+		return nil, NewValidationError("deductibleCents", "must not be negative")
+	}
+	lines, err := buildLines(in.Lines, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Estimate{
+		WorkOrderID:     workOrderID,
+		DeductibleCents: in.DeductibleCents,
+		Notes:           trimPtr(in.Notes),
+		CreatedByUserID: &actor.ID,
+		Lines:           lines,
+	}
+	created, err := s.repo.Create(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	s.applyTax(ctx, ref, created)
+	return created, nil
+}
+
+func (s *service) GetByID(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Estimate, error) {
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := s.workOrderFor(ctx, actor, e.WorkOrderID)
+	if err != nil {
+		return nil, err
+	}
+	s.applyTax(ctx, ref, e)
+	return e, nil
+}
+
+func (s *service) Update(ctx context.Context, actor *auth.AuthUser, id uuid.UUID, in *UpdateEstimateInput) (*Estimate, error) {
+	if !canWrite(actor) {
+		return nil, ErrForbidden
+	}
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := s.workOrderFor(ctx, actor, e.WorkOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != StatusDraft {
+		return nil, ErrNotEditable
+	}
+
+	if in.DeductibleCents != nil {
+		if *in.DeductibleCents < 0 {
+			return nil, NewValidationError("deductibleCents", "must not be negative")
+		}
+		e.DeductibleCents = *in.DeductibleCents
+	}
+	if in.Notes != nil {
+		e.Notes = trimPtr(in.Notes)
+	}
+	replaceLines := in.Lines != nil
+	if replaceLines {
+		if e.Lines, err = buildLines(in.Lines, ref); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.repo.Update(ctx, e, replaceLines)
+	if err != nil {
+		return nil, err
+	}
+	s.applyTax(ctx, ref, updated)
+	return updated, nil
+}
+
+func (s *service) Approve(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Estimate, error) {
+	// Approval commits the shop to a price (admins by default).
+	if !actor.Can(auth.PermEstimatesApprove) {
+		return nil, ErrForbidden
+	}
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := s.workOrderFor(ctx, actor, e.WorkOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Lines) == 0 {
+		return nil, NewValidationError("lines", "cannot approve an estimate without lines")
+	}
+
+	approved, err := s.repo.Approve(ctx, id, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.applyTax(ctx, ref, approved)
+	s.applyTax(ctx, ref, e) // so only the approval shows as changed
+	s.audit.Record(ctx, audit.Entry{
+		Action: "estimate.approve", EntityType: audit.EntityEstimate, EntityID: approved.ID.String(),
+		ShopID: &ref.ShopID, Before: e, After: approved,
+	})
+	return approved, nil
+}
+
+/* ---------- helpers ---------- */
+
+// workOrderFor loads the work order and checks the actor belongs to its shop.
+func (s *service) workOrderFor(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID) (*WorkOrderRef, error) {
+	ref, err := s.repo.GetWorkOrderRef(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanAccessShop(ref.ShopID) {
+		// Same answer as a missing work order, so other shops' IDs aren't confirmed.
+		return nil, ErrWorkOrderNotFound
+	}
+	return ref, nil
+}
+
+// applyTax fills in the tax breakdown using the shop's province at today's rates.
+func (s *service) applyTax(ctx context.Context, ref *WorkOrderRef, e *Estimate) {
+	b, err := tax.Calculate(ref.ShopProvince, s.now(), e.TaxLines())
+	if err != nil {
+		slog.WarnContext(ctx, "estimate tax not calculated", "estimate_id", e.ID, "error", err)
+		return
+	}
+	e.Tax = b
+}
+
+// canWrite reports whether actor may create or edit estimates.
+// By default bodymen edit estimate lines; adjusters only suggest via notes.
+func canWrite(actor *auth.AuthUser) bool {
+	return actor.Can(auth.PermEstimatesWrite)
+}
+
+// buildLines validates input lines and computes their amounts. Lines marked
+// UseShopRate take their unit price from the shop's labour rates in ref.
+func buildLines(in []LineInput, ref *WorkOrderRef) ([]Line, error) {
+	lines := make([]Line, 0, len(in))
+	for _, li := range in {
+		desc := strings.TrimSpace(li.Description)
+		if desc == "" {
+			return nil, NewValidationError("lines.description", "is required")
+		}
+		if !li.Category.IsValid() {
+			return nil, NewValidationError("lines.category", "must be one of pdr, r_and_i, paint, parts, sublet, other")
+		}
+		if li.Quantity <= 0 {
+			return nil, NewValidationError("lines.quantity", "must be greater than zero")
+		}
+		if li.UseShopRate {
+			rate, ok := ref.LabourRates[li.Category]
+			if !ok {
+				return nil, NewValidationError("lines.useShopRate", "the shop has no "+string(li.Category)+" labour rate configured")
+			}
+			li.UnitPriceCents = rate
+		}
+		if li.UnitPriceCents < 0 {
+			return nil, NewValidationError("lines.unitPriceCents", "must not be negative")
+		}
+		// numeric(10,2) in the DB; round here so the stored amount matches the stored quantity.
+		qty := math.Round(li.Quantity*100) / 100
+		taxable := true
+		if li.Taxable != nil {
+			taxable = *li.Taxable
+		}
+		lines = append(lines, Line{
+			Category:       li.Category,
+			Description:    desc,
+			Quantity:       qty,
+			UnitPriceCents: li.UnitPriceCents,
+			AmountCents:    int64(math.Round(qty * float64(li.UnitPriceCents))),
+			Taxable:        taxable,
+		})
+	}
+	return lines, nil
+}
+
+func trimPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
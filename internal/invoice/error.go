@@ -0,0 +1,43 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain-level errors for invoice operations
+var (
+	ErrNotFound     = errors.New("invoice not found")
+	ErrConflict     = errors.New("invoice conflict")
+	ErrInvalidInput = errors.New("invalid invoice input")
+	ErrForbidden    = errors.New("forbidden: insufficient permissions")
+
+	ErrWorkOrderNotFound     = errors.New("work order not found")
+	ErrWorkOrderNotCompleted = errors.New("work order must be completed before invoicing")
+	ErrNoApprovedEstimate    = errors.New("work order has no approved estimate")
+	ErrAlreadyInvoiced       = errors.New("work order already has an active invoice")
+	ErrInvoiceVoid           = errors.New("invoice is void")
+	ErrOverpayment           = errors.New("payment exceeds balance due")
+	ErrHasPayments           = errors.New("invoice with recorded payments cannot be voided")
+)
+
+// ValidationError represents validation errors with specific field information
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap allows errors.Is to work with ValidationError
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// NewValidationError creates a new ValidationError
+func NewValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
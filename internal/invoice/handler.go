@@ -0,0 +1,217 @@
+package invoice
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+/* -------------------- Handler Struct -------------------- */
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// RegisterRoutes mounts the invoice routes (under /invoices).
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/{id}", h.getByID)
+	r.Get("/{id}/payments", h.listPayments)
+	r.Post("/{id}/payments", h.recordPayment)
+	r.Post("/{id}/void", h.void)
+}
+
+// RegisterWorkOrderRoutes mounts the per-work-order routes (under /workorders/{id}/invoices).
+func (h *Handler) RegisterWorkOrderRoutes(r chi.Router) {
+	r.Get("/", h.listByWorkOrder)
+	r.Post("/", h.generate)
+}
+
+/* -------------------- Handlers -------------------- */
+
+func (h *Handler) generate(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	workOrderID, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	inv, err := h.svc.Generate(r.Context(), actor, workOrderID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, inv)
+}
+
+func (h *Handler) listByWorkOrder(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	workOrderID, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	list, err := h.svc.ListByWorkOrder(r.Context(), actor, workOrderID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	inv, err := h.svc.GetByID(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, inv)
+}
+
+func (h *Handler) listPayments(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	inv, err := h.svc.GetByID(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"payments": inv.Payments,
+		"balance":  inv.Balance,
+	})
+}
+
+func (h *Handler) recordPayment(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var in RecordPaymentInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	inv, err := h.svc.RecordPayment(r.Context(), actor, id, &in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, inv)
+}
+
+func (h *Handler) void(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "id")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	inv, err := h.svc.Void(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, inv)
+}
+
+/* -------------------- Helpers -------------------- */
+
+func parseID(r *http.Request, param string) (uuid.UUID, error) {
+	id, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, param)))
+	if err != nil {
+		return uuid.Nil, NewValidationError(param, "must be a valid UUID")
+	}
+	return id, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// writeError classifies known domain errors and delegates to httpError.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
+
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		httpError(w, http.StatusBadRequest, err.Error())
+
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrWorkOrderNotFound):
+		httpError(w, http.StatusNotFound, err.Error())
+
+	case errors.Is(err, ErrConflict), errors.Is(err, ErrAlreadyInvoiced),
+		errors.Is(err, ErrWorkOrderNotCompleted), errors.Is(err, ErrNoApprovedEstimate),
+		errors.Is(err, ErrInvoiceVoid), errors.Is(err, ErrHasPayments):
+		httpError(w, http.StatusConflict, err.Error())
+
+	case errors.Is(err, ErrOverpayment):
+		httpError(w, http.StatusUnprocessableEntity, err.Error())
+
+	case errors.Is(err, ErrForbidden):
+		httpError(w, http.StatusForbidden, err.Error())
+
+	default:
+		httpError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -0,0 +1,191 @@
+package invoice
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/estimate"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/tax"
+	"github.com/google/uuid"
+)
+
+// Status mirrors the SQL enum app.invoice_status
+type Status string
+
+const (
+	StatusIssued        Status = "issued"
+	StatusPartiallyPaid Status = "partially_paid"
+	StatusPaid          Status = "paid"
+	StatusVoid          Status = "void"
+)
+
+// PaymentMethod mirrors the SQL enum app.payment_method
+type PaymentMethod string
+
+const (
+	MethodCash          PaymentMethod = "cash"
+	MethodCard          PaymentMethod = "card"
+	MethodETransfer     PaymentMethod = "e_transfer"
+	MethodInsurerCheque PaymentMethod = "insurer_cheque"
+)
+
+// IsValid reports whether m is a known payment method.
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case MethodCash, MethodCard, MethodETransfer, MethodInsurerCheque:
+		return true
+	}
+	return false
+}
+
+// Payer mirrors the SQL enum app.payment_payer
+type Payer string
+
+const (
+	PayerCustomer Payer = "customer"
+	PayerInsurer  Payer = "insurer"
+)
+
+// IsValid reports whether p is a known payer.
+func (p Payer) IsValid() bool {
+	return p == PayerCustomer || p == PayerInsurer
+}
+
+// Line ↔ app.invoice_lines (a frozen copy of the approved estimate line)
+type Line struct {
+	ID             uuid.UUID         `json:"id"`
+	LineNo         int               `json:"lineNo"`
+	Category       estimate.Category `json:"category"`
+	Description    string            `json:"description"`
+	Quantity       float64           `json:"quantity"`
+	UnitPriceCents int64             `json:"unitPriceCents"`
+	AmountCents    int64             `json:"amountCents"`
+	Taxable        bool              `json:"taxable"`
+	TaxCents       int64             `json:"taxCents"`
+}
+
+// Payment ↔ app.payments
+type Payment struct {
+	ID               uuid.UUID     `json:"id"`
+	InvoiceID        uuid.UUID     `json:"invoiceId"`
+	Method           PaymentMethod `json:"method"`
+	Payer            Payer         `json:"payer"`
+	AmountCents      int64         `json:"amountCents"`
+	Reference        *string       `json:"reference,omitempty"`
+	Notes            *string       `json:"notes,omitempty"`
+	ReceivedAt       time.Time     `json:"receivedAt"`
+	RecordedByUserID *uuid.UUID    `json:"recordedByUserId,omitempty"`
+	CreatedAt        time.Time     `json:"createdAt"`
+}
+
+// Invoice ↔ app.invoices (with lines and payments)
+type Invoice struct {
+	ID                   uuid.UUID             `json:"id"`
+	Number               string                `json:"number"` // e.g., INV-CAL01-0001
+	ShopID               uuid.UUID             `json:"shopId"`
+	WorkOrderID          uuid.UUID             `json:"workOrderId"`
+	EstimateID           uuid.UUID             `json:"estimateId"`
+	Status               Status                `json:"status"`
+	Province             string                `json:"province"`
+	TaxDate              time.Time             `json:"taxDate"`
+	SubtotalCents        int64                 `json:"subtotalCents"`
+	TaxCents             int64                 `json:"taxCents"`
+	TotalCents           int64                 `json:"totalCents"`
+	DeductibleCents      int64                 `json:"deductibleCents"`
+	CustomerPortionCents int64                 `json:"customerPortionCents"`
+	InsurerPortionCents  int64                 `json:"insurerPortionCents"`
+	TaxComponents        []tax.ComponentAmount `json:"taxComponents"`
+	IssuedAt             time.Time             `json:"issuedAt"`
+	VoidedAt             *time.Time            `json:"voidedAt,omitempty"`
+	VoidedByUserID       *uuid.UUID            `json:"voidedByUserId,omitempty"`
+	CreatedByUserID      *uuid.UUID            `json:"createdByUserId,omitempty"`
+	CreatedAt            time.Time             `json:"createdAt"`
+	UpdatedAt            time.Time             `json:"updatedAt"`
+	Lines                []Line                `json:"lines"`
+	Payments             []Payment             `json:"payments"`
+	Balance              Balance               `json:"balance"`
+}
+
+// Balance summarises what has been paid against an invoice, overall and per payer.
+type Balance struct {
+	PaidCents         int64 `json:"paidCents"`
+	DueCents          int64 `json:"dueCents"`
+	CustomerPaidCents int64 `json:"customerPaidCents"`
+	CustomerDueCents  int64 `json:"customerDueCents"`
+	InsurerPaidCents  int64 `json:"insurerPaidCents"`
+	InsurerDueCents   int64 `json:"insurerDueCents"`
+}
+
+// ComputeBalance derives the balance from the invoice's portions and payments.
+// A void invoice has nothing due.
+func (inv *Invoice) ComputeBalance() {
+	var b Balance
+	for _, p := range inv.Payments {
+		b.PaidCents += p.AmountCents
+		switch p.Payer {
+		case PayerCustomer:
+			b.CustomerPaidCents += p.AmountCents
+		case PayerInsurer:
+			b.InsurerPaidCents += p.AmountCents
+		}
+	}
+	if inv.Status != StatusVoid {
+		b.DueCents = max(inv.TotalCents-b.PaidCents, 0)
+		b.CustomerDueCents = max(inv.CustomerPortionCents-b.CustomerPaidCents, 0)
+		b.InsurerDueCents = max(inv.InsurerPortionCents-b.InsurerPaidCents, 0)
+	}
+	inv.Balance = b
+}
+
+// CheckPayment rejects a payment beyond the balance due or beyond the unpaid
+// part of the payer's portion, so an insurer can't pay an invoice it has no
+// share of and a customer can't pay more than their deductible.
+func (b Balance) CheckPayment(payer Payer, amountCents int64) error {
+	if amountCents > b.DueCents {
+		return ErrOverpayment
+	}
+	due, who := b.CustomerDueCents, "customer"
+	if payer == PayerInsurer {
+		due, who = b.InsurerDueCents, "insurer"
+	}
+	if amountCents > due {
+		if due == 0 {
+			return NewValidationError("payer", fmt.Sprintf("nothing is due from the %s", who))
+		}
+		return NewValidationError("amountCents", fmt.Sprintf("exceeds the %d cents due from the %s", due, who))
+	}
+	return nil
+}
+
+// SplitPortions divides an invoice total between customer and insurer.
+// With insurance the customer owes the deductible (capped at the total) and the
+// insurer the rest; without insurance the customer owes everything.
+func SplitPortions(totalCents, deductibleCents int64, insured bool) (customer, insurer int64) {
+	if !insured || totalCents <= 0 {
+		return totalCents, 0
+	}
+	customer = min(max(deductibleCents, 0), totalCents)
+	return customer, totalCents - customer
+}
+
+// StatusForPaid returns the invoice status implied by the amount paid so far.
+func StatusForPaid(totalCents, paidCents int64) Status {
+	switch {
+	case paidCents >= totalCents:
+		return StatusPaid
+	case paidCents > 0:
+		return StatusPartiallyPaid
+	default:
+		return StatusIssued
+	}
+}
+
+// RecordPaymentInput represents the payload for POST /invoices/{id}/payments
+type RecordPaymentInput struct {
+	Method      PaymentMethod `json:"method"`
+	Payer       Payer         `json:"payer"`
+	AmountCents int64         `json:"amountCents"`
+	Reference   *string       `json:"reference,omitempty"`
+	Notes       *string       `json:"notes,omitempty"`
+	ReceivedAt  *time.Time    `json:"receivedAt,omitempty"` // defaults to now
+}
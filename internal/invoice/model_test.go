@@ -0,0 +1,95 @@
+package invoice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test: deductible split between customer and insurer
+func TestSplitPortions(t *testing.T) {
+	tests := []struct {
+		name                string
+		total, deductible   int64
+		insured             bool
+		customer, insurance int64
+	}{
+		{"insured, deductible below total", 250000, 50000, true, 50000, 200000},
+		{"insured, deductible above total", 30000, 50000, true, 30000, 0},
+		{"insured, no deductible", 100000, 0, true, 0, 100000},
+		{"uninsured pays everything", 100000, 50000, false, 100000, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, i := SplitPortions(tc.total, tc.deductible, tc.insured)
+			assert.Equal(t, tc.customer, c)
+			assert.Equal(t, tc.insurance, i)
+			assert.Equal(t, tc.total, c+i)
+		})
+	}
+}
+
+// Test: status follows the amount paid
+func TestStatusForPaid(t *testing.T) {
+	assert.Equal(t, StatusIssued, StatusForPaid(10000, 0))
+	assert.Equal(t, StatusPartiallyPaid, StatusForPaid(10000, 2500))
+	assert.Equal(t, StatusPaid, StatusForPaid(10000, 10000))
+}
+
+// Test: balance is tracked overall and per payer
+func TestComputeBalance(t *testing.T) {
+	inv := &Invoice{
+		Status:               StatusPartiallyPaid,
+		TotalCents:           250000,
+		CustomerPortionCents: 50000,
+		InsurerPortionCents:  200000,
+		Payments: []Payment{
+			{Payer: PayerCustomer, Method: MethodCard, AmountCents: 50000},
+			{Payer: PayerInsurer, Method: MethodInsurerCheque, AmountCents: 150000},
+		},
+	}
+	inv.ComputeBalance()
+
+	assert.Equal(t, int64(200000), inv.Balance.PaidCents)
+	assert.Equal(t, int64(50000), inv.Balance.DueCents)
+	assert.Equal(t, int64(0), inv.Balance.CustomerDueCents)
+	assert.Equal(t, int64(50000), inv.Balance.InsurerDueCents)
+
+	inv.Status = StatusVoid
+	inv.ComputeBalance()
+	assert.Equal(t, int64(0), inv.Balance.DueCents)
+}
+
+// Test: each payer can only pay down their own portion
+func TestCheckPayment(t *testing.T) {
+	// $500 deductible on a $2,500 claim, customer has paid $200
+	insured := &Invoice{
+		Status:               StatusPartiallyPaid,
+		TotalCents:           250000,
+		CustomerPortionCents: 50000,
+		InsurerPortionCents:  200000,
+		Payments:             []Payment{{Payer: PayerCustomer, Method: MethodCard, AmountCents: 20000}},
+	}
+	insured.ComputeBalance()
+
+	assert.NoError(t, insured.Balance.CheckPayment(PayerCustomer, 30000))
+	assert.NoError(t, insured.Balance.CheckPayment(PayerInsurer, 200000))
+
+	err := insured.Balance.CheckPayment(PayerCustomer, 30001)
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr, "customer overpaying the deductible") {
+		assert.Equal(t, "amountCents", verr.Field)
+	}
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.ErrorIs(t, insured.Balance.CheckPayment(PayerInsurer, 230001), ErrOverpayment)
+
+	// Uninsured: the customer owes everything, the insurer nothing
+	uninsured := &Invoice{Status: StatusIssued, TotalCents: 100000, CustomerPortionCents: 100000}
+	uninsured.ComputeBalance()
+
+	assert.NoError(t, uninsured.Balance.CheckPayment(PayerCustomer, 100000))
+	err = uninsured.Balance.CheckPayment(PayerInsurer, 1000)
+	if assert.ErrorAs(t, err, &verr, "insurer paying an invoice with no insurer portion") {
+		assert.Equal(t, "payer", verr.Field)
+	}
+}
@@ -0,0 +1,325 @@
+package invoice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository defines the persistence contract for invoices and payments.
+type Repository interface {
+	Create(ctx context.Context, inv *Invoice) (*Invoice, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*Invoice, error)
+	ListByWorkOrder(ctx context.Context, workOrderID uuid.UUID) ([]*Invoice, error)
+	RecordPayment(ctx context.Context, p *Payment) (*Invoice, error)
+	Void(ctx context.Context, id uuid.UUID, byUserID uuid.UUID) (*Invoice, error)
+}
+
+type pgRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository constructs a Postgres-backed invoice repository.
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgRepo{db: db}
+}
+
+/* ---------- error mapping ---------- */
+
+func mapPgError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.UniqueViolation:
+			if pgErr.ConstraintName == "uq_invoices_one_active_per_work_order" {
+				return ErrAlreadyInvoiced
+			}
+			return ErrConflict
+		case pgerrcode.ForeignKeyViolation, pgerrcode.CheckViolation, pgerrcode.NotNullViolation,
+			pgerrcode.InvalidTextRepresentation:
+			return ErrInvalidInput
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+/* ---------- queries ---------- */
+
+const invoiceSelect = `
+SELECT id, number, shop_id, work_order_id, estimate_id, status, province, tax_date,
+       subtotal_cents, tax_cents, total_cents, deductible_cents,
+       customer_portion_cents, insurer_portion_cents, tax_components,
+       issued_at, voided_at, voided_by_user_id, created_by_user_id, created_at, updated_at
+FROM app.invoices
+`
+
+func scanInvoice(row pgx.Row) (*Invoice, error) {
+	var inv Invoice
+	var components []byte
+	if err := row.Scan(
+		&inv.ID, &inv.Number, &inv.ShopID, &inv.WorkOrderID, &inv.EstimateID, &inv.Status, &inv.Province, &inv.TaxDate,
+		&inv.SubtotalCents, &inv.TaxCents, &inv.TotalCents, &inv.DeductibleCents,
+		&inv.CustomerPortionCents, &inv.InsurerPortionCents, &components,
+		&inv.IssuedAt, &inv.VoidedAt, &inv.VoidedByUserID, &inv.CreatedByUserID, &inv.CreatedAt, &inv.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(components, &inv.TaxComponents); err != nil {
+		return nil, fmt.Errorf("decode tax components: %w", err)
+	}
+	inv.Lines = make([]Line, 0)
+	inv.Payments = make([]Payment, 0)
+	return &inv, nil
+}
+
+// loadDetails fills in lines and payments and computes the balance.
+func (r *pgRepo) loadDetails(ctx context.Context, inv *Invoice) error {
+	rows, err := r.db.Query(ctx, `
+SELECT id, line_no, category, description, quantity::float8, unit_price_cents, amount_cents, taxable, tax_cents
+FROM app.invoice_lines
+WHERE invoice_id = $1
+ORDER BY line_no
+`, inv.ID)
+	if err != nil {
+		return fmt.Errorf("load invoice lines: %w", err)
+	}
+	for rows.Next() {
+		var l Line
+		if err := rows.Scan(&l.ID, &l.LineNo, &l.Category, &l.Description, &l.Quantity,
+			&l.UnitPriceCents, &l.AmountCents, &l.Taxable, &l.TaxCents); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan invoice line: %w", err)
+		}
+		inv.Lines = append(inv.Lines, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = r.db.Query(ctx, `
+SELECT id, invoice_id, method, payer, amount_cents, reference, notes, received_at, recorded_by_user_id, created_at
+FROM app.payments
+WHERE invoice_id = $1
+ORDER BY received_at, created_at
+`, inv.ID)
+	if err != nil {
+		return fmt.Errorf("load payments: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p Payment
+		if err := rows.Scan(&p.ID, &p.InvoiceID, &p.Method, &p.Payer, &p.AmountCents, &p.Reference, &p.Notes,
+			&p.ReceivedAt, &p.RecordedByUserID, &p.CreatedAt); err != nil {
+			return fmt.Errorf("scan payment: %w", err)
+		}
+		inv.Payments = append(inv.Payments, p)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	inv.ComputeBalance()
+	return nil
+}
+
+func (r *pgRepo) Create(ctx context.Context, inv *Invoice) (*Invoice, error) {
+	components, err := json.Marshal(inv.TaxComponents)
+	if err != nil {
+		return nil, fmt.Errorf("encode tax components: %w", err)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// number is left empty so trg_invoice_set_number assigns the next per-shop number.
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+INSERT INTO app.invoices
+(number, shop_id, work_order_id, estimate_id, status, province, tax_date,
+ subtotal_cents, tax_cents, total_cents, deductible_cents,
+ customer_portion_cents, insurer_portion_cents, tax_components, created_by_user_id)
+VALUES ('', $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING id
+`, inv.ShopID, inv.WorkOrderID, inv.EstimateID, StatusIssued, inv.Province, inv.TaxDate,
+		inv.SubtotalCents, inv.TaxCents, inv.TotalCents, inv.DeductibleCents,
+		inv.CustomerPortionCents, inv.InsurerPortionCents, components, inv.CreatedByUserID).Scan(&id)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+
+	for i, l := range inv.Lines {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO app.invoice_lines
+(invoice_id, line_no, category, description, quantity, unit_price_cents, amount_cents, taxable, tax_cents)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`, id, i+1, l.Category, l.Description, l.Quantity, l.UnitPriceCents, l.AmountCents, l.Taxable, l.TaxCents); err != nil {
+			return nil, mapPgError(err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *pgRepo) GetByID(ctx context.Context, id uuid.UUID) (*Invoice, error) {
+	inv, err := scanInvoice(r.db.QueryRow(ctx, invoiceSelect+` WHERE id = $1`, id))
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	if err := r.loadDetails(ctx, inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (r *pgRepo) ListByWorkOrder(ctx context.Context, workOrderID uuid.UUID) ([]*Invoice, error) {
+	rows, err := r.db.Query(ctx, invoiceSelect+` WHERE work_order_id = $1 ORDER BY issued_at DESC`, workOrderID)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	list := make([]*Invoice, 0)
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan invoice: %w", err)
+		}
+		list = append(list, inv)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A work order has a handful of invoices at most (one live plus any voided), so per-invoice loads are fine.
+	for _, inv := range list {
+		if err := r.loadDetails(ctx, inv); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// RecordPayment inserts a payment and moves the invoice status along.
+// The invoice row is locked so concurrent payments can't both pass the balance
+// and payer portion checks.
+func (r *pgRepo) RecordPayment(ctx context.Context, p *Payment) (*Invoice, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	inv := &Invoice{ID: p.InvoiceID}
+	err = tx.QueryRow(ctx, `
+SELECT status, total_cents, customer_portion_cents, insurer_portion_cents
+FROM app.invoices WHERE id = $1 FOR UPDATE
+`, p.InvoiceID).Scan(&inv.Status, &inv.TotalCents, &inv.CustomerPortionCents, &inv.InsurerPortionCents)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	if inv.Status == StatusVoid {
+		return nil, ErrInvoiceVoid
+	}
+
+	// One summed payment per payer is enough for the balance
+	rows, err := tx.Query(ctx, `
+SELECT payer, SUM(amount_cents) FROM app.payments WHERE invoice_id = $1 GROUP BY payer
+`, p.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var paid Payment
+		if err := rows.Scan(&paid.Payer, &paid.AmountCents); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		inv.Payments = append(inv.Payments, paid)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	inv.ComputeBalance()
+	if err := inv.Balance.CheckPayment(p.Payer, p.AmountCents); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO app.payments (invoice_id, method, payer, amount_cents, reference, notes, received_at, recorded_by_user_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`, p.InvoiceID, p.Method, p.Payer, p.AmountCents, p.Reference, p.Notes, p.ReceivedAt, p.RecordedByUserID); err != nil {
+		return nil, mapPgError(err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE app.invoices SET status = $2 WHERE id = $1`,
+		p.InvoiceID, StatusForPaid(inv.TotalCents, inv.Balance.PaidCents+p.AmountCents)); err != nil {
+		return nil, mapPgError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, p.InvoiceID)
+}
+
+// Void marks an invoice void, freeing the work order to be invoiced again.
+// Invoices with payments can't be voided; refunds are handled outside the system.
+func (r *pgRepo) Void(ctx context.Context, id uuid.UUID, byUserID uuid.UUID) (*Invoice, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status Status
+	if err := tx.QueryRow(ctx, `SELECT status FROM app.invoices WHERE id = $1 FOR UPDATE`, id).Scan(&status); err != nil {
+		return nil, mapPgError(err)
+	}
+	if status == StatusVoid {
+		return nil, ErrInvoiceVoid
+	}
+
+	var hasPayments bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM app.payments WHERE invoice_id = $1)`, id).
+		Scan(&hasPayments); err != nil {
+		return nil, err
+	}
+	if hasPayments {
+		return nil, ErrHasPayments
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE app.invoices
+SET status = 'void', voided_at = $2, voided_by_user_id = $3
+WHERE id = $1
+`, id, time.Now(), byUserID); err != nil {
+		return nil, mapPgError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
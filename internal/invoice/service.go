@@ -0,0 +1,225 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/estimate"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/tax"
+	"github.com/google/uuid"
+)
+
+// workOrderStatusCompleted is the app.work_order_status value that unlocks invoicing.
+const workOrderStatusCompleted = "completed"
+
+// EstimateSource is the slice of the estimate repository the invoice service reads from.
+type EstimateSource interface {
+	GetWorkOrderRef(ctx context.Context, workOrderID uuid.UUID) (*estimate.WorkOrderRef, error)
+	GetApprovedByWorkOrder(ctx context.Context, workOrderID uuid.UUID) (*estimate.Estimate, error)
+}
+
+// Service defines business operations for invoices and payments.
+type Service interface {
+	Generate(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID) (*Invoice, error)
+	ListByWorkOrder(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID) ([]*Invoice, error)
+	GetByID(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Invoice, error)
+	RecordPayment(ctx context.Context, actor *auth.AuthUser, invoiceID uuid.UUID, in *RecordPaymentInput) (*Invoice, error)
+	Void(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Invoice, error)
+}
+
+type service struct {
+	repo      Repository
+	estimates EstimateSource
+	audit     audit.Recorder
+	now       func() time.Time
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates an invoice service. Payments and voids are recorded to
+// rec; nil records nothing.
+func NewService(repo Repository, estimates EstimateSource, rec audit.Recorder) Service {
+	return &service{repo: repo, estimates: estimates, audit: audit.OrNop(rec), now: time.Now}
+}
+
+// Generate issues an invoice for a completed work order from its approved estimate.
+// Lines, tax and the deductible split are frozen on the invoice at issue time.
+func (s *service) Generate(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID) (*Invoice, error) {
+	if !actor.Can(auth.PermInvoicesManage) {
+		return nil, ErrForbidden
+	}
+	ref, err := s.workOrderFor(ctx, actor, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Status != workOrderStatusCompleted {
+		return nil, ErrWorkOrderNotCompleted
+	}
+
+	est, err := s.estimates.GetApprovedByWorkOrder(ctx, workOrderID)
+	if err != nil {
+		if errors.Is(err, estimate.ErrNotFound) {
+			return nil, ErrNoApprovedEstimate
+		}
+		return nil, err
+	}
+
+	taxDate := s.now()
+	b, err := tax.Calculate(ref.ShopProvince, taxDate, est.TaxLines())
+	if err != nil {
+		return nil, fmt.Errorf("calculate tax for shop province %q: %w", ref.ShopProvince, err)
+	}
+
+	lines := make([]Line, 0, len(est.Lines))
+	for i, l := range est.Lines {
+		lines = append(lines, Line{
+			Category:       l.Category,
+			Description:    l.Description,
+			Quantity:       l.Quantity,
+			UnitPriceCents: l.UnitPriceCents,
+			AmountCents:    l.AmountCents,
+			Taxable:        l.Taxable,
+			TaxCents:       b.Lines[i].TaxCents,
+		})
+	}
+
+	customer, insurer := SplitPortions(b.TotalCents, est.DeductibleCents, ref.HasInsurance)
+	inv := &Invoice{
+		ShopID:               ref.ShopID,
+		WorkOrderID:          workOrderID,
+		EstimateID:           est.ID,
+		Province:             b.Province,
+		TaxDate:              taxDate,
+		SubtotalCents:        b.SubtotalCents,
+		TaxCents:             b.TaxCents,
+		TotalCents:           b.TotalCents,
+		DeductibleCents:      est.DeductibleCents,
+		CustomerPortionCents: customer,
+		InsurerPortionCents:  insurer,
+		TaxComponents:        b.Components,
+		CreatedByUserID:      &actor.ID,
+		Lines:                lines,
+	}
+	return s.repo.Create(ctx, inv)
+}
+
+func (s *service) ListByWorkOrder(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID) ([]*Invoice, error) {
+	if _, err := s.workOrderFor(ctx, actor, workOrderID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByWorkOrder(ctx, workOrderID)
+}
+
+func (s *service) GetByID(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Invoice, error) {
+	inv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.CanAccessShop(inv.ShopID) {
+		return nil, ErrNotFound
+	}
+	return inv, nil
+}
+
+func (s *service) RecordPayment(ctx context.Context, actor *auth.AuthUser, invoiceID uuid.UUID, in *RecordPaymentInput) (*Invoice, error) {
+	if !actor.Can(auth.PermInvoicesManage) {
+		return nil, ErrForbidden
+	}
+	before, err := s.GetByID(ctx, actor, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !in.Method.IsValid() {
+		return nil, NewValidationError("method", "must be one of cash, card, e_transfer, insurer_cheque")
+	}
+	if !in.Payer.IsValid() {
+		return nil, NewValidationError("payer", "must be customer or insurer")
+	}
+	if in.Method == MethodInsurerCheque && in.Payer != PayerInsurer {
+		return nil, NewValidationError("payer", "insurer cheques must be paid by the insurer")
+	}
+	if in.AmountCents <= 0 {
+		return nil, NewValidationError("amountCents", "must be greater than zero")
+	}
+
+	receivedAt := s.now()
+	if in.ReceivedAt != nil {
+		if in.ReceivedAt.After(receivedAt) {
+			return nil, NewValidationError("receivedAt", "cannot be in the future")
+		}
+		receivedAt = *in.ReceivedAt
+	}
+
+	p := &Payment{
+		InvoiceID:        invoiceID,
+		Method:           in.Method,
+		Payer:            in.Payer,
+		AmountCents:      in.AmountCents,
+		Reference:        trimPtr(in.Reference),
+		Notes:            trimPtr(in.Notes),
+		ReceivedAt:       receivedAt,
+		RecordedByUserID: &actor.ID,
+	}
+	inv, err := s.repo.RecordPayment(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, audit.Entry{
+		Action: "invoice.payment", EntityType: audit.EntityInvoice, EntityID: inv.ID.String(),
+		ShopID: &inv.ShopID, Before: before, After: inv,
+	})
+	return inv, nil
+}
+
+func (s *service) Void(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Invoice, error) {
+	if !actor.Can(auth.PermInvoicesManage) {
+		return nil, ErrForbidden
+	}
+	before, err := s.GetByID(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := s.repo.Void(ctx, id, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, audit.Entry{
+		Action: "invoice.void", EntityType: audit.EntityInvoice, EntityID: inv.ID.String(),
+		ShopID: &inv.ShopID, Before: before, After: inv,
+	})
+	return inv, nil
+}
+
+/* ---------- helpers ---------- */
+
+// workOrderFor loads the work order and checks the actor belongs to its shop.
+func (s *service) workOrderFor(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID) (*estimate.WorkOrderRef, error) {
+	ref, err := s.estimates.GetWorkOrderRef(ctx, workOrderID)
+	if err != nil {
+		if errors.Is(err, estimate.ErrWorkOrderNotFound) {
+			return nil, ErrWorkOrderNotFound
+		}
+		return nil, err
+	}
+	if !actor.CanAccessShop(ref.ShopID) {
+		return nil, ErrWorkOrderNotFound
+	}
+	return ref, nil
+}
+
+func trimPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,35 @@
+package organization
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain-level errors for organization operations
+var (
+	ErrNotFound     = errors.New("organization not found")
+	ErrConflict     = errors.New("organization conflict")
+	ErrInvalidInput = errors.New("invalid organization input")
+	ErrForbidden    = errors.New("forbidden: insufficient permissions")
+)
+
+// ValidationError represents validation errors with specific field information
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap allows errors.Is to work with ValidationError
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// NewValidationError creates a new ValidationError
+func NewValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
@@ -0,0 +1,40 @@
+package organization
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status mirrors app.shop_status, which organizations share with shops.
+type Status string
+
+const (
+	Active   Status = "active"
+	Inactive Status = "inactive"
+)
+
+// Organization ↔ app.organizations. An organization (franchise brand) owns
+// shops; org admins act on every shop of their organization.
+type Organization struct {
+	ID        uuid.UUID `json:"id"`
+	Code      string    `json:"code"` // e.g. "DEFAULT", "HAVENZ"
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	ShopCount int       `json:"shopCount"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CreateInput is the body of POST /organizations.
+type CreateInput struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// UpdateInput is the body of PUT /organizations/{id}. Omitted fields are left
+// unchanged; the code never changes.
+type UpdateInput struct {
+	Name   *string `json:"name,omitempty"`
+	Status *Status `json:"status,omitempty"`
+}
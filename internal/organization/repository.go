@@ -0,0 +1,113 @@
+package organization
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository defines the persistence contract for organizations.
+type Repository interface {
+	List(ctx context.Context) ([]*Organization, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*Organization, error)
+	Create(ctx context.Context, in *CreateInput) (*Organization, error)
+	// Update leaves nil fields unchanged.
+	Update(ctx context.Context, id uuid.UUID, name *string, status *Status) (*Organization, error)
+}
+
+type pgRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository constructs a Postgres-backed organization repository.
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgRepo{db: db}
+}
+
+/* ---------- error mapping ---------- */
+
+func mapPgError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.UniqueViolation:
+			return ErrConflict
+		case pgerrcode.CheckViolation, pgerrcode.NotNullViolation:
+			return ErrInvalidInput
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+/* ---------- queries ---------- */
+
+const orgSelect = `
+SELECT o.id, o.code, o.name, o.status,
+       (SELECT count(*) FROM app.shop s WHERE s.organization_id = o.id),
+       o.created_at, o.updated_at
+FROM app.organizations o
+`
+
+func scanOrganization(row pgx.Row) (*Organization, error) {
+	var o Organization
+	if err := row.Scan(&o.ID, &o.Code, &o.Name, &o.Status, &o.ShopCount, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *pgRepo) List(ctx context.Context) ([]*Organization, error) {
+	rows, err := r.db.Query(ctx, orgSelect+` ORDER BY o.name, o.code`)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	defer rows.Close()
+
+	list := make([]*Organization, 0)
+	for rows.Next() {
+		o, err := scanOrganization(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+func (r *pgRepo) GetByID(ctx context.Context, id uuid.UUID) (*Organization, error) {
+	o, err := scanOrganization(r.db.QueryRow(ctx, orgSelect+` WHERE o.id = $1`, id))
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	return o, nil
+}
+
+func (r *pgRepo) Create(ctx context.Context, in *CreateInput) (*Organization, error) {
+	var id uuid.UUID
+	if err := r.db.QueryRow(ctx,
+		`INSERT INTO app.organizations (code, name) VALUES ($1, $2) RETURNING id`, in.Code, in.Name).Scan(&id); err != nil {
+		return nil, mapPgError(err)
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *pgRepo) Update(ctx context.Context, id uuid.UUID, name *string, status *Status) (*Organization, error) {
+	if _, err := r.db.Exec(ctx, `
+UPDATE app.organizations
+SET name = COALESCE($2, name), status = COALESCE($3::app.shop_status, status)
+WHERE id = $1`, id, name, status); err != nil {
+		return nil, mapPgError(err)
+	}
+	return r.GetByID(ctx, id)
+}
@@ -0,0 +1,148 @@
+// Package organization manages organizations, the franchise brands that own
+// shops. SuperAdmin manages every organization; other users may read their
+// own. Scoping of shops, users and work orders to an organization happens in
+// those packages' repositories (see auth.OrganizationScope).
+package organization
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/google/uuid"
+)
+
+// Service defines business operations for organizations.
+type Service interface {
+	List(ctx context.Context, actor *auth.AuthUser) ([]*Organization, error)
+	Get(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Organization, error)
+	Create(ctx context.Context, actor *auth.AuthUser, in *CreateInput) (*Organization, error)
+	Update(ctx context.Context, actor *auth.AuthUser, id uuid.UUID, in *UpdateInput) (*Organization, error)
+}
+
+// Same rule as ck_organizations_code
+var codeRegex = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{1,9}$`)
+
+const maxNameLength = 100
+
+type service struct {
+	repo  Repository
+	audit audit.Recorder
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates an organization service. Changes are recorded to rec;
+// nil records nothing.
+func NewService(repo Repository, rec audit.Recorder) Service {
+	return &service{repo: repo, audit: audit.OrNop(rec)}
+}
+
+// List returns every organization to SuperAdmin and only the caller's own to
+// everyone else.
+func (s *service) List(ctx context.Context, actor *auth.AuthUser) ([]*Organization, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	if actor.IsSuperAdmin() {
+		return s.repo.List(ctx)
+	}
+	if actor.OrganizationID == nil {
+		return []*Organization{}, nil
+	}
+	o, err := s.repo.GetByID(ctx, *actor.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	return []*Organization{o}, nil
+}
+
+func (s *service) Get(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Organization, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	// Don't reveal whether another organization exists
+	if !actor.IsSuperAdmin() && (actor.OrganizationID == nil || *actor.OrganizationID != id) {
+		return nil, ErrNotFound
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) Create(ctx context.Context, actor *auth.AuthUser, in *CreateInput) (*Organization, error) {
+	if actor == nil || !actor.IsSuperAdmin() {
+		return nil, ErrForbidden
+	}
+	if in == nil {
+		return nil, ErrInvalidInput
+	}
+
+	in.Code = strings.ToUpper(strings.TrimSpace(in.Code))
+	in.Name = strings.TrimSpace(in.Name)
+	if !codeRegex.MatchString(in.Code) {
+		return nil, NewValidationError("code", "must be 2-10 characters: A-Z, 0-9, '_' or '-'")
+	}
+	if err := validateName(in.Name); err != nil {
+		return nil, err
+	}
+	o, err := s.repo.Create(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, audit.Entry{
+		Action: "organization.create", EntityType: audit.EntityOrganization, EntityID: o.ID.String(),
+		OrganizationID: &o.ID, After: o,
+	})
+	return o, nil
+}
+
+// Update is SuperAdmin only. Status is recorded for the admin UI; it does not
+// by itself deactivate the organization's shops or users.
+func (s *service) Update(ctx context.Context, actor *auth.AuthUser, id uuid.UUID, in *UpdateInput) (*Organization, error) {
+	if actor == nil || !actor.IsSuperAdmin() {
+		return nil, ErrForbidden
+	}
+	if in == nil {
+		return nil, ErrInvalidInput
+	}
+
+	if in.Name != nil {
+		name := strings.TrimSpace(*in.Name)
+		if err := validateName(name); err != nil {
+			return nil, err
+		}
+		in.Name = &name
+	}
+	if in.Status != nil {
+		st := Status(strings.ToLower(strings.TrimSpace(string(*in.Status))))
+		if st != Active && st != Inactive {
+			return nil, NewValidationError("status", "must be 'active' or 'inactive'")
+		}
+		in.Status = &st
+	}
+
+	before, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	o, err := s.repo.Update(ctx, id, in.Name, in.Status)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, audit.Entry{
+		Action: "organization.update", EntityType: audit.EntityOrganization, EntityID: o.ID.String(),
+		OrganizationID: &o.ID, Before: before, After: o,
+	})
+	return o, nil
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return NewValidationError("name", "is required")
+	}
+	if len(name) > maxNameLength {
+		return NewValidationError("name", "is too long")
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package organization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* ---------- fakes ---------- */
+
+type fakeRepo struct {
+	orgs map[uuid.UUID]*Organization
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{orgs: make(map[uuid.UUID]*Organization)}
+}
+
+func (r *fakeRepo) add(code string) *Organization {
+	o := &Organization{ID: uuid.New(), Code: code, Name: "Org " + code, Status: Active}
+	r.orgs[o.ID] = o
+	return o
+}
+
+func (r *fakeRepo) List(context.Context) ([]*Organization, error) {
+	out := make([]*Organization, 0, len(r.orgs))
+	for _, o := range r.orgs {
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+func (r *fakeRepo) GetByID(_ context.Context, id uuid.UUID) (*Organization, error) {
+	o, ok := r.orgs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *o
+	return &cp, nil
+}
+
+func (r *fakeRepo) Create(_ context.Context, in *CreateInput) (*Organization, error) {
+	for _, o := range r.orgs {
+		if o.Code == in.Code {
+			return nil, ErrConflict
+		}
+	}
+	o := &Organization{ID: uuid.New(), Code: in.Code, Name: in.Name, Status: Active}
+	r.orgs[o.ID] = o
+	return o, nil
+}
+
+func (r *fakeRepo) Update(_ context.Context, id uuid.UUID, name *string, status *Status) (*Organization, error) {
+	o, ok := r.orgs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if name != nil {
+		o.Name = *name
+	}
+	if status != nil {
+		o.Status = *status
+	}
+	return o, nil
+}
+
+/* ---------- fixtures ---------- */
+
+func superAdmin() *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleSuperAdmin, IsActive: true}
+}
+
+func orgAdminOf(org uuid.UUID) *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleOrgAdmin, Permissions: []string{auth.PermOrgShops}, OrganizationID: &org, IsActive: true}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// auditLog collects audit entries in memory.
+type auditLog struct{ entries []audit.Entry }
+
+func (l *auditLog) Record(_ context.Context, e audit.Entry) { l.entries = append(l.entries, e) }
+
+/* ---------- tests ---------- */
+
+// Test: org admins only ever see their own organization
+func TestReadOwnOrganization(t *testing.T) {
+	repo := newFakeRepo()
+	a, b := repo.add("A"), repo.add("B")
+	svc := NewService(repo, nil)
+	ctx := context.Background()
+
+	list, err := svc.List(ctx, orgAdminOf(a.ID))
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, a.ID, list[0].ID)
+
+	_, err = svc.Get(ctx, orgAdminOf(a.ID), b.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	list, err = svc.List(ctx, superAdmin())
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+func TestCreateOrganization(t *testing.T) {
+	repo := newFakeRepo()
+	svc := NewService(repo, nil)
+	ctx := context.Background()
+
+	org, err := svc.Create(ctx, superAdmin(), &CreateInput{Code: " havenz ", Name: " Havenz Collision "})
+	require.NoError(t, err)
+	assert.Equal(t, "HAVENZ", org.Code)
+	assert.Equal(t, "Havenz Collision", org.Name)
+
+	_, err = svc.Create(ctx, orgAdminOf(org.ID), &CreateInput{Code: "OTHER", Name: "Other"})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = svc.Create(ctx, superAdmin(), &CreateInput{Code: "BAD CODE", Name: "Bad"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = svc.Create(ctx, superAdmin(), &CreateInput{Code: "NONAME", Name: " "})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestUpdateOrganization(t *testing.T) {
+	repo := newFakeRepo()
+	a := repo.add("A")
+	svc := NewService(repo, nil)
+	ctx := context.Background()
+
+	org, err := svc.Update(ctx, superAdmin(), a.ID, &UpdateInput{Status: ptr(Status("INACTIVE"))})
+	require.NoError(t, err)
+	assert.Equal(t, Inactive, org.Status)
+	assert.Equal(t, "Org A", org.Name, "omitted fields are left alone")
+
+	_, err = svc.Update(ctx, superAdmin(), a.ID, &UpdateInput{Status: ptr(Status("closed"))})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = svc.Update(ctx, orgAdminOf(a.ID), a.ID, &UpdateInput{Name: ptr("Renamed")})
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+// Test: creates and updates are audited under the organization itself
+func TestOrganizationChangesAreAudited(t *testing.T) {
+	log := &auditLog{}
+	svc := NewService(newFakeRepo(), log)
+	ctx := context.Background()
+
+	org, err := svc.Create(ctx, superAdmin(), &CreateInput{Code: "acme", Name: "Acme"})
+	require.NoError(t, err)
+	_, err = svc.Update(ctx, superAdmin(), org.ID, &UpdateInput{Name: ptr("Acme Collision")})
+	require.NoError(t, err)
+	_, err = svc.Update(ctx, superAdmin(), uuid.New(), &UpdateInput{Name: ptr("Ghost")})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.Len(t, log.entries, 2)
+	assert.Equal(t, "organization.create", log.entries[0].Action)
+	update := log.entries[1]
+	assert.Equal(t, "organization.update", update.Action)
+	assert.Equal(t, &org.ID, update.OrganizationID)
+	assert.Equal(t, "Acme", update.Before.(*Organization).Name)
+	assert.Equal(t, "Acme Collision", update.After.(*Organization).Name)
+}
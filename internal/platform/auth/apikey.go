@@ -0,0 +1,33 @@
+package auth
+
+import "strings"
+
+// APIKeyPrefix starts every API key (hz_<prefix>_<secret>), which is how the
+// auth middleware tells keys apart from ID tokens.
+const APIKeyPrefix = "hz_"
+
+// IsAPIKeyToken checks if a bearer token looks like an API key
+func IsAPIKeyToken(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// API key scopes. A key may only call routes that declare one of its scopes.
+const (
+	ScopeWorkOrdersRead = "workorders:read" // list/read work orders and the CSV export
+	ScopeImagesWrite    = "images:write"    // register uploaded work order images
+	ScopeScansCallback  = "scans:callback"  // AI scan worker result callbacks
+)
+
+// Scopes lists every scope a key can be granted. Add one here only together
+// with a route that checks it.
+var Scopes = []string{ScopeWorkOrdersRead, ScopeImagesWrite, ScopeScansCallback}
+
+// IsValidScope checks if scope is one of Scopes
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package auth
+
+import "math"
+
+// TokenVersionClaim is the Firebase custom claim carrying app.users.token_version.
+// A token whose claim is older than the DB value has been revoked.
+const TokenVersionClaim = "tv"
+
+// InitialTokenVersion is the token_version every user starts with. A token
+// without TokenVersionClaim counts as carrying it.
+const InitialTokenVersion = 1
+
+// TokenVersionFromClaims reads the token version from decoded ID token claims.
+// ok is false when the claim is missing or not a whole number (tokens issued
+// before the claim was introduced).
+func TokenVersionFromClaims(claims map[string]interface{}) (version int, ok bool) {
+	// Claims are decoded from JSON, so numbers arrive as float64.
+	switch v := claims[TokenVersionClaim].(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		return int(v), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,27 @@
+package auth
+
+import "testing"
+
+func TestTokenVersionFromClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		want   int
+		wantOK bool
+	}{
+		{"json number", map[string]interface{}{"tv": float64(3)}, 3, true},
+		{"int", map[string]interface{}{"tv": 2}, 2, true},
+		{"missing", map[string]interface{}{"email": "a@b.co"}, 0, false},
+		{"nil claims", nil, 0, false},
+		{"fractional", map[string]interface{}{"tv": 1.5}, 0, false},
+		{"string", map[string]interface{}{"tv": "4"}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := TokenVersionFromClaims(tt.claims)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("TokenVersionFromClaims() = %d, %v; want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -13,11 +13,19 @@ import (
 // contextKey is a private type for context keys to avoid collisions
 type contextKey string
 
-const authUserKey contextKey = "authUser"
+const (
+	authUserKey     contextKey = "authUser"
+	organizationKey contextKey = "organizationID"
+)
+
+// OrganizationHeader lets a SuperAdmin act within one organization
+// (value: the organization's UUID). Other users are always scoped to their own.
+const OrganizationHeader = "X-Organization-ID"
 
 // Canonical role codes used across the system.
 const (
 	RoleSuperAdmin = "superadmin"
+	RoleOrgAdmin   = "orgadmin"
 	RoleAdmin      = "admin"
 	RoleAdjuster   = "adjuster"
 	RoleBodyman    = "bodyman"
@@ -26,13 +34,25 @@ const (
 // AuthUser is the user information injected into request context
 // Contains limited fields needed for authorization and auditing
 type AuthUser struct {
-	ID           uuid.UUID  `json:"id"`
-	Email        string     `json:"email"`
-	ExternalID   string     `json:"externalId"`       // GCIP UID
-	RoleCode     string     `json:"roleCode"`         // "superadmin", "admin", "adjuster", "bodyman"
-	ShopID       *uuid.UUID `json:"shopId,omitempty"` // Nullable
-	TokenVersion int        `json:"tokenVersion"`     // For token revocation
-	IsActive     bool       `json:"isActive"`
+	ID         uuid.UUID  `json:"id"`
+	Email      string     `json:"email"`
+	ExternalID string     `json:"externalId"`       // GCIP UID
+	RoleCode   string     `json:"roleCode"`         // "superadmin", "admin", "adjuster", "bodyman"
+	ShopID     *uuid.UUID `json:"shopId,omitempty"` // Nullable
+	// OrganizationID is the organization the request is scoped to: the user's
+	// own, or for a SuperAdmin the one chosen with OrganizationHeader (nil
+	// means every organization).
+	OrganizationID *uuid.UUID `json:"organizationId,omitempty"`
+	TokenVersion   int        `json:"tokenVersion"` // For token revocation
+	IsActive       bool       `json:"isActive"`
+	// Permissions granted to RoleCode (see Can)
+	Permissions []string `json:"permissions,omitempty"`
+
+	// Set only when the request authenticated with an API key instead of a user
+	// token. Key principals have no RoleCode, so role checks never pass for them;
+	// what they may do is limited to Scopes.
+	APIKeyID *uuid.UUID `json:"apiKeyId,omitempty"`
+	Scopes   []string   `json:"scopes,omitempty"`
 }
 
 // SetAuthUser injects AuthUser into context
@@ -50,6 +70,22 @@ func GetAuthUser(ctx context.Context) (*AuthUser, error) {
 	return user, nil
 }
 
+// WithOrganization scopes ctx to an organization. The shop, user and work
+// order repositories only see rows of that organization.
+func WithOrganization(ctx context.Context, orgID uuid.UUID) context.Context {
+	return context.WithValue(ctx, organizationKey, orgID)
+}
+
+// OrganizationScope returns the organization ctx is scoped to, or nil when it
+// is unscoped (SuperAdmin without OrganizationHeader, system API keys,
+// background jobs).
+func OrganizationScope(ctx context.Context) *uuid.UUID {
+	if id, ok := ctx.Value(organizationKey).(uuid.UUID); ok {
+		return &id
+	}
+	return nil
+}
+
 // HasRole checks if user has any of the specified roles
 func (u *AuthUser) HasRole(roles ...string) bool {
 	for _, role := range roles {
@@ -84,3 +120,43 @@ func (u *AuthUser) IsStaff() bool {
 func (u *AuthUser) HasShop() bool {
 	return u.ShopID != nil
 }
+
+// IsAPIKey checks if the principal is an API key rather than a user
+func (u *AuthUser) IsAPIKey() bool {
+	return u.APIKeyID != nil
+}
+
+// IsSystemAPIKey checks if the principal is an API key not tied to a shop
+func (u *AuthUser) IsSystemAPIKey() bool {
+	return u.IsAPIKey() && u.ShopID == nil
+}
+
+// HasScope checks if an API key principal was granted scope.
+// Always false for users, who are governed by their role instead.
+func (u *AuthUser) HasScope(scope string) bool {
+	for _, s := range u.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOrgWide checks if user acts on every shop of their organization
+// (org.shops, e.g. orgadmin) rather than only their own shop.
+func (u *AuthUser) IsOrgWide() bool {
+	return !u.IsSuperAdmin() && u.Can(PermOrgShops)
+}
+
+// CanAccessShop checks if user may act on records belonging to shopID.
+// SuperAdmin and system-wide API keys can access every shop, and org-wide
+// users every shop of their organization; everyone else only their own.
+// For org-wide users the organization is not checked here: records are
+// loaded through repositories scoped by OrganizationScope, so a shop from
+// another organization is never found in the first place.
+func (u *AuthUser) CanAccessShop(shopID uuid.UUID) bool {
+	if u.IsSuperAdmin() || u.IsSystemAPIKey() || u.IsOrgWide() {
+		return true
+	}
+	return u.ShopID != nil && *u.ShopID == shopID
+}
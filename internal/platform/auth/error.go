@@ -24,6 +24,15 @@ var (
 	// ErrForbidden indicates insufficient permissions for the operation
 	ErrForbidden = errors.New("forbidden: insufficient permissions")
 
+	// ErrMissingScope indicates an API key lacks the scope a route requires
+	ErrMissingScope = errors.New("forbidden: API key lacks the required scope")
+
+	// ErrAPIKeyNotAllowed indicates an API key was sent to a route that only accepts user tokens
+	ErrAPIKeyNotAllowed = errors.New("API keys are not accepted on this endpoint")
+
+	// ErrInvalidOrganization indicates a malformed or disallowed OrganizationHeader
+	ErrInvalidOrganization = errors.New("invalid organization")
+
 	// ErrNoShopAssignment indicates user not assigned to any shop (required for some operations)
 	ErrNoShopAssignment = errors.New("user not assigned to any shop")
 )
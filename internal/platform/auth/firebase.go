@@ -10,9 +10,12 @@ import (
 	"log"
 	"os"
 	"sync"
+	"time"
 
 	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/config"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
@@ -25,27 +28,39 @@ var (
 )
 
 // InitFirebase initializes Firebase Admin SDK (GCIP)
-// Uses GOOGLE_APPLICATION_CREDENTIALS env var pointing to service account JSON file
-func InitFirebase(ctx context.Context) error {
+// Uses the service account JSON file cfg.CredentialsFile, unless
+// cfg.EmulatorHost is set, in which case the SDK talks to the Auth Emulator
+// and needs no credentials
+func InitFirebase(ctx context.Context, cfg config.Auth) error {
 	// Ensure singleton initialization
 	// No matter how many times called, only initialize once
 	once.Do(func() {
-		// Load from file path
-		credPath := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
-		if credPath == "" {
-			initError = fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS env var not set")
-			return
+		var opts []option.ClientOption
+		if host := cfg.EmulatorHost; host != "" {
+			// The Admin SDK only reads the emulator host from the environment
+			if err := os.Setenv("FIREBASE_AUTH_EMULATOR_HOST", host); err != nil {
+				initError = err
+				return
+			}
+			log.Printf("Using Firebase Auth Emulator at %s", host)
+		} else {
+			// Load from file path
+			credPath := cfg.CredentialsFile
+			if credPath == "" {
+				initError = fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS not set")
+				return
+			}
+
+			log.Printf("Loading Firebase credentials from file: %s", credPath)
+			opts = append(opts, option.WithCredentialsFile(credPath))
 		}
 
-		log.Printf("Loading Firebase credentials from file: %s", credPath)
-
 		// Initialize Firebase App with credentials
-		opt := option.WithCredentialsFile(credPath)
-		config := &firebase.Config{
-			ProjectID: os.Getenv("FIREBASE_PROJECT_ID"),
+		fbConfig := &firebase.Config{
+			ProjectID: cfg.ProjectID,
 		}
 
-		app, err := firebase.NewApp(ctx, config, opt)
+		app, err := firebase.NewApp(ctx, fbConfig, opts...)
 		if err != nil {
 			initError = fmt.Errorf("failed to initialize Firebase app: %w", err)
 			return
@@ -92,6 +107,40 @@ func VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error) {
 	return token, nil
 }
 
+// firebaseVerifier is the TokenVerifier backed by the Firebase Admin SDK.
+// In emulator mode the SDK accepts the emulator's unsigned tokens.
+type firebaseVerifier struct{}
+
+// NewFirebaseVerifier returns a TokenVerifier using the Firebase client
+// Must call InitFirebase() first
+func NewFirebaseVerifier() TokenVerifier {
+	return firebaseVerifier{}
+}
+
+// NewEmulatorVerifier initializes Firebase against the Auth Emulator at
+// cfg.EmulatorHost and returns a TokenVerifier for the emulator's tokens
+func NewEmulatorVerifier(ctx context.Context, cfg config.Auth) (TokenVerifier, error) {
+	if cfg.EmulatorHost == "" {
+		return nil, fmt.Errorf("FIREBASE_AUTH_EMULATOR_HOST not set")
+	}
+	if err := InitFirebase(ctx, cfg); err != nil {
+		return nil, err
+	}
+	return firebaseVerifier{}, nil
+}
+
+func (firebaseVerifier) VerifyIDToken(ctx context.Context, idToken string) (*VerifiedToken, error) {
+	token, err := VerifyIDToken(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifiedToken{
+		UID:     token.UID,
+		Expires: time.Unix(token.Expires, 0),
+		Claims:  token.Claims,
+	}, nil
+}
+
 // CreateFirebaseUserPasswordless creates a new user in Firebase/GCIP without password
 // User will receive a password reset link to set their own password
 // Parameters:
@@ -246,7 +295,7 @@ func SetEmailVerified(ctx context.Context, uid string, verified bool) error {
 }
 
 // SetCustomClaims sets custom claims on a Firebase user
-// NOTE: Currently unused - reserved for future use (token version/ roles), not used in current MVP
+// NOTE: This replaces all existing custom claims on the user
 func SetCustomClaims(ctx context.Context, uid string, claims map[string]interface{}) error {
 	client, err := GetAuthClient()
 	if err != nil {
@@ -260,3 +309,49 @@ func SetCustomClaims(ctx context.Context, uid string, claims map[string]interfac
 	log.Printf("Successfully set custom claims for UID: %s", uid)
 	return nil
 }
+
+// SetTokenVersionClaim writes the user's token_version into their custom claims.
+// ID tokens issued after this call carry the new version; older ones are rejected
+// by the auth middleware once the DB version has moved on.
+func SetTokenVersionClaim(ctx context.Context, uid string, version int) error {
+	return SetCustomClaims(ctx, uid, map[string]interface{}{TokenVersionClaim: version})
+}
+
+// RevokeRefreshTokens invalidates all refresh tokens of a Firebase user,
+// so the client cannot silently mint new ID tokens and has to sign in again
+func RevokeRefreshTokens(ctx context.Context, uid string) error {
+	client, err := GetAuthClient()
+	if err != nil {
+		return err
+	}
+
+	if err := client.RevokeRefreshTokens(ctx, uid); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	log.Printf("Successfully revoked refresh tokens for UID: %s", uid)
+	return nil
+}
+
+// ListFirebaseUsers calls fn for every Firebase/GCIP user, fetching them page by page
+// Stops at the first error returned by fn
+func ListFirebaseUsers(ctx context.Context, fn func(*auth.ExportedUserRecord) error) error {
+	client, err := GetAuthClient()
+	if err != nil {
+		return err
+	}
+
+	it := client.Users(ctx, "")
+	for {
+		u, err := it.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list users: %w", err)
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// Defaults for locally minted tokens (AUTH_MODE=local).
+const (
+	DefaultLocalIssuer   = "havenzsure-local"
+	DefaultLocalAudience = "havenzsure-api"
+)
+
+// registeredClaims are left out of VerifiedToken.Claims, matching what the
+// Firebase SDK puts in auth.Token.Claims.
+var registeredClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+// LocalVerifier verifies RS256 tokens against a JSON Web Key Set, for running
+// and testing the API without Google. Tokens come from cmd/devtoken or
+// SignLocalToken.
+type LocalVerifier struct {
+	keys     jose.JSONWebKeySet
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+var _ TokenVerifier = (*LocalVerifier)(nil)
+
+// NewLocalVerifierFromFile loads the key set from a JWKS file.
+func NewLocalVerifierFromFile(path, issuer, audience string) (*LocalVerifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS file: %w", err)
+	}
+	return NewLocalVerifier(data, issuer, audience)
+}
+
+// NewLocalVerifier parses a JWKS document. Every key must be an RSA public key
+// with a key ID.
+func NewLocalVerifier(jwks []byte, issuer, audience string) (*LocalVerifier, error) {
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(jwks, &set); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+	if len(set.Keys) == 0 {
+		return nil, errors.New("JWKS contains no keys")
+	}
+	for _, k := range set.Keys {
+		if _, ok := k.Key.(*rsa.PublicKey); !ok || k.KeyID == "" {
+			return nil, fmt.Errorf("JWKS key %q must be an RSA public key with a kid", k.KeyID)
+		}
+	}
+	return &LocalVerifier{keys: set, issuer: issuer, audience: audience, now: time.Now}, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience and expiry of a local token.
+func (v *LocalVerifier) VerifyIDToken(ctx context.Context, idToken string) (*VerifiedToken, error) {
+	tok, err := jwt.ParseSigned(idToken, []jose.SignatureAlgorithm{jose.RS256})
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	keys := v.keys.Key(tok.Headers[0].KeyID)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("invalid token: unknown key id %q", tok.Headers[0].KeyID)
+	}
+
+	var (
+		std    jwt.Claims
+		claims map[string]interface{}
+	)
+	if err := tok.Claims(keys[0].Key, &std, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if std.Expiry == nil {
+		return nil, errors.New("invalid token: missing exp")
+	}
+	if std.Subject == "" {
+		return nil, errors.New("invalid token: missing sub")
+	}
+	expected := jwt.Expected{
+		Issuer:      v.issuer,
+		AnyAudience: jwt.Audience{v.audience},
+		Time:        v.now(),
+	}
+	if err := std.ValidateWithLeeway(expected, jwt.DefaultLeeway); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	for _, c := range registeredClaims {
+		delete(claims, c)
+	}
+	return &VerifiedToken{UID: std.Subject, Expires: std.Expiry.Time(), Claims: claims}, nil
+}
+
+// LocalToken describes a token to mint with SignLocalToken.
+type LocalToken struct {
+	UID      string
+	Issuer   string // default: DefaultLocalIssuer
+	Audience string // default: DefaultLocalAudience
+	TTL      time.Duration
+	// Claims are extra claims, e.g. {"email": ..., TokenVersionClaim: 2}.
+	Claims map[string]interface{}
+}
+
+// SignLocalToken mints an RS256 token that a LocalVerifier holding the matching
+// public key accepts.
+func SignLocalToken(key *rsa.PrivateKey, kid string, t LocalToken) (string, error) {
+	if t.UID == "" {
+		return "", errors.New("uid is required")
+	}
+	if t.TTL <= 0 {
+		return "", errors.New("ttl must be positive")
+	}
+	if t.Issuer == "" {
+		t.Issuer = DefaultLocalIssuer
+	}
+	if t.Audience == "" {
+		t.Audience = DefaultLocalAudience
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: key, KeyID: kid}},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	std := jwt.Claims{
+		Issuer:   t.Issuer,
+		Subject:  t.UID,
+		Audience: jwt.Audience{t.Audience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(t.TTL)),
+	}
+	b := jwt.Signed(signer).Claims(std)
+	if len(t.Claims) > 0 {
+		b = b.Claims(t.Claims)
+	}
+	return b.Serialize()
+}
+
+// LocalJWKS returns the public JWKS document for key, and the key ID derived
+// from its thumbprint.
+func LocalJWKS(key *rsa.PrivateKey) (jwks []byte, kid string, err error) {
+	pub := jose.JSONWebKey{Key: &key.PublicKey, Algorithm: string(jose.RS256), Use: "sig"}
+	thumb, err := pub.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, "", err
+	}
+	pub.KeyID = base64.RawURLEncoding.EncodeToString(thumb)
+	jwks, err = json.MarshalIndent(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{pub}}, "", "  ")
+	return jwks, pub.KeyID, err
+}
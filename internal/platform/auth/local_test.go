@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLocalKey(t *testing.T) (*rsa.PrivateKey, string, *LocalVerifier) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, kid, err := LocalJWKS(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewLocalVerifierFromFile(path, DefaultLocalIssuer, DefaultLocalAudience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, kid, v
+}
+
+func TestLocalVerifierRoundTrip(t *testing.T) {
+	key, kid, v := newLocalKey(t)
+	token, err := SignLocalToken(key, kid, LocalToken{
+		UID:    "local-user-1",
+		TTL:    time.Hour,
+		Claims: map[string]interface{}{"email": "dev@example.com", TokenVersionClaim: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := v.VerifyIDToken(context.Background(), token)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if got.UID != "local-user-1" {
+		t.Errorf("UID = %q", got.UID)
+	}
+	if d := time.Until(got.Expires); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("Expires in %s, want ~1h", d)
+	}
+	if tv, ok := TokenVersionFromClaims(got.Claims); !ok || tv != 3 {
+		t.Errorf("token version = %d, %v", tv, ok)
+	}
+	if got.Claims["email"] != "dev@example.com" {
+		t.Errorf("email claim = %v", got.Claims["email"])
+	}
+	if _, ok := got.Claims["sub"]; ok {
+		t.Error("registered claims should not be in Claims")
+	}
+}
+
+func TestLocalVerifierRejects(t *testing.T) {
+	key, kid, v := newLocalKey(t)
+	otherKey, otherKid, _ := newLocalKey(t)
+
+	sign := func(key *rsa.PrivateKey, kid string, lt LocalToken) string {
+		t.Helper()
+		if lt.UID == "" {
+			lt.UID = "u1"
+		}
+		if lt.TTL == 0 {
+			lt.TTL = time.Hour
+		}
+		s, err := SignLocalToken(key, kid, lt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	valid := sign(key, kid, LocalToken{})
+	parts := strings.Split(valid, ".")
+
+	tests := map[string]string{
+		"garbage":         "not-a-token",
+		"unknown key":     sign(otherKey, otherKid, LocalToken{}),
+		"wrong signature": sign(otherKey, kid, LocalToken{}),
+		"wrong issuer":    sign(key, kid, LocalToken{Issuer: "someone-else"}),
+		"wrong audience":  sign(key, kid, LocalToken{Audience: "other-api"}),
+		"alg none":        "eyJhbGciOiJub25lIiwia2lkIjoiIn0." + parts[1] + ".",
+		"tampered":        parts[0] + "." + parts[1] + "x." + parts[2],
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := v.VerifyIDToken(context.Background(), token); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		v.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		defer func() { v.now = time.Now }()
+		if _, err := v.VerifyIDToken(context.Background(), valid); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestNewLocalVerifierValidatesKeys(t *testing.T) {
+	for name, jwks := range map[string]string{
+		"not json": "{",
+		"no keys":  `{"keys":[]}`,
+		"no kid":   `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+	} {
+		if _, err := NewLocalVerifier([]byte(jwks), DefaultLocalIssuer, DefaultLocalAudience); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package auth
+
+// Permission codes, as stored in app.permissions. Roles are granted
+// permissions in app.role_permissions; the auth middleware loads the
+// caller's set into AuthUser.Permissions.
+const (
+	PermShopsManage        = "shops.manage"        // create, update and list shops
+	PermUsersManage        = "users.manage"        // manage users with a lower role in own shop
+	PermWorkOrdersTransfer = "workorders.transfer" // move work orders between shops
+	PermWorkOrdersImport   = "workorders.import"   // bulk CSV/XLSX import
+	PermEstimatesWrite     = "estimates.write"     // create and edit draft estimates
+	PermEstimatesApprove   = "estimates.approve"   // approve estimates
+	PermInvoicesManage     = "invoices.manage"     // issue invoices and record payments
+	PermAPIKeysManage      = "apikeys.manage"      // create and revoke API keys
+	PermRolesManage        = "roles.manage"        // create custom roles and edit grants
+	PermOrgShops           = "org.shops"           // act on every shop in own organization
+	PermAuditRead          = "audit.read"          // read the change history of users, shops and work orders
+)
+
+// Can checks if the user holds permission. The system superadmin role holds
+// every permission, including ones added after its grants were written.
+func (u *AuthUser) Can(permission string) bool {
+	if u.IsSuperAdmin() {
+		return true
+	}
+	for _, p := range u.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAny checks if the user holds at least one of permissions
+func (u *AuthUser) CanAny(permissions ...string) bool {
+	for _, p := range permissions {
+		if u.Can(p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/config"
+)
+
+// VerifiedToken is the provider-neutral result of verifying an ID token.
+type VerifiedToken struct {
+	UID     string
+	Expires time.Time
+	// Claims holds the non-registered claims, including custom claims such as
+	// TokenVersionClaim.
+	Claims map[string]interface{}
+}
+
+// TokenVerifier verifies the ID tokens clients send as Bearer tokens.
+type TokenVerifier interface {
+	VerifyIDToken(ctx context.Context, idToken string) (*VerifiedToken, error)
+}
+
+// Verifier modes selected by AUTH_MODE.
+const (
+	ModeFirebase = config.AuthModeFirebase // Firebase/GCIP (default)
+	ModeEmulator = config.AuthModeEmulator // Firebase Auth Emulator
+	ModeLocal    = config.AuthModeLocal    // RS256 tokens checked against a local JWKS file
+)
+
+// NewTokenVerifier builds the verifier selected by cfg.Mode.
+//
+//   - firebase: cfg.CredentialsFile, cfg.ProjectID (see InitFirebase)
+//   - emulator: cfg.EmulatorHost, cfg.ProjectID
+//   - local:    cfg.JWKSFile, cfg.LocalIssuer, cfg.LocalAudience (empty:
+//     DefaultLocalIssuer, DefaultLocalAudience); mint tokens with cmd/devtoken
+//
+// Firebase and emulator modes also initialise the Firebase client used for user
+// management. Local mode does not; the server pairs it with an in-memory
+// identity provider instead.
+func NewTokenVerifier(ctx context.Context, cfg config.Auth) (TokenVerifier, error) {
+	switch cfg.Mode {
+	case "", ModeFirebase:
+		if err := InitFirebase(ctx, cfg); err != nil {
+			return nil, err
+		}
+		return NewFirebaseVerifier(), nil
+
+	case ModeEmulator:
+		return NewEmulatorVerifier(ctx, cfg)
+
+	case ModeLocal:
+		if cfg.JWKSFile == "" {
+			return nil, fmt.Errorf("AUTH_JWKS_FILE not set (required when AUTH_MODE=local)")
+		}
+		issuer := cmp.Or(cfg.LocalIssuer, DefaultLocalIssuer)
+		audience := cmp.Or(cfg.LocalAudience, DefaultLocalAudience)
+		v, err := NewLocalVerifierFromFile(cfg.JWKSFile, issuer, audience)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("WARNING: AUTH_MODE=local - accepting tokens signed by keys in %s; never use this in production", cfg.JWKSFile)
+		return v, nil
+
+	default:
+		return nil, fmt.Errorf("unknown AUTH_MODE %q (expected %s, %s or %s)", cfg.Mode, ModeFirebase, ModeEmulator, ModeLocal)
+	}
+}
+
+// VerifierReady reports why v cannot verify tokens yet, or nil. The Firebase
+// modes need the Firebase client that InitFirebase sets up.
+func VerifierReady(v TokenVerifier) error {
+	switch v.(type) {
+	case nil:
+		return fmt.Errorf("no token verifier configured")
+	case firebaseVerifier:
+		_, err := GetAuthClient()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New[string, int](2, time.Minute)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if _, ok := c.Get("a"); !ok { // a is now the most recently used
+		t.Fatal("a missing")
+	}
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %d, %v", v, ok)
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Errorf("Get(c) = %d, %v", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	now := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	c := New[string, string](10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("default", "x")
+	c.SetWithTTL("short", "y", 10*time.Second)
+	c.SetWithTTL("none", "z", 0)
+
+	if _, ok := c.Get("none"); ok {
+		t.Error("zero TTL entry should not be stored")
+	}
+
+	now = now.Add(30 * time.Second)
+	if _, ok := c.Get("short"); ok {
+		t.Error("short entry should have expired")
+	}
+	if _, ok := c.Get("default"); !ok {
+		t.Error("default entry should still be cached")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("default"); ok {
+		t.Error("default entry should have expired")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expired entries should be evicted on Get, Len() = %d", c.Len())
+	}
+}
+
+func TestLRUDeleteAndPurge(t *testing.T) {
+	c := New[int, int](10, time.Minute)
+	for i := range 5 {
+		c.Set(i, i)
+	}
+	c.Delete(3)
+	if _, ok := c.Get(3); ok {
+		t.Error("3 should be deleted")
+	}
+	c.Set(1, 10)
+	if v, _ := c.Get(1); v != 10 {
+		t.Errorf("Set should overwrite, got %d", v)
+	}
+	c.Purge()
+	if c.Len() != 0 {
+		t.Errorf("Len() after Purge = %d", c.Len())
+	}
+	c.Set(7, 7)
+	if _, ok := c.Get(7); !ok {
+		t.Error("cache unusable after Purge")
+	}
+}
@@ -0,0 +1,360 @@
+// Package config loads every setting of the API server and its commands in
+// one place: from the environment, optionally layered over a dotenv-style
+// file, validated up front so a misconfigured instance fails at startup with
+// all its problems listed instead of at the first request that needs them.
+// The parts are passed to constructors; nothing else reads the environment.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the whole configuration.
+type Config struct {
+	HTTP      HTTP
+	Database  Database
+	Auth      Auth
+	SMTP      SMTP
+	Log       Log
+	Tracing   Tracing
+	Metrics   Metrics
+	UserCache UserCache
+	Reconcile Reconcile
+}
+
+// HTTP configures the server and the router.
+type HTTP struct {
+	Port           string        // PORT (default 8080)
+	ReadTimeout    time.Duration // HTTP_READ_TIMEOUT (default 15s)
+	WriteTimeout   time.Duration // HTTP_WRITE_TIMEOUT (default 15s)
+	IdleTimeout    time.Duration // HTTP_IDLE_TIMEOUT (default 60s)
+	RequestTimeout time.Duration // HTTP_REQUEST_TIMEOUT (default 5s); streaming exports are exempt
+	CORSOrigins    []string      // CORS_ALLOWED_ORIGINS, comma-separated (default https://*,http://*)
+}
+
+// Database is the app_user connection.
+type Database struct {
+	Host     string // DB_HOST
+	Port     string // DB_PORT
+	User     string // DB_APP_USER
+	Password string // DB_APP_PASSWORD
+	Name     string // DB_NAME
+	Schema   string // DB_SCHEMA (default app)
+}
+
+// ConnString is the pgx connection string for d.
+func (d Database) ConnString() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable search_path=%s",
+		d.Host, d.Port, d.User, d.Password, d.Name, d.Schema)
+}
+
+// Token verifier modes (AUTH_MODE).
+const (
+	AuthModeFirebase = "firebase" // Firebase/GCIP (default)
+	AuthModeEmulator = "emulator" // Firebase Auth Emulator
+	AuthModeLocal    = "local"    // RS256 tokens checked against a local JWKS file
+)
+
+// Auth selects and configures the token verifier.
+type Auth struct {
+	Mode string // AUTH_MODE
+
+	// firebase and emulator modes
+	ProjectID       string // FIREBASE_PROJECT_ID (emulator default demo-havenzsure)
+	CredentialsFile string // GOOGLE_APPLICATION_CREDENTIALS (required in firebase mode)
+	EmulatorHost    string // FIREBASE_AUTH_EMULATOR_HOST (emulator default localhost:9099)
+
+	// local mode
+	JWKSFile      string // AUTH_JWKS_FILE (required)
+	LocalIssuer   string // AUTH_LOCAL_ISSUER (empty: auth.DefaultLocalIssuer)
+	LocalAudience string // AUTH_LOCAL_AUDIENCE (empty: auth.DefaultLocalAudience)
+}
+
+// SMTP configures outgoing email. Without a username the server only logs
+// the emails it would send.
+type SMTP struct {
+	Host     string // SMTP_HOST (default smtp.gmail.com)
+	Port     int    // SMTP_PORT (default 587)
+	Username string // SMTP_USERNAME
+	Password string // SMTP_PASSWORD (required with a username)
+	From     string // SMTP_FROM (default: the username)
+}
+
+// Enabled reports whether emails are sent at all.
+func (s SMTP) Enabled() bool {
+	return s.Username != ""
+}
+
+// Log configures the process logger.
+type Log struct {
+	Level  slog.Level // LOG_LEVEL: debug, info (default), warn, error
+	Format string     // LOG_FORMAT: json (default) or text
+}
+
+// Tracing selects the trace exporter. The OTLP exporter reads its endpoint
+// and headers from the standard OTEL_EXPORTER_OTLP_* variables itself.
+type Tracing struct {
+	Exporter string // OTEL_TRACES_EXPORTER: none (default), otlp or stdout
+}
+
+// Metrics configures the Prometheus endpoint.
+type Metrics struct {
+	Token string // METRICS_TOKEN: serve /metrics on the API port behind this bearer token
+	Addr  string // METRICS_ADDR: serve /metrics on a separate listener instead
+}
+
+// UserCache configures the auth middleware's user cache.
+type UserCache struct {
+	Size   int           // USER_CACHE_SIZE (default 10000)
+	TTL    time.Duration // USER_CACHE_TTL (default 60s)
+	Notify bool          // USER_CACHE_NOTIFY: invalidate across instances (default false)
+}
+
+// Reconcile schedules the GCIP/app.users drift check inside the server.
+type Reconcile struct {
+	Interval time.Duration // RECONCILE_INTERVAL (default 0: disabled)
+	Repair   bool          // RECONCILE_REPAIR (default false: report only)
+}
+
+// FileVar names the optional settings file. The environment takes precedence
+// over the file.
+const FileVar = "CONFIG_FILE"
+
+// Load reads the configuration from the environment, over the file named by
+// CONFIG_FILE when that is set.
+func Load() (*Config, error) {
+	src := Env()
+	if path := os.Getenv(FileVar); path != "" {
+		file, err := File(path)
+		if err != nil {
+			return nil, err
+		}
+		src = Layered(src, file)
+	}
+	return LoadFrom(src)
+}
+
+// LoadFrom reads and validates the configuration from src. The error lists
+// every invalid or missing setting.
+func LoadFrom(src Source) (*Config, error) {
+	l := &loader{src: src}
+	cfg := &Config{
+		HTTP: HTTP{
+			Port:           l.str("PORT", "8080"),
+			ReadTimeout:    l.duration("HTTP_READ_TIMEOUT", 15*time.Second),
+			WriteTimeout:   l.duration("HTTP_WRITE_TIMEOUT", 15*time.Second),
+			IdleTimeout:    l.duration("HTTP_IDLE_TIMEOUT", 60*time.Second),
+			RequestTimeout: l.duration("HTTP_REQUEST_TIMEOUT", 5*time.Second),
+			CORSOrigins:    l.list("CORS_ALLOWED_ORIGINS", []string{"https://*", "http://*"}),
+		},
+		Database: Database{
+			Host:     l.required("DB_HOST"),
+			Port:     l.required("DB_PORT"),
+			User:     l.required("DB_APP_USER"),
+			Password: l.required("DB_APP_PASSWORD"),
+			Name:     l.required("DB_NAME"),
+			Schema:   l.str("DB_SCHEMA", "app"),
+		},
+		Auth: l.auth(),
+		SMTP: SMTP{
+			Host:     l.str("SMTP_HOST", "smtp.gmail.com"),
+			Port:     l.integer("SMTP_PORT", 587),
+			Username: l.str("SMTP_USERNAME", ""),
+			Password: l.str("SMTP_PASSWORD", ""),
+		},
+		Log: Log{
+			Level:  l.level("LOG_LEVEL"),
+			Format: l.oneOf("LOG_FORMAT", "json", "text"),
+		},
+		Tracing: Tracing{
+			Exporter: l.oneOf("OTEL_TRACES_EXPORTER", "none", "otlp", "stdout"),
+		},
+		Metrics: Metrics{
+			Token: l.str("METRICS_TOKEN", ""),
+			Addr:  l.str("METRICS_ADDR", ""),
+		},
+		UserCache: UserCache{
+			Size:   l.integer("USER_CACHE_SIZE", 10000),
+			TTL:    l.duration("USER_CACHE_TTL", 60*time.Second),
+			Notify: l.boolean("USER_CACHE_NOTIFY"),
+		},
+		Reconcile: Reconcile{
+			Interval: l.duration("RECONCILE_INTERVAL", 0),
+			Repair:   l.boolean("RECONCILE_REPAIR"),
+		},
+	}
+
+	if cfg.SMTP.Enabled() && cfg.SMTP.Password == "" {
+		l.fail("SMTP_PASSWORD", "required when SMTP_USERNAME is set")
+	}
+	cfg.SMTP.From = l.str("SMTP_FROM", cfg.SMTP.Username)
+	if cfg.HTTP.RequestTimeout <= 0 {
+		l.fail("HTTP_REQUEST_TIMEOUT", "must be positive")
+	}
+	if cfg.UserCache.Size <= 0 {
+		l.fail("USER_CACHE_SIZE", "must be positive")
+	}
+	if cfg.UserCache.TTL <= 0 {
+		l.fail("USER_CACHE_TTL", "must be positive")
+	}
+
+	if len(l.errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(l.errs...))
+	}
+	return cfg, nil
+}
+
+func (l *loader) auth() Auth {
+	a := Auth{
+		Mode:            l.oneOf("AUTH_MODE", AuthModeFirebase, AuthModeEmulator, AuthModeLocal),
+		ProjectID:       l.str("FIREBASE_PROJECT_ID", ""),
+		CredentialsFile: l.str("GOOGLE_APPLICATION_CREDENTIALS", ""),
+		EmulatorHost:    l.str("FIREBASE_AUTH_EMULATOR_HOST", ""),
+		JWKSFile:        l.str("AUTH_JWKS_FILE", ""),
+		LocalIssuer:     l.str("AUTH_LOCAL_ISSUER", ""),
+		LocalAudience:   l.str("AUTH_LOCAL_AUDIENCE", ""),
+	}
+	switch a.Mode {
+	case AuthModeFirebase:
+		// the Admin SDK talks to the emulator instead when its host is set
+		if a.CredentialsFile == "" && a.EmulatorHost == "" {
+			l.fail("GOOGLE_APPLICATION_CREDENTIALS", "required when AUTH_MODE is firebase")
+		}
+	case AuthModeEmulator:
+		if a.EmulatorHost == "" {
+			a.EmulatorHost = "localhost:9099"
+		}
+		if a.ProjectID == "" {
+			a.ProjectID = "demo-havenzsure"
+		}
+	case AuthModeLocal:
+		if a.JWKSFile == "" {
+			l.fail("AUTH_JWKS_FILE", "required when AUTH_MODE is local")
+		}
+	}
+	return a
+}
+
+// loader reads typed settings from a Source and collects what is wrong with
+// them, so one error can report everything.
+type loader struct {
+	src  Source
+	errs []error
+}
+
+func (l *loader) fail(key, format string, args ...any) {
+	l.errs = append(l.errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+}
+
+// lookup is the trimmed value of key; blank counts as unset.
+func (l *loader) lookup(key string) (string, bool) {
+	v, ok := l.src(key)
+	v = strings.TrimSpace(v)
+	return v, ok && v != ""
+}
+
+func (l *loader) str(key, def string) string {
+	if v, ok := l.lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+func (l *loader) required(key string) string {
+	v, ok := l.lookup(key)
+	if !ok {
+		l.fail(key, "required")
+	}
+	return v
+}
+
+// oneOf is key's value, lower-cased, which must be one of allowed; the first
+// allowed value is the default.
+func (l *loader) oneOf(key string, allowed ...string) string {
+	v, ok := l.lookup(key)
+	if !ok {
+		return allowed[0]
+	}
+	v = strings.ToLower(v)
+	for _, a := range allowed {
+		if v == a {
+			return v
+		}
+	}
+	l.fail(key, "%q is not one of %s", v, strings.Join(allowed, ", "))
+	return allowed[0]
+}
+
+func (l *loader) integer(key string, def int) int {
+	v, ok := l.lookup(key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		l.fail(key, "%q is not a whole number", v)
+		return def
+	}
+	return n
+}
+
+func (l *loader) boolean(key string) bool {
+	v, ok := l.lookup(key)
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.fail(key, "%q is not true or false", v)
+	}
+	return b
+}
+
+// duration parses Go duration syntax (90s, 6h); negative values are invalid.
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	v, ok := l.lookup(key)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		l.fail(key, "%q is not a duration such as 30s or 6h", v)
+		return def
+	}
+	return d
+}
+
+func (l *loader) level(key string) slog.Level {
+	var lvl slog.Level
+	if v, ok := l.lookup(key); ok {
+		if err := lvl.UnmarshalText([]byte(v)); err != nil {
+			l.fail(key, "%q is not one of debug, info, warn, error", v)
+		}
+	}
+	return lvl
+}
+
+// list splits a comma-separated value, dropping empty items.
+func (l *loader) list(key string, def []string) []string {
+	v, ok := l.lookup(key)
+	if !ok {
+		return def
+	}
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		l.fail(key, "no values in %q", v)
+		return def
+	}
+	return items
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minimal holds the settings without defaults.
+func minimal() map[string]string {
+	return map[string]string{
+		"DB_HOST":         "localhost",
+		"DB_PORT":         "5433",
+		"DB_APP_USER":     "app_user",
+		"DB_APP_PASSWORD": "secret",
+		"DB_NAME":         "havenzsure",
+		"AUTH_MODE":       "emulator",
+	}
+}
+
+func with(m map[string]string, kv ...string) map[string]string {
+	for i := 0; i < len(kv); i += 2 {
+		m[kv[i]] = kv[i+1]
+	}
+	return m
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := LoadFrom(Map(minimal()))
+	require.NoError(t, err)
+
+	assert.Equal(t, "8080", cfg.HTTP.Port)
+	assert.Equal(t, 5*time.Second, cfg.HTTP.RequestTimeout)
+	assert.Equal(t, []string{"https://*", "http://*"}, cfg.HTTP.CORSOrigins)
+	assert.Equal(t, "app", cfg.Database.Schema)
+	assert.Equal(t, "host=localhost port=5433 user=app_user password=secret dbname=havenzsure sslmode=disable search_path=app",
+		cfg.Database.ConnString())
+	assert.Equal(t, Auth{Mode: AuthModeEmulator, EmulatorHost: "localhost:9099", ProjectID: "demo-havenzsure"}, cfg.Auth)
+	assert.False(t, cfg.SMTP.Enabled())
+	assert.Equal(t, 587, cfg.SMTP.Port)
+	assert.Equal(t, Log{Level: slog.LevelInfo, Format: "json"}, cfg.Log)
+	assert.Equal(t, "none", cfg.Tracing.Exporter)
+	assert.Equal(t, UserCache{Size: 10000, TTL: 60 * time.Second}, cfg.UserCache)
+	assert.Zero(t, cfg.Reconcile.Interval)
+}
+
+func TestLoadOverrides(t *testing.T) {
+	cfg, err := LoadFrom(Map(with(minimal(),
+		"PORT", "9000",
+		"HTTP_REQUEST_TIMEOUT", "10s",
+		"CORS_ALLOWED_ORIGINS", " https://app.example.com, ,https://admin.example.com ",
+		"SMTP_USERNAME", "noreply@example.com",
+		"SMTP_PASSWORD", "app-password",
+		"SMTP_PORT", "2525",
+		"LOG_LEVEL", "debug",
+		"LOG_FORMAT", "Text",
+		"OTEL_TRACES_EXPORTER", "stdout",
+		"USER_CACHE_NOTIFY", "true",
+		"RECONCILE_INTERVAL", "6h",
+	)))
+	require.NoError(t, err)
+
+	assert.Equal(t, "9000", cfg.HTTP.Port)
+	assert.Equal(t, 10*time.Second, cfg.HTTP.RequestTimeout)
+	assert.Equal(t, []string{"https://app.example.com", "https://admin.example.com"}, cfg.HTTP.CORSOrigins)
+	assert.True(t, cfg.SMTP.Enabled())
+	assert.Equal(t, "noreply@example.com", cfg.SMTP.From, "From defaults to the username")
+	assert.Equal(t, 2525, cfg.SMTP.Port)
+	assert.Equal(t, Log{Level: slog.LevelDebug, Format: "text"}, cfg.Log)
+	assert.Equal(t, "stdout", cfg.Tracing.Exporter)
+	assert.True(t, cfg.UserCache.Notify)
+	assert.Equal(t, 6*time.Hour, cfg.Reconcile.Interval)
+}
+
+// Test: every problem is reported at once, each under its variable name
+func TestLoadReportsEveryProblem(t *testing.T) {
+	_, err := LoadFrom(Map(map[string]string{
+		"DB_HOST":              "localhost",
+		"DB_PORT":              " ",
+		"SMTP_PORT":            "smtp",
+		"SMTP_USERNAME":        "noreply@example.com",
+		"LOG_LEVEL":            "loud",
+		"OTEL_TRACES_EXPORTER": "jaeger",
+		"USER_CACHE_TTL":       "-1s",
+		"RECONCILE_REPAIR":     "maybe",
+	}))
+	require.Error(t, err)
+
+	for _, want := range []string{
+		"DB_PORT: required",
+		"DB_APP_USER: required",
+		"DB_APP_PASSWORD: required",
+		"DB_NAME: required",
+		"GOOGLE_APPLICATION_CREDENTIALS: required when AUTH_MODE is firebase",
+		`SMTP_PORT: "smtp" is not a whole number`,
+		"SMTP_PASSWORD: required when SMTP_USERNAME is set",
+		`LOG_LEVEL: "loud" is not one of debug, info, warn, error`,
+		`OTEL_TRACES_EXPORTER: "jaeger" is not one of none, otlp, stdout`,
+		`USER_CACHE_TTL: "-1s" is not a duration`,
+		`RECONCILE_REPAIR: "maybe" is not true or false`,
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+	assert.NotContains(t, err.Error(), "DB_HOST")
+}
+
+func TestAuthModes(t *testing.T) {
+	_, err := LoadFrom(Map(with(minimal(), "AUTH_MODE", "local")))
+	assert.ErrorContains(t, err, "AUTH_JWKS_FILE: required when AUTH_MODE is local")
+
+	_, err = LoadFrom(Map(with(minimal(), "AUTH_MODE", "oauth")))
+	assert.ErrorContains(t, err, `AUTH_MODE: "oauth" is not one of firebase, emulator, local`)
+
+	// firebase mode against an emulator needs no credentials
+	cfg, err := LoadFrom(Map(with(minimal(), "AUTH_MODE", "", "FIREBASE_AUTH_EMULATOR_HOST", "localhost:9099")))
+	require.NoError(t, err)
+	assert.Equal(t, AuthModeFirebase, cfg.Auth.Mode)
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.env")
+	content := "# staging\nDB_HOST=db.internal\nDB_PORT=5432\nDB_APP_USER=app_user\nDB_APP_PASSWORD=from-file\nDB_NAME=havenzsure\nAUTH_MODE=emulator\nPORT=7000\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	file, err := File(path)
+	require.NoError(t, err)
+	cfg, err := LoadFrom(Layered(Map(map[string]string{"PORT": "9000"}), file))
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, "9000", cfg.HTTP.Port, "earlier sources win")
+
+	_, err = File(filepath.Join(t.TempDir(), "missing.env"))
+	assert.Error(t, err)
+}
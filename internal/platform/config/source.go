@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// Source looks a setting up by its environment variable name.
+type Source func(key string) (string, bool)
+
+// Env reads the process environment.
+func Env() Source {
+	return os.LookupEnv
+}
+
+// Map reads settings from m; tests use it to build a Config without touching
+// the process environment.
+func Map(m map[string]string) Source {
+	return func(key string) (string, bool) {
+		v, ok := m[key]
+		return v, ok
+	}
+}
+
+// File reads a dotenv-style file (KEY=value lines, # comments).
+func File(path string) (Source, error) {
+	m, err := godotenv.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return Map(m), nil
+}
+
+// Layered looks a key up in each source in turn; the first that has it wins.
+func Layered(sources ...Source) Source {
+	return func(key string) (string, bool) {
+		for _, src := range sources {
+			if v, ok := src(key); ok {
+				return v, true
+			}
+		}
+		return "", false
+	}
+}
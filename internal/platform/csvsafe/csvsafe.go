@@ -0,0 +1,22 @@
+// Package csvsafe defuses spreadsheet formulas in CSV files built from
+// user-entered text (CSV injection).
+package csvsafe
+
+import "strings"
+
+// Cell prefixes s with a quote when a spreadsheet app would otherwise
+// evaluate it as a formula.
+func Cell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Record applies Cell to every field of record, in place, and returns it.
+func Record(record []string) []string {
+	for i, s := range record {
+		record[i] = Cell(s)
+	}
+	return record
+}
@@ -0,0 +1,27 @@
+package csvsafe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCell(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                  "",
+		"Honda":             "Honda",
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"+1 403":            "'+1 403",
+		"-2+3":              "'-2+3",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"\t=1":              "'\t=1",
+		"a=b":               "a=b",
+	} {
+		assert.Equal(t, want, Cell(in), in)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	rec := []string{"ok", "=1+1"}
+	assert.Equal(t, []string{"ok", "'=1+1"}, Record(rec))
+}
@@ -2,13 +2,12 @@ package database
 
 import (
 	"context"
-	"fmt"
 	"log"
-	"os"
 	"time"
 
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/config"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
-	_ "github.com/joho/godotenv/autoload"
 )
 
 // Service represents the database service interact with PostgreSQL
@@ -21,32 +20,27 @@ type Service interface {
 }
 
 type service struct {
-	pool *pgxpool.Pool
+	pool     *pgxpool.Pool
+	database string
 }
 
-var (
-	host      = os.Getenv("DB_HOST")
-	port      = os.Getenv("DB_PORT")
-	user      = os.Getenv("DB_APP_USER")
-	password  = os.Getenv("DB_APP_PASSWORD")
-	database  = os.Getenv("DB_NAME")
-	schema    = os.Getenv("DB_SCHEMA")
-	dbService *service
-)
+var dbService *service
 
-func InitDB() (Service, error) {
+// InitDB connects to the database described by dbCfg.
+func InitDB(dbCfg config.Database) (Service, error) {
 
 	//Reuse existing connection if already initialized
 	if dbService != nil {
 		return dbService, nil
 	}
 
-	// Create the connection string
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable search_path=%s",
-		host, port, user, password, database, schema)
-
-	// Open connections to the database
-	pool, err := pgxpool.New(context.Background(), connStr)
+	// Open connections to the database; every query gets a trace span
+	cfg, err := pgxpool.ParseConfig(dbCfg.ConnString())
+	if err != nil {
+		return nil, err
+	}
+	cfg.ConnConfig.Tracer = tracing.QueryTracer{}
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -59,13 +53,13 @@ func InitDB() (Service, error) {
 		return nil, err
 	}
 
-	dbService = &service{pool: pool}
+	dbService = &service{pool: pool, database: dbCfg.Name}
 	return dbService, nil
 }
 
 func (s *service) Pool() *pgxpool.Pool { return s.pool }
 
 func (s *service) CloseDB() {
-	log.Printf("Disconnected from database: %s", database)
+	log.Printf("Disconnected from database: %s", s.database)
 	s.pool.Close()
 }
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/jackc/pgx/v5"
+)
+
+// Row-level security (see the enable_row_level_security migration) keys on
+// three transaction-local settings. A transaction that never sets them sees no
+// shop's rows, so every query on that data goes through the helpers below.
+const (
+	settingRole         = "app.current_role"
+	settingShop         = "app.current_shop_id"
+	settingOrganization = "app.current_organization_id"
+)
+
+// roleSystem is app.current_role for system-wide API keys and background
+// jobs, which see every shop like a superadmin.
+const roleSystem = "system"
+
+type systemScopeKey struct{}
+
+// WithSystemScope marks ctx as a background job's, so transactions scoped
+// from it see every shop. Jobs that have no caller must opt in explicitly:
+// without a scope the policies hide everything.
+func WithSystemScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemScopeKey{}, true)
+}
+
+// Beginner is satisfied by *pgxpool.Pool, *pgx.Conn and pgx.Tx.
+type Beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Scope is what the RLS policies see of the caller.
+type Scope struct {
+	Role           string
+	ShopID         string // empty unless the caller is bound to one shop
+	OrganizationID string // empty unless the caller spans an organization
+}
+
+// ScopeFromContext derives the RLS scope from the authenticated caller. With
+// no caller the scope is the system one for a WithSystemScope context and
+// empty otherwise, which the policies treat as seeing nothing.
+func ScopeFromContext(ctx context.Context) Scope {
+	u, err := auth.GetAuthUser(ctx)
+	if err != nil {
+		if system, _ := ctx.Value(systemScopeKey{}).(bool); system {
+			return Scope{Role: roleSystem}
+		}
+		return Scope{}
+	}
+
+	s := Scope{Role: u.RoleCode}
+	switch {
+	case u.IsSystemAPIKey():
+		s.Role = roleSystem
+	case u.IsSuperAdmin(), u.IsOrgWide():
+		// every shop, narrowed to the organization scope when there is one
+		if org := auth.OrganizationScope(ctx); org != nil {
+			s.OrganizationID = org.String()
+		}
+	case u.ShopID != nil:
+		s.ShopID = u.ShopID.String()
+	}
+	if s.Role == "" {
+		// shop-bound API keys have no role; they must not read as unscoped
+		s.Role = "apikey"
+	}
+	return s
+}
+
+// SetScope applies the caller's scope to tx with SET LOCAL semantics, so it
+// ends with the transaction. Use it for transactions the caller manages itself;
+// InScope covers the common case.
+func SetScope(ctx context.Context, tx pgx.Tx) error {
+	s := ScopeFromContext(ctx)
+	if s.Role == "" {
+		return nil
+	}
+	if _, err := tx.Exec(ctx,
+		`SELECT set_config($1, $2, true), set_config($3, $4, true), set_config($5, $6, true)`,
+		settingRole, s.Role, settingShop, s.ShopID, settingOrganization, s.OrganizationID,
+	); err != nil {
+		return fmt.Errorf("set rls scope: %w", err)
+	}
+	return nil
+}
+
+// InScope runs fn in a transaction scoped to ctx's caller and commits it when
+// fn succeeds. Rows read inside fn must be consumed before it returns.
+func InScope(ctx context.Context, db Beginner, fn func(tx pgx.Tx) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := SetScope(ctx, tx); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
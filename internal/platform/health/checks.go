@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Pinger is satisfied by *pgxpool.Pool.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Querier is satisfied by *pgxpool.Pool.
+type Querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Database checks that the pool can reach Postgres.
+func Database(db Pinger) Check {
+	return Check{Name: "database", Critical: true, Run: db.Ping}
+}
+
+// gooseVersionSQL reads the newest applied migration. goose's table lives in
+// public (see the grant_goose_version_read migration).
+const gooseVersionSQL = `
+SELECT COALESCE(max(version_id), 0)
+FROM public.goose_db_version
+WHERE is_applied`
+
+// Migrations checks that the database schema is at least at version
+// expected, the newest migration this binary was built with. A newer schema
+// is fine: during a rolling deploy the new release migrates first.
+func Migrations(db Querier, expected int64) Check {
+	return Check{Name: "migrations", Critical: true, Run: func(ctx context.Context) error {
+		var current int64
+		if err := db.QueryRow(ctx, gooseVersionSQL).Scan(&current); err != nil {
+			return fmt.Errorf("read schema version: %w", err)
+		}
+		if current < expected {
+			return fmt.Errorf("schema at version %d, expected %d", current, expected)
+		}
+		return nil
+	}}
+}
+
+// IdentityProvider checks that token verification was initialised; ready
+// reports why not.
+func IdentityProvider(ready func() error) Check {
+	return Check{Name: "identityProvider", Critical: true, Run: func(context.Context) error {
+		return ready()
+	}}
+}
+
+// errNotConfigured is the SMTP result when no SMTP sender was configured.
+var errNotConfigured = errors.New("not configured; emails are only logged")
+
+// SMTP checks that the mail server accepts connections. Mail failures never
+// block requests (sends are asynchronous), so this is a warning only. A nil
+// ping means SMTP is not configured.
+func SMTP(ping func(ctx context.Context) error) Check {
+	return Check{Name: "smtp", Run: func(ctx context.Context) error {
+		if ping == nil {
+			return errNotConfigured
+		}
+		return ping(ctx)
+	}}
+}
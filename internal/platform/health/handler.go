@@ -0,0 +1,157 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Build identification, set at link time:
+//
+//	go build -ldflags "-X github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/health.Version=v1.4.0 \
+//	  -X github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/health.Commit=$(git rev-parse HEAD)"
+//
+// Without them Commit falls back to the VCS revision Go stamps into the binary.
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// PoolStater is satisfied by *pgxpool.Pool.
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// Handler serves /healthz, /readyz and /status.
+type Handler struct {
+	checker *Checker
+	pool    PoolStater
+	started time.Time
+}
+
+// NewHandler returns a Handler; uptime counts from now.
+func NewHandler(checker *Checker, pool PoolStater) *Handler {
+	return &Handler{checker: checker, pool: pool, started: time.Now()}
+}
+
+// Live answers 200 as long as the process serves HTTP. It checks nothing
+// else, so a database outage does not get every instance restarted.
+func (h *Handler) Live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": StatusOK})
+}
+
+// ReadyPage is the body of /readyz: the overall status and each check's
+// status, without the error text, which can name hosts and addresses.
+type ReadyPage struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Ready runs the dependency checks: 200 when the instance can take traffic
+// (possibly with warnings), 503 when a critical check failed. It is
+// unauthenticated, so the check errors are left to /status.
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	rep := h.checker.Run(r.Context())
+	status := http.StatusOK
+	if !rep.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	page := ReadyPage{Status: rep.Status, Checks: make(map[string]string, len(rep.Checks))}
+	for name, res := range rep.Checks {
+		page.Checks[name] = res.Status
+	}
+	writeJSON(w, status, page)
+}
+
+// PoolStats is the connection pool part of the status page.
+type PoolStats struct {
+	MaxConns                int32 `json:"maxConns"`
+	TotalConns              int32 `json:"totalConns"`
+	IdleConns               int32 `json:"idleConns"`
+	AcquiredConns           int32 `json:"acquiredConns"`
+	ConstructingConns       int32 `json:"constructingConns"`
+	AcquireCount            int64 `json:"acquireCount"`
+	EmptyAcquireCount       int64 `json:"emptyAcquireCount"`
+	CanceledAcquireCount    int64 `json:"canceledAcquireCount"`
+	AcquireDurationMs       int64 `json:"acquireDurationMs"`
+	MaxLifetimeDestroyCount int64 `json:"maxLifetimeDestroyCount"`
+	MaxIdleDestroyCount     int64 `json:"maxIdleDestroyCount"`
+	NewConnsCount           int64 `json:"newConnsCount"`
+}
+
+// StatusPage is the body of /status.
+type StatusPage struct {
+	Version       string    `json:"version"`
+	Commit        string    `json:"commit,omitempty"`
+	GoVersion     string    `json:"goVersion"`
+	StartedAt     time.Time `json:"startedAt"`
+	UptimeSeconds int64     `json:"uptimeSeconds"`
+	Pool          PoolStats `json:"pool"`
+	Readiness     Report    `json:"readiness"`
+}
+
+// Status is the operator page: build, uptime, pool statistics and the
+// readiness checks. Mount it behind superadmin authentication.
+func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, StatusPage{
+		Version:       Version,
+		Commit:        commit(),
+		GoVersion:     runtime.Version(),
+		StartedAt:     h.started.UTC(),
+		UptimeSeconds: int64(time.Since(h.started).Seconds()),
+		Pool:          poolStats(h.pool.Stat()),
+		Readiness:     h.checker.Run(r.Context()),
+	})
+}
+
+func poolStats(s *pgxpool.Stat) PoolStats {
+	return PoolStats{
+		MaxConns:                s.MaxConns(),
+		TotalConns:              s.TotalConns(),
+		IdleConns:               s.IdleConns(),
+		AcquiredConns:           s.AcquiredConns(),
+		ConstructingConns:       s.ConstructingConns(),
+		AcquireCount:            s.AcquireCount(),
+		EmptyAcquireCount:       s.EmptyAcquireCount(),
+		CanceledAcquireCount:    s.CanceledAcquireCount(),
+		AcquireDurationMs:       s.AcquireDuration().Milliseconds(),
+		MaxLifetimeDestroyCount: s.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     s.MaxIdleDestroyCount(),
+		NewConnsCount:           s.NewConnsCount(),
+	}
+}
+
+// commit is Commit, or the revision from the binary's build info.
+func commit() string {
+	if Commit != "" {
+		return Commit
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var rev, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if rev != "" && modified == "true" {
+		rev += "-dirty"
+	}
+	return rev
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
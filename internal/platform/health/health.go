@@ -0,0 +1,97 @@
+// Package health serves the liveness, readiness and status endpoints the
+// orchestrator and operators poll. Readiness runs a set of dependency checks;
+// a failing critical check takes the instance out of rotation, a failing
+// warning-only check is reported but keeps it in.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Check statuses and overall report statuses.
+const (
+	StatusOK          = "ok"
+	StatusWarn        = "warn"        // a warning-only check failed
+	StatusFail        = "fail"        // a critical check failed
+	StatusDegraded    = "degraded"    // ready, with warnings
+	StatusUnavailable = "unavailable" // not ready
+)
+
+// checkTimeout bounds every check, so a hung dependency fails the probe
+// instead of stalling it past the orchestrator's own timeout.
+const checkTimeout = 2 * time.Second
+
+// Check is one dependency probe.
+type Check struct {
+	Name     string
+	Critical bool // failure makes the instance not ready
+	Run      func(ctx context.Context) error
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Report is the outcome of all checks.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Ready reports whether no critical check failed.
+func (r Report) Ready() bool {
+	return r.Status != StatusUnavailable
+}
+
+// Checker runs the readiness checks.
+type Checker struct {
+	checks []Check
+}
+
+// NewChecker returns a Checker for checks.
+func NewChecker(checks ...Check) *Checker {
+	return &Checker{checks: checks}
+}
+
+// Run runs every check concurrently and collects the results.
+func (c *Checker) Run(ctx context.Context) Report {
+	rep := Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func(chk Check) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := chk.Run(ctx)
+			res := Result{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				res.Error = err.Error()
+				if chk.Critical {
+					res.Status = StatusFail
+					rep.Status = StatusUnavailable
+				} else {
+					res.Status = StatusWarn
+					if rep.Status == StatusOK {
+						rep.Status = StatusDegraded
+					}
+				}
+			}
+			rep.Checks[chk.Name] = res
+		}(chk)
+	}
+	wg.Wait()
+	return rep
+}
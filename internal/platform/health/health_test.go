@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionRow is a pgx.Row holding the schema version (or an error).
+type versionRow struct {
+	version int64
+	err     error
+}
+
+func (r versionRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*int64) = r.version
+	return nil
+}
+
+type fakeDB struct {
+	pingErr error
+	row     versionRow
+}
+
+func (db fakeDB) Ping(context.Context) error { return db.pingErr }
+
+func (db fakeDB) QueryRow(context.Context, string, ...any) pgx.Row { return db.row }
+
+func ok(context.Context) error { return nil }
+
+func TestCheckerStatus(t *testing.T) {
+	down := errors.New("connection refused")
+	tests := []struct {
+		name   string
+		checks []Check
+		want   string
+	}{
+		{"all ok", []Check{{Name: "a", Critical: true, Run: ok}, {Name: "b", Run: ok}}, StatusOK},
+		{"warning only", []Check{{Name: "a", Critical: true, Run: ok}, SMTP(nil)}, StatusDegraded},
+		{"critical", []Check{Database(fakeDB{pingErr: down}), SMTP(nil)}, StatusUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep := NewChecker(tt.checks...).Run(context.Background())
+			assert.Equal(t, tt.want, rep.Status)
+			assert.Len(t, rep.Checks, len(tt.checks))
+		})
+	}
+
+	rep := NewChecker(Database(fakeDB{pingErr: down}), SMTP(nil)).Run(context.Background())
+	assert.Equal(t, Result{Status: StatusFail, Error: "connection refused"}, withoutDuration(rep.Checks["database"]))
+	assert.Equal(t, StatusWarn, rep.Checks["smtp"].Status)
+	assert.NotEmpty(t, rep.Checks["smtp"].Error)
+}
+
+func TestMigrationsCheck(t *testing.T) {
+	const expected = 20260112100000
+	tests := []struct {
+		name    string
+		row     versionRow
+		wantErr string
+	}{
+		{"current", versionRow{version: expected}, ""},
+		{"ahead during a rolling deploy", versionRow{version: expected + 1}, ""},
+		{"behind", versionRow{version: 20260110100000}, "schema at version 20260110100000, expected 20260112100000"},
+		{"unreadable", versionRow{err: errors.New("permission denied")}, "read schema version: permission denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Migrations(fakeDB{row: tt.row}, expected).Run(context.Background())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	// never connects: pgxpool dials lazily
+	pool, err := pgxpool.New(context.Background(), "postgres://nobody@127.0.0.1:1/none")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	notReady := NewHandler(NewChecker(Migrations(fakeDB{row: versionRow{version: 1}}, 2)), pool)
+
+	rec := httptest.NewRecorder()
+	notReady.Live(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "liveness ignores dependencies")
+
+	rec = httptest.NewRecorder()
+	notReady.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "error", "check errors stay off the public endpoint")
+	var ready ReadyPage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&ready))
+	assert.Equal(t, StatusUnavailable, ready.Status)
+	assert.Equal(t, StatusFail, ready.Checks["migrations"])
+
+	degraded := NewHandler(NewChecker(SMTP(nil)), pool)
+	rec = httptest.NewRecorder()
+	degraded.Ready(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "warnings keep the instance in rotation")
+
+	rec = httptest.NewRecorder()
+	degraded.Status(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var page StatusPage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	assert.Equal(t, Version, page.Version)
+	assert.NotEmpty(t, page.GoVersion)
+	assert.Positive(t, page.Pool.MaxConns)
+	assert.Equal(t, StatusDegraded, page.Readiness.Status)
+	assert.NotEmpty(t, page.Readiness.Checks["smtp"].Error, "the operator page keeps the detail")
+}
+
+func withoutDuration(r Result) Result {
+	r.DurationMs = 0
+	return r
+}
@@ -0,0 +1,63 @@
+// Package logging sets up the process-wide slog logger: JSON (or text) lines
+// at a configurable level, every string redacted of personal data and
+// secrets, and request-scoped fields (request ID, user, shop, route, trace)
+// added to records logged with a request context.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Options configures New.
+type Options struct {
+	Level  slog.Level
+	Format string    // "json" (default) or "text"
+	Writer io.Writer // defaults to os.Stderr
+}
+
+// New builds a logger from opts.
+func New(opts Options) *slog.Logger {
+	w := opts.Writer
+	if w == nil {
+		w = os.Stderr
+	}
+	ho := &slog.HandlerOptions{Level: opts.Level, ReplaceAttr: redactAttr}
+
+	var h slog.Handler
+	if opts.Format == "text" {
+		h = slog.NewTextHandler(w, ho)
+	} else {
+		h = slog.NewJSONHandler(w, ho)
+	}
+	return slog.New(contextHandler{h})
+}
+
+// Setup installs a logger for opts as the slog default. The standard
+// library's log package writes through it as well, so remaining log.Printf
+// calls come out as redacted INFO records.
+func Setup(opts Options) *slog.Logger {
+	logger := New(opts)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// contextHandler adds the request fields of the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(requestAttrs(ctx)...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
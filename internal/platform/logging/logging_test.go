@@ -0,0 +1,143 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"email", "welcome email to jane.doe+x@example.com sent", "welcome email to [email] sent"},
+		{"phone dashed", "call 403-555-1234", "call [phone]"},
+		{"phone plain", "phone=4035551234,", "phone=[phone],"},
+		{"phone with country code", "+1 (403) 555-1234", "[phone]"},
+		{"vin", "vehicle 1HGCM82633A004352 duplicated", "vehicle [vin] duplicated"},
+		{"bearer", "Authorization: Bearer abc.def", "Authorization: Bearer [token]"},
+		{"jwt", "token eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln expired", "token [token] expired"},
+		{"api key", "key hz_ab12cd34_s3cr3t revoked", "key [api-key] revoked"},
+		{"link", "link: https://auth.example.com/reset?oobCode=XYZ&email=a@b.co", "link: https://auth.example.com/[redacted]"},
+		{"bare host", "GET https://example.com failed", "GET https://example.com failed"},
+		{"uuid untouched", "user 123e4567-e89b-12d3-a456-426614174000", "user 123e4567-e89b-12d3-a456-426614174000"},
+		{"timestamp untouched", "at 2026-10-18T12:30:00Z took 12.345678ms", "at 2026-10-18T12:30:00Z took 12.345678ms"},
+		{"ip untouched", "from 203.0.113.7", "from 203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Redact(tt.in))
+		})
+	}
+}
+
+func newTestLogger(buf *bytes.Buffer, level slog.Level) *slog.Logger {
+	return New(Options{Level: level, Writer: buf})
+}
+
+func decode(t *testing.T, line string) map[string]any {
+	t.Helper()
+	var m map[string]any
+	require.NoError(t, json.Unmarshal([]byte(line), &m))
+	return m
+}
+
+func TestLoggerRedactsAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf, slog.LevelInfo)
+
+	logger.Info("sent to pat@example.com",
+		"to", "pat@example.com",
+		"link", "https://example.com/x",
+		"error", errors.New("smtp: 550 pat@example.com rejected"),
+	)
+
+	m := decode(t, buf.String())
+	assert.Equal(t, "sent to [email]", m["msg"])
+	assert.Equal(t, "[email]", m["to"])
+	assert.Equal(t, "[redacted]", m["link"])
+	assert.Equal(t, "smtp: 550 [email] rejected", m["error"])
+}
+
+func TestLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf, slog.LevelWarn)
+	logger.Info("dropped")
+	logger.Warn("kept")
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "kept")
+}
+
+// Test: records logged with a request context carry the request fields,
+// including from goroutines the request started
+func TestMiddlewareAddsRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(newTestLogger(&buf, slog.LevelInfo))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	done := make(chan struct{})
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID, Middleware)
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		SetUser(r.Context(), "u-1", "s-1")
+		go func(ctx context.Context) {
+			slog.InfoContext(ctx, "background")
+			close(done)
+		}(context.WithoutCancel(r.Context()))
+		<-done
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	bg, access := decode(t, lines[0]), decode(t, lines[1])
+
+	assert.Equal(t, "background", bg["msg"])
+	assert.NotEmpty(t, bg["request_id"])
+	assert.Equal(t, "u-1", bg["user_id"])
+
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, bg["request_id"], access["request_id"])
+	assert.Equal(t, "u-1", access["user_id"])
+	assert.Equal(t, "s-1", access["shop_id"])
+	assert.Equal(t, "/users/{id}", access["route"])
+	assert.Equal(t, float64(http.StatusNoContent), access["status"])
+}
+
+// Test: records logged inside a span carry its trace and span IDs
+func TestLoggerAddsTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf, slog.LevelInfo)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	logger.InfoContext(trace.ContextWithSpanContext(context.Background(), sc), "traced")
+	logger.Info("untraced")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	traced, untraced := decode(t, lines[0]), decode(t, lines[1])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traced["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", traced["span_id"])
+	assert.NotContains(t, untraced, "trace_id")
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Patterns for the personal data and secrets that show up in messages and
+// errors. Links go first: their query strings carry tokens and emails.
+var (
+	linkRegex   = regexp.MustCompile(`https?://[^\s"'<>]+`)
+	bearerRegex = regexp.MustCompile(`(?i)\bbearer\s+\S+`)
+	jwtRegex    = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	apiKeyRegex = regexp.MustCompile(`\bhz_[A-Za-z0-9_-]+`)
+	emailRegex  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	vinRegex    = regexp.MustCompile(`\b[A-HJ-NPR-Z0-9]{17}\b`)
+	phoneRegex  = regexp.MustCompile(`(?:\+?1[-. ]?)?(?:\(\d{3}\)|\b\d{3})[-. ]?\d{3}[-. ]?\d{4}\b`)
+)
+
+// sensitiveKeys are attributes whose whole value is a secret.
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"password":      true,
+	"secret":        true,
+	"token":         true,
+	"link":          true,
+	"api_key":       true,
+}
+
+// Redact masks emails, phone numbers, VINs, tokens and API keys in s, and cuts
+// links down to their scheme and host.
+func Redact(s string) string {
+	s = linkRegex.ReplaceAllStringFunc(s, redactLink)
+	s = bearerRegex.ReplaceAllString(s, "Bearer [token]")
+	s = jwtRegex.ReplaceAllString(s, "[token]")
+	s = apiKeyRegex.ReplaceAllString(s, "[api-key]")
+	s = emailRegex.ReplaceAllString(s, "[email]")
+	s = vinRegex.ReplaceAllString(s, "[vin]")
+	s = phoneRegex.ReplaceAllString(s, "[phone]")
+	return s
+}
+
+func redactLink(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return "[link]"
+	}
+	if strings.Trim(u.Path, "/") == "" && u.RawQuery == "" && u.Fragment == "" {
+		return u.Scheme + "://" + u.Host
+	}
+	return u.Scheme + "://" + u.Host + "/[redacted]"
+}
+
+// redactAttr is the handlers' ReplaceAttr: it runs on the message and every
+// attribute, including those added by contextHandler.
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, "[redacted]")
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(Redact(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(Redact(v.Error()))
+		case fmt.Stringer:
+			a.Value = slog.StringValue(Redact(v.String()))
+		}
+	}
+	return a
+}
@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestFields is shared by everything running for one request. The auth
+// middleware fills in the user after Middleware created it, so it is a
+// pointer guarded by a mutex: goroutines started by the request read it too.
+type requestFields struct {
+	mu        sync.Mutex
+	requestID string
+	userID    string
+	shopID    string
+}
+
+type ctxKey int
+
+const fieldsKey ctxKey = iota
+
+func fieldsFrom(ctx context.Context) *requestFields {
+	if ctx == nil {
+		return nil
+	}
+	f, _ := ctx.Value(fieldsKey).(*requestFields)
+	return f
+}
+
+// WithRequestID starts request fields for ctx. Middleware calls it; jobs may
+// use it to tag their own records.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, fieldsKey, &requestFields{requestID: requestID})
+}
+
+// SetUser records the authenticated caller for the rest of the request.
+// shopID may be empty. Outside a request it does nothing.
+func SetUser(ctx context.Context, userID, shopID string) {
+	f := fieldsFrom(ctx)
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	f.userID, f.shopID = userID, shopID
+	f.mu.Unlock()
+}
+
+// requestAttrs returns the request fields of ctx. The route pattern comes from
+// chi's routing context, which is only complete once routing has finished; the
+// trace and span IDs from the OpenTelemetry span ctx carries, if any.
+func requestAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+	if f := fieldsFrom(ctx); f != nil {
+		f.mu.Lock()
+		if f.requestID != "" {
+			attrs = append(attrs, slog.String("request_id", f.requestID))
+		}
+		if f.userID != "" {
+			attrs = append(attrs, slog.String("user_id", f.userID))
+		}
+		if f.shopID != "" {
+			attrs = append(attrs, slog.String("shop_id", f.shopID))
+		}
+		f.mu.Unlock()
+	}
+	if rc := chi.RouteContext(ctx); rc != nil {
+		if p := rc.RoutePattern(); p != "" {
+			attrs = append(attrs, slog.String("route", p))
+		}
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs,
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()))
+	}
+	return attrs
+}
+
+// Middleware replaces chi's Logger: it starts the request fields (taking the
+// ID from chi's RequestID middleware, which must run first) and logs one
+// record per request when it completes.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := WithRequestID(r.Context(), middleware.GetReqID(r.Context()))
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK // nothing written
+			}
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			slog.LogAttrs(ctx, level, "request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			)
+		}()
+		next.ServeHTTP(ww, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,181 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterDB adds the pool's statistics and the AI scan job queue, both read
+// at scrape time, to Registry. Call it once per process.
+func RegisterDB(pool *pgxpool.Pool) {
+	Registry.MustRegister(newPoolCollector(pool), newScanJobCollector(pool))
+}
+
+/* ---------- connection pool ---------- */
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired, idle, total, max                *prometheus.Desc
+	acquires, emptyAcquires, canceledAcquires *prometheus.Desc
+	acquireWait                               *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool:             pool,
+		acquired:         desc("acquired_conns", "Connections currently checked out."),
+		idle:             desc("idle_conns", "Idle connections in the pool."),
+		total:            desc("total_conns", "Open connections, including ones being set up."),
+		max:              desc("max_conns", "Maximum size of the pool."),
+		acquires:         desc("acquires_total", "Successful connection acquires."),
+		emptyAcquires:    desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		canceledAcquires: desc("canceled_acquires_total", "Acquires canceled by their context."),
+		acquireWait:      desc("acquire_wait_seconds_total", "Total time spent waiting in successful acquires."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.acquired, c.idle, c.total, c.max, c.acquires, c.emptyAcquires, c.canceledAcquires, c.acquireWait} {
+		ch <- d
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, s.AcquireDuration().Seconds())
+}
+
+/* ---------- AI scan jobs ---------- */
+
+// scanJobStatuses are the ai_scan_job states that count as queued work.
+var scanJobStatuses = []string{"pending", "queued", "running"}
+
+// scanJobCollector reports the AI scan queue from app.ai_scan_job. The scan
+// worker runs outside this service, so durations are taken from the table:
+// each scrape observes the jobs finished since the previous one. Every API
+// instance observes every job, so aggregate the histograms with max() rather
+// than sum() across instances.
+type scanJobCollector struct {
+	db    *pgxpool.Pool
+	depth *prometheus.Desc
+
+	mu       sync.Mutex
+	since    time.Time // completed_at of the newest job observed
+	duration *prometheus.HistogramVec
+	wait     *prometheus.HistogramVec
+}
+
+func newScanJobCollector(db *pgxpool.Pool) *scanJobCollector {
+	buckets := prometheus.ExponentialBuckets(1, 2, 12) // 1s .. ~34m
+	return &scanJobCollector{
+		db:    db,
+		depth: prometheus.NewDesc(prometheus.BuildFQName(namespace, "ai_scan", "jobs"), "AI scan jobs waiting or running, by status.", []string{"status"}, nil),
+		since: time.Now(),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "ai_scan", Name: "job_duration_seconds",
+			Help: "Time from an AI scan job starting to finishing, by final status.", Buckets: buckets,
+		}, []string{"status"}),
+		wait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "ai_scan", Name: "job_wait_seconds",
+			Help: "Time from an AI scan job being requested to starting, by final status.", Buckets: buckets,
+		}, []string{"status"}),
+	}
+}
+
+func (c *scanJobCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.depth
+	c.duration.Describe(ch)
+	c.wait.Describe(ch)
+}
+
+func (c *scanJobCollector) Collect(ch chan<- prometheus.Metric) {
+	// a scrape has no caller; it counts every shop's jobs
+	ctx, cancel := context.WithTimeout(database.WithSystemScope(context.Background()), 2*time.Second)
+	defer cancel()
+
+	if depth, err := c.queueDepth(ctx); err != nil {
+		slog.Warn("metrics: ai scan queue depth", "error", err)
+	} else {
+		for _, status := range scanJobStatuses {
+			ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(depth[status]), status)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.observeFinished(ctx); err != nil {
+		slog.Warn("metrics: ai scan durations", "error", err)
+	}
+	c.duration.Collect(ch)
+	c.wait.Collect(ch)
+}
+
+func (c *scanJobCollector) queueDepth(ctx context.Context) (map[string]int, error) {
+	depth := make(map[string]int)
+	err := database.InScope(ctx, c.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+SELECT status, count(*) FROM app.ai_scan_job
+WHERE status = ANY($1)
+GROUP BY status`, scanJobStatuses)
+		if err != nil {
+			return err
+		}
+		var (
+			status string
+			n      int
+		)
+		_, err = pgx.ForEachRow(rows, []any{&status, &n}, func() error {
+			depth[status] = n
+			return nil
+		})
+		return err
+	})
+	return depth, err
+}
+
+// observeFinished adds the jobs finished since the last scrape to the
+// histograms. Caller holds c.mu.
+func (c *scanJobCollector) observeFinished(ctx context.Context) error {
+	return database.InScope(ctx, c.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+SELECT status, requested_at, started_at, completed_at
+FROM app.ai_scan_job
+WHERE completed_at > $1
+ORDER BY completed_at`, c.since)
+		if err != nil {
+			return err
+		}
+		var (
+			status               string
+			requested, completed time.Time
+			started              *time.Time
+		)
+		_, err = pgx.ForEachRow(rows, []any{&status, &requested, &started, &completed}, func() error {
+			if started != nil {
+				c.wait.WithLabelValues(status).Observe(started.Sub(requested).Seconds())
+				c.duration.WithLabelValues(status).Observe(completed.Sub(*started).Seconds())
+			}
+			c.since = completed
+			return nil
+		})
+		return err
+	})
+}
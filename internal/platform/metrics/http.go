@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	httpRequests = newCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests, by method, route pattern and status code.",
+	}, "method", "route", "status")
+
+	httpDuration = newHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency, by method and route pattern.",
+		Buckets: prometheus.DefBuckets,
+	}, "method", "route")
+)
+
+// unmatchedRoute labels requests no route matched, so probes of random paths
+// cannot create new series.
+const unmatchedRoute = "unmatched"
+
+// Middleware records every request under its chi route pattern
+// ("/shops/{id}"), never the raw path.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+
+		defer func() {
+			route := unmatchedRoute
+			if rc := chi.RouteContext(r.Context()); rc != nil && rc.RoutePattern() != "" {
+				route = rc.RoutePattern()
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+			httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+		}()
+		next.ServeHTTP(ww, r)
+	})
+}
@@ -0,0 +1,131 @@
+// Package metrics exposes Prometheus metrics: HTTP traffic per route, the
+// database pool, background jobs, email delivery and authentication failures.
+// Everything is registered on Registry, which Handler serves. The endpoint is
+// never public: it runs on its own listener (METRICS_ADDR) or behind a bearer
+// token (METRICS_TOKEN) on the API router.
+package metrics
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "havenzsure"
+
+// Registry holds every metric of the process.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var (
+	authFailures = newCounterVec(prometheus.CounterOpts{
+		Name: "auth_failures_total",
+		Help: "Requests rejected by the auth middleware, by reason.",
+	}, "reason")
+
+	emailsSent = newCounterVec(prometheus.CounterOpts{
+		Name: "emails_sent_total",
+		Help: "Emails handed to the mail server, by kind and result (success, failure).",
+	}, "kind", "result")
+
+	importJobs = newCounterVec(prometheus.CounterOpts{
+		Name: "import_jobs_total",
+		Help: "Finished bulk import jobs, by status.",
+	}, "status")
+
+	importJobDuration = newHistogram(prometheus.HistogramOpts{
+		Name:    "import_job_duration_seconds",
+		Help:    "Time from a bulk import job starting to finishing.",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 12), // 0.5s .. ~17m
+	})
+)
+
+func newCounterVec(opts prometheus.CounterOpts, labels ...string) *prometheus.CounterVec {
+	opts.Namespace = namespace
+	c := prometheus.NewCounterVec(opts, labels)
+	Registry.MustRegister(c)
+	return c
+}
+
+func newHistogramVec(opts prometheus.HistogramOpts, labels ...string) *prometheus.HistogramVec {
+	opts.Namespace = namespace
+	h := prometheus.NewHistogramVec(opts, labels)
+	Registry.MustRegister(h)
+	return h
+}
+
+func newHistogram(opts prometheus.HistogramOpts) prometheus.Histogram {
+	opts.Namespace = namespace
+	h := prometheus.NewHistogram(opts)
+	Registry.MustRegister(h)
+	return h
+}
+
+// AuthFailure counts a request the auth middleware rejected.
+func AuthFailure(reason string) {
+	authFailures.WithLabelValues(reason).Inc()
+}
+
+// EmailSent counts one send attempt of kind (e.g. "welcome").
+func EmailSent(kind string, err error) {
+	emailsSent.WithLabelValues(kind, result(err)).Inc()
+}
+
+// ImportJobFinished records a finished bulk import job.
+func ImportJobFinished(status string, took time.Duration) {
+	importJobs.WithLabelValues(status).Inc()
+	importJobDuration.Observe(took.Seconds())
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// Handler serves Registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RequireToken only lets requests with "Authorization: Bearer <token>" through.
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Serve runs a listener on addr that serves only /metrics. It returns when the
+// listener fails.
+func Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	slog.Info("metrics listening", "addr", addr)
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewareLabelsByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Route("/shops", func(sub chi.Router) {
+		sub.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+	})
+
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/shops/{id}", "404"))
+	for _, path := range []string{"/shops/a", "/shops/b"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	assert.Equal(t, before+2, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/shops/{id}", "404")))
+
+	unmatched := testutil.ToFloat64(httpRequests.WithLabelValues("GET", unmatchedRoute, "404"))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/wp-login.php", nil))
+	assert.Equal(t, unmatched+1, testutil.ToFloat64(httpRequests.WithLabelValues("GET", unmatchedRoute, "404")))
+}
+
+func TestRequireToken(t *testing.T) {
+	h := RequireToken("s3cret")(Handler())
+
+	for name, header := range map[string]string{"missing": "", "wrong": "Bearer nope", "not bearer": "s3cret"} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		})
+	}
+
+	EmailSent("welcome", errors.New("smtp down"))
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.Contains(rec.Body.String(), `havenzsure_emails_sent_total{kind="welcome",result="failure"}`))
+}
@@ -6,36 +6,110 @@ package middleware
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/cache"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/logging"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/metrics"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/tracing"
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/user"
+	"github.com/google/uuid"
 )
 
-// AuthMiddleware verifies Firebase/GCIP ID Token
+// AuthConfig tunes the middleware's in-process caches.
+type AuthConfig struct {
+	// TokenCacheSize and TokenCacheTTL bound the cache of verified ID tokens.
+	// Entries never outlive the token's own expiry.
+	TokenCacheSize int
+	TokenCacheTTL  time.Duration
+	// SignInInterval is the minimum time between last_sign_in_at writes per user.
+	SignInInterval time.Duration
+}
+
+// DefaultAuthConfig is used by NewAuthMiddleware.
+var DefaultAuthConfig = AuthConfig{
+	TokenCacheSize: 10000,
+	TokenCacheTTL:  5 * time.Minute,
+	SignInInterval: 5 * time.Minute,
+}
+
+// AuthMiddleware verifies ID tokens (Firebase/GCIP, the emulator or local tokens,
+// depending on the TokenVerifier)
+// Pair it with a user.CachedRepository so the per-request user lookup is cached too.
+// Writes back to the sign-in side (email verified, token version claim) go
+// through the same user.IdentityProvider as the user service.
 type AuthMiddleware struct {
 	userRepo user.Repository
+	verifier auth.TokenVerifier
+	idp      user.IdentityProvider
+
+	tokenTTL   time.Duration
+	tokens     *cache.LRU[[sha256.Size]byte, verifiedToken]
+	lastSignIn *cache.LRU[string, struct{}] // users whose sign-in was recorded recently
+
+	// claimBackfill records users whose token version claim has been tried,
+	// so tokens without the claim trigger one write per process, whatever
+	// its outcome (local mode's in-memory provider knows no DB users).
+	claimBackfill sync.Map
+
+	// apiKeys authenticates hz_ keys on routes wrapped with VerifyOrAPIKey.
+	apiKeys APIKeyAuthenticator
 }
 
-// NewAuthMiddleware creates Auth middleware
-func NewAuthMiddleware(userRepo user.Repository) *AuthMiddleware {
+// APIKeyAuthenticator resolves an API key to its principal (see apikey.Service).
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*auth.AuthUser, error)
+}
+
+// verifiedToken is what the middleware needs from a verified ID token.
+type verifiedToken struct {
+	uid          string
+	tokenVersion int
+	hasVersion   bool
+}
+
+// NewAuthMiddleware creates Auth middleware with the default cache settings
+func NewAuthMiddleware(userRepo user.Repository, verifier auth.TokenVerifier, idp user.IdentityProvider) *AuthMiddleware {
+	return NewAuthMiddlewareWithConfig(userRepo, verifier, idp, DefaultAuthConfig)
+}
+
+// NewAuthMiddlewareWithConfig creates Auth middleware with explicit cache settings
+func NewAuthMiddlewareWithConfig(userRepo user.Repository, verifier auth.TokenVerifier, idp user.IdentityProvider, cfg AuthConfig) *AuthMiddleware {
 	return &AuthMiddleware{
-		userRepo: userRepo,
+		userRepo:   userRepo,
+		verifier:   verifier,
+		idp:        idp,
+		tokenTTL:   cfg.TokenCacheTTL,
+		tokens:     cache.New[[sha256.Size]byte, verifiedToken](cfg.TokenCacheSize, cfg.TokenCacheTTL),
+		lastSignIn: cache.New[string, struct{}](cfg.TokenCacheSize, cfg.SignInInterval),
 	}
 }
 
-// Verify is the main authentication middleware
+// WithAPIKeys enables VerifyOrAPIKey, returning m for chaining
+func (m *AuthMiddleware) WithAPIKeys(keys APIKeyAuthenticator) *AuthMiddleware {
+	m.apiKeys = keys
+	return m
+}
+
+// Verify is the main authentication middleware. It accepts user ID tokens
+// only; API keys are rejected unless the route uses VerifyOrAPIKey.
 // Flow:
 //  1. Extract Bearer token from Header
-//  2. Verify token signature with Firebase/GCIP
-//  3. Query user data from DB
+//  2. Verify token signature with the TokenVerifier (cached until the token expires)
+//  3. Query user data from DB (cached when userRepo is a user.CachedRepository)
 //  4. Check user status (active/inactive)
-//  5. If first login, mark email as verified
-//  6. Build AuthUser and inject into context
-//  7. Update last_sign_in_at asynchronously
+//  5. Reject tokens whose version claim is older than the DB token_version
+//  6. If first login, mark email as verified
+//  7. Build AuthUser, resolve its organization scope and inject both into context
+//  8. Update last_sign_in_at asynchronously (at most once per SignInInterval)
 func (m *AuthMiddleware) Verify(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -46,16 +120,20 @@ func (m *AuthMiddleware) Verify(next http.Handler) http.Handler {
 			writeAuthError(w, http.StatusUnauthorized, auth.ErrNoToken)
 			return
 		}
+		if auth.IsAPIKeyToken(token) {
+			writeAuthError(w, http.StatusUnauthorized, auth.ErrAPIKeyNotAllowed)
+			return
+		}
 
 		// 2. Verify Firebase token signature
-		firebaseToken, err := auth.VerifyIDToken(ctx, token)
+		verified, err := m.verifyToken(ctx, token)
 		if err != nil {
 			writeAuthError(w, http.StatusUnauthorized, auth.ErrInvalidToken)
 			return
 		}
 
 		// 3. Query user from DB (by external_id)
-		dbUser, err := m.userRepo.GetByExternalID(ctx, firebaseToken.UID)
+		dbUser, err := m.userRepo.GetByExternalID(ctx, verified.uid)
 		if err != nil {
 			// User may exist in GCIP but not in DB (should not happen)
 			writeAuthError(w, http.StatusUnauthorized, auth.ErrUserNotFound)
@@ -68,24 +146,37 @@ func (m *AuthMiddleware) Verify(next http.Handler) http.Handler {
 			return
 		}
 
-		// 5. If this is the first successful login, mark email as verified.
-		if !dbUser.EmailVerified {
-			go func(u *user.User) {
-				ctx := context.Background()
+		// 5. Check token version (revoked sessions)
+		// Tokens without the claim were minted before it existed and count as
+		// InitialTokenVersion, so they stop working once the user's sessions are
+		// revoked. The claim is written either way, for the next token.
+		tokenVersion := verified.tokenVersion
+		if !verified.hasVersion {
+			tokenVersion = auth.InitialTokenVersion
+			m.backfillTokenVersion(ctx, dbUser)
+		}
+		if tokenVersion < dbUser.TokenVersion {
+			writeAuthError(w, http.StatusUnauthorized, auth.ErrTokenRevoked)
+			return
+		}
 
-				// Set emailVerified in GCIP
-				if err := auth.SetEmailVerified(ctx, u.ExternalID, true); err != nil {
-					log.Printf("failed to set GCIP emailVerified for %s: %v", u.ExternalID, err)
+		// 6. If this is the first successful login, mark email as verified.
+		if !dbUser.EmailVerified {
+			// Outlives the request but keeps its log fields (request ID)
+			go func(ctx context.Context, u *user.User) {
+				// Set emailVerified with the identity provider
+				if err := m.idp.SetEmailVerified(ctx, u.ExternalID, true); err != nil {
+					slog.ErrorContext(ctx, "failed to set GCIP emailVerified", "external_id", u.ExternalID, "error", err)
 				}
 
 				// Update DB record
 				if err := m.userRepo.MarkEmailVerified(ctx, u.ID); err != nil {
-					log.Printf("failed to mark DB email_verified for %s: %v", u.ID, err)
+					slog.ErrorContext(ctx, "failed to mark DB email_verified", "error", err)
 				}
-			}(dbUser)
+			}(context.WithoutCancel(ctx), dbUser)
 		}
 
-		// 6. Build AuthUser and inject into context
+		// 7. Build AuthUser and inject into context
 		authUser := &auth.AuthUser{
 			ID:           dbUser.ID,
 			Email:        dbUser.Email,
@@ -94,19 +185,135 @@ func (m *AuthMiddleware) Verify(next http.Handler) http.Handler {
 			ShopID:       dbUser.ShopID,
 			TokenVersion: dbUser.TokenVersion,
 			IsActive:     dbUser.IsActive,
+			Permissions:  dbUser.Role.Permissions,
 		}
 
+		// 7a. Resolve the organization scope
+		orgID, err := organizationScope(r, authUser, dbUser.OrganizationID)
+		if err != nil {
+			writeAuthError(w, http.StatusForbidden, err)
+			return
+		}
+		authUser.OrganizationID = orgID
+
 		// Inject into context
 		ctx = auth.SetAuthUser(ctx, authUser)
+		setLogUser(ctx, authUser)
+		if orgID != nil {
+			ctx = auth.WithOrganization(ctx, *orgID)
+		}
 
-		// 7. Update last_sign_in_at (asynchronous, non-blocking)
-		go m.userRepo.TouchLastSignInNowByExternalID(context.Background(), dbUser.ExternalID)
+		// 8. Update last_sign_in_at (asynchronous, non-blocking, coalesced per user);
+		// WithoutCancel keeps the request values (user, trace) past the response
+		if _, recent := m.lastSignIn.Get(dbUser.ExternalID); !recent {
+			m.lastSignIn.Set(dbUser.ExternalID, struct{}{})
+			go m.userRepo.TouchLastSignInNowByExternalID(context.WithoutCancel(ctx), dbUser.ExternalID)
+		}
 
 		// Continue processing request
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// VerifyOrAPIKey authenticates a user token exactly like Verify, or an API key
+// (Authorization: Bearer hz_...) granted at least one of scopes. Routes opt in
+// to API keys by using this instead of Verify; the key's principal has no role,
+// so role checks downstream still reject it.
+func (m *AuthMiddleware) VerifyOrAPIKey(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		userAuth := m.Verify(next)
+		keyAuth := RequireScope(scopes...)(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := extractBearerToken(r)
+			if err != nil || !auth.IsAPIKeyToken(token) || m.apiKeys == nil {
+				userAuth.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := m.apiKeys.Authenticate(r.Context(), token)
+			if err != nil {
+				writeAuthError(w, http.StatusUnauthorized, auth.ErrInvalidToken)
+				return
+			}
+			setLogUser(r.Context(), principal)
+			keyAuth.ServeHTTP(w, r.WithContext(auth.SetAuthUser(r.Context(), principal)))
+		})
+	}
+}
+
+// setLogUser tags the request's log records with the caller.
+func setLogUser(ctx context.Context, u *auth.AuthUser) {
+	shopID := ""
+	if u.ShopID != nil {
+		shopID = u.ShopID.String()
+	}
+	logging.SetUser(ctx, u.ID.String(), shopID)
+}
+
+// organizationScope decides which organization the request is scoped to.
+// Users are always scoped to their own organization; OrganizationHeader may
+// name it but not another one. A SuperAdmin is unscoped unless the header
+// picks an organization.
+func organizationScope(r *http.Request, u *auth.AuthUser, own *uuid.UUID) (*uuid.UUID, error) {
+	header := strings.TrimSpace(r.Header.Get(auth.OrganizationHeader))
+	var requested *uuid.UUID
+	if header != "" {
+		id, err := uuid.Parse(header)
+		if err != nil {
+			return nil, auth.ErrInvalidOrganization
+		}
+		requested = &id
+	}
+
+	if u.IsSuperAdmin() {
+		return requested, nil
+	}
+	if requested != nil && (own == nil || *requested != *own) {
+		return nil, auth.ErrInvalidOrganization
+	}
+	return own, nil
+}
+
+// verifyToken verifies an ID token with the TokenVerifier, or returns the result
+// of an earlier verification of the same token. Tokens are cached by hash and
+// never beyond their own expiry.
+func (m *AuthMiddleware) verifyToken(ctx context.Context, token string) (verifiedToken, error) {
+	key := sha256.Sum256([]byte(token))
+	if v, ok := m.tokens.Get(key); ok {
+		return v, nil
+	}
+
+	ctx, span := tracing.Start(ctx, "auth.VerifyIDToken")
+	idToken, err := m.verifier.VerifyIDToken(ctx, token)
+	tracing.End(span, err)
+	if err != nil {
+		return verifiedToken{}, err
+	}
+	v := verifiedToken{uid: idToken.UID}
+	v.tokenVersion, v.hasVersion = auth.TokenVersionFromClaims(idToken.Claims)
+
+	ttl := min(m.tokenTTL, time.Until(idToken.Expires))
+	m.tokens.SetWithTTL(key, v, ttl)
+	return v, nil
+}
+
+// backfillTokenVersion writes the token version claim for a user whose token
+// doesn't carry one yet (asynchronous, at most once per user and process).
+// A failed write is not retried: the claim is written again whenever the
+// user's token version changes.
+func (m *AuthMiddleware) backfillTokenVersion(ctx context.Context, u *user.User) {
+	if _, loaded := m.claimBackfill.LoadOrStore(u.ExternalID, struct{}{}); loaded {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func(uid string, version int) {
+		if err := m.idp.SetTokenVersion(ctx, uid, version); err != nil {
+			slog.ErrorContext(ctx, "failed to backfill token version claim", "external_id", uid, "error", err)
+		}
+	}(u.ExternalID, u.TokenVersion)
+}
+
 // extractBearerToken extracts token from Authorization header
 // Format: "Authorization: Bearer <token>"
 func extractBearerToken(r *http.Request) (string, error) {
@@ -131,7 +338,32 @@ func extractBearerToken(r *http.Request) (string, error) {
 
 // writeAuthError returns unified authentication error format
 func writeAuthError(w http.ResponseWriter, status int, err error) {
+	metrics.AuthFailure(authFailureReason(err))
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 }
+
+// authFailureReasons label auth_failures_total; errors not listed count as "other".
+var authFailureReasons = map[error]string{
+	auth.ErrNoToken:             "no_token",
+	auth.ErrInvalidToken:        "invalid_token",
+	auth.ErrTokenExpired:        "token_expired",
+	auth.ErrTokenRevoked:        "token_revoked",
+	auth.ErrUserNotFound:        "user_not_found",
+	auth.ErrUserInactive:        "user_inactive",
+	auth.ErrForbidden:           "forbidden",
+	auth.ErrMissingScope:        "missing_scope",
+	auth.ErrAPIKeyNotAllowed:    "api_key_not_allowed",
+	auth.ErrInvalidOrganization: "invalid_organization",
+	auth.ErrNoShopAssignment:    "no_shop_assignment",
+}
+
+func authFailureReason(err error) string {
+	for e, reason := range authFailureReasons {
+		if errors.Is(err, e) {
+			return reason
+		}
+	}
+	return "other"
+}
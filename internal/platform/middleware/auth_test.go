@@ -0,0 +1,405 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/user"
+	"github.com/google/uuid"
+)
+
+// fakeUserRepo serves users by external ID; the embedded interface panics on
+// any method the middleware shouldn't call.
+type fakeUserRepo struct {
+	user.Repository
+
+	mu      sync.Mutex
+	users   map[string]*user.User
+	touches int
+}
+
+func (f *fakeUserRepo) GetByExternalID(_ context.Context, externalID string) (*user.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.users[externalID]
+	if !ok {
+		return nil, user.ErrNotFound
+	}
+	return u, nil
+}
+
+// IncrementTokenVersion bumps the version the way revoking sessions does.
+func (f *fakeUserRepo) IncrementTokenVersion(_ context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if u.ID == id {
+			u.TokenVersion++
+			return nil
+		}
+	}
+	return user.ErrNotFound
+}
+
+func (f *fakeUserRepo) TouchLastSignInNowByExternalID(context.Context, string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.touches++
+	return nil
+}
+
+// countingVerifier counts calls that reach the underlying verifier.
+type countingVerifier struct {
+	auth.TokenVerifier
+	calls atomic.Int32
+}
+
+func (c *countingVerifier) VerifyIDToken(ctx context.Context, token string) (*auth.VerifiedToken, error) {
+	c.calls.Add(1)
+	return c.TokenVerifier.VerifyIDToken(ctx, token)
+}
+
+type authFixture struct {
+	key      *rsa.PrivateKey
+	kid      string
+	repo     *fakeUserRepo
+	idp      *user.MemoryIdentityProvider
+	verifier *countingVerifier
+	handler  http.Handler
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, kid, err := auth.LocalJWKS(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := auth.NewLocalVerifier(jwks, auth.DefaultLocalIssuer, auth.DefaultLocalAudience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shopID := uuid.New()
+	repo := &fakeUserRepo{users: map[string]*user.User{
+		"active": {ID: uuid.New(), ExternalID: "active", Email: "a@example.com", IsActive: true,
+			EmailVerified: true, TokenVersion: 2, ShopID: &shopID, Role: user.Role{Code: auth.RoleAdjuster}},
+		"legacy": {ID: uuid.New(), ExternalID: "legacy", IsActive: true, EmailVerified: true,
+			TokenVersion: 1, Role: user.Role{Code: auth.RoleBodyman}},
+		"inactive": {ID: uuid.New(), ExternalID: "inactive", IsActive: false, EmailVerified: true,
+			TokenVersion: 2, Role: user.Role{Code: auth.RoleAdmin}},
+	}}
+	idp := user.NewMemoryIdentityProvider()
+	for uid := range repo.users {
+		idp.Add(user.MemoryIdentity{Identity: user.Identity{UID: uid}})
+	}
+	f := &authFixture{key: key, kid: kid, repo: repo, idp: idp, verifier: &countingVerifier{TokenVerifier: local}}
+
+	mw := NewAuthMiddleware(repo, f.verifier, idp)
+	f.handler = mw.Verify(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, err := auth.GetAuthUser(r.Context())
+		if err != nil {
+			t.Errorf("no auth user in context: %v", err)
+		}
+		w.Header().Set("X-User", u.ExternalID)
+		w.Header().Set("X-Role", u.RoleCode)
+		w.WriteHeader(http.StatusOK)
+	}))
+	return f
+}
+
+func (f *authFixture) token(t *testing.T, uid string, claims map[string]interface{}) string {
+	t.Helper()
+	s, err := auth.SignLocalToken(f.key, f.kid, auth.LocalToken{UID: uid, TTL: time.Hour, Claims: claims})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func (f *authFixture) do(token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestVerifyAcceptsCurrentToken(t *testing.T) {
+	f := newAuthFixture(t)
+	rec := f.do(f.token(t, "active", map[string]interface{}{auth.TokenVersionClaim: 2}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("X-User") != "active" || rec.Header().Get("X-Role") != auth.RoleAdjuster {
+		t.Errorf("auth user = %s/%s", rec.Header().Get("X-User"), rec.Header().Get("X-Role"))
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	f := newAuthFixture(t)
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"no token", "", auth.ErrNoToken},
+		{"bad token", "nope", auth.ErrInvalidToken},
+		{"unknown user", f.token(t, "ghost", nil), auth.ErrUserNotFound},
+		{"inactive", f.token(t, "inactive", map[string]interface{}{auth.TokenVersionClaim: 2}), auth.ErrUserInactive},
+		{"old version", f.token(t, "active", map[string]interface{}{auth.TokenVersionClaim: 1}), auth.ErrTokenRevoked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := f.do(tt.token)
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", rec.Code)
+			}
+			if want := `{"error":"` + tt.want.Error() + `"}` + "\n"; rec.Body.String() != want {
+				t.Errorf("body = %s, want %s", rec.Body, want)
+			}
+		})
+	}
+}
+
+func TestAuthFailureReason(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{auth.ErrUserInactive, "user_inactive"},
+		{auth.ErrInvalidOrganization, "invalid_organization"},
+		{fmt.Errorf("verify: %w", auth.ErrInvalidToken), "invalid_token"},
+		{errors.New("boom"), "other"},
+	} {
+		if got := authFailureReason(tt.err); got != tt.want {
+			t.Errorf("authFailureReason(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+// Test: tokens minted before the version claim existed count as the initial
+// version: accepted until the user's sessions are revoked, and the claim is
+// backfilled through the identity provider either way
+func TestVerifyLegacyTokenWithoutClaim(t *testing.T) {
+	f := newAuthFixture(t)
+	if rec := f.do(f.token(t, "legacy", nil)); rec.Code != http.StatusOK {
+		t.Fatalf("legacy: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := f.do(f.token(t, "active", nil)); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("active (token_version 2): status = %d, want 401", rec.Code)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		a, _ := f.idp.Get("active")
+		if a.TokenVersion == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("token version claim = %d, want 2", a.TokenVersion)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Test: revoking sessions cuts off a claimless token already in hand
+func TestVerifyRejectsLegacyTokenAfterRevokeSessions(t *testing.T) {
+	f := newAuthFixture(t)
+	legacy := f.repo.users["legacy"]
+	token := f.token(t, "legacy", nil)
+	if rec := f.do(token); rec.Code != http.StatusOK {
+		t.Fatalf("before revocation: status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	if err := f.repo.IncrementTokenVersion(context.Background(), legacy.ID); err != nil {
+		t.Fatal(err)
+	}
+	rec := f.do(token)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("after revocation: status = %d, want 401", rec.Code)
+	}
+	if want := `{"error":"` + auth.ErrTokenRevoked.Error() + `"}` + "\n"; rec.Body.String() != want {
+		t.Errorf("body = %s, want %s", rec.Body, want)
+	}
+}
+
+func TestVerifyCachesTokensAndCoalescesSignIns(t *testing.T) {
+	f := newAuthFixture(t)
+	token := f.token(t, "active", map[string]interface{}{auth.TokenVersionClaim: 2})
+	for range 3 {
+		if rec := f.do(token); rec.Code != http.StatusOK {
+			t.Fatalf("status = %d", rec.Code)
+		}
+	}
+	if n := f.verifier.calls.Load(); n != 1 {
+		t.Errorf("verifier called %d times, want 1", n)
+	}
+
+	// The sign-in write happens asynchronously
+	deadline := time.Now().Add(time.Second)
+	for {
+		f.repo.mu.Lock()
+		touches := f.repo.touches
+		f.repo.mu.Unlock()
+		if touches == 1 {
+			break
+		}
+		if touches > 1 || time.Now().After(deadline) {
+			t.Fatalf("last sign-in written %d times, want 1", touches)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// fakeKeys accepts the keys in its map.
+type fakeKeys map[string]*auth.AuthUser
+
+func (f fakeKeys) Authenticate(_ context.Context, key string) (*auth.AuthUser, error) {
+	if p, ok := f[key]; ok {
+		return p, nil
+	}
+	return nil, errors.New("unknown key")
+}
+
+func TestVerifyOrAPIKey(t *testing.T) {
+	f := newAuthFixture(t)
+	keyID := uuid.New()
+	keys := fakeKeys{
+		"hz_read_secret":  {ID: keyID, ExternalID: "apikey:read", APIKeyID: &keyID, Scopes: []string{auth.ScopeWorkOrdersRead}},
+		"hz_scans_secret": {ID: keyID, ExternalID: "apikey:scans", APIKeyID: &keyID, Scopes: []string{auth.ScopeScansCallback}},
+	}
+	mw := NewAuthMiddleware(f.repo, f.verifier, f.idp).WithAPIKeys(keys)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, _ := auth.GetAuthUser(r.Context())
+		w.Header().Set("X-User", u.ExternalID)
+	})
+	withKeys := mw.VerifyOrAPIKey(auth.ScopeWorkOrdersRead)(ok)
+	userOnly := mw.Verify(ok)
+
+	do := func(h http.Handler, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/workorders", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		token    string
+		wantCode int
+		wantUser string
+		wantErr  error
+	}{
+		{"key with scope", withKeys, "hz_read_secret", http.StatusOK, "apikey:read", nil},
+		{"key without scope", withKeys, "hz_scans_secret", http.StatusForbidden, "", auth.ErrMissingScope},
+		{"unknown key", withKeys, "hz_nope_secret", http.StatusUnauthorized, "", auth.ErrInvalidToken},
+		{"user token still works", withKeys, f.token(t, "active", map[string]interface{}{auth.TokenVersionClaim: 2}), http.StatusOK, "active", nil},
+		{"key on user-only route", userOnly, "hz_read_secret", http.StatusUnauthorized, "", auth.ErrAPIKeyNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(tt.handler, tt.token)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantCode, rec.Body)
+			}
+			if got := rec.Header().Get("X-User"); got != tt.wantUser {
+				t.Errorf("user = %q, want %q", got, tt.wantUser)
+			}
+			if tt.wantErr != nil {
+				if want := `{"error":"` + tt.wantErr.Error() + `"}` + "\n"; rec.Body.String() != want {
+					t.Errorf("body = %s, want %s", rec.Body, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEnforceShopScopeForAPIKeys(t *testing.T) {
+	shopID := uuid.New()
+	keyID := uuid.New()
+	handler := EnforceShopScope()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := GetShopIDFromContext(r.Context()); ok {
+			w.Header().Set("X-Shop", id.String())
+		}
+	}))
+
+	for name, tt := range map[string]struct {
+		shopID   *uuid.UUID
+		wantShop string
+	}{
+		"system-wide key is unrestricted": {nil, ""},
+		"shop key is scoped to its shop":  {&shopID, shopID.String()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			principal := &auth.AuthUser{ID: keyID, APIKeyID: &keyID, ShopID: tt.shopID}
+			req := httptest.NewRequest(http.MethodGet, "/workorders", nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req.WithContext(auth.SetAuthUser(req.Context(), principal)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			if got := rec.Header().Get("X-Shop"); got != tt.wantShop {
+				t.Errorf("shop = %q, want %q", got, tt.wantShop)
+			}
+		})
+	}
+}
+
+func TestOrganizationScope(t *testing.T) {
+	own, other := uuid.New(), uuid.New()
+	superAdmin := &auth.AuthUser{RoleCode: auth.RoleSuperAdmin}
+	orgAdmin := &auth.AuthUser{RoleCode: auth.RoleOrgAdmin, Permissions: []string{auth.PermOrgShops}}
+
+	for name, tt := range map[string]struct {
+		user    *auth.AuthUser
+		own     *uuid.UUID
+		header  string
+		want    *uuid.UUID
+		wantErr bool
+	}{
+		"superadmin is unscoped by default":    {superAdmin, nil, "", nil, false},
+		"superadmin switches organization":     {superAdmin, nil, other.String(), &other, false},
+		"user is scoped to own organization":   {orgAdmin, &own, "", &own, false},
+		"user may name own organization":       {orgAdmin, &own, own.String(), &own, false},
+		"user cannot switch organization":      {orgAdmin, &own, other.String(), nil, true},
+		"user without organization cannot set": {orgAdmin, nil, other.String(), nil, true},
+		"malformed header":                     {superAdmin, nil, "acme", nil, true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/shops", nil)
+			if tt.header != "" {
+				req.Header.Set(auth.OrganizationHeader, tt.header)
+			}
+			got, err := organizationScope(req, tt.user, tt.own)
+			if tt.wantErr {
+				if !errors.Is(err, auth.ErrInvalidOrganization) {
+					t.Fatalf("err = %v, want ErrInvalidOrganization", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("scope = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
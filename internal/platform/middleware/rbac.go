@@ -7,6 +7,7 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"slices"
 
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
 	"github.com/google/uuid"
@@ -41,8 +42,31 @@ func RequireRole(allowedRoles ...string) func(http.Handler) http.Handler {
 	}
 }
 
+// RequirePermission checks if user holds at least one of the specified
+// permissions (granted to their role in app.role_permissions)
+// Usage:
+//
+//	r.Use(middleware.RequirePermission(auth.PermWorkOrdersTransfer))
+func RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authUser, err := auth.GetAuthUser(r.Context())
+			if err != nil {
+				writeAuthError(w, http.StatusUnauthorized, err)
+				return
+			}
+
+			if !authUser.CanAny(permissions...) {
+				writeAuthError(w, http.StatusForbidden, auth.ErrForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // RequireSuperAdmin requires SuperAdmin role
-// NOTE: Currently unused. No route for only superadmin yet, but provided for future use
 func RequireSuperAdmin() func(http.Handler) http.Handler {
 	return RequireRole("superadmin")
 }
@@ -52,8 +76,31 @@ func RequireAdminOrAbove() func(http.Handler) http.Handler {
 	return RequireRole("superadmin", "admin")
 }
 
+// RequireScope requires API key principals to hold at least one of scopes.
+// Users pass through; their access is decided by role.
+func RequireScope(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authUser, err := auth.GetAuthUser(r.Context())
+			if err != nil {
+				writeAuthError(w, http.StatusUnauthorized, err)
+				return
+			}
+
+			if authUser.IsAPIKey() && !slices.ContainsFunc(scopes, authUser.HasScope) {
+				writeAuthError(w, http.StatusForbidden, auth.ErrMissingScope)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // EnforceShopScope enforces shop-scoped access for non-admin users
-// SuperAdmin bypass this check; other roles must have a shop assigned.
+// SuperAdmin, org-wide users (org.shops) and system-wide API keys bypass this
+// check; other roles (and shop API keys) must have a shop assigned. Org-wide
+// users are still limited to their organization by the repositories.
 // The user's shop ID is injected into context for downstream handlers to filter by shop.
 func EnforceShopScope() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -64,8 +111,8 @@ func EnforceShopScope() func(http.Handler) http.Handler {
 				return
 			}
 
-			// Only SuperAdmin is not restricted
-			if authUser.IsSuperAdmin() {
+			// Only SuperAdmin, org-wide users and system-wide API keys are not restricted to a shop
+			if authUser.IsSuperAdmin() || authUser.IsOrgWide() || authUser.IsSystemAPIKey() {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -85,7 +132,7 @@ func EnforceShopScope() func(http.Handler) http.Handler {
 
 // GetShopIDFromContext retrieves shop ID from context
 // Returns (shopID, true) if shop scope enforced, (uuid.Nil, false) otherwise
-// NOTE: Currently unused - will be used in work order service layer for filtering by shop
+// Used by the work order list and export to filter by shop
 func GetShopIDFromContext(ctx context.Context) (uuid.UUID, bool) {
 	shopID, ok := ctx.Value(shopIDKey).(uuid.UUID)
 	return shopID, ok
@@ -1,33 +1,56 @@
 package server
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"time"
 
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/apikey"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/bms"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/bulkimport"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/estimate"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/invoice"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/organization"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/health"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/logging"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/metrics"
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/middleware"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/tracing"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/rbac"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/reconcile"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/report"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/scan"
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/shop"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/shopdeactivation"
 	users "github.com/DashboardDivas/havenzsure-dashboard-backend/internal/user"
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/migrations"
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func (s *Server) RegisterRoutes(db *pgxpool.Pool) http.Handler {
+func (s *Server) RegisterRoutes(ctx context.Context, db *pgxpool.Pool) http.Handler {
 	router := chi.NewRouter()
 
 	// Global middlewares
-	router.Use(chimiddleware.Logger)
+	router.Use(chimiddleware.RequestID)
+	router.Use(tracing.Middleware) // request span; outside logging so logs carry the trace ID
+	router.Use(logging.Middleware) // structured access log; request fields for slog
+	router.Use(metrics.Middleware)
 	router.Use(chimiddleware.Recoverer)
-	router.Use(chimiddleware.Timeout(5 * time.Second))
+	// Request origin for audit entries
+	router.Use(audit.Middleware)
 
 	// Enables CORS so browser clients on other origins can call this API.
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
+		AllowedOrigins:   s.cfg.HTTP.CORSOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", auth.OrganizationHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -36,36 +59,139 @@ func (s *Server) RegisterRoutes(db *pgxpool.Pool) http.Handler {
 		w.WriteHeader(http.StatusOK)
 	})
 
+	// Prometheus metrics, only with a token; METRICS_ADDR serves them on a
+	// separate listener instead (see cmd/api)
+	if token := s.cfg.Metrics.Token; token != "" {
+		router.With(metrics.RequireToken(token)).Handle("/metrics", metrics.Handler())
+	}
+
 	// Protected routes (require authentication)
+	// --- Audit log (written by every service that changes data) ---
+	auditSvc := audit.NewService(audit.NewRepository(db))
+	auditHandler := audit.NewHandler(auditSvc)
+
+	// --- Organization route group ---
+	orgHandler := organization.NewHandler(organization.NewService(organization.NewRepository(db), auditSvc))
+
 	// --- Shop route group ---
 	shopRepo := shop.NewShopRepository(db)
-	shopSvc := shop.NewService(shopRepo)
+	shopSvc := shop.NewService(shopRepo, auditSvc)
 	shopHandler := shop.NewHandler(shopSvc)
 
 	// -- User route group ---
-	userRepo := users.NewUserRepository(db)
+	// Cached so the auth middleware doesn't hit the DB on every request; writes
+	// through this repository invalidate the cache (and other instances' caches
+	// when USER_CACHE_NOTIFY is set).
+	userRepo := users.NewCachedRepository(users.NewUserRepository(db), db, users.CacheConfig{
+		Size:   s.cfg.UserCache.Size,
+		TTL:    s.cfg.UserCache.TTL,
+		Notify: s.cfg.UserCache.Notify,
+	})
+	go userRepo.Listen(ctx)
+	// Local token mode runs without Google, so accounts live in memory
+	var idp users.IdentityProvider = users.NewGCIPIdentityProvider()
+	if _, local := s.verifier.(*auth.LocalVerifier); local {
+		idp = users.NewMemoryIdentityProvider()
+	} else {
+		// Periodic drift check between GCIP accounts and app.users (off unless
+		// RECONCILE_INTERVAL is set)
+		go reconcile.Schedule(ctx, reconcile.New(userRepo, idp), db, reconcile.JobConfig{
+			Interval: s.cfg.Reconcile.Interval,
+			Repair:   s.cfg.Reconcile.Repair,
+		})
+	}
 	var userSvc users.UserService
-	if smtpSender, err := users.NewSMTPSenderFromEnv(); err != nil {
+	var smtpPing func(context.Context) error // nil: SMTP not configured
+	if smtp := s.cfg.SMTP; !smtp.Enabled() {
+		log.Printf("WARNING: SMTP_USERNAME not set; falling back to log-only sender")
+		userSvc = users.NewService(userRepo, shopSvc, idp, auditSvc)
+	} else if smtpSender, err := users.NewSMTPSender(smtp.Host, smtp.Port, smtp.Username, smtp.Password, smtp.From); err != nil {
 		log.Printf("WARNING: failed to initialize SMTP sender: %v; falling back to log-only sender", err)
-		userSvc = users.NewService(userRepo, shopSvc)
+		userSvc = users.NewService(userRepo, shopSvc, idp, auditSvc)
 	} else {
-		userSvc = users.NewServiceWithEmailSender(userRepo, shopSvc, smtpSender)
+		userSvc = users.NewServiceWithEmailSender(userRepo, shopSvc, idp, smtpSender, auditSvc)
+		smtpPing = smtpSender.Ping
 	}
 	userHandler := users.NewHandler(userSvc)
 
+	// --- Shop deactivation (moves/holds work orders, deactivates users) ---
+	deactivationSvc := shopdeactivation.NewService(shopdeactivation.NewRepository(db), userSvc, auditSvc)
+	deactivationHandler := shopdeactivation.NewHandler(deactivationSvc)
+
+	// --- Work order images and AI scan results ---
+	scanSvc := scan.NewService(scan.NewRepository(db), auditSvc)
+	scanHandler := scan.NewHandler(scanSvc)
+
+	// --- Roles and permissions ---
+	// Cached users carry their role's permissions, so grant changes purge them
+	rbacSvc := rbac.NewService(rbac.NewRepository(db), userRepo, auditSvc)
+	rbacHandler := rbac.NewHandler(rbacSvc)
+
 	// --- Me route group ---
 	meSvc := users.NewMeService(userSvc)
 	meHandler := users.NewMeHandler(meSvc)
 
 	// --- WorkOrder route group ---
 	workorderRepo := workorder.NewRepository(db)
-	workorderSvc := workorder.NewService(workorderRepo)
+	workorderSvc := workorder.NewService(workorderRepo, auditSvc)
 	workorderHandler := workorder.NewHandler(workorderSvc)
 
+	// --- Billing (estimates, invoices, payments) ---
+	estimateRepo := estimate.NewRepository(db)
+	estimateSvc := estimate.NewService(estimateRepo, auditSvc)
+	estimateHandler := estimate.NewHandler(estimateSvc)
+
+	invoiceRepo := invoice.NewRepository(db)
+	invoiceSvc := invoice.NewService(invoiceRepo, estimateRepo, auditSvc)
+	invoiceHandler := invoice.NewHandler(invoiceSvc)
+
+	// --- PDF documents ---
+	reportRepo := report.NewRepository(db)
+	reportSvc := report.NewService(reportRepo, workorderSvc, estimateSvc, invoiceSvc, report.NewHTTPImageLoader(3*time.Second))
+	reportHandler := report.NewHandler(reportSvc)
+
+	// --- Spreadsheet import ---
+	importRepo := bulkimport.NewRepository(db)
+	importSvc := bulkimport.NewService(importRepo, workorderSvc)
+	importHandler := bulkimport.NewHandler(importSvc)
+	s.drains = append(s.drains, importSvc.Shutdown)
+	go importSvc.FailAbandonedJobs(ctx)
+
+	// --- Insurer exchange (CIECA BMS) ---
+	bmsSvc := bms.NewService(workorderSvc, estimateSvc)
+	bmsHandler := bms.NewHandler(bmsSvc)
+
+	// --- Service-to-service API keys ---
+	apiKeySvc := apikey.NewService(apikey.NewRepository(db), shopSvc, auditSvc)
+	apiKeyHandler := apikey.NewHandler(apiKeySvc)
+
+	// --- Health (liveness, readiness, operator status) ---
+	schemaVersion, err := migrations.LatestVersion()
+	if err != nil {
+		log.Fatalf("embedded migrations: %v", err)
+	}
+	healthHandler := health.NewHandler(health.NewChecker(
+		health.Database(db),
+		health.Migrations(db, schemaVersion),
+		health.IdentityProvider(func() error { return auth.VerifierReady(s.verifier) }),
+		health.SMTP(smtpPing),
+	), db)
+	router.Get("/healthz", healthHandler.Live)
+	router.Get("/readyz", healthHandler.Ready)
+
 	// Auth middleware
-	authMiddleware := middleware.NewAuthMiddleware(userRepo)
+	authMiddleware := middleware.NewAuthMiddleware(userRepo, s.verifier, idp).WithAPIKeys(apiKeySvc)
 
+	// Streaming exports run for as long as they need, so they sit outside the
+	// request timeout below. Static paths take precedence over the /workorders mount.
 	router.Group(func(r chi.Router) {
+		r.Use(authMiddleware.VerifyOrAPIKey(auth.ScopeWorkOrdersRead))
+		r.With(middleware.EnforceShopScope()).Get("/workorders/export.csv", workorderHandler.ExportCSV)
+	})
+
+	router.Group(func(r chi.Router) {
+		r.Use(chimiddleware.Timeout(s.cfg.HTTP.RequestTimeout))
+
 		// Apply authentication middleware
 		// All routes inside this group require valid Firebase/GCIP ID Token
 		r.Use(authMiddleware.Verify)
@@ -77,26 +203,104 @@ func (s *Server) RegisterRoutes(db *pgxpool.Pool) http.Handler {
 			meHandler.RegisterRoutes(sub)
 		})
 
-		// --- Shop Routes (SuperAdmin + Admin only) ---
+		// --- Status page (superadmin only) ---
+		r.With(middleware.RequireSuperAdmin()).Get("/status", healthHandler.Status)
+
+		// --- Audit Routes (audit.read; the full log is superadmin only) ---
+		r.Route("/audit", func(sub chi.Router) {
+			sub.Use(middleware.RequirePermission(auth.PermAuditRead))
+			auditHandler.RegisterRoutes(sub)
+		})
+
+		// --- Organization Routes (superadmin manages; others read their own) ---
+		r.Route("/organizations", orgHandler.RegisterRoutes)
+
+		// --- Shop Routes (shops.manage; /shops/options for everyone) ---
 		r.Route("/shops", func(sub chi.Router) {
-			sub.Use(middleware.RequireAdminOrAbove())
-			shopHandler.RegisterRoutes(sub)
+			shopHandler.RegisterOptionRoutes(sub)
+			sub.Group(func(g chi.Router) {
+				g.Use(middleware.RequirePermission(auth.PermShopsManage))
+				shopHandler.RegisterRoutes(g)
+				deactivationHandler.RegisterRoutes(g)
+			})
+		})
+
+		// --- API Key Routes (apikeys.manage) ---
+		r.Route("/api-keys", func(sub chi.Router) {
+			sub.Use(middleware.RequirePermission(auth.PermAPIKeysManage))
+			apiKeyHandler.RegisterRoutes(sub)
 		})
 
-		// --- User Routes (SuperAdmin + Admin only) ---
+		// --- User Routes (users.manage) ---
 		// Note: Fine-grained permission checks are in service layer
 		r.Route("/users", func(sub chi.Router) {
-			sub.Use(middleware.RequireAdminOrAbove())
+			sub.Use(middleware.RequirePermission(auth.PermUsersManage))
 			userHandler.RegisterRoutes(sub)
 		})
 
+		// --- Role Routes (roles.manage; user managers may read) ---
+		r.Route("/roles", func(sub chi.Router) {
+			sub.Use(middleware.RequirePermission(auth.PermRolesManage, auth.PermUsersManage))
+			rbacHandler.RegisterRoutes(sub)
+		})
+		r.Route("/permissions", func(sub chi.Router) {
+			sub.Use(middleware.RequirePermission(auth.PermRolesManage))
+			rbacHandler.RegisterPermissionRoutes(sub)
+		})
+
 		// --- Work Order Routes (all authenticated users can access) ---
 		// But with fine-grained permission control inside
 		r.Route("/workorders", func(sub chi.Router) {
 			sub.Use(middleware.EnforceShopScope())
 			workorderHandler.RegisterRoutes(sub)
+			sub.Get("/{id}/report.pdf", reportHandler.WorkOrderPDF)
+			sub.Get("/{id}/bms.xml", bmsHandler.ExportWorkOrder)
+			sub.Post("/import/bms", bmsHandler.ImportAssignment)
+			sub.Route("/import", importHandler.RegisterRoutes)
+			sub.Route("/{id}/estimates", estimateHandler.RegisterWorkOrderRoutes)
+			sub.Route("/{id}/invoices", invoiceHandler.RegisterWorkOrderRoutes)
+		})
+
+		// --- Billing Routes (shop-scoped; role checks are in service layer) ---
+		r.Route("/estimates", func(sub chi.Router) {
+			sub.Use(middleware.EnforceShopScope())
+			estimateHandler.RegisterRoutes(sub)
+			sub.Get("/{id}/report.pdf", reportHandler.EstimatePDF)
+		})
+		r.Route("/invoices", func(sub chi.Router) {
+			sub.Use(middleware.EnforceShopScope())
+			invoiceHandler.RegisterRoutes(sub)
+			sub.Get("/{id}/report.pdf", reportHandler.InvoicePDF)
 		})
 	})
 
+	// Read-only work order routes that also accept API keys (Bearer hz_...).
+	// Registered after the /workorders mount so they take over these two paths
+	// from it; deeper paths still go to the mount. A new method on
+	// /workorders/{id} has to be added here too, or it answers 405.
+	router.Group(func(r chi.Router) {
+		r.Use(chimiddleware.Timeout(s.cfg.HTTP.RequestTimeout))
+		r.Use(authMiddleware.VerifyOrAPIKey(auth.ScopeWorkOrdersRead))
+		r.Use(middleware.EnforceShopScope())
+		r.Get("/workorders", workorderHandler.ListWorkOrder)
+		r.Get("/workorders/{id}", workorderHandler.GetWorkOrderByID)
+	})
+
+	// Image registration and scan result callbacks, used by the upload and
+	// scan workers with their own API key scopes. Shop keys only reach their
+	// own shop's work orders and jobs through row-level security.
+	router.Group(func(r chi.Router) {
+		r.Use(chimiddleware.Timeout(s.cfg.HTTP.RequestTimeout))
+		r.Use(authMiddleware.VerifyOrAPIKey(auth.ScopeImagesWrite))
+		r.Use(middleware.EnforceShopScope())
+		r.Post("/workorders/{id}/images", scanHandler.RegisterImage)
+	})
+	router.Group(func(r chi.Router) {
+		r.Use(chimiddleware.Timeout(s.cfg.HTTP.RequestTimeout))
+		r.Use(authMiddleware.VerifyOrAPIKey(auth.ScopeScansCallback))
+		r.Use(middleware.EnforceShopScope())
+		r.Post("/scans/{id}/results", scanHandler.RecordResult)
+	})
+
 	return router
 }
@@ -1,30 +1,46 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"net/http"
-	"os"
-	"time"
 
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/config"
 	"github.com/jackc/pgx/v5/pgxpool"
-	_ "github.com/joho/godotenv/autoload"
 )
 
 type Server struct {
-	port string
+	cfg      *config.Config
+	verifier auth.TokenVerifier
+	// drains stop work that outlives its request (import jobs)
+	drains []func(context.Context) error
 }
 
-func NewServer(db *pgxpool.Pool) *http.Server {
-	port := os.Getenv("PORT")
+// NewServer builds the HTTP server. The background work the routes start (the
+// user cache listener, the reconcile job, the import job sweep) runs until ctx
+// is cancelled. Call drain once the server has shut down, before closing db:
+// it waits for the work requests left running, such as import jobs.
+func NewServer(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, verifier auth.TokenVerifier) (srv *http.Server, drain func(context.Context) error) {
 	NewServer := &Server{
-		port: port,
+		cfg:      cfg,
+		verifier: verifier,
 	}
 
 	server := &http.Server{
-		Addr:         ":" + NewServer.port,
-		Handler:      NewServer.RegisterRoutes(db),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:         ":" + cfg.HTTP.Port,
+		Handler:      NewServer.RegisterRoutes(ctx, db),
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
 	}
-	return server
+	return server, NewServer.drain
+}
+
+func (s *Server) drain(ctx context.Context) error {
+	var errs []error
+	for _, d := range s.drains {
+		errs = append(errs, d(ctx))
+	}
+	return errors.Join(errs...)
 }
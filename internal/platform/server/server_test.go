@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/config"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test: the server is built from the Config it is given, not the environment
+func TestNewServerUsesConfig(t *testing.T) {
+	cfg, err := config.LoadFrom(config.Map(map[string]string{
+		"DB_HOST": "127.0.0.1", "DB_PORT": "1", "DB_APP_USER": "nobody", "DB_APP_PASSWORD": "x", "DB_NAME": "none",
+		"AUTH_MODE":            "emulator",
+		"PORT":                 "9123",
+		"HTTP_READ_TIMEOUT":    "3s",
+		"CORS_ALLOWED_ORIGINS": "https://app.example.com",
+		"USER_CACHE_NOTIFY":    "true",
+		"RECONCILE_INTERVAL":   "1h",
+	}))
+	require.NoError(t, err)
+	// never connects: pgxpool dials lazily
+	pool, err := pgxpool.New(context.Background(), cfg.Database.ConnString())
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	goroutines := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	srv, drain := NewServer(ctx, cfg, pool, nil)
+	assert.Equal(t, ":9123", srv.Addr)
+	assert.Equal(t, 3*time.Second, srv.ReadTimeout)
+
+	rec := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	for origin, allowed := range map[string]bool{"https://app.example.com": true, "https://evil.example.com": false} {
+		req := httptest.NewRequest(http.MethodOptions, "/me", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		rec := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, req)
+
+		if allowed {
+			assert.Equal(t, origin, rec.Header().Get("Access-Control-Allow-Origin"))
+		} else {
+			assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"), origin)
+		}
+	}
+
+	// the cache listener, the reconcile job and the import job sweep stop with
+	// the context (polled here: assert.Eventually would count its own goroutine)
+	require.NoError(t, drain(context.Background()))
+	cancel()
+	for deadline := time.Now().Add(5 * time.Second); runtime.NumGoroutine() > goroutines && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines, "background goroutines outlive the server context")
+}
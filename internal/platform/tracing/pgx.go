@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer is a pgx.QueryTracer that wraps every query in a client span
+// named by its operation ("SELECT", "INSERT", ...). The SQL text is recorded,
+// never the arguments. Set it on the pool's ConnConfig.Tracer.
+type QueryTracer struct{}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	op := operation(data.SQL)
+	ctx, _ = Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(op),
+			semconv.DBQueryText(data.SQL),
+		))
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer. No rows is an answer, not a
+// failure, so it does not mark the span as an error.
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	End(span, err)
+}
+
+// operation is the leading keyword of sql, upper-cased, after any "--"
+// comment lines.
+func operation(sql string) string {
+	sql = strings.TrimSpace(sql)
+	for strings.HasPrefix(sql, "--") {
+		nl := strings.IndexByte(sql, '\n')
+		if nl < 0 {
+			return "query"
+		}
+		sql = strings.TrimSpace(sql[nl+1:])
+	}
+	end := strings.IndexFunc(sql, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+	if end < 0 {
+		end = len(sql)
+	}
+	if end == 0 {
+		return "query"
+	}
+	return strings.ToUpper(sql[:end])
+}
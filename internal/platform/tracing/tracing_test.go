@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// recordSpans installs a tracer provider that keeps ended spans in memory.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+func TestMiddlewareNamesSpansByRoute(t *testing.T) {
+	rec := recordSpans(t)
+
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/workorders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/workorders/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/wp-login.php", nil))
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+
+	s := spans[0]
+	assert.Equal(t, "GET /workorders/{id}", s.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", s.SpanContext().TraceID().String(), "continues the caller's trace")
+	assert.Equal(t, codes.Error, s.Status().Code)
+	assert.Contains(t, s.Attributes(), semconv.HTTPRoute("/workorders/{id}"))
+	assert.Contains(t, s.Attributes(), semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+
+	assert.Equal(t, "GET "+unmatchedRoute, spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code, "a 404 is not a server error")
+}
+
+func TestQueryTracer(t *testing.T) {
+	rec := recordSpans(t)
+	var qt QueryTracer
+
+	ctx := qt.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1 WHERE $1"})
+	qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: pgx.ErrNoRows})
+	ctx = qt.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "update app.shop set x = 1"})
+	qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("deadlock detected")})
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "SELECT", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code, "no rows is not an error")
+	assert.Contains(t, spans[0].Attributes(), semconv.DBQueryText("SELECT 1 WHERE $1"))
+	assert.Equal(t, "UPDATE", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestOperation(t *testing.T) {
+	for sql, want := range map[string]string{
+		"SELECT * FROM app.shop":                  "SELECT",
+		"\n\t insert into app.shop":               "INSERT",
+		"-- load the work order\nWITH x AS (...)": "WITH",
+		"":                  "query",
+		"-- only a comment": "query",
+	} {
+		assert.Equal(t, want, operation(sql), sql)
+	}
+}
@@ -0,0 +1,38 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain-level errors for role operations
+var (
+	ErrNotFound     = errors.New("role not found")
+	ErrConflict     = errors.New("role conflict")
+	ErrInvalidInput = errors.New("invalid role input")
+	ErrForbidden    = errors.New("forbidden: insufficient permissions")
+
+	ErrSystemRole = errors.New("system roles cannot be changed")
+	ErrRoleInUse  = errors.New("role is assigned to users")
+)
+
+// ValidationError represents validation errors with specific field information
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap allows errors.Is to work with ValidationError
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// NewValidationError creates a new ValidationError
+func NewValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
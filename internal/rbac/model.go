@@ -0,0 +1,39 @@
+package rbac
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permission ↔ app.permissions
+type Permission struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// Role ↔ app.roles, with its grants from app.role_permissions
+type Role struct {
+	ID          uuid.UUID `json:"id"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	IsSystem    bool      `json:"isSystem"` // superadmin, orgadmin; cannot be changed
+	Permissions []string  `json:"permissions"`
+	UserCount   int       `json:"userCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// CreateRoleInput is the body of POST /roles.
+type CreateRoleInput struct {
+	Code        string   `json:"code"` // e.g. "estimator", "front_desk"
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleInput is the body of PUT /roles/{code}. Omitted fields are left
+// unchanged; permissions, when present, replace the role's grants.
+type UpdateRoleInput struct {
+	Name        *string   `json:"name,omitempty"`
+	Permissions *[]string `json:"permissions,omitempty"`
+}
@@ -0,0 +1,194 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository defines the persistence contract for roles and permissions.
+type Repository interface {
+	ListPermissions(ctx context.Context) ([]*Permission, error)
+	ListRoles(ctx context.Context) ([]*Role, error)
+	GetRole(ctx context.Context, code string) (*Role, error)
+	CreateRole(ctx context.Context, in *CreateRoleInput) (*Role, error)
+	// UpdateRole leaves the grants alone when permissions is nil.
+	UpdateRole(ctx context.Context, code string, name *string, permissions []string) (*Role, error)
+	DeleteRole(ctx context.Context, code string) error
+}
+
+type pgRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository constructs a Postgres-backed role repository.
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgRepo{db: db}
+}
+
+/* ---------- error mapping ---------- */
+
+func mapPgError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.UniqueViolation:
+			return ErrConflict
+		case pgerrcode.ForeignKeyViolation:
+			// users.role_id is ON DELETE RESTRICT
+			if pgErr.TableName == "users" {
+				return ErrRoleInUse
+			}
+			return ErrInvalidInput
+		case pgerrcode.CheckViolation, pgerrcode.NotNullViolation:
+			return ErrInvalidInput
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+/* ---------- queries ---------- */
+
+const roleSelect = `
+SELECT r.id, r.code, r.name, r.is_system,
+       ARRAY(SELECT rp.permission_code FROM app.role_permissions rp
+             WHERE rp.role_id = r.id ORDER BY rp.permission_code),
+       (SELECT count(*) FROM app.users u WHERE u.role_id = r.id),
+       r.created_at, r.updated_at
+FROM app.roles r
+`
+
+func scanRole(row pgx.Row) (*Role, error) {
+	var r Role
+	if err := row.Scan(&r.ID, &r.Code, &r.Name, &r.IsSystem, &r.Permissions, &r.UserCount,
+		&r.CreatedAt, &r.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *pgRepo) ListPermissions(ctx context.Context) ([]*Permission, error) {
+	rows, err := r.db.Query(ctx, `SELECT code, description FROM app.permissions ORDER BY code`)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	defer rows.Close()
+
+	list := make([]*Permission, 0)
+	for rows.Next() {
+		var p Permission
+		if err := rows.Scan(&p.Code, &p.Description); err != nil {
+			return nil, err
+		}
+		list = append(list, &p)
+	}
+	return list, rows.Err()
+}
+
+func (r *pgRepo) ListRoles(ctx context.Context) ([]*Role, error) {
+	rows, err := r.db.Query(ctx, roleSelect+` ORDER BY r.is_system DESC, r.code`)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	defer rows.Close()
+
+	list := make([]*Role, 0)
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, role)
+	}
+	return list, rows.Err()
+}
+
+func (r *pgRepo) GetRole(ctx context.Context, code string) (*Role, error) {
+	role, err := scanRole(r.db.QueryRow(ctx, roleSelect+` WHERE r.code = $1`, code))
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	return role, nil
+}
+
+func (r *pgRepo) CreateRole(ctx context.Context, in *CreateRoleInput) (*Role, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO app.roles (code, name) VALUES ($1, $2) RETURNING id`, in.Code, in.Name).Scan(&id); err != nil {
+		return nil, mapPgError(err)
+	}
+	if err := grant(ctx, tx, id, in.Permissions); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetRole(ctx, in.Code)
+}
+
+func (r *pgRepo) UpdateRole(ctx context.Context, code string, name *string, permissions []string) (*Role, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Updates the row even when only grants change, so updated_at reflects it
+	var id uuid.UUID
+	if err := tx.QueryRow(ctx, `
+UPDATE app.roles SET name = COALESCE($2, name)
+WHERE code = $1
+RETURNING id`, code, name).Scan(&id); err != nil {
+		return nil, mapPgError(err)
+	}
+	if permissions != nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM app.role_permissions WHERE role_id = $1`, id); err != nil {
+			return nil, mapPgError(err)
+		}
+		if err := grant(ctx, tx, id, permissions); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetRole(ctx, code)
+}
+
+func grant(ctx context.Context, tx pgx.Tx, roleID uuid.UUID, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+INSERT INTO app.role_permissions (role_id, permission_code)
+SELECT $1, unnest($2::text[])`, roleID, permissions)
+	return mapPgError(err)
+}
+
+func (r *pgRepo) DeleteRole(ctx context.Context, code string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM app.roles WHERE code = $1`, code)
+	if err != nil {
+		return mapPgError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
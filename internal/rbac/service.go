@@ -0,0 +1,214 @@
+// Package rbac manages roles and the permissions granted to them. Permission
+// checks themselves happen on auth.AuthUser (Can), which the auth middleware
+// fills from the caller's role.
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+)
+
+// Service defines business operations for roles.
+type Service interface {
+	ListPermissions(ctx context.Context, actor *auth.AuthUser) ([]*Permission, error)
+	ListRoles(ctx context.Context, actor *auth.AuthUser) ([]*Role, error)
+	GetRole(ctx context.Context, actor *auth.AuthUser, code string) (*Role, error)
+	CreateRole(ctx context.Context, actor *auth.AuthUser, in *CreateRoleInput) (*Role, error)
+	UpdateRole(ctx context.Context, actor *auth.AuthUser, code string, in *UpdateRoleInput) (*Role, error)
+	DeleteRole(ctx context.Context, actor *auth.AuthUser, code string) error
+}
+
+// UserCache is told when grants change, since cached users carry their
+// role's permissions (see user.CachedRepository).
+type UserCache interface {
+	PurgeAll(ctx context.Context)
+}
+
+var roleCodeRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+
+const maxNameLength = 100
+
+type service struct {
+	repo  Repository
+	users UserCache
+	audit audit.Recorder
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates a role service. users may be nil when user lookups
+// aren't cached; rec may be nil to record nothing.
+func NewService(repo Repository, users UserCache, rec audit.Recorder) Service {
+	return &service{repo: repo, users: users, audit: audit.OrNop(rec)}
+}
+
+func (s *service) ListPermissions(ctx context.Context, actor *auth.AuthUser) ([]*Permission, error) {
+	if !actor.Can(auth.PermRolesManage) {
+		return nil, ErrForbidden
+	}
+	return s.repo.ListPermissions(ctx)
+}
+
+// ListRoles is also open to user managers, who pick roles when creating users.
+func (s *service) ListRoles(ctx context.Context, actor *auth.AuthUser) ([]*Role, error) {
+	if !actor.CanAny(auth.PermRolesManage, auth.PermUsersManage) {
+		return nil, ErrForbidden
+	}
+	return s.repo.ListRoles(ctx)
+}
+
+func (s *service) GetRole(ctx context.Context, actor *auth.AuthUser, code string) (*Role, error) {
+	if !actor.CanAny(auth.PermRolesManage, auth.PermUsersManage) {
+		return nil, ErrForbidden
+	}
+	return s.repo.GetRole(ctx, strings.ToLower(strings.TrimSpace(code)))
+}
+
+func (s *service) CreateRole(ctx context.Context, actor *auth.AuthUser, in *CreateRoleInput) (*Role, error) {
+	if !actor.Can(auth.PermRolesManage) {
+		return nil, ErrForbidden
+	}
+	if in == nil {
+		return nil, ErrInvalidInput
+	}
+
+	in.Code = strings.ToLower(strings.TrimSpace(in.Code))
+	if !roleCodeRegex.MatchString(in.Code) {
+		return nil, NewValidationError("code", "must be 2-32 lowercase letters, digits or underscores, starting with a letter")
+	}
+	name, err := validateName(in.Name)
+	if err != nil {
+		return nil, err
+	}
+	in.Name = name
+	if in.Permissions, err = s.validatePermissions(ctx, actor, in.Permissions); err != nil {
+		return nil, err
+	}
+
+	role, err := s.repo.CreateRole(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "role created", "role", role.Code, "permissions", role.Permissions)
+	s.audit.Record(ctx, audit.Entry{Action: "role.create", EntityType: audit.EntityRole, EntityID: role.ID.String(), After: role})
+	return role, nil
+}
+
+func (s *service) UpdateRole(ctx context.Context, actor *auth.AuthUser, code string, in *UpdateRoleInput) (*Role, error) {
+	if !actor.Can(auth.PermRolesManage) {
+		return nil, ErrForbidden
+	}
+	if in == nil {
+		return nil, ErrInvalidInput
+	}
+	current, err := s.repo.GetRole(ctx, strings.ToLower(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	if current.IsSystem {
+		return nil, ErrSystemRole
+	}
+
+	var name *string
+	if in.Name != nil {
+		n, err := validateName(*in.Name)
+		if err != nil {
+			return nil, err
+		}
+		name = &n
+	}
+	var permissions []string
+	if in.Permissions != nil {
+		if permissions, err = s.validatePermissions(ctx, actor, *in.Permissions); err != nil {
+			return nil, err
+		}
+		// Nil means "unchanged" to the repository
+		if permissions == nil {
+			permissions = []string{}
+		}
+	}
+
+	role, err := s.repo.UpdateRole(ctx, current.Code, name, permissions)
+	if err != nil {
+		return nil, err
+	}
+	if permissions != nil {
+		s.purgeUsers(ctx)
+		slog.InfoContext(ctx, "role permissions changed", "role", role.Code, "from", current.Permissions, "to", role.Permissions)
+	}
+	s.audit.Record(ctx, audit.Entry{Action: "role.update", EntityType: audit.EntityRole, EntityID: role.ID.String(), Before: current, After: role})
+	return role, nil
+}
+
+func (s *service) DeleteRole(ctx context.Context, actor *auth.AuthUser, code string) error {
+	if !actor.Can(auth.PermRolesManage) {
+		return ErrForbidden
+	}
+	current, err := s.repo.GetRole(ctx, strings.ToLower(strings.TrimSpace(code)))
+	if err != nil {
+		return err
+	}
+	if current.IsSystem {
+		return ErrSystemRole
+	}
+	if current.UserCount > 0 {
+		return ErrRoleInUse
+	}
+	if err := s.repo.DeleteRole(ctx, current.Code); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "role deleted", "role", current.Code)
+	s.audit.Record(ctx, audit.Entry{Action: "role.delete", EntityType: audit.EntityRole, EntityID: current.ID.String(), Before: current})
+	return nil
+}
+
+/* ---------- helpers ---------- */
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", NewValidationError("name", "is required")
+	}
+	if len(name) > maxNameLength {
+		return "", NewValidationError("name", fmt.Sprintf("must be at most %d characters", maxNameLength))
+	}
+	return name, nil
+}
+
+// validatePermissions checks that every permission exists and that the actor
+// holds it, so roles.manage can't be used to grant more than one has.
+// Duplicates are dropped.
+func (s *service) validatePermissions(ctx context.Context, actor *auth.AuthUser, requested []string) ([]string, error) {
+	known, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, p := range requested {
+		p = strings.TrimSpace(p)
+		if !slices.ContainsFunc(known, func(k *Permission) bool { return k.Code == p }) {
+			return nil, NewValidationError("permissions", fmt.Sprintf("unknown permission %q", p))
+		}
+		if !actor.Can(p) {
+			return nil, NewValidationError("permissions", fmt.Sprintf("cannot grant %q, which you do not hold", p))
+		}
+		if !slices.Contains(out, p) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (s *service) purgeUsers(ctx context.Context) {
+	if s.users != nil {
+		s.users.PurgeAll(ctx)
+	}
+}
@@ -0,0 +1,239 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* ---------- fakes ---------- */
+
+type fakeRepo struct {
+	perms []*Permission
+	roles map[string]*Role
+}
+
+func newFakeRepo() *fakeRepo {
+	r := &fakeRepo{roles: make(map[string]*Role)}
+	for _, code := range []string{auth.PermShopsManage, auth.PermUsersManage, auth.PermEstimatesWrite, auth.PermRolesManage, auth.PermAPIKeysManage} {
+		r.perms = append(r.perms, &Permission{Code: code})
+	}
+	r.roles[auth.RoleSuperAdmin] = &Role{ID: uuid.New(), Code: auth.RoleSuperAdmin, IsSystem: true, UserCount: 1}
+	r.roles[auth.RoleOrgAdmin] = &Role{ID: uuid.New(), Code: auth.RoleOrgAdmin, IsSystem: true,
+		Permissions: []string{auth.PermShopsManage, auth.PermUsersManage, auth.PermRolesManage}}
+	r.roles[auth.RoleBodyman] = &Role{ID: uuid.New(), Code: auth.RoleBodyman, Permissions: []string{auth.PermEstimatesWrite}, UserCount: 3}
+	return r
+}
+
+func (r *fakeRepo) ListPermissions(context.Context) ([]*Permission, error) { return r.perms, nil }
+
+func (r *fakeRepo) ListRoles(context.Context) ([]*Role, error) {
+	var out []*Role
+	for _, role := range r.roles {
+		out = append(out, role)
+	}
+	return out, nil
+}
+
+func (r *fakeRepo) GetRole(_ context.Context, code string) (*Role, error) {
+	role, ok := r.roles[code]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *role
+	return &cp, nil
+}
+
+func (r *fakeRepo) CreateRole(_ context.Context, in *CreateRoleInput) (*Role, error) {
+	if _, ok := r.roles[in.Code]; ok {
+		return nil, ErrConflict
+	}
+	role := &Role{ID: uuid.New(), Code: in.Code, Name: in.Name, Permissions: in.Permissions}
+	r.roles[in.Code] = role
+	return role, nil
+}
+
+func (r *fakeRepo) UpdateRole(_ context.Context, code string, name *string, permissions []string) (*Role, error) {
+	role, ok := r.roles[code]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if name != nil {
+		role.Name = *name
+	}
+	if permissions != nil {
+		role.Permissions = permissions
+	}
+	return role, nil
+}
+
+func (r *fakeRepo) DeleteRole(_ context.Context, code string) error {
+	delete(r.roles, code)
+	return nil
+}
+
+type countingCache struct{ purges int }
+
+// auditLog collects audit entries in memory.
+type auditLog struct{ entries []audit.Entry }
+
+func (l *auditLog) Record(_ context.Context, e audit.Entry) { l.entries = append(l.entries, e) }
+
+func (c *countingCache) PurgeAll(context.Context) { c.purges++ }
+
+/* ---------- fixtures ---------- */
+
+func superAdmin() *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleSuperAdmin}
+}
+
+func roleManager(perms ...string) *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: "owner", Permissions: append([]string{auth.PermRolesManage}, perms...)}
+}
+
+/* ---------- tests ---------- */
+
+func TestCreateRole(t *testing.T) {
+	svc := NewService(newFakeRepo(), nil, nil)
+
+	role, err := svc.CreateRole(context.Background(), superAdmin(), &CreateRoleInput{
+		Code: " Estimator ", Name: " Estimator ", Permissions: []string{auth.PermEstimatesWrite, auth.PermEstimatesWrite},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "estimator", role.Code)
+	assert.Equal(t, "Estimator", role.Name)
+	assert.Equal(t, []string{auth.PermEstimatesWrite}, role.Permissions, "duplicates dropped")
+}
+
+func TestCreateRoleValidation(t *testing.T) {
+	svc := NewService(newFakeRepo(), nil, nil)
+	ctx := context.Background()
+
+	cases := map[string]*CreateRoleInput{
+		"bad code":           {Code: "1x", Name: "X"},
+		"missing name":       {Code: "estimator"},
+		"unknown permission": {Code: "estimator", Name: "X", Permissions: []string{"fleet.fly"}},
+	}
+	for name, in := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.CreateRole(ctx, superAdmin(), in)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
+
+// Test: roles.manage only lets an actor grant permissions it already holds
+func TestCreateRoleCannotEscalate(t *testing.T) {
+	svc := NewService(newFakeRepo(), nil, nil)
+	ctx := context.Background()
+	actor := roleManager(auth.PermEstimatesWrite)
+
+	_, err := svc.CreateRole(ctx, actor, &CreateRoleInput{Code: "estimator", Name: "Estimator", Permissions: []string{auth.PermEstimatesWrite}})
+	require.NoError(t, err)
+
+	_, err = svc.CreateRole(ctx, actor, &CreateRoleInput{Code: "keymaster", Name: "Keys", Permissions: []string{auth.PermAPIKeysManage}})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestRolePermissionChecks(t *testing.T) {
+	svc := NewService(newFakeRepo(), nil, nil)
+	ctx := context.Background()
+	userManager := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleAdmin, Permissions: []string{auth.PermUsersManage}}
+	bodyman := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleBodyman, Permissions: []string{auth.PermEstimatesWrite}}
+
+	_, err := svc.ListRoles(ctx, userManager)
+	assert.NoError(t, err, "user managers can read roles")
+	_, err = svc.ListPermissions(ctx, userManager)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.CreateRole(ctx, userManager, &CreateRoleInput{Code: "x1", Name: "X"})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = svc.ListRoles(ctx, bodyman)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+// Test: replacing a role's grants purges cached users; renaming doesn't
+func TestUpdateRolePurgesUserCache(t *testing.T) {
+	cache := &countingCache{}
+	svc := NewService(newFakeRepo(), cache, nil)
+	ctx := context.Background()
+
+	name := "Body Technician"
+	role, err := svc.UpdateRole(ctx, superAdmin(), auth.RoleBodyman, &UpdateRoleInput{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, name, role.Name)
+	assert.Equal(t, []string{auth.PermEstimatesWrite}, role.Permissions)
+	assert.Equal(t, 0, cache.purges)
+
+	none := []string{}
+	role, err = svc.UpdateRole(ctx, superAdmin(), auth.RoleBodyman, &UpdateRoleInput{Permissions: &none})
+	require.NoError(t, err)
+	assert.Empty(t, role.Permissions)
+	assert.Equal(t, 1, cache.purges)
+}
+
+func TestSystemRoleIsImmutable(t *testing.T) {
+	svc := NewService(newFakeRepo(), nil, nil)
+	ctx := context.Background()
+
+	name := "Root"
+	_, err := svc.UpdateRole(ctx, superAdmin(), auth.RoleSuperAdmin, &UpdateRoleInput{Name: &name})
+	assert.ErrorIs(t, err, ErrSystemRole)
+	assert.ErrorIs(t, svc.DeleteRole(ctx, superAdmin(), auth.RoleSuperAdmin), ErrSystemRole)
+}
+
+// Test: orgadmin is built in too, so removing its permissions or deleting it
+// fails, even for a roles.manage holder ranked below it
+func TestOrgAdminRoleIsImmutable(t *testing.T) {
+	svc := NewService(newFakeRepo(), nil, nil)
+	ctx := context.Background()
+	roleManager := &auth.AuthUser{ID: uuid.New(), RoleCode: "rolemanager", Permissions: []string{auth.PermRolesManage}}
+
+	for _, actor := range []*auth.AuthUser{superAdmin(), roleManager} {
+		none := []string{}
+		_, err := svc.UpdateRole(ctx, actor, auth.RoleOrgAdmin, &UpdateRoleInput{Permissions: &none})
+		assert.ErrorIs(t, err, ErrSystemRole, actor.RoleCode)
+		assert.ErrorIs(t, svc.DeleteRole(ctx, actor, auth.RoleOrgAdmin), ErrSystemRole, actor.RoleCode)
+	}
+}
+
+func TestDeleteRole(t *testing.T) {
+	repo := newFakeRepo()
+	svc := NewService(repo, nil, nil)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, svc.DeleteRole(ctx, superAdmin(), auth.RoleBodyman), ErrRoleInUse)
+
+	_, err := svc.CreateRole(ctx, superAdmin(), &CreateRoleInput{Code: "temp", Name: "Temp"})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteRole(ctx, superAdmin(), "temp"))
+	assert.NotContains(t, repo.roles, "temp")
+}
+
+// Test: role changes are audited; refused ones are not
+func TestRoleChangesAreAudited(t *testing.T) {
+	log := &auditLog{}
+	svc := NewService(newFakeRepo(), nil, log)
+	ctx := context.Background()
+
+	role, err := svc.CreateRole(ctx, superAdmin(), &CreateRoleInput{Code: "temp", Name: "Temp"})
+	require.NoError(t, err)
+	perms := []string{auth.PermEstimatesWrite}
+	_, err = svc.UpdateRole(ctx, superAdmin(), "temp", &UpdateRoleInput{Permissions: &perms})
+	require.NoError(t, err)
+	assert.ErrorIs(t, svc.DeleteRole(ctx, superAdmin(), auth.RoleBodyman), ErrRoleInUse)
+	require.NoError(t, svc.DeleteRole(ctx, superAdmin(), "temp"))
+
+	require.Len(t, log.entries, 3)
+	for i, action := range []string{"role.create", "role.update", "role.delete"} {
+		assert.Equal(t, action, log.entries[i].Action)
+		assert.Equal(t, audit.EntityRole, log.entries[i].EntityType)
+		assert.Equal(t, role.ID.String(), log.entries[i].EntityID)
+	}
+	assert.Nil(t, log.entries[2].After)
+}
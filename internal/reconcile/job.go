@@ -0,0 +1,72 @@
+package reconcile
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// jobLockKey is the advisory lock that keeps scheduled runs on one instance.
+const jobLockKey = 0x686e7a7263 // "hnzrc"
+
+// JobConfig controls the scheduled run inside the API server.
+type JobConfig struct {
+	Interval time.Duration // 0 disables the job
+	Repair   bool
+}
+
+// Schedule runs r every cfg.Interval until ctx is cancelled and logs each
+// report. When db is set, a Postgres advisory lock ensures only one instance
+// runs at a time; the others skip that tick.
+func Schedule(ctx context.Context, r *Reconciler, db *pgxpool.Pool, cfg JobConfig) {
+	if cfg.Interval <= 0 {
+		return
+	}
+	log.Printf("reconcile: scheduled every %s (repair=%v)", cfg.Interval, cfg.Repair)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runOnce(ctx, r, db, cfg.Repair)
+		}
+	}
+}
+
+func runOnce(ctx context.Context, r *Reconciler, db *pgxpool.Pool, repair bool) {
+	if db != nil {
+		conn, err := db.Acquire(ctx)
+		if err != nil {
+			log.Printf("WARNING: reconcile: acquire connection: %v", err)
+			return
+		}
+		defer conn.Release()
+
+		var locked bool
+		if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, jobLockKey).Scan(&locked); err != nil {
+			log.Printf("WARNING: reconcile: advisory lock: %v", err)
+			return
+		}
+		if !locked {
+			return // another instance is running it
+		}
+		defer conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, jobLockKey)
+	}
+
+	report, err := r.Run(ctx, repair)
+	if err != nil {
+		log.Printf("ERROR: reconcile: %v", err)
+		return
+	}
+	log.Print(report.Summary())
+	for _, d := range report.Drifts {
+		if !d.Repaired {
+			log.Printf("reconcile: %s %s <%s>: %s", d.Kind, d.ExternalID, d.Email, d.Detail)
+		}
+	}
+}
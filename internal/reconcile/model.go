@@ -0,0 +1,91 @@
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind classifies a difference between the identity provider and app.users.
+type Kind string
+
+const (
+	// KindOrphanIdentity: an account with no app.users row, typically left by a
+	// CreateUser whose DB insert failed and whose rollback also failed.
+	// Repair disables the account.
+	KindOrphanIdentity Kind = "orphan_identity"
+	// KindMissingIdentity: an app.users row whose external_id has no account,
+	// so the user cannot sign in. Repair deactivates the row if it is active.
+	KindMissingIdentity Kind = "missing_identity"
+	// KindActiveMismatch: is_active disagrees with the account's disabled flag.
+	// The DB is the source of truth; repair enables or disables the account.
+	KindActiveMismatch Kind = "active_mismatch"
+	// KindEmailVerifiedMismatch: email_verified disagrees between the two sides.
+	// Verification only ever goes one way, so repair marks both verified.
+	KindEmailVerifiedMismatch Kind = "email_verified_mismatch"
+	// KindEmailMismatch: the two sides hold different addresses. Report only.
+	KindEmailMismatch Kind = "email_mismatch"
+)
+
+// Drift is one difference found by a run.
+type Drift struct {
+	Kind       Kind       `json:"kind"`
+	UserID     *uuid.UUID `json:"userId,omitempty"` // nil for orphan identities
+	ExternalID string     `json:"externalId"`
+	Email      string     `json:"email"`
+	Detail     string     `json:"detail"`
+	// Repair is what a repairing run does (or did) about it; empty if nothing.
+	Repair      string `json:"repair,omitempty"`
+	Repaired    bool   `json:"repaired"`
+	RepairError string `json:"repairError,omitempty"`
+}
+
+// Report is the outcome of one run.
+type Report struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Repair     bool      `json:"repair"`
+	DBUsers    int       `json:"dbUsers"`
+	Identities int       `json:"identities"`
+	Drifts     []Drift   `json:"drifts"`
+}
+
+// Counts returns the number of drifts per kind.
+func (r *Report) Counts() map[Kind]int {
+	out := make(map[Kind]int)
+	for _, d := range r.Drifts {
+		out[d.Kind]++
+	}
+	return out
+}
+
+// Unresolved returns the number of drifts a repair didn't fix (all of them for
+// a report-only run).
+func (r *Report) Unresolved() int {
+	n := 0
+	for _, d := range r.Drifts {
+		if !d.Repaired {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary is a one-line description for logs.
+func (r *Report) Summary() string {
+	counts := r.Counts()
+	kinds := make([]string, 0, len(counts))
+	for k, n := range counts {
+		kinds = append(kinds, fmt.Sprintf("%s=%d", k, n))
+	}
+	sort.Strings(kinds)
+	detail := "no drift"
+	if len(kinds) > 0 {
+		detail = strings.Join(kinds, " ")
+	}
+	return fmt.Sprintf("reconcile: %d users, %d identities, %s, %d unresolved (repair=%v, %s)",
+		r.DBUsers, r.Identities, detail, r.Unresolved(), r.Repair, r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond))
+}
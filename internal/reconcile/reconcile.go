@@ -0,0 +1,196 @@
+// Package reconcile compares the identity provider's accounts with app.users,
+// reports where they have drifted apart and optionally repairs them.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/user"
+	"github.com/google/uuid"
+)
+
+// UserStore is the part of user.Repository the reconciler needs.
+type UserStore interface {
+	List(ctx context.Context, limit, offset int) ([]*user.User, error)
+	Deactivate(ctx context.Context, id uuid.UUID, byUserID *uuid.UUID) error
+	MarkEmailVerified(ctx context.Context, id uuid.UUID) error
+}
+
+// pageSize is how many app.users rows are read per query.
+const pageSize = 500
+
+// DefaultGracePeriod skips accounts created this recently when looking for
+// orphans, so a CreateUser that is between the provider call and the DB insert
+// isn't mistaken for one.
+const DefaultGracePeriod = 10 * time.Minute
+
+// Reconciler compares a UserStore with an IdentityProvider.
+type Reconciler struct {
+	users       UserStore
+	idp         user.IdentityProvider
+	gracePeriod time.Duration
+	now         func() time.Time
+}
+
+// New creates a Reconciler with the default grace period.
+func New(users UserStore, idp user.IdentityProvider) *Reconciler {
+	return &Reconciler{users: users, idp: idp, gracePeriod: DefaultGracePeriod, now: time.Now}
+}
+
+// Run lists both sides and reports every drift. With repair set it also fixes
+// what it safely can; repair failures are recorded on the drift rather than
+// stopping the run. Listing failures abort the run, since a partial list
+// would make every missing entry look like drift.
+func (r *Reconciler) Run(ctx context.Context, repair bool) (*Report, error) {
+	report := &Report{StartedAt: r.now(), Repair: repair, Drifts: []Drift{}}
+
+	dbUsers, err := r.listUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	identities := make(map[string]user.Identity)
+	if err := r.idp.ListUsers(ctx, func(id user.Identity) error {
+		identities[id.UID] = id
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("list identities: %w", err)
+	}
+	report.DBUsers, report.Identities = len(dbUsers), len(identities)
+
+	seen := make(map[string]bool, len(dbUsers))
+	for _, u := range dbUsers {
+		seen[u.ExternalID] = true
+		id, ok := identities[u.ExternalID]
+		if !ok {
+			report.add(r.missingIdentity(ctx, u, repair))
+			continue
+		}
+		for _, d := range r.compare(ctx, u, id, repair) {
+			report.add(d)
+		}
+	}
+
+	cutoff := r.now().Add(-r.gracePeriod)
+	for _, uid := range slices.Sorted(maps.Keys(identities)) {
+		id := identities[uid]
+		if seen[uid] || id.CreatedAt.After(cutoff) {
+			continue
+		}
+		report.add(r.orphanIdentity(ctx, id, repair))
+	}
+
+	report.FinishedAt = r.now()
+	return report, nil
+}
+
+func (r *Reconciler) listUsers(ctx context.Context) ([]*user.User, error) {
+	var all []*user.User
+	for offset := 0; ; offset += pageSize {
+		page, err := r.users.List(ctx, pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+func (r *Reconciler) missingIdentity(ctx context.Context, u *user.User, repair bool) Drift {
+	d := userDrift(KindMissingIdentity, u, "no identity provider account for this user")
+	if !u.IsActive {
+		d.Detail += " (already inactive)"
+		return d
+	}
+	d.Repair = "deactivate user"
+	if repair {
+		d.apply(r.users.Deactivate(ctx, u.ID, nil))
+	}
+	return d
+}
+
+func (r *Reconciler) orphanIdentity(ctx context.Context, id user.Identity, repair bool) Drift {
+	d := Drift{
+		Kind:       KindOrphanIdentity,
+		ExternalID: id.UID,
+		Email:      id.Email,
+		Detail:     "identity provider account with no app.users row",
+	}
+	if id.Disabled {
+		d.Detail += " (already disabled)"
+		return d
+	}
+	d.Repair = "disable account"
+	if repair {
+		d.apply(r.idp.DisableUser(ctx, id.UID))
+	}
+	return d
+}
+
+func (r *Reconciler) compare(ctx context.Context, u *user.User, id user.Identity, repair bool) []Drift {
+	var drifts []Drift
+
+	if u.IsActive == id.Disabled {
+		d := userDrift(KindActiveMismatch, u, fmt.Sprintf("is_active=%v but account disabled=%v", u.IsActive, id.Disabled))
+		if u.IsActive {
+			d.Repair = "enable account"
+		} else {
+			d.Repair = "disable account"
+		}
+		if repair {
+			if u.IsActive {
+				d.apply(r.idp.EnableUser(ctx, id.UID))
+			} else {
+				d.apply(r.idp.DisableUser(ctx, id.UID))
+			}
+		}
+		drifts = append(drifts, d)
+	}
+
+	if u.EmailVerified != id.EmailVerified {
+		d := userDrift(KindEmailVerifiedMismatch, u, fmt.Sprintf("email_verified=%v but account emailVerified=%v", u.EmailVerified, id.EmailVerified))
+		if u.EmailVerified {
+			d.Repair = "mark account verified"
+		} else {
+			d.Repair = "mark user verified"
+		}
+		if repair {
+			if u.EmailVerified {
+				d.apply(r.idp.SetEmailVerified(ctx, id.UID, true))
+			} else {
+				d.apply(r.users.MarkEmailVerified(ctx, u.ID))
+			}
+		}
+		drifts = append(drifts, d)
+	}
+
+	if !strings.EqualFold(u.Email, id.Email) {
+		drifts = append(drifts, userDrift(KindEmailMismatch, u, fmt.Sprintf("account email is %q", id.Email)))
+	}
+	return drifts
+}
+
+func userDrift(kind Kind, u *user.User, detail string) Drift {
+	id := u.ID
+	return Drift{Kind: kind, UserID: &id, ExternalID: u.ExternalID, Email: u.Email, Detail: detail}
+}
+
+func (d *Drift) apply(err error) {
+	if err != nil {
+		d.RepairError = err.Error()
+		log.Printf("WARNING: reconcile: %s for %s failed: %v", d.Repair, d.ExternalID, err)
+		return
+	}
+	d.Repaired = true
+}
+
+func (r *Report) add(d Drift) {
+	r.Drifts = append(r.Drifts, d)
+}
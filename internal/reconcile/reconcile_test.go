@@ -0,0 +1,236 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/user"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore is an in-memory UserStore; users are listed in insertion order.
+type fakeStore struct {
+	users       []*user.User
+	failList    error
+	failRepairs error
+}
+
+func (s *fakeStore) add(externalID, email string, active, verified bool) *user.User {
+	u := &user.User{ID: uuid.New(), ExternalID: externalID, Email: email, IsActive: active, EmailVerified: verified}
+	s.users = append(s.users, u)
+	return u
+}
+
+func (s *fakeStore) List(_ context.Context, limit, offset int) ([]*user.User, error) {
+	if s.failList != nil {
+		return nil, s.failList
+	}
+	if offset >= len(s.users) {
+		return nil, nil
+	}
+	return s.users[offset:min(offset+limit, len(s.users))], nil
+}
+
+func (s *fakeStore) find(id uuid.UUID) *user.User {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) Deactivate(_ context.Context, id uuid.UUID, _ *uuid.UUID) error {
+	if s.failRepairs != nil {
+		return s.failRepairs
+	}
+	s.find(id).IsActive = false
+	return nil
+}
+
+func (s *fakeStore) MarkEmailVerified(_ context.Context, id uuid.UUID) error {
+	if s.failRepairs != nil {
+		return s.failRepairs
+	}
+	s.find(id).EmailVerified = true
+	return nil
+}
+
+var longAgo = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func account(uid, email string, disabled, verified bool) user.MemoryIdentity {
+	return user.MemoryIdentity{Identity: user.Identity{
+		UID: uid, Email: email, Disabled: disabled, EmailVerified: verified, CreatedAt: longAgo,
+	}}
+}
+
+func kinds(r *Report) []Kind {
+	out := make([]Kind, 0, len(r.Drifts))
+	for _, d := range r.Drifts {
+		out = append(out, d.Kind)
+	}
+	return out
+}
+
+func TestRun_InSync(t *testing.T) {
+	store := &fakeStore{}
+	idp := user.NewMemoryIdentityProvider()
+	store.add("u1", "a@example.com", true, true)
+	idp.Add(account("u1", "A@example.com", false, true))
+
+	report, err := New(store, idp).Run(context.Background(), false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Drifts)
+	assert.Equal(t, 1, report.DBUsers)
+	assert.Equal(t, 1, report.Identities)
+	assert.Contains(t, report.Summary(), "no drift")
+}
+
+func TestRun_ReportOnly(t *testing.T) {
+	store := &fakeStore{}
+	idp := user.NewMemoryIdentityProvider()
+	store.add("missing", "m@example.com", true, false)
+	store.add("inactive", "i@example.com", false, false)
+	idp.Add(account("inactive", "i@example.com", false, false))
+	store.add("verified", "v@example.com", true, true)
+	idp.Add(account("verified", "v@example.com", false, false))
+	store.add("renamed", "old@example.com", true, false)
+	idp.Add(account("renamed", "new@example.com", false, false))
+	idp.Add(account("orphan", "o@example.com", false, false))
+
+	report, err := New(store, idp).Run(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, []Kind{
+		KindMissingIdentity, KindActiveMismatch, KindEmailVerifiedMismatch, KindEmailMismatch, KindOrphanIdentity,
+	}, kinds(report))
+	assert.Equal(t, 5, report.Unresolved())
+
+	// Nothing was touched
+	assert.True(t, store.users[0].IsActive)
+	a, _ := idp.Get("orphan")
+	assert.False(t, a.Disabled)
+	a, _ = idp.Get("inactive")
+	assert.False(t, a.Disabled)
+}
+
+func TestRun_Repair(t *testing.T) {
+	store := &fakeStore{}
+	idp := user.NewMemoryIdentityProvider()
+	missing := store.add("missing", "m@example.com", true, false)
+	store.add("inactive", "i@example.com", false, false)
+	idp.Add(account("inactive", "i@example.com", false, false))
+	store.add("disabled", "d@example.com", true, false)
+	idp.Add(account("disabled", "d@example.com", true, false))
+	store.add("db-verified", "v@example.com", true, true)
+	idp.Add(account("db-verified", "v@example.com", false, false))
+	idpVerified := store.add("idp-verified", "w@example.com", true, false)
+	idp.Add(account("idp-verified", "w@example.com", false, true))
+	store.add("renamed", "old@example.com", true, false)
+	idp.Add(account("renamed", "new@example.com", false, false))
+	idp.Add(account("orphan", "o@example.com", false, false))
+
+	report, err := New(store, idp).Run(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, report.Drifts, 7)
+	assert.Equal(t, 1, report.Unresolved(), "only the email mismatch is report-only")
+
+	assert.False(t, missing.IsActive)
+	assert.True(t, idpVerified.EmailVerified)
+	for uid, disabled := range map[string]bool{"inactive": true, "disabled": false, "orphan": true} {
+		a, _ := idp.Get(uid)
+		assert.Equal(t, disabled, a.Disabled, uid)
+	}
+	a, _ := idp.Get("db-verified")
+	assert.True(t, a.EmailVerified)
+
+	// A second run finds the neutralised missing/orphan pairs (which need a
+	// person to delete them) and the email mismatch, with nothing left to repair
+	report, err = New(store, idp).Run(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, []Kind{KindMissingIdentity, KindEmailMismatch, KindOrphanIdentity}, kinds(report))
+	for _, d := range report.Drifts {
+		assert.Empty(t, d.Repair, d.Kind)
+	}
+}
+
+func TestRun_AlreadyHandledDriftHasNoRepair(t *testing.T) {
+	store := &fakeStore{}
+	idp := user.NewMemoryIdentityProvider()
+	store.add("gone", "g@example.com", false, false)
+	idp.Add(account("orphan", "o@example.com", true, false))
+
+	report, err := New(store, idp).Run(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, report.Drifts, 2)
+	for _, d := range report.Drifts {
+		assert.Empty(t, d.Repair, d.Kind)
+		assert.False(t, d.Repaired, d.Kind)
+	}
+}
+
+func TestRun_GracePeriodSkipsNewAccounts(t *testing.T) {
+	store := &fakeStore{}
+	idp := user.NewMemoryIdentityProvider()
+	now := longAgo.Add(time.Hour)
+	fresh := account("fresh", "f@example.com", false, false)
+	fresh.CreatedAt = now.Add(-time.Minute)
+	idp.Add(fresh)
+	idp.Add(account("stale", "s@example.com", false, false))
+
+	r := New(store, idp)
+	r.now = func() time.Time { return now }
+	report, err := r.Run(context.Background(), false)
+	require.NoError(t, err)
+	require.Len(t, report.Drifts, 1)
+	assert.Equal(t, "stale", report.Drifts[0].ExternalID)
+	assert.Nil(t, report.Drifts[0].UserID)
+}
+
+func TestRun_RepairFailuresAreRecorded(t *testing.T) {
+	boom := errors.New("boom")
+	store := &fakeStore{failRepairs: boom}
+	idp := user.NewMemoryIdentityProvider()
+	idp.FailOn["DisableUser"] = boom
+	store.add("missing", "m@example.com", true, false)
+	idp.Add(account("orphan", "o@example.com", false, false))
+
+	report, err := New(store, idp).Run(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, report.Drifts, 2)
+	for _, d := range report.Drifts {
+		assert.False(t, d.Repaired)
+		assert.Equal(t, "boom", d.RepairError)
+	}
+	assert.Equal(t, 2, report.Unresolved())
+}
+
+func TestRun_ListFailureAborts(t *testing.T) {
+	boom := errors.New("boom")
+
+	_, err := New(&fakeStore{failList: boom}, user.NewMemoryIdentityProvider()).Run(context.Background(), true)
+	assert.ErrorIs(t, err, boom)
+
+	idp := user.NewMemoryIdentityProvider()
+	idp.FailOn["ListUsers"] = boom
+	_, err = New(&fakeStore{}, idp).Run(context.Background(), true)
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestRun_Paginates(t *testing.T) {
+	store := &fakeStore{}
+	idp := user.NewMemoryIdentityProvider()
+	for i := 0; i < pageSize+3; i++ {
+		uid := uuid.NewString()
+		store.add(uid, uid+"@example.com", true, false)
+		idp.Add(account(uid, uid+"@example.com", false, false))
+	}
+
+	report, err := New(store, idp).Run(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, pageSize+3, report.DBUsers)
+	assert.Empty(t, report.Drifts)
+}
@@ -0,0 +1,9 @@
+package report
+
+import "errors"
+
+// Domain-level errors for report rendering
+var (
+	ErrNotFound     = errors.New("document not found")
+	ErrInvalidInput = errors.New("invalid input")
+)
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// GET /workorders/{id}/report.pdf?photos=<id>,<id>
+// Without ?photos the first few photos are included; ?photos=none leaves them out.
+func (h *Handler) WorkOrderPDF(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, func(buf *bytes.Buffer, actor *auth.AuthUser, id uuid.UUID) (string, error) {
+		photoIDs, err := parsePhotoIDs(r.URL.Query().Get("photos"))
+		if err != nil {
+			return "", err
+		}
+		return h.svc.WorkOrderPDF(r.Context(), actor, id, photoIDs, buf)
+	})
+}
+
+// GET /estimates/{id}/report.pdf
+func (h *Handler) EstimatePDF(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, func(buf *bytes.Buffer, actor *auth.AuthUser, id uuid.UUID) (string, error) {
+		return h.svc.EstimatePDF(r.Context(), actor, id, buf)
+	})
+}
+
+// GET /invoices/{id}/report.pdf
+func (h *Handler) InvoicePDF(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, func(buf *bytes.Buffer, actor *auth.AuthUser, id uuid.UUID) (string, error) {
+		return h.svc.InvoicePDF(r.Context(), actor, id, buf)
+	})
+}
+
+// render buffers the whole document so a failure half-way still gets a JSON error.
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, fn func(*bytes.Buffer, *auth.AuthUser, uuid.UUID) (string, error)) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	var buf bytes.Buffer
+	filename, err := fn(&buf, actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+}
+
+// parsePhotoIDs returns nil when the parameter is absent and an empty slice for "none".
+func parsePhotoIDs(raw string) ([]uuid.UUID, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if raw == "none" {
+		return []uuid.UUID{}, nil
+	}
+	parts := strings.Split(raw, ",")
+	if len(parts) > MaxPhotos {
+		return nil, fmt.Errorf("%w: at most %d photos can be selected", ErrInvalidInput, MaxPhotos)
+	}
+	ids := make([]uuid.UUID, 0, len(parts))
+	for _, p := range parts {
+		id, err := uuid.Parse(strings.TrimSpace(p))
+		if err != nil {
+			return nil, fmt.Errorf("%w: photos must be a comma-separated list of image IDs", ErrInvalidInput)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// writeError classifies known domain errors and delegates to httpError.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
+
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		httpError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNotFound):
+		httpError(w, http.StatusNotFound, err.Error())
+	default:
+		httpError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
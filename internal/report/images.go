@@ -0,0 +1,52 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/tracing"
+)
+
+// maxImageBytes caps a single photo download; phone photos are well under this.
+const maxImageBytes = 15 << 20
+
+// ImageLoader fetches the bytes of a stored photo.
+type ImageLoader interface {
+	Load(ctx context.Context, ref ImageRef) ([]byte, error)
+}
+
+type httpImageLoader struct {
+	client *http.Client
+}
+
+// NewHTTPImageLoader loads photos from their public (or signed) URL.
+func NewHTTPImageLoader(timeout time.Duration) ImageLoader {
+	return &httpImageLoader{client: &http.Client{Timeout: timeout, Transport: tracing.Transport(nil)}}
+}
+
+func (l *httpImageLoader) Load(ctx context.Context, ref ImageRef) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ref.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch image %s: status %d", ref.ID, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImageBytes {
+		return nil, fmt.Errorf("fetch image %s: larger than %d bytes", ref.ID, maxImageBytes)
+	}
+	return data, nil
+}
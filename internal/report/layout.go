@@ -0,0 +1,424 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // register decoders for photos and logos
+	"image/jpeg"
+	_ "image/png"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Fonts are the Go fonts compiled into the binary, so rendering never touches
+// the filesystem and full UTF-8 (accents in names and addresses) works.
+const fontFamily = "Go"
+
+const (
+	pageMargin   = 15.0
+	footerMargin = 18.0
+	lineHeight   = 5.0
+
+	// Photos are downscaled before embedding to keep documents small enough to email.
+	maxImagePx  = 1600
+	jpegQuality = 80
+)
+
+var (
+	colorText    = [3]int{33, 37, 41}
+	colorMuted   = [3]int{108, 117, 125}
+	colorRule    = [3]int{206, 212, 218}
+	colorSection = [3]int{233, 236, 239}
+)
+
+// newDocument creates a Letter-sized page with the shop letterhead and a page footer.
+func newDocument(title string, shop Branding, generatedAt time.Time) *fpdf.Fpdf {
+	pdf := fpdf.New("P", "mm", "Letter", "")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", gobold.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "I", goitalic.TTF)
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, footerMargin)
+	pdf.SetTitle(title, true)
+	pdf.SetCreator(shop.Name, true)
+	pdf.SetCreationDate(generatedAt)
+	pdf.AliasNbPages("")
+
+	var logo string
+	if shop.Logo != nil {
+		if name, ok := registerPhoto(pdf, "logo", shop.Logo.Data); ok {
+			logo = name
+		}
+	}
+
+	pdf.SetHeaderFunc(func() { letterhead(pdf, title, shop, logo) })
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-footerMargin + 4)
+		setFont(pdf, "I", 8, colorMuted)
+		half := contentWidth(pdf) / 2
+		pdf.CellFormat(half, 4, "Generated "+generatedAt.Format("Jan 2, 2006 15:04 MST"), "", 0, "L", false, 0, "")
+		pdf.CellFormat(half, 4, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+	return pdf
+}
+
+func letterhead(pdf *fpdf.Fpdf, title string, shop Branding, logo string) {
+	left, top, right, _ := pdf.GetMargins()
+	pageW, _ := pdf.GetPageSize()
+	textX := left
+
+	if logo != "" {
+		pdf.ImageOptions(logo, left, top, 0, 18, false, fpdf.ImageOptions{}, 0, "")
+		if info := pdf.GetImageInfo(logo); info != nil && info.Height() > 0 {
+			textX += 18*info.Width()/info.Height() + 4
+		}
+	}
+
+	pdf.SetXY(textX, top)
+	setFont(pdf, "B", 14, colorText)
+	pdf.CellFormat(0, 7, shop.Name, "", 2, "L", false, 0, "")
+
+	setFont(pdf, "", 9, colorMuted)
+	for _, l := range []string{
+		joinNonEmpty(", ", shop.Address, shop.City, joinNonEmpty(" ", shop.Province, shop.PostalCode)),
+		joinNonEmpty("  ·  ", shop.Phone, shop.Email),
+		shop.Hours,
+	} {
+		if l != "" {
+			pdf.CellFormat(0, 4.5, l, "", 2, "L", false, 0, "")
+		}
+	}
+
+	pdf.SetXY(left, top)
+	setFont(pdf, "B", 16, colorText)
+	pdf.CellFormat(pageW-left-right, 7, title, "", 0, "R", false, 0, "")
+
+	y := top + 21
+	setDraw(pdf, colorRule)
+	pdf.Line(left, y, pageW-right, y)
+	pdf.SetXY(left, y+4)
+}
+
+/* ---------- building blocks ---------- */
+
+func setFont(pdf *fpdf.Fpdf, style string, size float64, c [3]int) {
+	pdf.SetFont(fontFamily, style, size)
+	pdf.SetTextColor(c[0], c[1], c[2])
+}
+
+func setDraw(pdf *fpdf.Fpdf, c [3]int) {
+	pdf.SetDrawColor(c[0], c[1], c[2])
+}
+
+func contentWidth(pdf *fpdf.Fpdf) float64 {
+	left, _, right, _ := pdf.GetMargins()
+	w, _ := pdf.GetPageSize()
+	return w - left - right
+}
+
+// ensureSpace starts a new page if less than h mm remain above the footer.
+func ensureSpace(pdf *fpdf.Fpdf, h float64) {
+	_, pageH := pdf.GetPageSize()
+	if pdf.GetY()+h > pageH-footerMargin {
+		pdf.AddPage()
+	}
+}
+
+func section(pdf *fpdf.Fpdf, title string) {
+	ensureSpace(pdf, 20)
+	pdf.Ln(3)
+	setFont(pdf, "B", 10.5, colorText)
+	pdf.SetFillColor(colorSection[0], colorSection[1], colorSection[2])
+	pdf.CellFormat(0, 6.5, "  "+title, "", 1, "L", true, 0, "")
+	pdf.Ln(1.5)
+}
+
+func note(pdf *fpdf.Fpdf, text string) {
+	setFont(pdf, "I", 9, colorMuted)
+	pdf.MultiCell(0, lineHeight, text, "", "L", false)
+}
+
+// field is one label/value pair in a fields block.
+type field struct {
+	label string
+	value string
+}
+
+// fields prints label/value pairs in two columns, skipping empty values.
+func fields(pdf *fpdf.Fpdf, pairs ...field) {
+	const labelW = 30.0
+	colW := contentWidth(pdf) / 2
+	left, _, _, _ := pdf.GetMargins()
+
+	kept := pairs[:0:0]
+	for _, p := range pairs {
+		if strings.TrimSpace(p.value) != "" {
+			kept = append(kept, p)
+		}
+	}
+
+	for i := 0; i < len(kept); i += 2 {
+		ensureSpace(pdf, lineHeight)
+		y := pdf.GetY()
+		for col := 0; col < 2 && i+col < len(kept); col++ {
+			p := kept[i+col]
+			pdf.SetXY(left+float64(col)*colW, y)
+			setFont(pdf, "", 8.5, colorMuted)
+			pdf.CellFormat(labelW, lineHeight, p.label, "", 0, "L", false, 0, "")
+			setFont(pdf, "", 9.5, colorText)
+			pdf.CellFormat(colW-labelW-2, lineHeight, fit(pdf, p.value, colW-labelW-2), "", 0, "L", false, 0, "")
+		}
+		pdf.SetXY(left, y+lineHeight)
+	}
+}
+
+// column describes one column of a table; width is a fraction of the content width.
+type column struct {
+	title string
+	width float64
+	align string
+}
+
+// table prints a header row and data rows, repeating the header after page breaks.
+func table(pdf *fpdf.Fpdf, cols []column, rows [][]string) {
+	total := contentWidth(pdf)
+	header := func() {
+		setFont(pdf, "B", 8.5, colorMuted)
+		setDraw(pdf, colorRule)
+		for i, c := range cols {
+			ln := 0
+			if i == len(cols)-1 {
+				ln = 1
+			}
+			pdf.CellFormat(c.width*total, 6, c.title, "B", ln, c.align, false, 0, "")
+		}
+	}
+	header()
+
+	for _, row := range rows {
+		_, pageH := pdf.GetPageSize()
+		if pdf.GetY()+6 > pageH-footerMargin {
+			pdf.AddPage()
+			header()
+		}
+		setFont(pdf, "", 9, colorText)
+		for i, c := range cols {
+			ln := 0
+			if i == len(cols)-1 {
+				ln = 1
+			}
+			w := c.width * total
+			pdf.CellFormat(w, 6, fit(pdf, row[i], w-1), "B", ln, c.align, false, 0, "")
+		}
+	}
+}
+
+// totals prints right-aligned label/amount rows; bold rows are emphasised.
+func totals(pdf *fpdf.Fpdf, rows ...totalRow) {
+	total := contentWidth(pdf)
+	ensureSpace(pdf, float64(len(rows))*6+2)
+	pdf.Ln(2)
+	for _, r := range rows {
+		style := ""
+		if r.bold {
+			style = "B"
+		}
+		setFont(pdf, style, 9.5, colorText)
+		pdf.CellFormat(total-35, 6, r.label, "", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 6, r.amount, "", 1, "R", false, 0, "")
+	}
+}
+
+type totalRow struct {
+	label  string
+	amount string
+	bold   bool
+}
+
+// stamp draws a large translucent diagonal word across the current page (e.g. VOID).
+func stamp(pdf *fpdf.Fpdf, word string) {
+	w, h := pdf.GetPageSize()
+	x, y := pdf.GetXY()
+	pdf.SetAlpha(0.15, "Normal")
+	setFont(pdf, "B", 96, [3]int{220, 53, 69})
+	pdf.TransformBegin()
+	pdf.TransformRotate(35, w/2, h/2)
+	pdf.Text(w/2-pdf.GetStringWidth(word)/2, h/2, word)
+	pdf.TransformEnd()
+	pdf.SetAlpha(1, "Normal")
+	pdf.SetXY(x, y)
+}
+
+/* ---------- images ---------- */
+
+// registerPhoto normalises an image to JPEG and registers it under name.
+// Images that can't be decoded are skipped rather than failing the document.
+func registerPhoto(pdf *fpdf.Fpdf, name string, data []byte) (string, bool) {
+	normalised, err := normaliseImage(data)
+	if err != nil {
+		return "", false
+	}
+	pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "JPG"}, bytes.NewReader(normalised))
+	return name, pdf.Ok()
+}
+
+// normaliseImage decodes any supported image, flattens transparency onto white,
+// downsizes it and re-encodes it as baseline JPEG, which fpdf always accepts
+// (it rejects e.g. 16-bit or interlaced PNGs).
+func normaliseImage(data []byte) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil, fmt.Errorf("empty image")
+	}
+	if w > maxImagePx || h > maxImagePx {
+		if w >= h {
+			w, h = maxImagePx, h*maxImagePx/w
+		} else {
+			w, h = w*maxImagePx/h, maxImagePx
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, max(w, 1), max(h, 1)))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// photoGrid lays photos out two per row with captions.
+func photoGrid(pdf *fpdf.Fpdf, photos []Photo) {
+	const (
+		gap       = 6.0
+		maxH      = 75.0
+		captionH  = 5.0
+		perRow    = 2
+		maxPhotos = 12
+	)
+	left, _, _, _ := pdf.GetMargins()
+	cellW := (contentWidth(pdf) - gap) / perRow
+
+	type placed struct {
+		name    string
+		caption string
+		w, h    float64
+	}
+	items := make([]placed, 0, len(photos))
+	for i, p := range photos {
+		if len(items) == maxPhotos {
+			break
+		}
+		name, ok := registerPhoto(pdf, fmt.Sprintf("photo-%d", i), p.Data)
+		if !ok {
+			continue
+		}
+		info := pdf.GetImageInfo(name)
+		w, h := cellW, cellW*info.Height()/info.Width()
+		if h > maxH {
+			w, h = maxH*info.Width()/info.Height(), maxH
+		}
+		items = append(items, placed{name: name, caption: p.Caption, w: w, h: h})
+	}
+	if len(items) == 0 {
+		note(pdf, "No photos could be included.")
+		return
+	}
+
+	for i := 0; i < len(items); i += perRow {
+		rowH := 0.0
+		for j := i; j < i+perRow && j < len(items); j++ {
+			rowH = max(rowH, items[j].h)
+		}
+		ensureSpace(pdf, rowH+captionH+gap)
+		y := pdf.GetY()
+		for j := i; j < i+perRow && j < len(items); j++ {
+			it := items[j]
+			x := left + float64(j-i)*(cellW+gap)
+			pdf.ImageOptions(it.name, x+(cellW-it.w)/2, y, it.w, it.h, false, fpdf.ImageOptions{}, 0, "")
+			pdf.SetXY(x, y+rowH+1)
+			setFont(pdf, "", 8, colorMuted)
+			pdf.CellFormat(cellW, captionH-1, fit(pdf, it.caption, cellW), "", 0, "C", false, 0, "")
+		}
+		pdf.SetXY(left, y+rowH+captionH+gap)
+	}
+}
+
+/* ---------- text helpers ---------- */
+
+// fit truncates s with an ellipsis so it fits in w mm at the current font.
+func fit(pdf *fpdf.Fpdf, s string, w float64) string {
+	if pdf.GetStringWidth(s) <= w {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && pdf.GetStringWidth(string(r)+"…") > w {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+// formatCents renders an amount like $1,234.56 (or -$12.00).
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	whole := fmt.Sprintf("%d", cents/100)
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	return fmt.Sprintf("%s$%s.%02d", sign, whole, cents%100)
+}
+
+// formatQuantity prints 2 as "2" and 1.5 as "1.50".
+func formatQuantity(q float64) string {
+	if q == float64(int64(q)) {
+		return fmt.Sprintf("%d", int64(q))
+	}
+	return fmt.Sprintf("%.2f", q)
+}
+
+// humanize turns enum values like waiting_for_inspection into "Waiting for inspection".
+func humanize(s string) string {
+	s = strings.ReplaceAll(s, "_", " ")
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("Jan 2, 2006")
+}
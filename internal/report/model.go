@@ -0,0 +1,71 @@
+package report
+
+import (
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/estimate"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/invoice"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/google/uuid"
+)
+
+// Branding is the shop letterhead printed at the top of every document.
+type Branding struct {
+	Name       string
+	Address    string
+	City       string
+	Province   string
+	PostalCode string
+	Phone      string
+	Email      string
+	Hours      string // business hours summary, from shop settings
+	Footer     string // invoice footer text, from shop settings
+	Logo       *Photo // optional
+}
+
+// DamageItem is one row of the damage summary (accepted/proposed AI detections grouped).
+type DamageItem struct {
+	Category string
+	Severity string
+	Count    int
+}
+
+// ImageRef points at a stored work order photo.
+type ImageRef struct {
+	ID       uuid.UUID
+	URL      string
+	Filename string
+}
+
+// Photo is an image to embed in a document. Data may be JPEG, PNG or GIF;
+// it is re-encoded before embedding, so anything the standard decoders accept works.
+type Photo struct {
+	ID      uuid.UUID
+	Caption string
+	Data    []byte
+}
+
+// WorkOrderDoc is everything printed on a work order summary.
+type WorkOrderDoc struct {
+	Detail      dto.WorkOrderDetail
+	Shop        Branding
+	Damage      []DamageItem
+	Photos      []Photo
+	GeneratedAt time.Time
+}
+
+// EstimateDoc is everything printed on an estimate.
+type EstimateDoc struct {
+	WorkOrder   dto.WorkOrderDetail
+	Shop        Branding
+	Estimate    *estimate.Estimate
+	GeneratedAt time.Time
+}
+
+// InvoiceDoc is everything printed on an invoice.
+type InvoiceDoc struct {
+	WorkOrder   dto.WorkOrderDetail
+	Shop        Branding
+	Invoice     *invoice.Invoice
+	GeneratedAt time.Time
+}
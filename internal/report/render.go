@@ -0,0 +1,262 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/estimate"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/invoice"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/tax"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/go-pdf/fpdf"
+)
+
+// RenderWorkOrder writes the work order summary PDF to w.
+func RenderWorkOrder(w io.Writer, doc *WorkOrderDoc) error {
+	d := doc.Detail
+	pdf := newDocument("Work Order "+d.Code, doc.Shop, doc.GeneratedAt)
+
+	section(pdf, "Work Order")
+	fields(pdf,
+		field{"Code", d.Code},
+		field{"Status", humanize(string(d.Status))},
+		field{"Received", formatDate(d.DateReceived)},
+		field{"Last updated", formatDate(d.DateUpdated)},
+		field{"Shop", joinNonEmpty(" · ", d.Shop.ShopCode, d.Shop.ShopName)},
+	)
+
+	customerSection(pdf, d)
+	vehicleSection(pdf, d)
+	insuranceSection(pdf, d)
+
+	section(pdf, "Damage Summary")
+	if len(doc.Damage) == 0 {
+		note(pdf, "No damage has been recorded for this work order.")
+	} else {
+		rows := make([][]string, 0, len(doc.Damage))
+		for _, item := range doc.Damage {
+			rows = append(rows, []string{humanize(item.Category), humanize(item.Severity), strconv.Itoa(item.Count)})
+		}
+		table(pdf, []column{
+			{"Damage", 0.5, "L"},
+			{"Severity", 0.3, "L"},
+			{"Count", 0.2, "R"},
+		}, rows)
+	}
+
+	if len(doc.Photos) > 0 {
+		section(pdf, "Photos")
+		photoGrid(pdf, doc.Photos)
+	}
+
+	return output(pdf, w)
+}
+
+// RenderEstimate writes the estimate PDF to w.
+func RenderEstimate(w io.Writer, doc *EstimateDoc) error {
+	e := doc.Estimate
+	d := doc.WorkOrder
+	pdf := newDocument(fmt.Sprintf("Estimate v%d", e.Version), doc.Shop, doc.GeneratedAt)
+
+	section(pdf, "Estimate")
+	var approvedAt string
+	if e.ApprovedAt != nil {
+		approvedAt = formatDate(*e.ApprovedAt)
+	}
+	fields(pdf,
+		field{"Work order", d.Code},
+		field{"Version", strconv.Itoa(e.Version)},
+		field{"Status", humanize(string(e.Status))},
+		field{"Date", formatDate(e.UpdatedAt)},
+		field{"Approved", approvedAt},
+	)
+
+	customerSection(pdf, d)
+	vehicleSection(pdf, d)
+	insuranceSection(pdf, d)
+
+	section(pdf, "Repairs")
+	rows := make([][]string, 0, len(e.Lines))
+	for _, l := range e.Lines {
+		rows = append(rows, lineRow(l.LineNo, l.Category, l.Description, l.Quantity, l.UnitPriceCents, l.AmountCents, l.Taxable))
+	}
+	table(pdf, lineColumns, rows)
+
+	if e.Tax != nil {
+		trs := taxTotals(e.Tax.SubtotalCents, e.Tax.Components, e.Tax.TotalCents)
+		if e.DeductibleCents > 0 {
+			trs = append(trs, totalRow{label: "Deductible", amount: formatCents(e.DeductibleCents)})
+		}
+		totals(pdf, trs...)
+	}
+
+	if e.Notes != nil {
+		section(pdf, "Notes")
+		setFont(pdf, "", 9.5, colorText)
+		pdf.MultiCell(0, lineHeight, *e.Notes, "", "L", false)
+	}
+
+	return output(pdf, w)
+}
+
+// RenderInvoice writes the invoice PDF to w.
+func RenderInvoice(w io.Writer, doc *InvoiceDoc) error {
+	inv := doc.Invoice
+	d := doc.WorkOrder
+	pdf := newDocument("Invoice "+inv.Number, doc.Shop, doc.GeneratedAt)
+	if inv.Status == invoice.StatusVoid {
+		stamp(pdf, "VOID")
+	}
+
+	section(pdf, "Invoice")
+	fields(pdf,
+		field{"Invoice #", inv.Number},
+		field{"Work order", d.Code},
+		field{"Issued", formatDate(inv.IssuedAt)},
+		field{"Status", humanize(string(inv.Status))},
+	)
+
+	customerSection(pdf, d)
+	vehicleSection(pdf, d)
+	insuranceSection(pdf, d)
+
+	section(pdf, "Charges")
+	rows := make([][]string, 0, len(inv.Lines))
+	for _, l := range inv.Lines {
+		rows = append(rows, lineRow(l.LineNo, l.Category, l.Description, l.Quantity, l.UnitPriceCents, l.AmountCents, l.Taxable))
+	}
+	table(pdf, lineColumns, rows)
+
+	trs := taxTotals(inv.SubtotalCents, inv.TaxComponents, inv.TotalCents)
+	if inv.InsurerPortionCents > 0 {
+		trs = append(trs,
+			totalRow{label: "Customer portion (deductible)", amount: formatCents(inv.CustomerPortionCents)},
+			totalRow{label: "Insurer portion", amount: formatCents(inv.InsurerPortionCents)},
+		)
+	}
+	totals(pdf, trs...)
+
+	if len(inv.Payments) > 0 {
+		section(pdf, "Payments")
+		prows := make([][]string, 0, len(inv.Payments))
+		for _, p := range inv.Payments {
+			ref := ""
+			if p.Reference != nil {
+				ref = *p.Reference
+			}
+			prows = append(prows, []string{formatDate(p.ReceivedAt), humanize(string(p.Method)), humanize(string(p.Payer)), ref, formatCents(p.AmountCents)})
+		}
+		table(pdf, []column{
+			{"Date", 0.18, "L"},
+			{"Method", 0.2, "L"},
+			{"Payer", 0.15, "L"},
+			{"Reference", 0.3, "L"},
+			{"Amount", 0.17, "R"},
+		}, prows)
+	}
+
+	if inv.Status != invoice.StatusVoid {
+		balance := []totalRow{{label: "Balance due", amount: formatCents(inv.Balance.DueCents), bold: true}}
+		if inv.InsurerPortionCents > 0 && inv.Balance.DueCents > 0 {
+			balance = append(balance,
+				totalRow{label: "Due from customer", amount: formatCents(inv.Balance.CustomerDueCents)},
+				totalRow{label: "Due from insurer", amount: formatCents(inv.Balance.InsurerDueCents)},
+			)
+		}
+		totals(pdf, balance...)
+	}
+
+	if doc.Shop.Footer != "" {
+		pdf.Ln(4)
+		note(pdf, doc.Shop.Footer)
+	}
+
+	return output(pdf, w)
+}
+
+/* ---------- shared sections ---------- */
+
+func customerSection(pdf *fpdf.Fpdf, d dto.WorkOrderDetail) {
+	c := d.Customer
+	section(pdf, "Customer")
+	fields(pdf,
+		field{"Name", c.FullName},
+		field{"Phone", c.Phone},
+		field{"Email", c.Email},
+		field{"Address", joinNonEmpty(", ", c.Address, c.City, joinNonEmpty(" ", c.Province, c.PostalCode))},
+	)
+}
+
+func vehicleSection(pdf *fpdf.Fpdf, d dto.WorkOrderDetail) {
+	v := d.Vehicle
+	year := ""
+	if v.ModelYear > 0 {
+		year = strconv.Itoa(v.ModelYear)
+	}
+	section(pdf, "Vehicle")
+	fields(pdf,
+		field{"Vehicle", joinNonEmpty(" ", year, v.Make, v.Model)},
+		field{"VIN", v.VIN},
+		field{"Body style", v.BodyStyle},
+		field{"Colour", v.Color},
+		field{"Plate", v.PlateNo},
+	)
+}
+
+func insuranceSection(pdf *fpdf.Fpdf, d dto.WorkOrderDetail) {
+	section(pdf, "Insurance")
+	ins := d.Insurance
+	if ins == nil {
+		note(pdf, "No insurance on file.")
+		return
+	}
+	fields(pdf,
+		field{"Company", ins.InsuranceCompany},
+		field{"Claim #", ins.ClaimNumber},
+		field{"Policy #", ins.PolicyNumber},
+		field{"Agent", joinNonEmpty(" · ", ins.AgentFullName, ins.AgentPhone)},
+	)
+}
+
+var lineColumns = []column{
+	{"#", 0.05, "L"},
+	{"Type", 0.12, "L"},
+	{"Description", 0.43, "L"},
+	{"Qty", 0.08, "R"},
+	{"Unit", 0.14, "R"},
+	{"Amount", 0.18, "R"},
+}
+
+func lineRow(no int, cat estimate.Category, desc string, qty float64, unit, amount int64, taxable bool) []string {
+	if !taxable {
+		desc += " (tax exempt)"
+	}
+	return []string{strconv.Itoa(no), categoryLabel(cat), desc, formatQuantity(qty), formatCents(unit), formatCents(amount)}
+}
+
+func taxTotals(subtotal int64, components []tax.ComponentAmount, total int64) []totalRow {
+	rows := []totalRow{{label: "Subtotal", amount: formatCents(subtotal)}}
+	for _, c := range components {
+		rows = append(rows, totalRow{label: fmt.Sprintf("%s (%s)", c.Name, c.Rate.Percent()), amount: formatCents(c.TaxCents)})
+	}
+	return append(rows, totalRow{label: "Total", amount: formatCents(total), bold: true})
+}
+
+func categoryLabel(c estimate.Category) string {
+	switch c {
+	case estimate.CategoryPDR:
+		return "PDR"
+	case estimate.CategoryRAndI:
+		return "R&I"
+	default:
+		return humanize(string(c))
+	}
+}
+
+func output(pdf *fpdf.Fpdf, w io.Writer) error {
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("render pdf: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/estimate"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/invoice"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/tax"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var generatedAt = time.Date(2025, time.June, 1, 10, 30, 0, 0, time.UTC)
+
+func testShop() Branding {
+	return Branding{
+		Name: "Haven Auto Body", Address: "123 Main St", City: "Calgary", Province: "AB",
+		PostalCode: "T2P2B5", Phone: "403-555-1234", Email: "shop@example.com",
+	}
+}
+
+func testDetail() dto.WorkOrderDetail {
+	return dto.WorkOrderDetail{
+		ID:           uuid.New(),
+		Code:         "WO-0042",
+		Status:       dto.WOStatusCompleted,
+		DateReceived: generatedAt.AddDate(0, 0, -10),
+		DateUpdated:  generatedAt,
+		Customer: dto.CustomerDetail{
+			FullName: "Zoë Lévesque", Address: "9 Rue Saint-Jean", City: "Québec",
+			Province: "QC", PostalCode: "G1R1N8", Email: "zoe@example.com", Phone: "418-555-0000",
+		},
+		Vehicle: dto.VehicleDetail{Make: "Honda", Model: "Civic", ModelYear: 2021, VIN: "2HGFC2F59MH000000", Color: "Blue"},
+		Shop:    dto.ShopSummary{ShopID: uuid.New(), ShopCode: "CAL01", ShopName: "Haven Auto Body"},
+		Insurance: &dto.InsuranceDetail{
+			InsuranceCompany: "Prairie Mutual", ClaimNumber: "CLM-1", PolicyNumber: "POL-1",
+		},
+	}
+}
+
+func pngWithAlpha(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 128})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func assertPDF(t *testing.T, buf *bytes.Buffer) {
+	t.Helper()
+	require.Greater(t, buf.Len(), 1000)
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte("%%EOF")))
+}
+
+// Test: work order summary with photos, including one that can't be decoded
+func TestRenderWorkOrder(t *testing.T) {
+	shop := testShop()
+	shop.Logo = &Photo{Data: pngWithAlpha(t, 120, 40)}
+
+	var buf bytes.Buffer
+	err := RenderWorkOrder(&buf, &WorkOrderDoc{
+		Detail: testDetail(),
+		Shop:   shop,
+		Damage: []DamageItem{{Category: "dent", Severity: "moderate", Count: 14}, {Category: "scratch", Count: 2}},
+		Photos: []Photo{
+			{Caption: "front.png", Data: pngWithAlpha(t, 64, 48)},
+			{Caption: "corrupt.jpg", Data: []byte("not an image")},
+			{Caption: "tall.png", Data: pngWithAlpha(t, 40, 120)},
+		},
+		GeneratedAt: generatedAt,
+	})
+	require.NoError(t, err)
+	assertPDF(t, &buf)
+}
+
+// Test: a work order without insurance, damage or photos still renders
+func TestRenderWorkOrderMinimal(t *testing.T) {
+	d := testDetail()
+	d.Insurance = nil
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderWorkOrder(&buf, &WorkOrderDoc{Detail: d, Shop: testShop(), GeneratedAt: generatedAt}))
+	assertPDF(t, &buf)
+}
+
+// Test: estimate with enough lines to spill onto a second page
+func TestRenderEstimate(t *testing.T) {
+	e := &estimate.Estimate{Version: 2, Status: estimate.StatusApproved, DeductibleCents: 50000, UpdatedAt: generatedAt}
+	for i := 1; i <= 60; i++ {
+		e.Lines = append(e.Lines, estimate.Line{
+			LineNo: i, Category: estimate.CategoryPDR, Description: "Roof panel dent repair — large",
+			Quantity: 1.5, UnitPriceCents: 8500, AmountCents: 12750, Taxable: i%10 != 0,
+		})
+	}
+	b, err := tax.Calculate("AB", generatedAt, e.TaxLines())
+	require.NoError(t, err)
+	e.Tax = b
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderEstimate(&buf, &EstimateDoc{WorkOrder: testDetail(), Shop: testShop(), Estimate: e, GeneratedAt: generatedAt}))
+	assertPDF(t, &buf)
+}
+
+// Test: void invoice with payments
+func TestRenderInvoice(t *testing.T) {
+	ref := "CHQ 1001"
+	inv := &invoice.Invoice{
+		Number: "INV-CAL01-0007", Status: invoice.StatusVoid, IssuedAt: generatedAt,
+		SubtotalCents: 100000, TaxCents: 5000, TotalCents: 105000,
+		CustomerPortionCents: 50000, InsurerPortionCents: 55000,
+		TaxComponents: []tax.ComponentAmount{{Kind: tax.GST, Name: "GST", Rate: 5000, TaxableCents: 100000, TaxCents: 5000}},
+		Lines:         []invoice.Line{{LineNo: 1, Category: estimate.CategoryRAndI, Description: "Headliner R&I", Quantity: 2, UnitPriceCents: 50000, AmountCents: 100000, Taxable: true, TaxCents: 5000}},
+		Payments: []invoice.Payment{
+			{Method: invoice.MethodInsurerCheque, Payer: invoice.PayerInsurer, AmountCents: 55000, Reference: &ref, ReceivedAt: generatedAt},
+		},
+	}
+	inv.ComputeBalance()
+	shop := testShop()
+	shop.Hours = "Mon–Fri 08:00–17:00 · Sat 09:00–13:00"
+	shop.Footer = "Thank you for your business. Payment is due within 30 days."
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderInvoice(&buf, &InvoiceDoc{WorkOrder: testDetail(), Shop: shop, Invoice: inv, GeneratedAt: generatedAt}))
+	assertPDF(t, &buf)
+}
+
+// Test: money and quantity formatting
+func TestFormatting(t *testing.T) {
+	assert.Equal(t, "$0.00", formatCents(0))
+	assert.Equal(t, "$12.05", formatCents(1205))
+	assert.Equal(t, "$1,234,567.89", formatCents(123456789))
+	assert.Equal(t, "-$1,000.00", formatCents(-100000))
+	assert.Equal(t, "2", formatQuantity(2))
+	assert.Equal(t, "1.50", formatQuantity(1.5))
+	assert.Equal(t, "Waiting for inspection", humanize("waiting_for_inspection"))
+}
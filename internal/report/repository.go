@@ -0,0 +1,126 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/shop"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository reads the extra data documents need beyond the work order itself.
+type Repository interface {
+	GetBranding(ctx context.Context, shopID uuid.UUID) (*Branding, error)
+	DamageSummary(ctx context.Context, workOrderID uuid.UUID) ([]DamageItem, error)
+	ListImages(ctx context.Context, workOrderID uuid.UUID, ids []uuid.UUID, limit int) ([]ImageRef, error)
+}
+
+type pgRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository constructs a Postgres-backed report repository.
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgRepo{db: db}
+}
+
+func (r *pgRepo) GetBranding(ctx context.Context, shopID uuid.UUID) (*Branding, error) {
+	var b Branding
+	var hours shop.Hours
+	var footer *string
+	var logo []byte
+	err := r.db.QueryRow(ctx, `
+SELECT s.shop_name, s.address, s.city, s.province, s.postal_code, s.phone, s.email,
+       COALESCE(ss.business_hours, '[]'), ss.invoice_footer, ss.logo
+FROM app.shop s
+LEFT JOIN app.shop_settings ss ON ss.shop_id = s.id
+WHERE s.id = $1
+`, shopID).Scan(&b.Name, &b.Address, &b.City, &b.Province, &b.PostalCode, &b.Phone, &b.Email,
+		&hours, &footer, &logo)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get shop branding: %w", err)
+	}
+
+	b.Hours = hours.String()
+	if footer != nil {
+		b.Footer = *footer
+	}
+	if len(logo) > 0 {
+		b.Logo = &Photo{Caption: "logo", Data: logo}
+	}
+	return &b, nil
+}
+
+// DamageSummary groups the work order's live detections by category and severity.
+// Rejected, hidden and false-positive detections are left out.
+func (r *pgRepo) DamageSummary(ctx context.Context, workOrderID uuid.UUID) (items []DamageItem, err error) {
+	err = database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+SELECT COALESCE(mapped_category, model_category, 'unclassified') AS category,
+       COALESCE(severity, '') AS severity,
+       count(*)
+FROM app.ai_detection
+WHERE work_order_id = $1
+  AND deleted_at IS NULL
+  AND NOT is_false_positive
+  AND status IN ('proposed', 'accepted')
+GROUP BY 1, 2
+ORDER BY 1, 2
+`, workOrderID)
+		if err != nil {
+			return fmt.Errorf("damage summary: %w", err)
+		}
+		defer rows.Close()
+
+		items = make([]DamageItem, 0)
+		for rows.Next() {
+			var it DamageItem
+			if err := rows.Scan(&it.Category, &it.Severity, &it.Count); err != nil {
+				return fmt.Errorf("scan damage summary: %w", err)
+			}
+			items = append(items, it)
+		}
+		return rows.Err()
+	})
+	return items, err
+}
+
+// ListImages returns the work order's photos that have a fetchable URL.
+// When ids is non-empty only those photos are returned, in upload order.
+func (r *pgRepo) ListImages(ctx context.Context, workOrderID uuid.UUID, ids []uuid.UUID, limit int) (refs []ImageRef, err error) {
+	err = database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+SELECT id, public_url, COALESCE(original_filename, '')
+FROM app.work_order_image
+WHERE work_order_id = $1
+  AND deleted_at IS NULL
+  AND status <> 'archived'
+  AND public_url IS NOT NULL
+  AND (cardinality($2::uuid[]) = 0 OR id = ANY($2))
+ORDER BY created_at
+LIMIT $3
+`, workOrderID, ids, limit)
+		if err != nil {
+			return fmt.Errorf("list work order images: %w", err)
+		}
+		defer rows.Close()
+
+		refs = make([]ImageRef, 0)
+		for rows.Next() {
+			var ref ImageRef
+			if err := rows.Scan(&ref.ID, &ref.URL, &ref.Filename); err != nil {
+				return fmt.Errorf("scan work order image: %w", err)
+			}
+			refs = append(refs, ref)
+		}
+		return rows.Err()
+	})
+	return refs, err
+}
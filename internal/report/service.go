@@ -0,0 +1,177 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/estimate"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/invoice"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultPhotoCount is how many photos a summary includes when none are selected.
+	defaultPhotoCount = 6
+	// MaxPhotos is the most photos a single summary will embed.
+	MaxPhotos = 12
+)
+
+// Service renders work order, estimate and invoice documents.
+// Each method writes the PDF to w and returns a suggested file name.
+// For work orders, nil photoIDs picks the first few photos and an empty,
+// non-nil slice includes none.
+type Service interface {
+	WorkOrderPDF(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID, photoIDs []uuid.UUID, w io.Writer) (string, error)
+	EstimatePDF(ctx context.Context, actor *auth.AuthUser, estimateID uuid.UUID, w io.Writer) (string, error)
+	InvoicePDF(ctx context.Context, actor *auth.AuthUser, invoiceID uuid.UUID, w io.Writer) (string, error)
+}
+
+type service struct {
+	repo       Repository
+	workorders workorder.Service
+	estimates  estimate.Service
+	invoices   invoice.Service
+	images     ImageLoader
+	now        func() time.Time
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates a report service. Estimate and invoice access checks are
+// delegated to their own services.
+func NewService(repo Repository, workorders workorder.Service, estimates estimate.Service, invoices invoice.Service, images ImageLoader) Service {
+	return &service{
+		repo:       repo,
+		workorders: workorders,
+		estimates:  estimates,
+		invoices:   invoices,
+		images:     images,
+		now:        time.Now,
+	}
+}
+
+func (s *service) WorkOrderPDF(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID, photoIDs []uuid.UUID, w io.Writer) (string, error) {
+	detail, shop, err := s.workOrder(ctx, actor, workOrderID)
+	if err != nil {
+		return "", err
+	}
+
+	damage, err := s.repo.DamageSummary(ctx, workOrderID)
+	if err != nil {
+		return "", err
+	}
+
+	var refs []ImageRef
+	if photoIDs == nil || len(photoIDs) > 0 {
+		limit := defaultPhotoCount
+		if len(photoIDs) > 0 {
+			limit = min(len(photoIDs), MaxPhotos)
+		}
+		if refs, err = s.repo.ListImages(ctx, workOrderID, photoIDs, limit); err != nil {
+			return "", err
+		}
+	}
+
+	err = RenderWorkOrder(w, &WorkOrderDoc{
+		Detail:      detail,
+		Shop:        *shop,
+		Damage:      damage,
+		Photos:      s.loadPhotos(ctx, refs),
+		GeneratedAt: s.now(),
+	})
+	return detail.Code + ".pdf", err
+}
+
+func (s *service) EstimatePDF(ctx context.Context, actor *auth.AuthUser, estimateID uuid.UUID, w io.Writer) (string, error) {
+	e, err := s.estimates.GetByID(ctx, actor, estimateID)
+	if err != nil {
+		if errors.Is(err, estimate.ErrNotFound) || errors.Is(err, estimate.ErrWorkOrderNotFound) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	detail, shop, err := s.workOrder(ctx, actor, e.WorkOrderID)
+	if err != nil {
+		return "", err
+	}
+
+	err = RenderEstimate(w, &EstimateDoc{WorkOrder: detail, Shop: *shop, Estimate: e, GeneratedAt: s.now()})
+	return fmt.Sprintf("%s-estimate-v%d.pdf", detail.Code, e.Version), err
+}
+
+func (s *service) InvoicePDF(ctx context.Context, actor *auth.AuthUser, invoiceID uuid.UUID, w io.Writer) (string, error) {
+	inv, err := s.invoices.GetByID(ctx, actor, invoiceID)
+	if err != nil {
+		if errors.Is(err, invoice.ErrNotFound) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	detail, shop, err := s.workOrder(ctx, actor, inv.WorkOrderID)
+	if err != nil {
+		return "", err
+	}
+
+	err = RenderInvoice(w, &InvoiceDoc{WorkOrder: detail, Shop: *shop, Invoice: inv, GeneratedAt: s.now()})
+	return inv.Number + ".pdf", err
+}
+
+// workOrder loads the work order with its shop's branding, enforcing shop scope.
+func (s *service) workOrder(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (dto.WorkOrderDetail, *Branding, error) {
+	detail, err := s.workorders.GetWorkOrderByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, workorder.ErrNotFound) {
+			return detail, nil, ErrNotFound
+		}
+		return detail, nil, err
+	}
+	if !actor.CanAccessShop(detail.Shop.ShopID) {
+		return detail, nil, ErrNotFound
+	}
+
+	shop, err := s.repo.GetBranding(ctx, detail.Shop.ShopID)
+	if err != nil {
+		return detail, nil, err
+	}
+	return detail, shop, nil
+}
+
+// loadPhotos fetches photos in parallel. A photo that can't be fetched is left
+// out of the document rather than failing it.
+func (s *service) loadPhotos(ctx context.Context, refs []ImageRef) []Photo {
+	photos := make([]Photo, len(refs))
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := s.images.Load(ctx, ref)
+			if err != nil {
+				slog.WarnContext(ctx, "report: skipping photo", "image_id", ref.ID, "error", err)
+				return
+			}
+			caption := ref.Filename
+			if caption == "" {
+				caption = fmt.Sprintf("Photo %d", i+1)
+			}
+			photos[i] = Photo{ID: ref.ID, Caption: caption, Data: data}
+		}()
+	}
+	wg.Wait()
+
+	loaded := photos[:0]
+	for _, p := range photos {
+		if p.Data != nil {
+			loaded = append(loaded, p)
+		}
+	}
+	return loaded
+}
@@ -0,0 +1,39 @@
+package scan
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain-level errors for work order images and scan results
+var (
+	ErrWorkOrderNotFound = errors.New("work order not found")
+	ErrJobNotFound       = errors.New("scan job not found")
+	ErrImageNotInJob     = errors.New("image is not part of this scan job")
+	ErrInvalidInput      = errors.New("invalid scan input")
+	ErrForbidden         = errors.New("forbidden: only the scan worker can report scan results")
+
+	ErrJobFinished     = errors.New("scan job is no longer running")
+	ErrAlreadyReported = errors.New("a result for this image was already reported")
+)
+
+// ValidationError represents validation errors with specific field information
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap allows errors.Is to work with ValidationError
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// NewValidationError creates a new ValidationError
+func NewValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
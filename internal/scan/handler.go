@@ -0,0 +1,118 @@
+package scan
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+/* -------------------- Handler Struct -------------------- */
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// RegisterImage handles POST /workorders/{id}/images.
+func (h *Handler) RegisterImage(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "invalid work order ID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var in RegisterImageInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, NewValidationError("body", "invalid JSON"))
+		return
+	}
+
+	img, err := h.svc.RegisterImage(r.Context(), actor, id, &in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, img)
+}
+
+// RecordResult handles POST /scans/{id}/results.
+func (h *Handler) RecordResult(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := parseID(r, "invalid scan job ID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var in ResultInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, r, NewValidationError("body", "invalid JSON"))
+		return
+	}
+
+	job, err := h.svc.RecordResult(r.Context(), actor, id, &in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+/* -------------------- Helpers -------------------- */
+
+func parseID(r *http.Request, msg string) (uuid.UUID, error) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return uuid.Nil, NewValidationError("id", msg)
+	}
+	return id, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// writeError classifies known domain errors and delegates to httpError.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
+
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		httpError(w, http.StatusBadRequest, err.Error())
+
+	case errors.Is(err, ErrWorkOrderNotFound), errors.Is(err, ErrJobNotFound), errors.Is(err, ErrImageNotInJob):
+		httpError(w, http.StatusNotFound, err.Error())
+
+	case errors.Is(err, ErrJobFinished), errors.Is(err, ErrAlreadyReported):
+		httpError(w, http.StatusConflict, err.Error())
+
+	case errors.Is(err, ErrForbidden):
+		httpError(w, http.StatusForbidden, err.Error())
+
+	default:
+		httpError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
@@ -0,0 +1,93 @@
+// Package scan is the AI damage scan worker's side of the API: it registers
+// the work order photos the worker uploads to storage and records the results
+// it posts back for each photo of a scan job.
+package scan
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Image statuses (app.work_order_image.status)
+const (
+	ImageDraft         = "draft"
+	ImageReadyForScan  = "ready_for_scan"
+	ImageScanCompleted = "scan_completed"
+	ImageScanFailed    = "scan_failed"
+)
+
+// Per-image result statuses the worker reports (app.ai_scan_job_image.status)
+const (
+	ResultSuccess = "success"
+	ResultFailed  = "failed"
+)
+
+// Image ↔ app.work_order_image
+type Image struct {
+	ID               uuid.UUID  `json:"id"`
+	WorkOrderID      uuid.UUID  `json:"workOrderId"`
+	StoragePath      string     `json:"storagePath"`
+	PublicURL        *string    `json:"publicUrl,omitempty"`
+	ThumbnailURL     *string    `json:"thumbnailUrl,omitempty"`
+	OriginalFilename *string    `json:"originalFilename,omitempty"`
+	MimeType         *string    `json:"mimeType,omitempty"`
+	FileSizeBytes    *int64     `json:"fileSizeBytes,omitempty"`
+	WidthPx          *int       `json:"widthPx,omitempty"`
+	HeightPx         *int       `json:"heightPx,omitempty"`
+	Status           string     `json:"status"`
+	CreatedByUserID  *uuid.UUID `json:"createdByUserId,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+// RegisterImageInput is the body of POST /workorders/{id}/images: a photo
+// already uploaded to storage.
+type RegisterImageInput struct {
+	StoragePath      string  `json:"storagePath"`
+	PublicURL        *string `json:"publicUrl,omitempty"`
+	ThumbnailURL     *string `json:"thumbnailUrl,omitempty"`
+	OriginalFilename *string `json:"originalFilename,omitempty"`
+	MimeType         *string `json:"mimeType,omitempty"`
+	FileSizeBytes    *int64  `json:"fileSizeBytes,omitempty"`
+	WidthPx          *int    `json:"widthPx,omitempty"`
+	HeightPx         *int    `json:"heightPx,omitempty"`
+	Status           string  `json:"status,omitempty"` // draft (default) or ready_for_scan
+}
+
+// ResultInput is the body of POST /scans/{id}/results: the outcome for one
+// photo of the job.
+type ResultInput struct {
+	ImageID       uuid.UUID        `json:"imageId"` // work_order_image id
+	Status        string           `json:"status"`  // success or failed
+	ErrorCode     *string          `json:"errorCode,omitempty"`
+	ErrorMessage  *string          `json:"errorMessage,omitempty"`
+	Provider      *string          `json:"provider,omitempty"`
+	SchemaVersion *string          `json:"schemaVersion,omitempty"`
+	Raw           json.RawMessage  `json:"raw,omitempty"` // the model's payload, kept as is
+	Detections    []DetectionInput `json:"detections,omitempty"`
+}
+
+// DetectionInput is one damage detection ↔ app.ai_detection.
+type DetectionInput struct {
+	Category       string          `json:"category"` // the model's own label
+	MappedCategory *string         `json:"mappedCategory,omitempty"`
+	Confidence     *float64        `json:"confidence,omitempty"` // 0 to 1
+	Severity       *string         `json:"severity,omitempty"`
+	BBox           json.RawMessage `json:"bbox,omitempty"`
+	Polygon        json.RawMessage `json:"polygon,omitempty"`
+	Area           *float64        `json:"area,omitempty"`
+}
+
+// Job is the progress of a scan job ↔ app.ai_scan_job.
+type Job struct {
+	ID              uuid.UUID  `json:"id"`
+	WorkOrderID     uuid.UUID  `json:"workOrderId"`
+	Status          string     `json:"status"`
+	TotalImages     int        `json:"totalImages"`
+	SuccessImages   int        `json:"successImages"`
+	FailedImages    int        `json:"failedImages"`
+	TotalDetections *int       `json:"totalDetections,omitempty"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+}
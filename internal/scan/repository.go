@@ -0,0 +1,184 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository persists work order images and scan results. Every query runs in
+// the caller's row-level security scope, so a shop-bound key only reaches its
+// own shop's work orders and jobs.
+type Repository interface {
+	// WorkOrderShop returns the shop of a work order the caller can see.
+	WorkOrderShop(ctx context.Context, workOrderID uuid.UUID) (uuid.UUID, error)
+	CreateImage(ctx context.Context, workOrderID uuid.UUID, in *RegisterImageInput, createdBy *uuid.UUID) (*Image, error)
+	// RecordResult stores one image's result and moves the job on; the job
+	// completes (or fails, when no image succeeded) with its last image.
+	RecordResult(ctx context.Context, jobID uuid.UUID, in *ResultInput) (*Job, error)
+}
+
+type pgRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository constructs a Postgres-backed scan repository.
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgRepo{db: db}
+}
+
+func (r *pgRepo) WorkOrderShop(ctx context.Context, workOrderID uuid.UUID) (shopID uuid.UUID, err error) {
+	err = database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `SELECT shop_id FROM app.work_orders WHERE id = $1`, workOrderID).Scan(&shopID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrWorkOrderNotFound
+		}
+		return err
+	})
+	return shopID, err
+}
+
+func (r *pgRepo) CreateImage(ctx context.Context, workOrderID uuid.UUID, in *RegisterImageInput, createdBy *uuid.UUID) (img *Image, err error) {
+	err = database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		img = &Image{}
+		return tx.QueryRow(ctx, `
+INSERT INTO app.work_order_image
+	(work_order_id, storage_path, public_url, thumbnail_url, original_filename,
+	 mime_type, file_size_bytes, width_px, height_px, status, created_by_user_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, work_order_id, storage_path, public_url, thumbnail_url, original_filename,
+          mime_type, file_size_bytes, width_px, height_px, status, created_by_user_id, created_at
+`, workOrderID, in.StoragePath, in.PublicURL, in.ThumbnailURL, in.OriginalFilename,
+			in.MimeType, in.FileSizeBytes, in.WidthPx, in.HeightPx, in.Status, createdBy,
+		).Scan(&img.ID, &img.WorkOrderID, &img.StoragePath, &img.PublicURL, &img.ThumbnailURL, &img.OriginalFilename,
+			&img.MimeType, &img.FileSizeBytes, &img.WidthPx, &img.HeightPx, &img.Status, &img.CreatedByUserID, &img.CreatedAt)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create work order image: %w", err)
+	}
+	return img, nil
+}
+
+func (r *pgRepo) RecordResult(ctx context.Context, jobID uuid.UUID, in *ResultInput) (job *Job, err error) {
+	err = database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		// Lock the job so concurrent results for the same job count correctly.
+		var (
+			workOrderID, requestedBy uuid.UUID
+			status                   string
+		)
+		err := tx.QueryRow(ctx, `
+SELECT work_order_id, requested_by_user_id, status FROM app.ai_scan_job WHERE id = $1 FOR UPDATE
+`, jobID).Scan(&workOrderID, &requestedBy, &status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrJobNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("lock scan job: %w", err)
+		}
+		switch status {
+		case "completed", "failed", "canceled":
+			return ErrJobFinished
+		}
+
+		var jobImageID uuid.UUID
+		err = tx.QueryRow(ctx, `
+SELECT id, status FROM app.ai_scan_job_image
+WHERE ai_scan_job_id = $1 AND work_order_image_id = $2
+`, jobID, in.ImageID).Scan(&jobImageID, &status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrImageNotInJob
+		}
+		if err != nil {
+			return fmt.Errorf("get scan job image: %w", err)
+		}
+		switch status {
+		case "pending", "queued", "running":
+		default:
+			return ErrAlreadyReported
+		}
+
+		if len(in.Raw) > 0 {
+			if _, err := tx.Exec(ctx, `
+INSERT INTO app.ai_detection_raw (ai_scan_job_image_id, raw_payload, provider, schema_version)
+VALUES ($1, $2, $3, $4)
+`, jobImageID, in.Raw, in.Provider, in.SchemaVersion); err != nil {
+				return fmt.Errorf("insert raw detections: %w", err)
+			}
+		}
+		for _, d := range in.Detections {
+			// the worker has no user; detections are attributed to whoever requested the scan
+			if _, err := tx.Exec(ctx, `
+INSERT INTO app.ai_detection
+	(ai_scan_job_image_id, work_order_image_id, work_order_id, model_category, mapped_category,
+	 confidence, severity, bbox, polygon, area, created_by_user_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+`, jobImageID, in.ImageID, workOrderID, d.Category, d.MappedCategory,
+				d.Confidence, d.Severity, jsonOrNil(d.BBox), jsonOrNil(d.Polygon), d.Area, requestedBy); err != nil {
+				return fmt.Errorf("insert detection: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `
+UPDATE app.ai_scan_job_image
+SET status = $2, error_code = $3, error_message = $4, processed_at = now(), detection_count = $5
+WHERE id = $1
+`, jobImageID, in.Status, in.ErrorCode, in.ErrorMessage, len(in.Detections)); err != nil {
+			return fmt.Errorf("update scan job image: %w", err)
+		}
+		imageStatus := ImageScanCompleted
+		if in.Status == ResultFailed {
+			imageStatus = ImageScanFailed
+		}
+		if _, err := tx.Exec(ctx, `UPDATE app.work_order_image SET status = $2 WHERE id = $1`, in.ImageID, imageStatus); err != nil {
+			return fmt.Errorf("update work order image: %w", err)
+		}
+
+		var success, failed int
+		if in.Status == ResultSuccess {
+			success = 1
+		} else {
+			failed = 1
+		}
+		job = &Job{}
+		err = tx.QueryRow(ctx, `
+WITH left_over AS (
+	SELECT count(*) AS n FROM app.ai_scan_job_image
+	WHERE ai_scan_job_id = $1 AND status IN ('pending', 'queued', 'running')
+)
+UPDATE app.ai_scan_job j SET
+	success_images = j.success_images + $2,
+	failed_images = j.failed_images + $3,
+	total_detections = COALESCE(j.total_detections, 0) + $4,
+	started_at = COALESCE(j.started_at, now()),
+	status = CASE
+		WHEN l.n > 0 THEN 'running'
+		WHEN j.success_images + $2 > 0 THEN 'completed'
+		ELSE 'failed'
+	END,
+	completed_at = CASE WHEN l.n = 0 THEN now() END
+FROM left_over l
+WHERE j.id = $1
+RETURNING j.id, j.work_order_id, j.status, j.total_images, j.success_images, j.failed_images,
+          j.total_detections, j.started_at, j.completed_at
+`, jobID, success, failed, len(in.Detections)).Scan(&job.ID, &job.WorkOrderID, &job.Status, &job.TotalImages,
+			&job.SuccessImages, &job.FailedImages, &job.TotalDetections, &job.StartedAt, &job.CompletedAt)
+		if err != nil {
+			return fmt.Errorf("update scan job: %w", err)
+		}
+		return nil
+	})
+	return job, err
+}
+
+// jsonOrNil stores an omitted JSON field as NULL rather than failing on "".
+func jsonOrNil(raw []byte) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
@@ -0,0 +1,151 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/google/uuid"
+)
+
+// Service registers work order photos and records scan results.
+type Service interface {
+	// RegisterImage records a photo already uploaded to storage. API keys need
+	// images:write; users may register photos of work orders they can see.
+	RegisterImage(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID, in *RegisterImageInput) (*Image, error)
+	// RecordResult stores the scan worker's result for one photo of a job and
+	// returns the job's progress. Only API keys (scans:callback) may call it.
+	RecordResult(ctx context.Context, actor *auth.AuthUser, jobID uuid.UUID, in *ResultInput) (*Job, error)
+}
+
+const (
+	maxTextLength = 1000
+	maxDetections = 1000 // per image
+)
+
+type service struct {
+	repo  Repository
+	audit audit.Recorder
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates the scan service. Image registrations are reported to
+// rec (nil records nothing); scan results are not, they are machine output.
+func NewService(repo Repository, rec audit.Recorder) Service {
+	return &service{repo: repo, audit: audit.OrNop(rec)}
+}
+
+func (s *service) RegisterImage(ctx context.Context, actor *auth.AuthUser, workOrderID uuid.UUID, in *RegisterImageInput) (*Image, error) {
+	if err := validateImage(in); err != nil {
+		return nil, err
+	}
+	shopID, err := s.repo.WorkOrderShop(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// API keys are not users, so nobody is recorded as the uploader
+	var createdBy *uuid.UUID
+	if !actor.IsAPIKey() {
+		createdBy = &actor.ID
+	}
+	img, err := s.repo.CreateImage(ctx, workOrderID, in, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, audit.Entry{
+		Action: "workorder.image_add", EntityType: audit.EntityWorkOrder, EntityID: workOrderID.String(),
+		ShopID: &shopID, After: img,
+	})
+	return img, nil
+}
+
+func (s *service) RecordResult(ctx context.Context, actor *auth.AuthUser, jobID uuid.UUID, in *ResultInput) (*Job, error) {
+	if !actor.IsAPIKey() {
+		return nil, ErrForbidden
+	}
+	if err := validateResult(in); err != nil {
+		return nil, err
+	}
+	return s.repo.RecordResult(ctx, jobID, in)
+}
+
+/* ---------- validation ---------- */
+
+func validateImage(in *RegisterImageInput) error {
+	in.StoragePath = strings.TrimSpace(in.StoragePath)
+	if in.StoragePath == "" {
+		return NewValidationError("storagePath", "is required")
+	}
+	if len(in.StoragePath) > maxTextLength {
+		return NewValidationError("storagePath", "is too long")
+	}
+	if in.PublicURL != nil && !isHTTPURL(*in.PublicURL) {
+		return NewValidationError("publicUrl", "must be an http(s) URL")
+	}
+	if in.ThumbnailURL != nil && !isHTTPURL(*in.ThumbnailURL) {
+		return NewValidationError("thumbnailUrl", "must be an http(s) URL")
+	}
+	if in.MimeType != nil && !strings.HasPrefix(*in.MimeType, "image/") {
+		return NewValidationError("mimeType", "must be an image type")
+	}
+	if in.FileSizeBytes != nil && *in.FileSizeBytes < 0 {
+		return NewValidationError("fileSizeBytes", "cannot be negative")
+	}
+	if (in.WidthPx != nil && *in.WidthPx <= 0) || (in.HeightPx != nil && *in.HeightPx <= 0) {
+		return NewValidationError("widthPx", "width and height must be positive")
+	}
+	switch in.Status {
+	case "":
+		in.Status = ImageDraft
+	case ImageDraft, ImageReadyForScan:
+	default:
+		return NewValidationError("status", "must be draft or ready_for_scan")
+	}
+	return nil
+}
+
+func validateResult(in *ResultInput) error {
+	if in.ImageID == uuid.Nil {
+		return NewValidationError("imageId", "is required")
+	}
+	switch in.Status {
+	case ResultSuccess:
+		if len(in.Raw) == 0 {
+			return NewValidationError("raw", "is required for a successful scan")
+		}
+	case ResultFailed:
+		if len(in.Detections) > 0 {
+			return NewValidationError("detections", "must be empty for a failed scan")
+		}
+	default:
+		return NewValidationError("status", "must be success or failed")
+	}
+	if len(in.Raw) > 0 && !json.Valid(in.Raw) {
+		return NewValidationError("raw", "must be JSON")
+	}
+	if len(in.Detections) > maxDetections {
+		return NewValidationError("detections", "too many detections")
+	}
+	for _, d := range in.Detections {
+		if strings.TrimSpace(d.Category) == "" {
+			return NewValidationError("detections.category", "is required")
+		}
+		if d.Confidence != nil && (*d.Confidence < 0 || *d.Confidence > 1) {
+			return NewValidationError("detections.confidence", "must be between 0 and 1")
+		}
+		if d.Area != nil && *d.Area < 0 {
+			return NewValidationError("detections.area", "cannot be negative")
+		}
+	}
+	return nil
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,158 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* ---------- fakes ---------- */
+
+type fakeRepo struct {
+	workOrders map[uuid.UUID]uuid.UUID // work order → shop
+	images     []*Image
+	results    []*ResultInput
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{workOrders: make(map[uuid.UUID]uuid.UUID)}
+}
+
+func (r *fakeRepo) WorkOrderShop(_ context.Context, workOrderID uuid.UUID) (uuid.UUID, error) {
+	shopID, ok := r.workOrders[workOrderID]
+	if !ok {
+		return uuid.Nil, ErrWorkOrderNotFound
+	}
+	return shopID, nil
+}
+
+func (r *fakeRepo) CreateImage(_ context.Context, workOrderID uuid.UUID, in *RegisterImageInput, createdBy *uuid.UUID) (*Image, error) {
+	img := &Image{ID: uuid.New(), WorkOrderID: workOrderID, StoragePath: in.StoragePath, Status: in.Status, CreatedByUserID: createdBy}
+	r.images = append(r.images, img)
+	return img, nil
+}
+
+func (r *fakeRepo) RecordResult(_ context.Context, jobID uuid.UUID, in *ResultInput) (*Job, error) {
+	r.results = append(r.results, in)
+	return &Job{ID: jobID, Status: "running", TotalImages: 2, SuccessImages: 1}, nil
+}
+
+type auditLog struct{ entries []audit.Entry }
+
+func (l *auditLog) Record(_ context.Context, e audit.Entry) { l.entries = append(l.entries, e) }
+
+func apiKey() *auth.AuthUser {
+	keyID := uuid.New()
+	return &auth.AuthUser{ID: keyID, APIKeyID: &keyID}
+}
+
+/* ---------- RegisterImage ---------- */
+
+func TestRegisterImage(t *testing.T) {
+	repo := newFakeRepo()
+	woID, shopID := uuid.New(), uuid.New()
+	repo.workOrders[woID] = shopID
+	log := &auditLog{}
+	svc := NewService(repo, log)
+
+	user := &auth.AuthUser{ID: uuid.New(), ShopID: &shopID}
+	img, err := svc.RegisterImage(context.Background(), user, woID, &RegisterImageInput{StoragePath: " wo/front.jpg "})
+	require.NoError(t, err)
+	assert.Equal(t, "wo/front.jpg", img.StoragePath)
+	assert.Equal(t, ImageDraft, img.Status)
+	require.NotNil(t, img.CreatedByUserID)
+	assert.Equal(t, user.ID, *img.CreatedByUserID)
+
+	require.Len(t, log.entries, 1)
+	assert.Equal(t, "workorder.image_add", log.entries[0].Action)
+	assert.Equal(t, audit.EntityWorkOrder, log.entries[0].EntityType)
+	assert.Equal(t, woID.String(), log.entries[0].EntityID)
+	assert.Equal(t, &shopID, log.entries[0].ShopID)
+}
+
+func TestRegisterImageByAPIKeyHasNoUploader(t *testing.T) {
+	repo := newFakeRepo()
+	woID := uuid.New()
+	repo.workOrders[woID] = uuid.New()
+	svc := NewService(repo, nil)
+
+	img, err := svc.RegisterImage(context.Background(), apiKey(), woID,
+		&RegisterImageInput{StoragePath: "wo/rear.jpg", Status: ImageReadyForScan})
+	require.NoError(t, err)
+	assert.Nil(t, img.CreatedByUserID, "an API key is not a user")
+	assert.Equal(t, ImageReadyForScan, img.Status)
+}
+
+func TestRegisterImageValidation(t *testing.T) {
+	woID := uuid.New()
+	url := "ftp://bucket/wo.jpg"
+	mime := "application/pdf"
+	width := 0
+	cases := map[string]*RegisterImageInput{
+		"missing path": {StoragePath: "  "},
+		"bad url":      {StoragePath: "wo.jpg", PublicURL: &url},
+		"not an image": {StoragePath: "wo.jpg", MimeType: &mime},
+		"zero width":   {StoragePath: "wo.jpg", WidthPx: &width},
+		"bad status":   {StoragePath: "wo.jpg", Status: ImageScanCompleted},
+	}
+	for name, in := range cases {
+		t.Run(name, func(t *testing.T) {
+			repo := newFakeRepo()
+			repo.workOrders[woID] = uuid.New()
+			_, err := NewService(repo, nil).RegisterImage(context.Background(), apiKey(), woID, in)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+			assert.Empty(t, repo.images)
+		})
+	}
+}
+
+func TestRegisterImageUnknownWorkOrder(t *testing.T) {
+	svc := NewService(newFakeRepo(), nil)
+	_, err := svc.RegisterImage(context.Background(), apiKey(), uuid.New(), &RegisterImageInput{StoragePath: "wo.jpg"})
+	assert.ErrorIs(t, err, ErrWorkOrderNotFound)
+}
+
+/* ---------- RecordResult ---------- */
+
+func TestRecordResultOnlyForAPIKeys(t *testing.T) {
+	repo := newFakeRepo()
+	svc := NewService(repo, nil)
+	in := &ResultInput{ImageID: uuid.New(), Status: ResultFailed}
+
+	_, err := svc.RecordResult(context.Background(), &auth.AuthUser{ID: uuid.New()}, uuid.New(), in)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.Empty(t, repo.results)
+
+	job, err := svc.RecordResult(context.Background(), apiKey(), uuid.New(), in)
+	require.NoError(t, err)
+	assert.Equal(t, 1, job.SuccessImages)
+	assert.Len(t, repo.results, 1)
+}
+
+func TestRecordResultValidation(t *testing.T) {
+	conf := 1.5
+	raw := json.RawMessage(`{"boxes":[]}`)
+	cases := map[string]*ResultInput{
+		"missing image":         {Status: ResultSuccess, Raw: raw},
+		"bad status":            {ImageID: uuid.New(), Status: "done", Raw: raw},
+		"success without raw":   {ImageID: uuid.New(), Status: ResultSuccess},
+		"raw not json":          {ImageID: uuid.New(), Status: ResultSuccess, Raw: json.RawMessage(`{`)},
+		"failed with findings":  {ImageID: uuid.New(), Status: ResultFailed, Detections: []DetectionInput{{Category: "dent"}}},
+		"detection no category": {ImageID: uuid.New(), Status: ResultSuccess, Raw: raw, Detections: []DetectionInput{{}}},
+		"confidence over 1":     {ImageID: uuid.New(), Status: ResultSuccess, Raw: raw, Detections: []DetectionInput{{Category: "dent", Confidence: &conf}}},
+	}
+	for name, in := range cases {
+		t.Run(name, func(t *testing.T) {
+			repo := newFakeRepo()
+			_, err := NewService(repo, nil).RecordResult(context.Background(), apiKey(), uuid.New(), in)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+			assert.Empty(t, repo.results)
+		})
+	}
+}
@@ -10,6 +10,7 @@ var (
 	ErrNotFound     = errors.New("shop not found")
 	ErrConflict     = errors.New("shop already exists")
 	ErrInvalidInput = errors.New("invalid shop input")
+	ErrForbidden    = errors.New("forbidden: insufficient permissions for this shop")
 )
 
 // ValidationError represents an error due to invalid input data.
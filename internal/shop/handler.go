@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -28,33 +30,60 @@ func NewHandler(svc ShopService) *Handler { return &Handler{svc: svc} }
 // Endpoints (UUID-based resource paths):
 //
 //	POST /shops          -> create a shop
-//	GET  /shops          -> list shops (supports ?limit=&offset=)
+//	GET  /shops          -> list shops (supports ?status=&province=&city=&q=&limit=&offset=)
+//	GET  /shops/by-code/{code} -> get a shop by code
 //	GET  /shops/{id}     -> get a shop by ID
 //	PUT  /shops/{id}     -> update a shop by ID
+//	GET  /shops/{id}/settings       -> get the shop's profile settings
+//	PUT  /shops/{id}/settings       -> replace the shop's profile settings
+//	GET  /shops/{id}/settings/logo  -> download the logo
+//	PUT  /shops/{id}/settings/logo  -> upload a logo (raw PNG/JPEG body)
+//	DELETE /shops/{id}/settings/logo -> remove the logo
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Post("/", h.create)
 	r.Get("/", h.list)
+	r.Get("/by-code/{code}", h.getByCode)
 	r.Get("/{id}", h.getByID)
 	r.Put("/{id}", h.update)
+	r.Get("/{id}/settings", h.getSettings)
+	r.Put("/{id}/settings", h.updateSettings)
+	r.Get("/{id}/settings/logo", h.getLogo)
+	r.Put("/{id}/settings/logo", h.putLogo)
+	r.Delete("/{id}/settings/logo", h.deleteLogo)
+}
+
+// RegisterOptionRoutes mounts the endpoints open to every authenticated user:
+//
+//	GET /shops/options -> id/code/name of active shops (?includeInactive=true for shop managers)
+//
+// The caller mounts these outside the shops.manage check.
+func (h *Handler) RegisterOptionRoutes(r chi.Router) {
+	r.Get("/options", h.options)
 }
 
 // create handles POST /shops.
 // - Decodes the JSON payload into a Shop.
 // - Delegates creation to the service (which fills fields like ID).
-// - Returns 201 with Location header pointing to /shops/{code}.
+// - Returns 201 with Location header pointing to /shops/by-code/{code}.
 func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	var s Shop
 	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
 		httpError(w, http.StatusBadRequest, "invalid json")
 		return
 	}
-	if err := h.svc.CreateShop(r.Context(), &s); err != nil {
-		writeError(w, err)
+	if err := h.svc.CreateShop(r.Context(), actor, &s); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	// Since users navigate by code, expose the resource URL by code.
-	w.Header().Set("Location", fmt.Sprintf("/shops/%s", s.Code))
+	w.Header().Set("Location", fmt.Sprintf("/shops/by-code/%s", s.Code))
 	writeJSON(w, http.StatusCreated, &s)
 }
 
@@ -63,37 +92,86 @@ func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
 // - Delegates fetching to the service.
 // - Returns 200 with the shop on success.
 func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, ErrInvalidInput)
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	out, err := h.svc.GetShopByID(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// getByCode handles GET /shops/by-code/{code}.
+// - Delegates fetching (and code normalization) to the service.
+// - Returns 200 with the shop on success.
+func (h *Handler) getByCode(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	out, err := h.svc.GetShopByID(r.Context(), id)
+	out, err := h.svc.GetShopByCode(r.Context(), actor, chi.URLParam(r, "code"))
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, out)
 }
 
-// list handles GET /shops?limit=&offset=.
-// - Parses pagination params with safe defaults.
-// - Delegates listing to the service.
-// - Returns 200 with an array of shops.
+// list handles GET /shops?status=&province=&city=&q=&limit=&offset=.
+// - Parses filters and pagination params with safe defaults.
+// - Delegates filtering and listing to the service.
+// - Returns 200 with {items, total, limit, offset}.
 func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
-	limit := atoiDefault(r.URL.Query().Get("limit"), 50)
-	offset := atoiDefault(r.URL.Query().Get("offset"), 0)
-	if limit <= 0 {
-		limit = 50
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
-	if offset < 0 {
-		offset = 0
+
+	q := r.URL.Query()
+	f := ListFilter{
+		Status:   Status(q.Get("status")),
+		Province: q.Get("province"),
+		City:     q.Get("city"),
+		Query:    q.Get("q"),
+		Limit:    atoiDefault(q.Get("limit"), 50),
+		Offset:   atoiDefault(q.Get("offset"), 0),
 	}
-	out, err := h.svc.ListShops(r.Context(), limit, offset)
+	out, err := h.svc.ListShops(r.Context(), actor, f)
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// options handles GET /shops/options?includeInactive=.
+// - Returns 200 with a (possibly empty) array of {id, code, shopName, status}.
+func (h *Handler) options(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	includeInactive, _ := strconv.ParseBool(r.URL.Query().Get("includeInactive"))
+	out, err := h.svc.ListShopOptions(r.Context(), actor, includeInactive)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, out)
@@ -105,10 +183,16 @@ func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
 // - Delegates updating to the service.
 // - Returns 200 with the updated shop.
 func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, ErrInvalidInput)
+		writeError(w, r, ErrInvalidInput)
 		return
 	}
 
@@ -118,14 +202,141 @@ func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	out, err := h.svc.UpdateShop(r.Context(), id, &in)
+	out, err := h.svc.UpdateShop(r.Context(), actor, id, &in)
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, out)
 }
 
+// getSettings handles GET /shops/{id}/settings.
+func (h *Handler) getSettings(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	out, err := h.svc.GetSettings(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// updateSettings handles PUT /shops/{id}/settings.
+// - The body replaces all settings except the logo.
+func (h *Handler) updateSettings(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	var in Settings
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	out, err := h.svc.UpdateSettings(r.Context(), actor, id, &in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// getLogo handles GET /shops/{id}/settings/logo.
+func (h *Handler) getLogo(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	logo, err := h.svc.GetLogo(r.Context(), actor, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", logo.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(logo.Data)))
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(logo.Data)
+}
+
+// putLogo handles PUT /shops/{id}/settings/logo.
+// - The body is the image itself; its type is detected from the bytes.
+// - Returns 204 on success.
+func (h *Handler) putLogo(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxLogoBytes+1))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpError(w, http.StatusRequestEntityTooLarge, "logo too large")
+			return
+		}
+		httpError(w, http.StatusBadRequest, "could not read body")
+		return
+	}
+
+	if err := h.svc.SetLogo(r.Context(), actor, id, data); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteLogo handles DELETE /shops/{id}/settings/logo.
+func (h *Handler) deleteLogo(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	if err := h.svc.SetLogo(r.Context(), actor, id, nil); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // ---- helpers ----
 
 // atoiDefault parses an int or returns a default value if parsing fails or s is empty.
@@ -156,22 +367,29 @@ func httpError(w http.ResponseWriter, status int, msg string) {
 }
 
 // writeError maps domain-level errors to HTTP status codes.
+// - ValidationError → 400 (with the field message)
 // - ErrInvalidInput → 400
 // - ErrConflict     → 409
 // - ErrNotFound     → 404
+// - ErrForbidden    → 403
 // - others          → 500
-func writeError(w http.ResponseWriter, err error) {
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
 	// Log the error for server-side diagnostics
-	log.Printf("[ERROR] %v", err)
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
 
 	// Map domain errors to HTTP status codes
+	var ve *ValidationError
 	switch {
+	case errors.As(err, &ve):
+		httpError(w, http.StatusBadRequest, ve.Error())
 	case errors.Is(err, ErrInvalidInput):
 		httpError(w, http.StatusBadRequest, "invalid input")
 	case errors.Is(err, ErrConflict):
 		httpError(w, http.StatusConflict, "conflict")
 	case errors.Is(err, ErrNotFound):
 		httpError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, ErrForbidden):
+		httpError(w, http.StatusForbidden, "forbidden")
 	default:
 		httpError(w, http.StatusInternalServerError, "internal error")
 	}
@@ -45,17 +45,95 @@ const (
 )
 
 type Shop struct {
-	ID          uuid.UUID `json:"id"`
-	Code        string    `json:"code"`
-	ShopName    string    `json:"shopName"`
-	Status      Status    `json:"status"`
-	Address     string    `json:"address"`
-	City        string    `json:"city"`
-	Province    string    `json:"province"`
-	PostalCode  string    `json:"postalCode"`
-	ContactName string    `json:"contactName"`
-	Phone       string    `json:"phone"`
-	Email       string    `json:"email"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID uuid.UUID `json:"id"`
+	// OrganizationID is the owning organization. It is set on create (from the
+	// request's organization scope unless a SuperAdmin names one) and never
+	// changes afterwards.
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Code           string    `json:"code"`
+	ShopName       string    `json:"shopName"`
+	Status         Status    `json:"status"`
+	Address        string    `json:"address"`
+	City           string    `json:"city"`
+	Province       string    `json:"province"`
+	PostalCode     string    `json:"postalCode"`
+	ContactName    string    `json:"contactName"`
+	Phone          string    `json:"phone"`
+	Email          string    `json:"email"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// ListFilter narrows GET /shops. Zero values mean "no restriction".
+type ListFilter struct {
+	// OrganizationID is set by the repository from the request's organization scope.
+	OrganizationID *uuid.UUID
+
+	Status   Status // active or inactive
+	Province string // two-letter code, e.g. AB
+	City     string // exact match, case-insensitive
+	Query    string // matches shop name or code
+	Limit    int
+	Offset   int
+}
+
+// ShopPage is one page of a filtered shop list plus the total match count.
+type ShopPage struct {
+	Items  []*Shop `json:"items"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+// Option is the minimal shop reference used to fill dropdowns.
+type Option struct {
+	ID       uuid.UUID `json:"id"`
+	Code     string    `json:"code"`
+	ShopName string    `json:"shopName"`
+	Status   Status    `json:"status"`
+}
+
+// BusinessHours is one day's opening hours ("08:00"–"17:30", 24-hour clock).
+// Days without an entry are closed.
+type BusinessHours struct {
+	Day   string `json:"day"` // mon, tue, wed, thu, fri, sat, sun
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// Hours is a shop's weekly schedule.
+type Hours []BusinessHours
+
+// Holiday is a date the shop is closed.
+type Holiday struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Name string `json:"name"`
+}
+
+// LabourRates are hourly rates in cents; nil means no default for that category.
+type LabourRates struct {
+	PDRCents   *int64 `json:"pdrCents"`
+	RAndICents *int64 `json:"rAndICents"`
+	PaintCents *int64 `json:"paintCents"`
+}
+
+// Settings ↔ app.shop_settings. A shop without a row has empty settings.
+type Settings struct {
+	ShopID        uuid.UUID   `json:"shopId"`
+	BusinessHours Hours       `json:"businessHours"`
+	Holidays      []Holiday   `json:"holidays"`
+	LabourRates   LabourRates `json:"labourRates"`
+	// TaxProvince overrides the shop's address province for sales tax.
+	TaxProvince   *string    `json:"taxProvince"`
+	InvoiceFooter *string    `json:"invoiceFooter"`
+	HasLogo       bool       `json:"hasLogo"` // set via PUT /shops/{id}/settings/logo
+	LogoUpdatedAt *time.Time `json:"logoUpdatedAt,omitempty"`
+	UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
+}
+
+// Logo is the shop's uploaded logo image.
+type Logo struct {
+	Data        []byte
+	ContentType string // image/png or image/jpeg
+	UpdatedAt   time.Time
 }
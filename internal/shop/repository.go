@@ -6,7 +6,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
@@ -21,7 +24,19 @@ type Repository interface {
 	GetShopByID(ctx context.Context, id uuid.UUID) (*Shop, error)
 	GetShopIDByCode(ctx context.Context, code string) (uuid.UUID, error)
 	UpdateShop(ctx context.Context, id uuid.UUID, shop *Shop) (*Shop, error)
-	ListShops(ctx context.Context, limit, offset int) ([]*Shop, error)
+	GetShopByCode(ctx context.Context, code string) (*Shop, error)
+	// ListShops returns one page of shops matching f and the total number of matches.
+	ListShops(ctx context.Context, f ListFilter) ([]*Shop, int, error)
+	// ListOptions returns shops ordered by name; inactive shops only if asked for.
+	ListOptions(ctx context.Context, includeInactive bool) ([]Option, error)
+
+	// GetSettings returns empty settings for a shop that has never saved any.
+	GetSettings(ctx context.Context, shopID uuid.UUID) (*Settings, error)
+	UpsertSettings(ctx context.Context, s *Settings, byUserID uuid.UUID) (*Settings, error)
+	// GetLogo returns ErrNotFound when the shop has no logo.
+	GetLogo(ctx context.Context, shopID uuid.UUID) (*Logo, error)
+	// SetLogo replaces the logo; nil removes it.
+	SetLogo(ctx context.Context, shopID uuid.UUID, logo *Logo, byUserID uuid.UUID) error
 }
 
 // PGRepository is a Postgres implementation of Repository using pgxpool.
@@ -36,20 +51,20 @@ func NewShopRepository(db *pgxpool.Pool) *PGRepository {
 
 // Implementing the Repository interface methods
 func (r *PGRepository) CreateShop(ctx context.Context, shop *Shop) error {
-	const q = `INSERT INTO app.shop (code, shop_name, status, address, city, province, postal_code, contact_name, phone, email)
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+	const q = `INSERT INTO app.shop (code, shop_name, status, address, city, province, postal_code, contact_name, phone, email, organization_id)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
 RETURNING id, code, shop_name;`
 	if err := r.db.QueryRow(ctx, q,
 		shop.Code, shop.ShopName, shop.Status, shop.Address,
 		shop.City, shop.Province, shop.PostalCode,
-		shop.ContactName, shop.Phone, shop.Email,
+		shop.ContactName, shop.Phone, shop.Email, shop.OrganizationID,
 	).Scan(&shop.ID, &shop.Code, &shop.ShopName); err != nil {
 		var pe *pgconn.PgError
 		if errors.As(err, &pe) {
 			switch pe.Code {
 			case pgerrcode.UniqueViolation:
 				return ErrConflict
-			case pgerrcode.NotNullViolation, pgerrcode.CheckViolation:
+			case pgerrcode.NotNullViolation, pgerrcode.CheckViolation, pgerrcode.ForeignKeyViolation:
 				return ErrInvalidInput
 			}
 		}
@@ -59,12 +74,12 @@ RETURNING id, code, shop_name;`
 }
 
 func (r *PGRepository) GetShopByID(ctx context.Context, id uuid.UUID) (*Shop, error) {
-	const q = `
-SELECT id, code, shop_name, status, address, city, province, postal_code, contact_name, phone, email, created_at, updated_at
+	q := `
+SELECT id, organization_id, code, shop_name, status, address, city, province, postal_code, contact_name, phone, email, created_at, updated_at
 FROM app.shop
-WHERE id=$1;`
+WHERE id=$1` + inOrg(2) + `;`
 	var s Shop
-	if err := r.db.QueryRow(ctx, q, id).Scan(&s.ID, &s.Code, &s.ShopName, &s.Status, &s.Address, &s.City, &s.Province, &s.PostalCode,
+	if err := r.db.QueryRow(ctx, q, id, auth.OrganizationScope(ctx)).Scan(&s.ID, &s.OrganizationID, &s.Code, &s.ShopName, &s.Status, &s.Address, &s.City, &s.Province, &s.PostalCode,
 		&s.ContactName, &s.Phone, &s.Email, &s.CreatedAt, &s.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -75,27 +90,114 @@ WHERE id=$1;`
 }
 
 func (r *PGRepository) GetShopIDByCode(ctx context.Context, code string) (uuid.UUID, error) {
-	var id uuid.UUID
-	err := r.db.QueryRow(ctx,
-		"SELECT id FROM app.shop WHERE code = $1", code).Scan(&id)
+	s, err := r.GetShopByCode(ctx, code)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return uuid.Nil, ErrNotFound
+		return uuid.Nil, err
+	}
+	return s.ID, nil
+}
+
+// GetShopByCode looks the code up within the request's organization. Codes are
+// only unique per organization, so an unscoped lookup (SuperAdmin without
+// auth.OrganizationHeader) that matches more than one shop is rejected.
+func (r *PGRepository) GetShopByCode(ctx context.Context, code string) (*Shop, error) {
+	q := `
+SELECT id, organization_id, code, shop_name, status, address, city, province, postal_code, contact_name, phone, email, created_at, updated_at
+FROM app.shop
+WHERE code=$1` + inOrg(2) + `
+LIMIT 2;`
+	rows, err := r.db.Query(ctx, q, code, auth.OrganizationScope(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the shop by code: %w", err)
+	}
+	defer rows.Close()
+
+	var found []*Shop
+	for rows.Next() {
+		var s Shop
+		if err := rows.Scan(&s.ID, &s.OrganizationID, &s.Code, &s.ShopName, &s.Status, &s.Address, &s.City, &s.Province, &s.PostalCode,
+			&s.ContactName, &s.Phone, &s.Email, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan shop row: %w", err)
 		}
-		return uuid.Nil, fmt.Errorf("failed to get the shop id by code: %w", err)
+		found = append(found, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get the shop by code: %w", err)
+	}
+	switch len(found) {
+	case 0:
+		return nil, ErrNotFound
+	case 1:
+		return found[0], nil
+	default:
+		return nil, NewValidationError("code", "shop code exists in several organizations; choose one with the "+auth.OrganizationHeader+" header")
 	}
-	return id, nil
 }
 
-func (r *PGRepository) ListShops(ctx context.Context, limit, offset int) ([]*Shop, error) {
-	const q = `
-SELECT id, code, shop_name, status, address, city, province, postal_code, contact_name, phone, email, created_at, updated_at
+// inOrg restricts a query on app.shop to the request's organization
+// (auth.OrganizationScope); n is the parameter number. A NULL scope matches
+// every shop.
+func inOrg(n int) string {
+	return fmt.Sprintf(" AND ($%[1]d::uuid IS NULL OR organization_id = $%[1]d)", n)
+}
+
+// listFilterClause builds the WHERE clause shared by the list and count queries.
+func listFilterClause(f ListFilter) (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.OrganizationID != nil {
+		conds = append(conds, "organization_id = "+arg(*f.OrganizationID))
+	}
+	if f.Status != "" {
+		conds = append(conds, "status = "+arg(f.Status))
+	}
+	if f.Province != "" {
+		conds = append(conds, "province = "+arg(f.Province))
+	}
+	if f.City != "" {
+		conds = append(conds, "lower(city) = lower("+arg(f.City)+")")
+	}
+	if f.Query != "" {
+		p := arg("%" + escapeLike(f.Query) + "%")
+		conds = append(conds, "(shop_name ILIKE "+p+" OR code ILIKE "+p+")")
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *PGRepository) ListShops(ctx context.Context, f ListFilter) ([]*Shop, int, error) {
+	f.OrganizationID = auth.OrganizationScope(ctx)
+	where, args := listFilterClause(f)
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT count(*) FROM app.shop `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count shops: %w", err)
+	}
+
+	n := len(args)
+	q := fmt.Sprintf(`
+SELECT id, organization_id, code, shop_name, status, address, city, province, postal_code, contact_name, phone, email, created_at, updated_at
 FROM app.shop
+%s
 ORDER BY created_at DESC
-LIMIT $1 OFFSET $2;`
-	rows, err := r.db.Query(ctx, q, limit, offset)
+LIMIT $%d OFFSET $%d;`, where, n+1, n+2)
+	rows, err := r.db.Query(ctx, q, append(args, f.Limit, f.Offset)...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute list shop query: %w", err)
+		return nil, 0, fmt.Errorf("failed to execute list shop query: %w", err)
 	}
 	defer rows.Close()
 
@@ -104,16 +206,42 @@ LIMIT $1 OFFSET $2;`
 
 	for rows.Next() {
 		shop := new(Shop)
-		if err := rows.Scan(&shop.ID, &shop.Code, &shop.ShopName, &shop.Status, &shop.Address, &shop.City, &shop.Province, &shop.PostalCode, &shop.ContactName, &shop.Phone, &shop.Email, &shop.CreatedAt, &shop.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan shop row: %w", err)
+		if err := rows.Scan(&shop.ID, &shop.OrganizationID, &shop.Code, &shop.ShopName, &shop.Status, &shop.Address, &shop.City, &shop.Province, &shop.PostalCode, &shop.ContactName, &shop.Phone, &shop.Email, &shop.CreatedAt, &shop.UpdatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan shop row: %w", err)
 		}
 		shops = append(shops, shop)
 	}
 	err = rows.Err()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list shops: %w", err)
+		return nil, 0, fmt.Errorf("failed to list shops: %w", err)
+	}
+	return shops, total, nil
+}
+
+func (r *PGRepository) ListOptions(ctx context.Context, includeInactive bool) ([]Option, error) {
+	q := `
+SELECT id, code, shop_name, status
+FROM app.shop
+WHERE ($1 OR status = 'active')` + inOrg(2) + `
+ORDER BY shop_name, code;`
+	rows, err := r.db.Query(ctx, q, includeInactive, auth.OrganizationScope(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shop options: %w", err)
+	}
+	defer rows.Close()
+
+	opts := make([]Option, 0)
+	for rows.Next() {
+		var o Option
+		if err := rows.Scan(&o.ID, &o.Code, &o.ShopName, &o.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan shop option: %w", err)
+		}
+		opts = append(opts, o)
 	}
-	return shops, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list shop options: %w", err)
+	}
+	return opts, nil
 }
 
 func (r *PGRepository) UpdateShop(ctx context.Context, id uuid.UUID, s *Shop) (*Shop, error) {
@@ -123,20 +251,20 @@ func (r *PGRepository) UpdateShop(ctx context.Context, id uuid.UUID, s *Shop) (*
 	if id == uuid.Nil {
 		return nil, ErrInvalidInput
 	}
-	const q = `
+	q := `
 UPDATE app.shop
 SET code=$2, shop_name=$3, status=$4, address=$5, city=$6, province=$7, postal_code=$8,
     contact_name=$9, phone=$10, email=$11
-WHERE id=$1
-RETURNING id, code, shop_name, status, address, city, province, postal_code,
+WHERE id=$1` + inOrg(12) + `
+RETURNING id, organization_id, code, shop_name, status, address, city, province, postal_code,
           contact_name, phone, email, created_at, updated_at;`
 	row := r.db.QueryRow(ctx, q,
 		id, s.Code, s.ShopName, s.Status, s.Address, s.City, s.Province, s.PostalCode,
-		s.ContactName, s.Phone, s.Email,
+		s.ContactName, s.Phone, s.Email, auth.OrganizationScope(ctx),
 	)
 	var updatedShop Shop
 	if err := row.Scan(
-		&updatedShop.ID, &updatedShop.Code, &updatedShop.ShopName, &updatedShop.Status, &updatedShop.Address, &updatedShop.City, &updatedShop.Province, &updatedShop.PostalCode,
+		&updatedShop.ID, &updatedShop.OrganizationID, &updatedShop.Code, &updatedShop.ShopName, &updatedShop.Status, &updatedShop.Address, &updatedShop.City, &updatedShop.Province, &updatedShop.PostalCode,
 		&updatedShop.ContactName, &updatedShop.Phone, &updatedShop.Email, &updatedShop.CreatedAt, &updatedShop.UpdatedAt,
 	); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -155,3 +283,100 @@ RETURNING id, code, shop_name, status, address, city, province, postal_code,
 	}
 	return &updatedShop, nil
 }
+
+// ---- settings ----
+
+const settingsColumns = `business_hours, holidays, pdr_rate_cents, r_and_i_rate_cents, paint_rate_cents,
+       tax_province, invoice_footer, logo IS NOT NULL, logo_updated_at, updated_at`
+
+func scanSettings(row pgx.Row, shopID uuid.UUID) (*Settings, error) {
+	st := Settings{ShopID: shopID}
+	var updatedAt time.Time
+	if err := row.Scan(
+		&st.BusinessHours, &st.Holidays, &st.LabourRates.PDRCents, &st.LabourRates.RAndICents, &st.LabourRates.PaintCents,
+		&st.TaxProvince, &st.InvoiceFooter, &st.HasLogo, &st.LogoUpdatedAt, &updatedAt,
+	); err != nil {
+		return nil, err
+	}
+	st.UpdatedAt = &updatedAt
+	return &st, nil
+}
+
+func (r *PGRepository) GetSettings(ctx context.Context, shopID uuid.UUID) (*Settings, error) {
+	st, err := scanSettings(r.db.QueryRow(ctx, `SELECT `+settingsColumns+` FROM app.shop_settings WHERE shop_id = $1`, shopID), shopID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &Settings{ShopID: shopID, BusinessHours: Hours{}, Holidays: []Holiday{}}, nil
+		}
+		return nil, fmt.Errorf("failed to get shop settings: %w", err)
+	}
+	return st, nil
+}
+
+func (r *PGRepository) UpsertSettings(ctx context.Context, s *Settings, byUserID uuid.UUID) (*Settings, error) {
+	const q = `
+INSERT INTO app.shop_settings (shop_id, business_hours, holidays, pdr_rate_cents, r_and_i_rate_cents, paint_rate_cents,
+                               tax_province, invoice_footer, updated_by)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+ON CONFLICT (shop_id) DO UPDATE
+SET business_hours = EXCLUDED.business_hours, holidays = EXCLUDED.holidays,
+    pdr_rate_cents = EXCLUDED.pdr_rate_cents, r_and_i_rate_cents = EXCLUDED.r_and_i_rate_cents,
+    paint_rate_cents = EXCLUDED.paint_rate_cents, tax_province = EXCLUDED.tax_province,
+    invoice_footer = EXCLUDED.invoice_footer, updated_by = EXCLUDED.updated_by
+RETURNING ` + settingsColumns + `;`
+	st, err := scanSettings(r.db.QueryRow(ctx, q,
+		s.ShopID, s.BusinessHours, s.Holidays, s.LabourRates.PDRCents, s.LabourRates.RAndICents, s.LabourRates.PaintCents,
+		s.TaxProvince, s.InvoiceFooter, byUserID,
+	), s.ShopID)
+	if err != nil {
+		var pe *pgconn.PgError
+		if errors.As(err, &pe) {
+			switch pe.Code {
+			case pgerrcode.ForeignKeyViolation:
+				return nil, ErrNotFound
+			case pgerrcode.CheckViolation:
+				return nil, ErrInvalidInput
+			}
+		}
+		return nil, fmt.Errorf("failed to save shop settings: %w", err)
+	}
+	return st, nil
+}
+
+func (r *PGRepository) GetLogo(ctx context.Context, shopID uuid.UUID) (*Logo, error) {
+	var l Logo
+	err := r.db.QueryRow(ctx, `
+SELECT logo, logo_content_type, logo_updated_at
+FROM app.shop_settings
+WHERE shop_id = $1 AND logo IS NOT NULL`, shopID).Scan(&l.Data, &l.ContentType, &l.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get shop logo: %w", err)
+	}
+	return &l, nil
+}
+
+func (r *PGRepository) SetLogo(ctx context.Context, shopID uuid.UUID, logo *Logo, byUserID uuid.UUID) error {
+	var data []byte
+	var contentType *string
+	if logo != nil {
+		data, contentType = logo.Data, &logo.ContentType
+	}
+	_, err := r.db.Exec(ctx, `
+INSERT INTO app.shop_settings (shop_id, logo, logo_content_type, logo_updated_at, updated_by)
+VALUES ($1, $2, $3, now(), $4)
+ON CONFLICT (shop_id) DO UPDATE
+SET logo = EXCLUDED.logo, logo_content_type = EXCLUDED.logo_content_type,
+    logo_updated_at = EXCLUDED.logo_updated_at, updated_by = EXCLUDED.updated_by;`,
+		shopID, data, contentType, byUserID)
+	if err != nil {
+		var pe *pgconn.PgError
+		if errors.As(err, &pe) && pe.Code == pgerrcode.ForeignKeyViolation {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to set shop logo: %w", err)
+	}
+	return nil
+}
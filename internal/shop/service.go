@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
 	"github.com/google/uuid"
 )
 
@@ -28,51 +30,110 @@ var (
 // ShopService defines business operations for the Shop domain.
 // The service coordinates domain logic and delegates persistence to Repository.
 // It should not know anything about HTTP, SQL, or other infrastructure details.
+//
+// Methods that serve HTTP requests take the acting user:
+//   - SuperAdmin: create, view and update any shop, including code and status
+//   - shops.manage holders (Admin): view any shop, update only their own shop,
+//     and never its code or status
+//
+// Other callers get ErrForbidden.
 type ShopService interface {
 
-	// CreateShop creates a new shop. On success, the input *Shop is updated in-place
-	// with generated values (e.g., ID) by the repository. Returns a domain error
-	// such as ErrInvalidInput or ErrConflict where appropriate.
-	CreateShop(ctx context.Context, shop *Shop) error
+	// CreateShop creates a new shop (SuperAdmin only). On success, the input *Shop
+	// is updated in-place with generated values (e.g., ID) by the repository.
+	// Returns a domain error such as ErrInvalidInput or ErrConflict where appropriate.
+	CreateShop(ctx context.Context, actor *auth.AuthUser, shop *Shop) error
 
 	// GetShopByID fetches a shop by its unique ID.
 	// Returns (*Shop, nil) on success, or ErrNotFound if no record exists.
-	GetShopByID(ctx context.Context, id uuid.UUID) (*Shop, error)
+	GetShopByID(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Shop, error)
 
 	// GetShopIDByCode fetches a shop's ID by its unique code.
 	// Returns (uuid.UUID, nil) on success, or ErrNotFound if no record exists.
+	// It is an internal lookup for other services (which do their own RBAC),
+	// so it takes no actor.
 	GetShopIDByCode(ctx context.Context, code string) (uuid.UUID, error)
 
 	// UpdateShop updates an existing shop identified by its ID (already set on s).
 	// Returns the freshly-updated copy from the database (useful for refreshing UI).
-	UpdateShop(ctx context.Context, id uuid.UUID, shop *Shop) (*Shop, error)
-
-	// ListShops returns a page of shops. The service applies safe defaults for
-	// limit/offset if the caller passes invalid values. An empty slice with nil error
-	// means “no rows for this page”, not an error.
-	ListShops(ctx context.Context, limit, offset int) ([]*Shop, error)
+	UpdateShop(ctx context.Context, actor *auth.AuthUser, id uuid.UUID, shop *Shop) (*Shop, error)
+
+	// GetShopByCode fetches a shop by its code (case-insensitive), with the same
+	// access rules as GetShopByID. Returns ErrNotFound if no record exists.
+	GetShopByCode(ctx context.Context, actor *auth.AuthUser, code string) (*Shop, error)
+
+	// ListShops returns a page of shops matching the filter, with the total
+	// match count. The service applies safe defaults for limit/offset if the
+	// caller passes invalid values. An empty page with nil error means “no rows
+	// for this page”, not an error.
+	ListShops(ctx context.Context, actor *auth.AuthUser, f ListFilter) (*ShopPage, error)
+
+	// ListShopOptions returns id/code/name for every active shop, for dropdowns.
+	// Any authenticated user may call it; includeInactive is honoured only for
+	// shops.manage holders.
+	ListShopOptions(ctx context.Context, actor *auth.AuthUser, includeInactive bool) ([]Option, error)
+
+	// GetSettings and UpdateSettings read and replace a shop's profile settings
+	// (hours, holidays, labour rates, tax province, invoice footer). They follow
+	// the same rules as UpdateShop: SuperAdmin any shop, Admin own shop only.
+	GetSettings(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID) (*Settings, error)
+	UpdateSettings(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID, in *Settings) (*Settings, error)
+
+	// GetLogo returns the shop's logo, or ErrNotFound if it has none.
+	GetLogo(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID) (*Logo, error)
+	// SetLogo validates and stores a PNG or JPEG logo; nil data removes it.
+	SetLogo(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID, data []byte) error
 }
 
 // service is the concrete implementation of ShopService.
 // It depends only on the Repository interface (infrastructure-agnostic).
 type service struct {
-	repo Repository
+	repo  Repository
+	audit audit.Recorder
 }
 
-// NewService constructs a Shop service that uses the given Repository.
+// NewService constructs a Shop service that uses the given Repository and
+// reports changes to rec (nil records nothing).
 // Typical wiring (in your server/app layer):
 //
-//	repo := shop.NewShopRepository(db)  // concrete PG repository
-//	svc  := shop.NewService(repo)       // service depends on the interface
-func NewService(repo Repository) *service {
-	return &service{repo: repo}
+//	repo := shop.NewShopRepository(db)      // concrete PG repository
+//	svc  := shop.NewService(repo, auditSvc) // service depends on the interface
+func NewService(repo Repository, rec audit.Recorder) *service {
+	return &service{repo: repo, audit: audit.OrNop(rec)}
 }
 
 // CreateShop performs normalization and validation before delegating to the repository.
 // The repository fills in generated fields via the pointer (e.g., shop.ID).
 // Errors are wrapped with context ("service create shop") so logs show WHERE
 // failures occurred; the original error is preserved with %w for errors.Is/As.
-func (svc *service) CreateShop(ctx context.Context, s *Shop) error {
+//
+// The shop goes into the actor's organization. SuperAdmin may name one in
+// s.OrganizationID, otherwise the request's organization scope is used.
+func (svc *service) CreateShop(ctx context.Context, actor *auth.AuthUser, s *Shop) error {
+	if actor == nil {
+		return ErrForbidden
+	}
+	if !actor.IsSuperAdmin() && !(actor.IsOrgWide() && actor.Can(auth.PermShopsManage)) {
+		return ErrForbidden
+	}
+	if s == nil {
+		return ErrInvalidInput
+	}
+
+	switch {
+	case !actor.IsSuperAdmin():
+		if actor.OrganizationID == nil {
+			return ErrForbidden
+		}
+		s.OrganizationID = *actor.OrganizationID
+	case s.OrganizationID == uuid.Nil:
+		org := auth.OrganizationScope(ctx)
+		if org == nil {
+			return NewValidationError("organizationId", "is required (or set the "+auth.OrganizationHeader+" header)")
+		}
+		s.OrganizationID = *org
+	}
+
 	normalizeShop(s)
 
 	if err := validateShop(s); err != nil {
@@ -82,6 +143,10 @@ func (svc *service) CreateShop(ctx context.Context, s *Shop) error {
 	if err := svc.repo.CreateShop(ctx, s); err != nil {
 		return fmt.Errorf("service create shop: %w", err)
 	}
+	svc.audit.Record(ctx, audit.Entry{
+		Action: "shop.create", EntityType: audit.EntityShop, EntityID: s.ID.String(),
+		ShopID: &s.ID, OrganizationID: &s.OrganizationID, After: s,
+	})
 	return nil
 }
 
@@ -98,7 +163,10 @@ func (s *service) GetShopIDByCode(ctx context.Context, code string) (uuid.UUID,
 
 // GetShopByID validates the input ID and delegates to the repository.
 // Returns ErrInvalidInput if the ID is nil.
-func (s *service) GetShopByID(ctx context.Context, id uuid.UUID) (*Shop, error) {
+func (s *service) GetShopByID(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) (*Shop, error) {
+	if !canViewShops(actor) {
+		return nil, ErrForbidden
+	}
 	if id == uuid.Nil {
 		return nil, NewValidationError("id", "invalid shop ID")
 	}
@@ -111,28 +179,85 @@ func (s *service) GetShopByID(ctx context.Context, id uuid.UUID) (*Shop, error)
 	return shop, nil
 }
 
-// ListShops applies defensive defaults for pagination and delegates to the repository.
-// Empty results are returned as an empty slice and nil error (not ErrNotFound).
-func (svc *service) ListShops(ctx context.Context, limit, offset int) ([]*Shop, error) {
-	if limit <= 0 {
-		limit = 50
+// GetShopByCode normalizes the code the same way CreateShop does and delegates
+// to the repository.
+func (svc *service) GetShopByCode(ctx context.Context, actor *auth.AuthUser, code string) (*Shop, error) {
+	if !canViewShops(actor) {
+		return nil, ErrForbidden
 	}
-	if offset < 0 {
-		offset = 0
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, NewValidationError("code", "is required")
 	}
-	shops, err := svc.repo.ListShops(ctx, limit, offset)
+
+	shop, err := svc.repo.GetShopByCode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("service get shop by code: %w", err)
+	}
+	return shop, nil
+}
+
+// maxListLimit caps the page size of ListShops.
+const maxListLimit = 200
+
+// ListShops validates the filter, applies defensive defaults for pagination
+// and delegates to the repository.
+// Empty results are returned as an empty page and nil error (not ErrNotFound).
+func (svc *service) ListShops(ctx context.Context, actor *auth.AuthUser, f ListFilter) (*ShopPage, error) {
+	if !canViewShops(actor) {
+		return nil, ErrForbidden
+	}
+	if f.Limit <= 0 {
+		f.Limit = 50
+	}
+	f.Limit = min(f.Limit, maxListLimit)
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+
+	f.Status = Status(strings.ToLower(strings.TrimSpace(string(f.Status))))
+	if f.Status != "" && f.Status != Active && f.Status != Inactive {
+		return nil, NewValidationError("status", "must be 'active' or 'inactive'")
+	}
+	f.Province = strings.ToUpper(strings.TrimSpace(f.Province))
+	if f.Province != "" && !validProvinces[f.Province] {
+		return nil, NewValidationError("province", "invalid province code")
+	}
+	f.City = strings.TrimSpace(f.City)
+	f.Query = strings.TrimSpace(f.Query)
+
+	shops, total, err := svc.repo.ListShops(ctx, f)
 	if err != nil {
 		return nil, fmt.Errorf("service list shops: %w", err)
 	}
-	return shops, nil
+	return &ShopPage{Items: shops, Total: total, Limit: f.Limit, Offset: f.Offset}, nil
+}
+
+// ListShopOptions only needs an authenticated actor; the options carry no
+// contact details.
+func (svc *service) ListShopOptions(ctx context.Context, actor *auth.AuthUser, includeInactive bool) ([]Option, error) {
+	if actor == nil {
+		return nil, ErrForbidden
+	}
+	opts, err := svc.repo.ListOptions(ctx, includeInactive && canViewShops(actor))
+	if err != nil {
+		return nil, fmt.Errorf("service list shop options: %w", err)
+	}
+	return opts, nil
 }
 
 // UpdateShop performs normalization and validation before delegating to the repository.
 // Returns the updated shop on success. Errors are wrapped with context.
-func (svc *service) UpdateShop(ctx context.Context, id uuid.UUID, s *Shop) (*Shop, error) {
+func (svc *service) UpdateShop(ctx context.Context, actor *auth.AuthUser, id uuid.UUID, s *Shop) (*Shop, error) {
+	if err := canManageShop(actor, id); err != nil {
+		return nil, err
+	}
+
 	if s == nil {
 		return nil, ErrInvalidInput
 	}
+	// the owning organization never changes through an update
+	s.OrganizationID = uuid.Nil
 
 	if id == uuid.Nil {
 		return nil, ErrInvalidInput
@@ -143,13 +268,91 @@ func (svc *service) UpdateShop(ctx context.Context, id uuid.UUID, s *Shop) (*Sho
 	if err := validateShop(s); err != nil {
 		return nil, err
 	}
+
+	current, err := svc.repo.GetShopByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service update shop: %w", err)
+	}
+	if err := checkFieldUpdatePermission(actor, current, s); err != nil {
+		return nil, err
+	}
+
 	shop, err := svc.repo.UpdateShop(ctx, id, s)
 	if err != nil {
 		return nil, fmt.Errorf("service update shop: %w", err)
 	}
+
+	action := "shop.update"
+	switch {
+	case current.Status == Active && shop.Status == Inactive:
+		action = "shop.deactivate"
+	case current.Status == Inactive && shop.Status == Active:
+		action = "shop.reactivate"
+	}
+	svc.audit.Record(ctx, audit.Entry{
+		Action: action, EntityType: audit.EntityShop, EntityID: id.String(),
+		ShopID: &id, OrganizationID: &shop.OrganizationID, Before: current, After: shop,
+	})
 	return shop, nil
 }
 
+// -------------------- Permission Helpers -------------------- //
+
+// canViewShops reports whether actor may read shop details. Shop details are
+// non-sensitive business info, so anyone managing shops may see every shop.
+func canViewShops(actor *auth.AuthUser) bool {
+	return actor != nil && actor.Can(auth.PermShopsManage)
+}
+
+// canManageShop checks whether actor may update the shop with the given ID.
+// Org-wide actors pass for any ID; callers must then load the shop through the
+// repository (scoped to their organization) before acting on it, see
+// authorizeShop.
+func canManageShop(actor *auth.AuthUser, shopID uuid.UUID) error {
+	// SuperAdmin: No restrictions
+	if actor != nil && actor.IsSuperAdmin() {
+		return nil
+	}
+	if actor == nil || !actor.Can(auth.PermShopsManage) {
+		return ErrForbidden
+	}
+	// Org admin: any shop of the organization
+	if actor.IsOrgWide() {
+		return nil
+	}
+	// Own shop only
+	if actor.ShopID == nil || *actor.ShopID != shopID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// checkFieldUpdatePermission stops non-superadmins from changing the fields that
+// identify a shop or take it in and out of service. Deactivation has to go
+// through POST /shops/{id}/deactivate (package shopdeactivation), which deals
+// with the shop's open work orders; a plain update may only reactivate. Both s
+// and current are normalized, so plain comparison is enough.
+func checkFieldUpdatePermission(actor *auth.AuthUser, current, s *Shop) error {
+	if current.Status == Active && s.Status == Inactive {
+		return NewValidationError("status", "use POST /shops/{id}/deactivate to deactivate a shop")
+	}
+	// SuperAdmin: No other restrictions
+	if actor.IsSuperAdmin() {
+		return nil
+	}
+	// Org admin: may recode and reactivate the organization's shops
+	if actor.IsOrgWide() {
+		return nil
+	}
+	if s.Code != current.Code {
+		return NewValidationError("code", "cannot change shop code")
+	}
+	if s.Status != current.Status {
+		return NewValidationError("status", "only superadmins can activate or deactivate shops")
+	}
+	return nil
+}
+
 // normalizeShop trims whitespace and normalizes casing for certain fields.
 func normalizeShop(s *Shop) {
 	s.Code = strings.ToUpper(strings.TrimSpace(s.Code))
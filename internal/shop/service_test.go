@@ -0,0 +1,612 @@
+package shop
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Service tests run against memRepo, an in-memory Repository. Like the PG
+// repository it only sees shops of the ctx's organization scope.
+
+type memRepo struct {
+	mu       sync.Mutex
+	shops    map[uuid.UUID]*Shop
+	settings map[uuid.UUID]*Settings
+	logos    map[uuid.UUID]*Logo
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{
+		shops:    make(map[uuid.UUID]*Shop),
+		settings: make(map[uuid.UUID]*Settings),
+		logos:    make(map[uuid.UUID]*Logo),
+	}
+}
+
+// visible reports whether s is inside ctx's organization scope.
+func visible(ctx context.Context, s *Shop) bool {
+	org := auth.OrganizationScope(ctx)
+	return org == nil || *org == s.OrganizationID
+}
+
+func (r *memRepo) CreateShop(_ context.Context, s *Shop) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.shops {
+		if existing.OrganizationID == s.OrganizationID && existing.Code == s.Code {
+			return ErrConflict
+		}
+	}
+	s.ID = uuid.New()
+	s.CreatedAt, s.UpdatedAt = time.Now(), time.Now()
+	cp := *s
+	r.shops[s.ID] = &cp
+	return nil
+}
+
+func (r *memRepo) GetShopByID(ctx context.Context, id uuid.UUID) (*Shop, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.shops[id]
+	if !ok || !visible(ctx, s) {
+		return nil, ErrNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (r *memRepo) GetShopIDByCode(ctx context.Context, code string) (uuid.UUID, error) {
+	s, err := r.GetShopByCode(ctx, code)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return s.ID, nil
+}
+
+func (r *memRepo) UpdateShop(ctx context.Context, id uuid.UUID, s *Shop) (*Shop, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.shops[id]
+	if !ok || !visible(ctx, existing) {
+		return nil, ErrNotFound
+	}
+	cp := *s
+	cp.ID, cp.OrganizationID, cp.CreatedAt, cp.UpdatedAt = id, existing.OrganizationID, existing.CreatedAt, time.Now()
+	r.shops[id] = &cp
+	out := cp
+	return &out, nil
+}
+
+func (r *memRepo) GetShopByCode(ctx context.Context, code string) (*Shop, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found *Shop
+	for _, s := range r.shops {
+		if s.Code != code || !visible(ctx, s) {
+			continue
+		}
+		if found != nil {
+			return nil, NewValidationError("code", "shop code exists in several organizations")
+		}
+		cp := *s
+		found = &cp
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+func (r *memRepo) ListShops(ctx context.Context, f ListFilter) ([]*Shop, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]*Shop, 0, len(r.shops))
+	for _, s := range r.shops {
+		if !visible(ctx, s) ||
+			(f.Status != "" && s.Status != f.Status) ||
+			(f.Province != "" && s.Province != f.Province) ||
+			(f.City != "" && !strings.EqualFold(s.City, f.City)) ||
+			(f.Query != "" && !strings.Contains(strings.ToLower(s.ShopName+" "+s.Code), strings.ToLower(f.Query))) {
+			continue
+		}
+		cp := *s
+		out = append(out, &cp)
+	}
+	slices.SortFunc(out, func(a, b *Shop) int { return strings.Compare(a.Code, b.Code) })
+	if f.Offset >= len(out) {
+		return []*Shop{}, len(out), nil
+	}
+	return out[f.Offset:min(f.Offset+f.Limit, len(out))], len(out), nil
+}
+
+func (r *memRepo) ListOptions(ctx context.Context, includeInactive bool) ([]Option, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Option, 0, len(r.shops))
+	for _, s := range r.shops {
+		if visible(ctx, s) && (includeInactive || s.Status == Active) {
+			out = append(out, Option{ID: s.ID, Code: s.Code, ShopName: s.ShopName, Status: s.Status})
+		}
+	}
+	slices.SortFunc(out, func(a, b Option) int { return strings.Compare(a.ShopName, b.ShopName) })
+	return out, nil
+}
+
+func (r *memRepo) GetSettings(_ context.Context, shopID uuid.UUID) (*Settings, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st, ok := r.settings[shopID]
+	if !ok {
+		return &Settings{ShopID: shopID, BusinessHours: Hours{}, Holidays: []Holiday{}, HasLogo: r.logos[shopID] != nil}, nil
+	}
+	cp := *st
+	cp.HasLogo = r.logos[shopID] != nil
+	return &cp, nil
+}
+
+func (r *memRepo) UpsertSettings(ctx context.Context, s *Settings, _ uuid.UUID) (*Settings, error) {
+	r.mu.Lock()
+	if _, ok := r.shops[s.ShopID]; !ok {
+		r.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	cp := *s
+	now := time.Now()
+	cp.UpdatedAt = &now
+	r.settings[s.ShopID] = &cp
+	r.mu.Unlock()
+	return r.GetSettings(ctx, s.ShopID)
+}
+
+func (r *memRepo) GetLogo(_ context.Context, shopID uuid.UUID) (*Logo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.logos[shopID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return l, nil
+}
+
+func (r *memRepo) SetLogo(_ context.Context, shopID uuid.UUID, logo *Logo, _ uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.shops[shopID]; !ok {
+		return ErrNotFound
+	}
+	if logo == nil {
+		delete(r.logos, shopID)
+		return nil
+	}
+	r.logos[shopID] = logo
+	return nil
+}
+
+/* ---------- fixtures ---------- */
+
+// auditLog collects audit entries in memory.
+type auditLog struct {
+	entries []audit.Entry
+}
+
+func (l *auditLog) Record(_ context.Context, e audit.Entry) {
+	l.entries = append(l.entries, e)
+}
+
+type serviceFixture struct {
+	svc   ShopService
+	repo  *memRepo
+	audit *auditLog
+	shopA *Shop
+	shopB *Shop
+}
+
+func newServiceFixture(t *testing.T) *serviceFixture {
+	t.Helper()
+	f := &serviceFixture{repo: newMemRepo(), audit: &auditLog{}}
+	f.svc = NewService(f.repo, f.audit)
+	f.shopA = f.seed(t, "SHOPA")
+	f.shopB = f.seed(t, "SHOPB")
+	return f
+}
+
+func (f *serviceFixture) seed(t *testing.T, code string) *Shop {
+	t.Helper()
+	s := validShop(code)
+	require.NoError(t, f.svc.CreateShop(context.Background(), superAdmin(), s))
+	return s
+}
+
+// testOrg owns every fixture shop.
+var testOrg = uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+
+func validShop(code string) *Shop {
+	return &Shop{
+		OrganizationID: testOrg,
+		Code:           code, ShopName: "Shop " + code, Status: Active,
+		Address: "1 Main St", City: "Calgary", Province: "AB", PostalCode: "T2P2B5",
+		ContactName: "Pat", Phone: "403-555-1234", Email: "shop@example.com",
+	}
+}
+
+func superAdmin() *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleSuperAdmin, IsActive: true}
+}
+
+func adminOf(shopID uuid.UUID) *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleAdmin, Permissions: []string{auth.PermShopsManage}, ShopID: &shopID, IsActive: true}
+}
+
+// orgAdminOf returns an org admin of org, with the request ctx scoped the way
+// the auth middleware scopes it.
+func orgAdminOf(org uuid.UUID) (*auth.AuthUser, context.Context) {
+	u := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleOrgAdmin, Permissions: []string{auth.PermShopsManage, auth.PermOrgShops}, OrganizationID: &org, IsActive: true}
+	return u, auth.WithOrganization(context.Background(), org)
+}
+
+func bodymanOf(shopID uuid.UUID) *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleBodyman, Permissions: []string{auth.PermEstimatesWrite}, ShopID: &shopID, IsActive: true}
+}
+
+// editOf returns a copy of s as a client would PUT it back.
+func editOf(s *Shop) *Shop {
+	cp := *s
+	return &cp
+}
+
+/* ---------- create ---------- */
+
+func TestCreateShopRequiresSuperAdminOrOrgAdmin(t *testing.T) {
+	f := newServiceFixture(t)
+
+	err := f.svc.CreateShop(context.Background(), adminOf(f.shopA.ID), validShop("SHOPC"))
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	err = f.svc.CreateShop(context.Background(), nil, validShop("SHOPC"))
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestCreateShopNormalizes(t *testing.T) {
+	f := newServiceFixture(t)
+	s := validShop(" shopc ")
+	s.PostalCode = "t2p 2b5"
+
+	require.NoError(t, f.svc.CreateShop(context.Background(), superAdmin(), s))
+	assert.Equal(t, "SHOPC", s.Code)
+	assert.Equal(t, "T2P2B5", s.PostalCode)
+	assert.NotEqual(t, uuid.Nil, s.ID)
+}
+
+/* ---------- view ---------- */
+
+// Test: shop details are visible to every shop manager, not just the own shop
+func TestViewShops(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+	admin := adminOf(f.shopA.ID)
+
+	got, err := f.svc.GetShopByID(ctx, admin, f.shopB.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "SHOPB", got.Code)
+
+	page, err := f.svc.ListShops(ctx, admin, ListFilter{})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, 2, page.Total)
+
+	got, err = f.svc.GetShopByCode(ctx, admin, " shopb ")
+	require.NoError(t, err)
+	assert.Equal(t, f.shopB.ID, got.ID)
+}
+
+func TestViewShopsRequiresShopsManage(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+	bodyman := bodymanOf(f.shopA.ID)
+
+	_, err := f.svc.GetShopByID(ctx, bodyman, f.shopA.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = f.svc.ListShops(ctx, bodyman, ListFilter{})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = f.svc.GetShopByCode(ctx, bodyman, f.shopA.Code)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestListShopsFilters(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+	c := validShop("EDM01")
+	c.ShopName, c.City, c.Province = "North Side Collision", "Edmonton", "AB"
+	require.NoError(t, f.svc.CreateShop(ctx, superAdmin(), c))
+	f.repo.shops[f.shopB.ID].Status = Inactive
+
+	cases := map[string]struct {
+		filter ListFilter
+		want   []string
+	}{
+		"status":        {ListFilter{Status: "INACTIVE"}, []string{"SHOPB"}},
+		"city":          {ListFilter{City: " edmonton "}, []string{"EDM01"}},
+		"province":      {ListFilter{Province: "ab"}, []string{"EDM01", "SHOPA", "SHOPB"}},
+		"name search":   {ListFilter{Query: "collision"}, []string{"EDM01"}},
+		"code search":   {ListFilter{Query: "shopa"}, []string{"SHOPA"}},
+		"combined":      {ListFilter{Status: Active, City: "Calgary"}, []string{"SHOPA"}},
+		"no match":      {ListFilter{Province: "BC"}, []string{}},
+		"paged":         {ListFilter{Limit: 1, Offset: 1}, []string{"SHOPA"}},
+		"offset beyond": {ListFilter{Offset: 10}, []string{}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			page, err := f.svc.ListShops(ctx, superAdmin(), tc.filter)
+			require.NoError(t, err)
+			codes := make([]string, 0, len(page.Items))
+			for _, s := range page.Items {
+				codes = append(codes, s.Code)
+			}
+			assert.Equal(t, tc.want, codes)
+		})
+	}
+
+	page, err := f.svc.ListShops(ctx, superAdmin(), ListFilter{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total, "total counts every match, not just the page")
+	assert.Equal(t, 1, page.Limit)
+
+	page, err = f.svc.ListShops(ctx, superAdmin(), ListFilter{Limit: 10_000})
+	require.NoError(t, err)
+	assert.Equal(t, maxListLimit, page.Limit)
+}
+
+func TestListShopsRejectsBadFilters(t *testing.T) {
+	f := newServiceFixture(t)
+
+	_, err := f.svc.ListShops(context.Background(), superAdmin(), ListFilter{Status: "closed"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = f.svc.ListShops(context.Background(), superAdmin(), ListFilter{Province: "WA"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestGetShopByCodeNotFound(t *testing.T) {
+	f := newServiceFixture(t)
+
+	_, err := f.svc.GetShopByCode(context.Background(), superAdmin(), "NOPE")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = f.svc.GetShopByCode(context.Background(), superAdmin(), " ")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+// Test: options are open to every role, but only managers see inactive shops
+func TestListShopOptions(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+	f.repo.shops[f.shopB.ID].Status = Inactive
+
+	opts, err := f.svc.ListShopOptions(ctx, bodymanOf(f.shopA.ID), true)
+	require.NoError(t, err)
+	require.Len(t, opts, 1)
+	assert.Equal(t, Option{ID: f.shopA.ID, Code: "SHOPA", ShopName: "Shop SHOPA", Status: Active}, opts[0])
+
+	opts, err = f.svc.ListShopOptions(ctx, adminOf(f.shopA.ID), true)
+	require.NoError(t, err)
+	assert.Len(t, opts, 2)
+
+	_, err = f.svc.ListShopOptions(ctx, nil, false)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+/* ---------- update ---------- */
+
+func TestAdminUpdatesOwnShop(t *testing.T) {
+	f := newServiceFixture(t)
+	in := editOf(f.shopA)
+	in.ShopName = "  Shop A Collision "
+	in.Code = "shopa" // same code, different case
+
+	out, err := f.svc.UpdateShop(context.Background(), adminOf(f.shopA.ID), f.shopA.ID, in)
+	require.NoError(t, err)
+	assert.Equal(t, "Shop A Collision", out.ShopName)
+	assert.Equal(t, "SHOPA", out.Code)
+}
+
+func TestAdminCannotUpdateOtherShop(t *testing.T) {
+	f := newServiceFixture(t)
+
+	_, err := f.svc.UpdateShop(context.Background(), adminOf(f.shopA.ID), f.shopB.ID, editOf(f.shopB))
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	stored, _ := f.repo.GetShopByID(context.Background(), f.shopB.ID)
+	assert.Equal(t, f.shopB.ShopName, stored.ShopName)
+}
+
+func TestAdminWithoutShopCannotUpdate(t *testing.T) {
+	f := newServiceFixture(t)
+	admin := adminOf(f.shopA.ID)
+	admin.ShopID = nil
+
+	_, err := f.svc.UpdateShop(context.Background(), admin, f.shopA.ID, editOf(f.shopA))
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestAdminCannotChangeCodeOrStatus(t *testing.T) {
+	f := newServiceFixture(t)
+	admin := adminOf(f.shopA.ID)
+
+	t.Run("code", func(t *testing.T) {
+		in := editOf(f.shopA)
+		in.Code = "NEWCODE"
+		_, err := f.svc.UpdateShop(context.Background(), admin, f.shopA.ID, in)
+		require.ErrorIs(t, err, ErrInvalidInput)
+		var ve *ValidationError
+		require.ErrorAs(t, err, &ve)
+		assert.Equal(t, "code", ve.Field)
+	})
+
+	t.Run("deactivate", func(t *testing.T) {
+		in := editOf(f.shopA)
+		in.Status = Inactive
+		_, err := f.svc.UpdateShop(context.Background(), admin, f.shopA.ID, in)
+		require.ErrorIs(t, err, ErrInvalidInput)
+		var ve *ValidationError
+		require.ErrorAs(t, err, &ve)
+		assert.Equal(t, "status", ve.Field)
+	})
+
+	stored, _ := f.repo.GetShopByID(context.Background(), f.shopA.ID)
+	assert.Equal(t, "SHOPA", stored.Code)
+	assert.Equal(t, Active, stored.Status)
+}
+
+func TestStaffCannotUpdateShop(t *testing.T) {
+	f := newServiceFixture(t)
+
+	_, err := f.svc.UpdateShop(context.Background(), bodymanOf(f.shopA.ID), f.shopA.ID, editOf(f.shopA))
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestSuperAdminUpdatesAnyShop(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+
+	in := editOf(f.shopB)
+	in.Code = "SHOPB2"
+	out, err := f.svc.UpdateShop(ctx, superAdmin(), f.shopB.ID, in)
+	require.NoError(t, err)
+	assert.Equal(t, "SHOPB2", out.Code)
+}
+
+// Test: deactivation has its own workflow; a plain update may only reactivate
+func TestUpdateShopStatus(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+
+	in := editOf(f.shopB)
+	in.Status = Inactive
+	_, err := f.svc.UpdateShop(ctx, superAdmin(), f.shopB.ID, in)
+	require.ErrorIs(t, err, ErrInvalidInput)
+
+	f.repo.shops[f.shopB.ID].Status = Inactive
+	in.Status = Active
+	out, err := f.svc.UpdateShop(ctx, superAdmin(), f.shopB.ID, in)
+	require.NoError(t, err)
+	assert.Equal(t, Active, out.Status, "superadmin can reactivate")
+
+	f.repo.shops[f.shopA.ID].Status = Inactive
+	in = editOf(f.shopA)
+	in.Status = Active
+	_, err = f.svc.UpdateShop(ctx, adminOf(f.shopA.ID), f.shopA.ID, in)
+	assert.ErrorIs(t, err, ErrInvalidInput, "admins cannot reactivate")
+}
+
+/* ---------- audit ---------- */
+
+// Test: changes are recorded with the shop before and after
+func TestShopChangesAreAudited(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+	require.Len(t, f.audit.entries, 2)
+	assert.Equal(t, "shop.create", f.audit.entries[0].Action)
+	assert.Equal(t, &testOrg, f.audit.entries[0].OrganizationID)
+
+	in := editOf(f.shopA)
+	in.ShopName = "Renamed"
+	_, err := f.svc.UpdateShop(ctx, adminOf(f.shopA.ID), f.shopA.ID, in)
+	require.NoError(t, err)
+
+	f.repo.shops[f.shopB.ID].Status = Inactive
+	in = editOf(f.shopB)
+	in.Status = Active
+	_, err = f.svc.UpdateShop(ctx, superAdmin(), f.shopB.ID, in)
+	require.NoError(t, err)
+
+	in = editOf(f.shopB)
+	in.ShopName = "Rejected"
+	_, err = f.svc.UpdateShop(ctx, adminOf(f.shopA.ID), f.shopB.ID, in)
+	require.ErrorIs(t, err, ErrForbidden)
+
+	require.Len(t, f.audit.entries, 4, "failed changes are not recorded")
+	update := f.audit.entries[2]
+	assert.Equal(t, "shop.update", update.Action)
+	assert.Equal(t, &f.shopA.ID, update.ShopID)
+	assert.Equal(t, "Shop SHOPA", update.Before.(*Shop).ShopName)
+	assert.Equal(t, "Renamed", update.After.(*Shop).ShopName)
+	assert.Equal(t, "shop.reactivate", f.audit.entries[3].Action)
+}
+
+/* ---------- organizations ---------- */
+
+// Test: an org admin manages every shop of their organization and nothing else
+func TestOrgAdminScope(t *testing.T) {
+	f := newServiceFixture(t)
+	other := validShop("SHOPA") // same code, other organization
+	other.OrganizationID = uuid.New()
+	require.NoError(t, f.svc.CreateShop(context.Background(), superAdmin(), other))
+
+	orgAdmin, ctx := orgAdminOf(testOrg)
+
+	in := editOf(f.shopB)
+	in.Code = "SHOPB2"
+	out, err := f.svc.UpdateShop(ctx, orgAdmin, f.shopB.ID, in)
+	require.NoError(t, err)
+	assert.Equal(t, "SHOPB2", out.Code)
+	assert.Equal(t, testOrg, out.OrganizationID)
+
+	_, err = f.svc.UpdateShop(ctx, orgAdmin, other.ID, editOf(other))
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = f.svc.GetSettings(ctx, orgAdmin, other.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	page, err := f.svc.ListShops(ctx, orgAdmin, ListFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+
+	got, err := f.svc.GetShopByCode(ctx, orgAdmin, "SHOPA")
+	require.NoError(t, err)
+	assert.Equal(t, f.shopA.ID, got.ID)
+}
+
+func TestCreateShopOrganization(t *testing.T) {
+	f := newServiceFixture(t)
+
+	t.Run("org admin creates in own organization", func(t *testing.T) {
+		orgAdmin, ctx := orgAdminOf(testOrg)
+		s := validShop("SHOPC")
+		s.OrganizationID = uuid.New()
+		require.NoError(t, f.svc.CreateShop(ctx, orgAdmin, s))
+		assert.Equal(t, testOrg, s.OrganizationID)
+	})
+
+	t.Run("superadmin falls back to the scope", func(t *testing.T) {
+		org := uuid.New()
+		s := validShop("SHOPD")
+		s.OrganizationID = uuid.Nil
+		require.NoError(t, f.svc.CreateShop(auth.WithOrganization(context.Background(), org), superAdmin(), s))
+		assert.Equal(t, org, s.OrganizationID)
+	})
+
+	t.Run("superadmin without organization", func(t *testing.T) {
+		s := validShop("SHOPE")
+		s.OrganizationID = uuid.Nil
+		err := f.svc.CreateShop(context.Background(), superAdmin(), s)
+		require.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("codes are unique per organization", func(t *testing.T) {
+		s := validShop("SHOPA")
+		s.OrganizationID = uuid.New()
+		require.NoError(t, f.svc.CreateShop(context.Background(), superAdmin(), s))
+
+		_, err := f.svc.GetShopByCode(context.Background(), superAdmin(), "SHOPA")
+		assert.ErrorIs(t, err, ErrInvalidInput, "ambiguous without an organization scope")
+	})
+}
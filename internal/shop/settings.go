@@ -0,0 +1,300 @@
+package shop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register decoders for logo validation
+	_ "image/png"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxLogoBytes caps an uploaded logo; it is stored in the settings row and
+	// embedded in every PDF.
+	MaxLogoBytes = 512 << 10
+	// maxLogoPx keeps logos to a sensible pixel size for the letterhead.
+	maxLogoPx = 2000
+
+	maxHolidays         = 100
+	maxHolidayName      = 100
+	maxInvoiceFooter    = 1000
+	maxLabourRateCents  = 1_000_000 // $10,000/h; anything above is a typo
+	businessHoursLayout = "15:04"
+	holidayLayout       = "2006-01-02"
+)
+
+// weekdays lists the accepted day keys in display order.
+var weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// authorizeShop runs canManageShop and makes sure the shop exists within the
+// request's organization scope, which is what confines org admins to their own
+// organization.
+func (svc *service) authorizeShop(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID) error {
+	if err := canManageShop(actor, shopID); err != nil {
+		return err
+	}
+	if _, err := svc.repo.GetShopByID(ctx, shopID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetSettings returns the shop's settings (empty for a shop that never saved any).
+func (svc *service) GetSettings(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID) (*Settings, error) {
+	if err := svc.authorizeShop(ctx, actor, shopID); err != nil {
+		return nil, fmt.Errorf("service get shop settings: %w", err)
+	}
+	st, err := svc.repo.GetSettings(ctx, shopID)
+	if err != nil {
+		return nil, fmt.Errorf("service get shop settings: %w", err)
+	}
+	return st, nil
+}
+
+// UpdateSettings normalizes and validates the settings, then replaces the stored
+// ones. The logo is managed separately (SetLogo) and left untouched.
+func (svc *service) UpdateSettings(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID, in *Settings) (*Settings, error) {
+	if err := svc.authorizeShop(ctx, actor, shopID); err != nil {
+		return nil, err
+	}
+	if in == nil {
+		return nil, ErrInvalidInput
+	}
+	in.ShopID = shopID
+	normalizeSettings(in)
+	if err := validateSettings(in); err != nil {
+		return nil, err
+	}
+
+	before, err := svc.repo.GetSettings(ctx, shopID)
+	if err != nil {
+		return nil, fmt.Errorf("service update shop settings: %w", err)
+	}
+	st, err := svc.repo.UpsertSettings(ctx, in, actor.ID)
+	if err != nil {
+		return nil, fmt.Errorf("service update shop settings: %w", err)
+	}
+	svc.audit.Record(ctx, audit.Entry{
+		Action: "shop.settings.update", EntityType: audit.EntityShop, EntityID: shopID.String(),
+		ShopID: &shopID, Before: before, After: st,
+	})
+	return st, nil
+}
+
+func (svc *service) GetLogo(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID) (*Logo, error) {
+	if err := svc.authorizeShop(ctx, actor, shopID); err != nil {
+		return nil, err
+	}
+	logo, err := svc.repo.GetLogo(ctx, shopID)
+	if err != nil {
+		return nil, fmt.Errorf("service get shop logo: %w", err)
+	}
+	return logo, nil
+}
+
+// SetLogo sniffs the image type rather than trusting the upload's Content-Type.
+func (svc *service) SetLogo(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID, data []byte) error {
+	if err := svc.authorizeShop(ctx, actor, shopID); err != nil {
+		return err
+	}
+
+	var logo *Logo
+	if data != nil {
+		if len(data) == 0 {
+			return NewValidationError("logo", "file is empty")
+		}
+		if len(data) > MaxLogoBytes {
+			return NewValidationError("logo", fmt.Sprintf("must be at most %d KB", MaxLogoBytes>>10))
+		}
+		contentType := http.DetectContentType(data)
+		if contentType != "image/png" && contentType != "image/jpeg" {
+			return NewValidationError("logo", "must be a PNG or JPEG image")
+		}
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return NewValidationError("logo", "could not be read as an image")
+		}
+		if cfg.Width > maxLogoPx || cfg.Height > maxLogoPx {
+			return NewValidationError("logo", fmt.Sprintf("must be at most %dx%d pixels", maxLogoPx, maxLogoPx))
+		}
+		logo = &Logo{Data: data, ContentType: contentType}
+	}
+
+	if err := svc.repo.SetLogo(ctx, shopID, logo, actor.ID); err != nil {
+		return fmt.Errorf("service set shop logo: %w", err)
+	}
+	// the image itself stays out of the log
+	svc.audit.Record(ctx, audit.Entry{
+		Action: "shop.logo.update", EntityType: audit.EntityShop, EntityID: shopID.String(),
+		ShopID: &shopID, After: map[string]bool{"hasLogo": logo != nil},
+	})
+	return nil
+}
+
+// normalizeSettings trims text, lower-cases day keys, sorts hours into week
+// order and turns blank optional fields into nil.
+func normalizeSettings(s *Settings) {
+	if s.BusinessHours == nil {
+		s.BusinessHours = Hours{}
+	}
+	for i := range s.BusinessHours {
+		h := &s.BusinessHours[i]
+		h.Day = strings.ToLower(strings.TrimSpace(h.Day))
+		h.Open = strings.TrimSpace(h.Open)
+		h.Close = strings.TrimSpace(h.Close)
+	}
+	sortHours(s.BusinessHours)
+
+	if s.Holidays == nil {
+		s.Holidays = []Holiday{}
+	}
+	for i := range s.Holidays {
+		s.Holidays[i].Date = strings.TrimSpace(s.Holidays[i].Date)
+		s.Holidays[i].Name = strings.TrimSpace(s.Holidays[i].Name)
+	}
+
+	if s.TaxProvince != nil {
+		p := strings.ToUpper(strings.TrimSpace(*s.TaxProvince))
+		s.TaxProvince = &p
+		if p == "" {
+			s.TaxProvince = nil
+		}
+	}
+	if s.InvoiceFooter != nil {
+		f := strings.TrimSpace(*s.InvoiceFooter)
+		s.InvoiceFooter = &f
+		if f == "" {
+			s.InvoiceFooter = nil
+		}
+	}
+}
+
+// validateSettings checks the Settings fields and returns the first ValidationError.
+func validateSettings(s *Settings) error {
+	seenDays := make(map[string]bool)
+	for _, h := range s.BusinessHours {
+		if dayIndex(h.Day) < 0 {
+			return NewValidationError("businessHours.day", "must be one of mon, tue, wed, thu, fri, sat, sun")
+		}
+		if seenDays[h.Day] {
+			return NewValidationError("businessHours.day", fmt.Sprintf("%s is listed more than once", h.Day))
+		}
+		seenDays[h.Day] = true
+		open, err1 := time.Parse(businessHoursLayout, h.Open)
+		closeAt, err2 := time.Parse(businessHoursLayout, h.Close)
+		if err1 != nil || err2 != nil {
+			return NewValidationError("businessHours", "open and close must be in format HH:MM (24-hour)")
+		}
+		if !open.Before(closeAt) {
+			return NewValidationError("businessHours", fmt.Sprintf("%s closes before it opens", h.Day))
+		}
+	}
+
+	if len(s.Holidays) > maxHolidays {
+		return NewValidationError("holidays", fmt.Sprintf("must have at most %d entries", maxHolidays))
+	}
+	seenDates := make(map[string]bool)
+	for _, h := range s.Holidays {
+		if _, err := time.Parse(holidayLayout, h.Date); err != nil {
+			return NewValidationError("holidays.date", "must be in format YYYY-MM-DD")
+		}
+		if seenDates[h.Date] {
+			return NewValidationError("holidays.date", fmt.Sprintf("%s is listed more than once", h.Date))
+		}
+		seenDates[h.Date] = true
+		if h.Name == "" {
+			return NewValidationError("holidays.name", "name is required")
+		}
+		if len(h.Name) > maxHolidayName {
+			return NewValidationError("holidays.name", fmt.Sprintf("must be at most %d characters", maxHolidayName))
+		}
+	}
+
+	for _, r := range []struct {
+		field string
+		cents *int64
+	}{
+		{"labourRates.pdrCents", s.LabourRates.PDRCents},
+		{"labourRates.rAndICents", s.LabourRates.RAndICents},
+		{"labourRates.paintCents", s.LabourRates.PaintCents},
+	} {
+		if r.cents != nil && (*r.cents < 0 || *r.cents > maxLabourRateCents) {
+			return NewValidationError(r.field, fmt.Sprintf("must be between 0 and %d", maxLabourRateCents))
+		}
+	}
+
+	if s.TaxProvince != nil && !validProvinces[*s.TaxProvince] {
+		return NewValidationError("taxProvince", "invalid province code")
+	}
+	if s.InvoiceFooter != nil && len(*s.InvoiceFooter) > maxInvoiceFooter {
+		return NewValidationError("invoiceFooter", fmt.Sprintf("must be at most %d characters", maxInvoiceFooter))
+	}
+	return nil
+}
+
+func dayIndex(day string) int {
+	for i, d := range weekdays {
+		if d == day {
+			return i
+		}
+	}
+	return -1
+}
+
+func sortHours(h Hours) {
+	slices.SortStableFunc(h, func(a, b BusinessHours) int { return dayIndex(a.Day) - dayIndex(b.Day) })
+}
+
+// String renders the schedule for documents, grouping consecutive days with
+// the same hours: "Mon–Fri 08:00–17:00 · Sat 09:00–13:00".
+func (h Hours) String() string {
+	sorted := slices.Clone(h)
+	sortHours(sorted)
+
+	var parts []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) &&
+			dayIndex(sorted[j+1].Day) == dayIndex(sorted[j].Day)+1 &&
+			sorted[j+1].Open == sorted[i].Open && sorted[j+1].Close == sorted[i].Close {
+			j++
+		}
+		days := dayLabel(sorted[i].Day)
+		if j > i {
+			days += "–" + dayLabel(sorted[j].Day)
+		}
+		parts = append(parts, fmt.Sprintf("%s %s–%s", days, sorted[i].Open, sorted[i].Close))
+		i = j + 1
+	}
+	return strings.Join(parts, " · ")
+}
+
+func dayLabel(day string) string {
+	if day == "" {
+		return ""
+	}
+	return strings.ToUpper(day[:1]) + day[1:]
+}
+
+// RateFor returns the hourly rate for a labour category ("pdr", "r_and_i",
+// "paint"), or nil if the shop has none set.
+func (r LabourRates) RateFor(category string) *int64 {
+	switch category {
+	case "pdr":
+		return r.PDRCents
+	case "r_and_i":
+		return r.RAndICents
+	case "paint":
+		return r.PaintCents
+	}
+	return nil
+}
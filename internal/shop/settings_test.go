@@ -0,0 +1,167 @@
+package shop
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cents(n int64) *int64 { return &n }
+
+func strPtr(s string) *string { return &s }
+
+func validSettings() *Settings {
+	return &Settings{
+		BusinessHours: Hours{
+			{Day: "SAT", Open: "09:00", Close: "13:00"},
+			{Day: "mon", Open: "08:00", Close: "17:00"},
+			{Day: "tue", Open: "08:00", Close: "17:00"},
+		},
+		Holidays:      []Holiday{{Date: "2025-12-25", Name: " Christmas Day "}},
+		LabourRates:   LabourRates{PDRCents: cents(9500), PaintCents: cents(11000)},
+		TaxProvince:   strPtr(" ab "),
+		InvoiceFooter: strPtr("  Thank you for choosing us.  "),
+	}
+}
+
+func pngBytes(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func TestUpdateSettingsNormalizes(t *testing.T) {
+	f := newServiceFixture(t)
+
+	st, err := f.svc.UpdateSettings(context.Background(), adminOf(f.shopA.ID), f.shopA.ID, validSettings())
+	require.NoError(t, err)
+	assert.Equal(t, f.shopA.ID, st.ShopID)
+	assert.Equal(t, []string{"mon", "tue", "sat"}, []string{st.BusinessHours[0].Day, st.BusinessHours[1].Day, st.BusinessHours[2].Day})
+	assert.Equal(t, "Christmas Day", st.Holidays[0].Name)
+	assert.Equal(t, "AB", *st.TaxProvince)
+	assert.Equal(t, "Thank you for choosing us.", *st.InvoiceFooter)
+	assert.Equal(t, int64(9500), *st.LabourRates.RateFor("pdr"))
+	assert.Nil(t, st.LabourRates.RateFor("r_and_i"))
+	assert.Nil(t, st.LabourRates.RateFor("parts"))
+
+	got, err := f.svc.GetSettings(context.Background(), adminOf(f.shopA.ID), f.shopA.ID)
+	require.NoError(t, err)
+	assert.Equal(t, st.BusinessHours, got.BusinessHours)
+}
+
+func TestUpdateSettingsBlankOptionalFields(t *testing.T) {
+	f := newServiceFixture(t)
+	in := &Settings{TaxProvince: strPtr("  "), InvoiceFooter: strPtr("")}
+
+	st, err := f.svc.UpdateSettings(context.Background(), superAdmin(), f.shopA.ID, in)
+	require.NoError(t, err)
+	assert.Nil(t, st.TaxProvince)
+	assert.Nil(t, st.InvoiceFooter)
+	assert.NotNil(t, st.BusinessHours, "empty lists encode as []")
+	assert.NotNil(t, st.Holidays)
+}
+
+func TestSettingsValidation(t *testing.T) {
+	f := newServiceFixture(t)
+
+	cases := map[string]struct {
+		field  string
+		mutate func(*Settings)
+	}{
+		"unknown day":       {"businessHours.day", func(s *Settings) { s.BusinessHours[0].Day = "funday" }},
+		"duplicate day":     {"businessHours.day", func(s *Settings) { s.BusinessHours[2].Day = "mon" }},
+		"bad time":          {"businessHours", func(s *Settings) { s.BusinessHours[0].Open = "9am" }},
+		"closes early":      {"businessHours", func(s *Settings) { s.BusinessHours[0].Close = "08:00" }},
+		"bad holiday date":  {"holidays.date", func(s *Settings) { s.Holidays[0].Date = "25/12/2025" }},
+		"duplicate holiday": {"holidays.date", func(s *Settings) { s.Holidays = append(s.Holidays, s.Holidays[0]) }},
+		"unnamed holiday":   {"holidays.name", func(s *Settings) { s.Holidays[0].Name = " " }},
+		"negative rate":     {"labourRates.paintCents", func(s *Settings) { s.LabourRates.PaintCents = cents(-1) }},
+		"bad province":      {"taxProvince", func(s *Settings) { s.TaxProvince = strPtr("WA") }},
+		"long footer":       {"invoiceFooter", func(s *Settings) { s.InvoiceFooter = strPtr(strings.Repeat("x", maxInvoiceFooter+1)) }},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			in := validSettings()
+			tc.mutate(in)
+			_, err := f.svc.UpdateSettings(context.Background(), superAdmin(), f.shopA.ID, in)
+			var ve *ValidationError
+			require.ErrorAs(t, err, &ve)
+			assert.Equal(t, tc.field, ve.Field)
+		})
+	}
+}
+
+// Test: settings follow the shop edit rules (admins: own shop only)
+func TestSettingsPermissions(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+
+	_, err := f.svc.GetSettings(ctx, adminOf(f.shopA.ID), f.shopB.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = f.svc.UpdateSettings(ctx, adminOf(f.shopA.ID), f.shopB.ID, validSettings())
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = f.svc.UpdateSettings(ctx, bodymanOf(f.shopA.ID), f.shopA.ID, validSettings())
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, f.svc.SetLogo(ctx, adminOf(f.shopA.ID), f.shopB.ID, pngBytes(t, 10, 10)), ErrForbidden)
+
+	_, err = f.svc.UpdateSettings(ctx, superAdmin(), f.shopB.ID, validSettings())
+	assert.NoError(t, err)
+}
+
+func TestSetLogo(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+	admin := adminOf(f.shopA.ID)
+
+	_, err := f.svc.GetLogo(ctx, admin, f.shopA.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, f.svc.SetLogo(ctx, admin, f.shopA.ID, pngBytes(t, 120, 40)))
+	logo, err := f.svc.GetLogo(ctx, admin, f.shopA.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", logo.ContentType)
+
+	st, err := f.svc.GetSettings(ctx, admin, f.shopA.ID)
+	require.NoError(t, err)
+	assert.True(t, st.HasLogo)
+
+	require.NoError(t, f.svc.SetLogo(ctx, admin, f.shopA.ID, nil))
+	_, err = f.svc.GetLogo(ctx, admin, f.shopA.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSetLogoValidation(t *testing.T) {
+	f := newServiceFixture(t)
+	ctx := context.Background()
+
+	cases := map[string][]byte{
+		"empty":     {},
+		"not image": []byte("%PDF-1.4 not a logo"),
+		"too large": append(pngBytes(t, 10, 10), make([]byte, MaxLogoBytes)...),
+		"too wide":  pngBytes(t, maxLogoPx+1, 10),
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, f.svc.SetLogo(ctx, superAdmin(), f.shopA.ID, data), ErrInvalidInput)
+		})
+	}
+}
+
+func TestHoursString(t *testing.T) {
+	h := Hours{
+		{Day: "sat", Open: "09:00", Close: "13:00"},
+		{Day: "mon", Open: "08:00", Close: "17:00"},
+		{Day: "tue", Open: "08:00", Close: "17:00"},
+		{Day: "wed", Open: "08:00", Close: "17:00"},
+		{Day: "fri", Open: "08:00", Close: "17:00"},
+	}
+	assert.Equal(t, "Mon–Wed 08:00–17:00 · Fri 08:00–17:00 · Sat 09:00–13:00", h.String())
+	assert.Equal(t, "", Hours{}.String())
+}
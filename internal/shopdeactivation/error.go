@@ -0,0 +1,37 @@
+package shopdeactivation
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain-level errors for shop deactivation
+var (
+	ErrNotFound     = errors.New("shop not found")
+	ErrInvalidInput = errors.New("invalid deactivation input")
+	ErrForbidden    = errors.New("forbidden: only superadmins can deactivate shops")
+
+	ErrAlreadyInactive = errors.New("shop is already inactive")
+	ErrTargetInactive  = errors.New("target shop is inactive")
+)
+
+// ValidationError represents validation errors with specific field information
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Unwrap allows errors.Is to work with ValidationError
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// NewValidationError creates a new ValidationError
+func NewValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
@@ -0,0 +1,68 @@
+package shopdeactivation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShopRef identifies a shop in previews and results.
+type ShopRef struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"-"`
+	Code           string    `json:"code"`
+	Name           string    `json:"name"`
+	Status         string    `json:"status"`
+}
+
+// UserRef is an active user of the shop being deactivated.
+type UserRef struct {
+	ID       uuid.UUID `json:"id"`
+	Email    string    `json:"email"`
+	FullName string    `json:"fullName"`
+	RoleCode string    `json:"roleCode"`
+}
+
+// WorkOrderRef is an open (not completed) work order of the shop.
+type WorkOrderRef struct {
+	ID        uuid.UUID `json:"id"`
+	Code      string    `json:"code"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Preview is what deactivating a shop would affect (GET /shops/{id}/deactivate).
+type Preview struct {
+	Shop           ShopRef        `json:"shop"`
+	ActiveUsers    []UserRef      `json:"activeUsers"`
+	OpenWorkOrders []WorkOrderRef `json:"openWorkOrders"`
+}
+
+// Request is the body of POST /shops/{id}/deactivate. Open work orders must be
+// either moved to TargetShopCode or put on hold; exactly one of the two.
+type Request struct {
+	TargetShopCode *string `json:"targetShopCode,omitempty"`
+	Hold           bool    `json:"hold"`
+	HoldReason     string  `json:"holdReason,omitempty"`
+	// DeactivateUsers also deactivates every active user of the shop
+	// (except the caller), revoking their sessions.
+	DeactivateUsers bool `json:"deactivateUsers"`
+}
+
+// UserFailure is a user that could not be deactivated. The shop itself stays
+// deactivated; the caller can retry the user through /users.
+type UserFailure struct {
+	UserID uuid.UUID `json:"userId"`
+	Email  string    `json:"email"`
+	Error  string    `json:"error"`
+}
+
+// Result is the outcome of a deactivation.
+type Result struct {
+	Shop             ShopRef       `json:"shop"`
+	TargetShop       *ShopRef      `json:"targetShop,omitempty"`
+	MovedWorkOrders  int           `json:"movedWorkOrders"`
+	HeldWorkOrders   int           `json:"heldWorkOrders"`
+	DeactivatedUsers []uuid.UUID   `json:"deactivatedUsers"`
+	UserFailures     []UserFailure `json:"userFailures"`
+}
@@ -0,0 +1,168 @@
+package shopdeactivation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repository reads the shop's users and work orders and applies the
+// deactivation.
+type Repository interface {
+	GetShop(ctx context.Context, id uuid.UUID) (*ShopRef, error)
+	// GetShopByCode looks code up within orgID; codes are unique per organization.
+	GetShopByCode(ctx context.Context, orgID uuid.UUID, code string) (*ShopRef, error)
+	ListActiveUsers(ctx context.Context, shopID uuid.UUID) ([]UserRef, error)
+	ListOpenWorkOrders(ctx context.Context, shopID uuid.UUID) ([]WorkOrderRef, error)
+	// Deactivate marks the shop inactive and, in the same transaction, moves
+	// its open work orders to target, or holds them with holdReason when
+	// target is nil. It returns the number of work orders affected, and
+	// ErrAlreadyInactive / ErrTargetInactive if a shop changed status since
+	// the caller looked.
+	Deactivate(ctx context.Context, shopID uuid.UUID, target *uuid.UUID, holdReason string, byUserID uuid.UUID) (int, error)
+}
+
+type pgRepo struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository constructs a Postgres-backed deactivation repository.
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgRepo{db: db}
+}
+
+/* ---------- queries ---------- */
+
+const shopSelect = `SELECT id, organization_id, code, shop_name, status FROM app.shop`
+
+func scanShop(row pgx.Row) (*ShopRef, error) {
+	var s ShopRef
+	if err := row.Scan(&s.ID, &s.OrganizationID, &s.Code, &s.Name, &s.Status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *pgRepo) GetShop(ctx context.Context, id uuid.UUID) (*ShopRef, error) {
+	return scanShop(r.db.QueryRow(ctx, shopSelect+` WHERE id = $1 AND ($2::uuid IS NULL OR organization_id = $2)`,
+		id, auth.OrganizationScope(ctx)))
+}
+
+func (r *pgRepo) GetShopByCode(ctx context.Context, orgID uuid.UUID, code string) (*ShopRef, error) {
+	return scanShop(r.db.QueryRow(ctx, shopSelect+` WHERE organization_id = $1 AND code = $2`, orgID, code))
+}
+
+func (r *pgRepo) ListActiveUsers(ctx context.Context, shopID uuid.UUID) ([]UserRef, error) {
+	rows, err := r.db.Query(ctx, `
+SELECT u.id, u.email, u.first_name || ' ' || u.last_name, r.code
+FROM app.users u
+JOIN app.roles r ON r.id = u.role_id
+WHERE u.shop_id = $1 AND u.is_active
+ORDER BY u.email
+`, shopID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (UserRef, error) {
+		var u UserRef
+		err := row.Scan(&u.ID, &u.Email, &u.FullName, &u.RoleCode)
+		return u, err
+	})
+}
+
+func (r *pgRepo) ListOpenWorkOrders(ctx context.Context, shopID uuid.UUID) (list []WorkOrderRef, err error) {
+	err = database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+SELECT id, code, status, created_at
+FROM app.work_orders
+WHERE shop_id = $1 AND status <> 'completed'
+ORDER BY created_at
+`, shopID)
+		if err != nil {
+			return err
+		}
+		list, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (WorkOrderRef, error) {
+			var wo WorkOrderRef
+			err := row.Scan(&wo.ID, &wo.Code, &wo.Status, &wo.CreatedAt)
+			return wo, err
+		})
+		return err
+	})
+	return list, err
+}
+
+func (r *pgRepo) Deactivate(ctx context.Context, shopID uuid.UUID, target *uuid.UUID, holdReason string, byUserID uuid.UUID) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	if err := database.SetScope(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	// Lock the shop first: intake takes FOR SHARE on it, so no work order can
+	// be added between the move below and the status change.
+	var status string
+	if err := tx.QueryRow(ctx, `SELECT status FROM app.shop WHERE id = $1 FOR UPDATE`, shopID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("lock shop: %w", err)
+	}
+	if status != "active" {
+		return 0, ErrAlreadyInactive
+	}
+
+	var affected int64
+	if target != nil {
+		if err := tx.QueryRow(ctx, `SELECT status FROM app.shop WHERE id = $1 FOR SHARE`, *target).Scan(&status); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, ErrNotFound
+			}
+			return 0, fmt.Errorf("lock target shop: %w", err)
+		}
+		if status != "active" {
+			return 0, ErrTargetInactive
+		}
+		tag, err := tx.Exec(ctx, `
+UPDATE app.work_orders SET shop_id = $2
+WHERE shop_id = $1 AND status <> 'completed'
+`, shopID, *target)
+		if err != nil {
+			return 0, fmt.Errorf("move work orders: %w", err)
+		}
+		affected = tag.RowsAffected()
+	} else {
+		tag, err := tx.Exec(ctx, `
+UPDATE app.work_orders SET held_at = now(), hold_reason = NULLIF($2, '')
+WHERE shop_id = $1 AND status <> 'completed'
+`, shopID, holdReason)
+		if err != nil {
+			return 0, fmt.Errorf("hold work orders: %w", err)
+		}
+		affected = tag.RowsAffected()
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE app.shop
+SET status = 'inactive', deactivated_at = now(), deactivated_by = $2
+WHERE id = $1
+`, shopID, byUserID); err != nil {
+		return 0, fmt.Errorf("deactivate shop: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(affected), nil
+}
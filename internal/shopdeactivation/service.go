@@ -0,0 +1,187 @@
+// Package shopdeactivation takes a shop out of service: it previews the
+// impact, moves or holds the shop's open work orders, marks the shop inactive
+// and optionally deactivates its users. Intake itself refuses inactive shops
+// (see workorder.ErrShopInactive).
+package shopdeactivation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/google/uuid"
+)
+
+// Service defines the deactivation workflow.
+type Service interface {
+	Preview(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID) (*Preview, error)
+	Deactivate(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID, req *Request) (*Result, error)
+}
+
+// UserDeactivator is the part of user.UserService the workflow needs, so
+// deactivated users go through the same checks and session revocation as
+// DELETE /users/{id}.
+type UserDeactivator interface {
+	DeactivateUser(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) error
+}
+
+const maxHoldReasonLength = 500
+
+type service struct {
+	repo  Repository
+	users UserDeactivator
+	audit audit.Recorder
+}
+
+var _ Service = (*service)(nil)
+
+// NewService creates the deactivation service. Deactivations are reported to
+// rec (nil records nothing); the users it deactivates are recorded by users.
+func NewService(repo Repository, users UserDeactivator, rec audit.Recorder) Service {
+	return &service{repo: repo, users: users, audit: audit.OrNop(rec)}
+}
+
+func (s *service) Preview(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID) (*Preview, error) {
+	if !canDeactivate(actor) {
+		return nil, ErrForbidden
+	}
+	shop, err := s.repo.GetShop(ctx, shopID)
+	if err != nil {
+		return nil, err
+	}
+	return s.preview(ctx, shop)
+}
+
+func (s *service) preview(ctx context.Context, shop *ShopRef) (*Preview, error) {
+	users, err := s.repo.ListActiveUsers(ctx, shop.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list active users: %w", err)
+	}
+	workOrders, err := s.repo.ListOpenWorkOrders(ctx, shop.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list open work orders: %w", err)
+	}
+	if users == nil {
+		users = []UserRef{}
+	}
+	if workOrders == nil {
+		workOrders = []WorkOrderRef{}
+	}
+	return &Preview{Shop: *shop, ActiveUsers: users, OpenWorkOrders: workOrders}, nil
+}
+
+// Deactivate requires an explicit decision for open work orders even when the
+// shop has none, so the request means the same thing whatever the data.
+// User deactivation runs after the shop is committed; failures are reported
+// per user rather than undoing the shop.
+func (s *service) Deactivate(ctx context.Context, actor *auth.AuthUser, shopID uuid.UUID, req *Request) (*Result, error) {
+	if !canDeactivate(actor) {
+		return nil, ErrForbidden
+	}
+	if req == nil {
+		return nil, ErrInvalidInput
+	}
+
+	hasTarget := req.TargetShopCode != nil && strings.TrimSpace(*req.TargetShopCode) != ""
+	switch {
+	case hasTarget && req.Hold:
+		return nil, NewValidationError("targetShopCode", "choose either a target shop or hold, not both")
+	case !hasTarget && !req.Hold:
+		return nil, NewValidationError("targetShopCode", "a target shop or hold is required for open work orders")
+	}
+	reason := strings.TrimSpace(req.HoldReason)
+	if reason != "" && !req.Hold {
+		return nil, NewValidationError("holdReason", "only allowed with hold")
+	}
+	if len(reason) > maxHoldReasonLength {
+		return nil, NewValidationError("holdReason", fmt.Sprintf("must be at most %d characters", maxHoldReasonLength))
+	}
+
+	shop, err := s.repo.GetShop(ctx, shopID)
+	if err != nil {
+		return nil, err
+	}
+	if shop.Status != "active" {
+		return nil, ErrAlreadyInactive
+	}
+
+	res := &Result{DeactivatedUsers: []uuid.UUID{}, UserFailures: []UserFailure{}}
+	var targetID *uuid.UUID
+	if hasTarget {
+		// work orders stay within the organization
+		target, err := s.repo.GetShopByCode(ctx, shop.OrganizationID, strings.ToUpper(strings.TrimSpace(*req.TargetShopCode)))
+		if err != nil {
+			return nil, fmt.Errorf("target shop: %w", err)
+		}
+		if target.ID == shop.ID {
+			return nil, NewValidationError("targetShopCode", "must be a different shop")
+		}
+		if target.Status != "active" {
+			return nil, ErrTargetInactive
+		}
+		targetID = &target.ID
+		res.TargetShop = target
+	}
+
+	// Read users before the shop goes inactive, so the list matches the preview
+	var users []UserRef
+	if req.DeactivateUsers {
+		if users, err = s.repo.ListActiveUsers(ctx, shop.ID); err != nil {
+			return nil, fmt.Errorf("list active users: %w", err)
+		}
+	}
+
+	n, err := s.repo.Deactivate(ctx, shop.ID, targetID, reason, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+	if hasTarget {
+		res.MovedWorkOrders = n
+	} else {
+		res.HeldWorkOrders = n
+	}
+	before := *shop
+	shop.Status = "inactive"
+	res.Shop = *shop
+	s.audit.Record(ctx, audit.Entry{
+		Action: "shop.deactivate", EntityType: audit.EntityShop, EntityID: shop.ID.String(),
+		ShopID: &shop.ID, OrganizationID: &shop.OrganizationID,
+		Before: before,
+		After: auditState{
+			ShopRef: *shop, TargetShop: res.TargetShop, HoldReason: reason,
+			MovedWorkOrders: res.MovedWorkOrders, HeldWorkOrders: res.HeldWorkOrders,
+		},
+	})
+	slog.InfoContext(ctx, "shop deactivated", "shop_code", shop.Code, "moved", res.MovedWorkOrders, "held", res.HeldWorkOrders)
+
+	for _, u := range users {
+		if u.ID == actor.ID {
+			continue
+		}
+		if err := s.users.DeactivateUser(ctx, actor, u.ID); err != nil {
+			slog.WarnContext(ctx, "deactivating shop user failed", "target_user_id", u.ID, "shop_code", shop.Code, "error", err)
+			res.UserFailures = append(res.UserFailures, UserFailure{UserID: u.ID, Email: u.Email, Error: err.Error()})
+			continue
+		}
+		res.DeactivatedUsers = append(res.DeactivatedUsers, u.ID)
+	}
+	return res, nil
+}
+
+// auditState is the shop as recorded after deactivation, with what happened
+// to its open work orders.
+type auditState struct {
+	ShopRef
+	TargetShop      *ShopRef `json:"targetShop,omitempty"`
+	HoldReason      string   `json:"holdReason,omitempty"`
+	MovedWorkOrders int      `json:"movedWorkOrders"`
+	HeldWorkOrders  int      `json:"heldWorkOrders"`
+}
+
+// canDeactivate: like activating a shop, deactivating one is SuperAdmin only.
+func canDeactivate(actor *auth.AuthUser) bool {
+	return actor != nil && actor.IsSuperAdmin()
+}
@@ -0,0 +1,249 @@
+package shopdeactivation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/* ---------- fakes ---------- */
+
+type fakeRepo struct {
+	shops      map[uuid.UUID]*ShopRef
+	users      map[uuid.UUID][]UserRef
+	workOrders map[uuid.UUID][]WorkOrderRef
+	held       map[uuid.UUID]string // work order → hold reason
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		shops:      make(map[uuid.UUID]*ShopRef),
+		users:      make(map[uuid.UUID][]UserRef),
+		workOrders: make(map[uuid.UUID][]WorkOrderRef),
+		held:       make(map[uuid.UUID]string),
+	}
+}
+
+func (r *fakeRepo) addShop(code, status string) *ShopRef {
+	s := &ShopRef{ID: uuid.New(), Code: code, Name: "Shop " + code, Status: status}
+	r.shops[s.ID] = s
+	return s
+}
+
+func (r *fakeRepo) GetShop(_ context.Context, id uuid.UUID) (*ShopRef, error) {
+	s, ok := r.shops[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (r *fakeRepo) GetShopByCode(_ context.Context, orgID uuid.UUID, code string) (*ShopRef, error) {
+	for _, s := range r.shops {
+		if s.OrganizationID == orgID && s.Code == code {
+			cp := *s
+			return &cp, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *fakeRepo) ListActiveUsers(_ context.Context, shopID uuid.UUID) ([]UserRef, error) {
+	return r.users[shopID], nil
+}
+
+func (r *fakeRepo) ListOpenWorkOrders(_ context.Context, shopID uuid.UUID) ([]WorkOrderRef, error) {
+	return r.workOrders[shopID], nil
+}
+
+func (r *fakeRepo) Deactivate(_ context.Context, shopID uuid.UUID, target *uuid.UUID, holdReason string, _ uuid.UUID) (int, error) {
+	if r.shops[shopID].Status != "active" {
+		return 0, ErrAlreadyInactive
+	}
+	open := r.workOrders[shopID]
+	if target != nil {
+		r.workOrders[*target] = append(r.workOrders[*target], open...)
+		delete(r.workOrders, shopID)
+	} else {
+		for _, wo := range open {
+			r.held[wo.ID] = holdReason
+		}
+	}
+	r.shops[shopID].Status = "inactive"
+	return len(open), nil
+}
+
+type fakeUsers struct {
+	deactivated []uuid.UUID
+	fail        map[uuid.UUID]error
+}
+
+func (u *fakeUsers) DeactivateUser(_ context.Context, _ *auth.AuthUser, id uuid.UUID) error {
+	if err := u.fail[id]; err != nil {
+		return err
+	}
+	u.deactivated = append(u.deactivated, id)
+	return nil
+}
+
+/* ---------- fixtures ---------- */
+
+type fixture struct {
+	svc    Service
+	repo   *fakeRepo
+	users  *fakeUsers
+	closed *ShopRef
+	other  *ShopRef
+}
+
+func newFixture() *fixture {
+	f := &fixture{repo: newFakeRepo(), users: &fakeUsers{fail: make(map[uuid.UUID]error)}}
+	f.svc = NewService(f.repo, f.users, nil)
+	f.closed = f.repo.addShop("CLOSING", "active")
+	f.other = f.repo.addShop("OTHER", "active")
+	f.repo.users[f.closed.ID] = []UserRef{
+		{ID: uuid.New(), Email: "admin@example.com", RoleCode: auth.RoleAdmin},
+		{ID: uuid.New(), Email: "tech@example.com", RoleCode: auth.RoleBodyman},
+	}
+	f.repo.workOrders[f.closed.ID] = []WorkOrderRef{
+		{ID: uuid.New(), Code: "WO-1", Status: "in_progress", CreatedAt: time.Now()},
+		{ID: uuid.New(), Code: "WO-2", Status: "awaiting_info", CreatedAt: time.Now()},
+	}
+	return f
+}
+
+func superAdmin() *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleSuperAdmin}
+}
+
+func ptr(s string) *string { return &s }
+
+/* ---------- tests ---------- */
+
+func TestPreview(t *testing.T) {
+	f := newFixture()
+
+	p, err := f.svc.Preview(context.Background(), superAdmin(), f.closed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "CLOSING", p.Shop.Code)
+	assert.Len(t, p.ActiveUsers, 2)
+	assert.Len(t, p.OpenWorkOrders, 2)
+
+	p, err = f.svc.Preview(context.Background(), superAdmin(), f.other.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, p.ActiveUsers, "empty lists encode as []")
+	assert.NotNil(t, p.OpenWorkOrders)
+}
+
+func TestDeactivateRequiresSuperAdmin(t *testing.T) {
+	f := newFixture()
+	admin := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleAdmin, Permissions: []string{auth.PermShopsManage, auth.PermWorkOrdersTransfer}, ShopID: &f.closed.ID}
+
+	_, err := f.svc.Preview(context.Background(), admin, f.closed.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = f.svc.Deactivate(context.Background(), admin, f.closed.ID, &Request{Hold: true})
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.Equal(t, "active", f.repo.shops[f.closed.ID].Status)
+}
+
+func TestDeactivateRequiresWorkOrderDecision(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	cases := map[string]*Request{
+		"neither":               {},
+		"both":                  {TargetShopCode: ptr("OTHER"), Hold: true},
+		"reason without hold":   {TargetShopCode: ptr("OTHER"), HoldReason: "closing"},
+		"same shop as target":   {TargetShopCode: ptr("closing")},
+		"blank target, no hold": {TargetShopCode: ptr("  ")},
+	}
+	for name, req := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := f.svc.Deactivate(ctx, superAdmin(), f.closed.ID, req)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+	assert.Equal(t, "active", f.repo.shops[f.closed.ID].Status)
+}
+
+func TestDeactivateMovesWorkOrders(t *testing.T) {
+	f := newFixture()
+
+	res, err := f.svc.Deactivate(context.Background(), superAdmin(), f.closed.ID, &Request{TargetShopCode: ptr(" other ")})
+	require.NoError(t, err)
+	assert.Equal(t, "inactive", res.Shop.Status)
+	require.NotNil(t, res.TargetShop)
+	assert.Equal(t, "OTHER", res.TargetShop.Code)
+	assert.Equal(t, 2, res.MovedWorkOrders)
+	assert.Zero(t, res.HeldWorkOrders)
+	assert.Len(t, f.repo.workOrders[f.other.ID], 2)
+	assert.Empty(t, f.users.deactivated, "users are kept unless asked")
+}
+
+// Test: work orders cannot be moved into another organization's shop
+func TestDeactivateTargetMustShareOrganization(t *testing.T) {
+	f := newFixture()
+	f.repo.shops[f.other.ID].OrganizationID = uuid.New()
+
+	_, err := f.svc.Deactivate(context.Background(), superAdmin(), f.closed.ID, &Request{TargetShopCode: ptr("OTHER")})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "active", f.repo.shops[f.closed.ID].Status)
+}
+
+func TestDeactivateHoldsWorkOrders(t *testing.T) {
+	f := newFixture()
+
+	res, err := f.svc.Deactivate(context.Background(), superAdmin(), f.closed.ID, &Request{Hold: true, HoldReason: " lease ended "})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.HeldWorkOrders)
+	assert.Nil(t, res.TargetShop)
+	for _, wo := range f.repo.workOrders[f.closed.ID] {
+		assert.Equal(t, "lease ended", f.repo.held[wo.ID])
+	}
+}
+
+func TestDeactivateRejectsInactiveShops(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	f.repo.addShop("GONE", "inactive")
+
+	_, err := f.svc.Deactivate(ctx, superAdmin(), f.closed.ID, &Request{TargetShopCode: ptr("GONE")})
+	assert.ErrorIs(t, err, ErrTargetInactive)
+
+	_, err = f.svc.Deactivate(ctx, superAdmin(), f.closed.ID, &Request{Hold: true})
+	require.NoError(t, err)
+	_, err = f.svc.Deactivate(ctx, superAdmin(), f.closed.ID, &Request{Hold: true})
+	assert.ErrorIs(t, err, ErrAlreadyInactive)
+}
+
+// Test: user failures are reported per user and don't undo the shop
+func TestDeactivateUsers(t *testing.T) {
+	f := newFixture()
+	shopUsers := f.repo.users[f.closed.ID]
+	f.users.fail[shopUsers[1].ID] = errors.New("gcip unavailable")
+
+	res, err := f.svc.Deactivate(context.Background(), superAdmin(), f.closed.ID, &Request{Hold: true, DeactivateUsers: true})
+	require.NoError(t, err)
+	assert.Equal(t, "inactive", res.Shop.Status)
+	assert.Equal(t, []uuid.UUID{shopUsers[0].ID}, res.DeactivatedUsers)
+	require.Len(t, res.UserFailures, 1)
+	assert.Equal(t, "tech@example.com", res.UserFailures[0].Email)
+}
+
+func TestDeactivateUsersSkipsActor(t *testing.T) {
+	f := newFixture()
+	actor := superAdmin()
+	f.repo.users[f.closed.ID] = append(f.repo.users[f.closed.ID], UserRef{ID: actor.ID, Email: "root@example.com"})
+
+	res, err := f.svc.Deactivate(context.Background(), actor, f.closed.ID, &Request{Hold: true, DeactivateUsers: true})
+	require.NoError(t, err)
+	assert.Len(t, res.DeactivatedUsers, 2)
+	assert.NotContains(t, f.users.deactivated, actor.ID)
+}
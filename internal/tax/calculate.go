@@ -0,0 +1,110 @@
+package tax
+
+import "time"
+
+// Line is a single billable amount to be taxed (e.g. an estimate or invoice line).
+// Amounts are in cents to avoid floating point rounding.
+type Line struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	AmountCents int64  `json:"amountCents"`
+	Exempt      bool   `json:"exempt"` // e.g. out-of-scope charges such as government fees
+}
+
+// ComponentAmount is the tax of one component on one line (or summed over all lines).
+type ComponentAmount struct {
+	Kind         Kind   `json:"kind"`
+	Name         string `json:"name"`
+	Rate         Rate   `json:"rate"`
+	TaxableCents int64  `json:"taxableCents"`
+	TaxCents     int64  `json:"taxCents"`
+}
+
+// LineTax is the tax breakdown for a single line.
+type LineTax struct {
+	LineID      string            `json:"lineId"`
+	Description string            `json:"description"`
+	AmountCents int64             `json:"amountCents"`
+	Exempt      bool              `json:"exempt"`
+	Taxes       []ComponentAmount `json:"taxes"`
+	TaxCents    int64             `json:"taxCents"`
+	TotalCents  int64             `json:"totalCents"`
+}
+
+// Breakdown is the result of taxing a document.
+// Tax is rounded per line and per component, so line totals always add up
+// to the document totals exactly.
+type Breakdown struct {
+	Province      string            `json:"province"`
+	TaxDate       time.Time         `json:"taxDate"`
+	EffectiveFrom time.Time         `json:"effectiveFrom"`
+	Lines         []LineTax         `json:"lines"`
+	Components    []ComponentAmount `json:"components"`
+	SubtotalCents int64             `json:"subtotalCents"`
+	TaxCents      int64             `json:"taxCents"`
+	TotalCents    int64             `json:"totalCents"`
+}
+
+// Calculate taxes the lines using the schedule in effect for the province on the given date.
+func (t *Table) Calculate(province string, on time.Time, lines []Line) (*Breakdown, error) {
+	schedule, err := t.Lookup(province, on)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Breakdown{
+		Province:      schedule.Province,
+		TaxDate:       civilDate(on),
+		EffectiveFrom: schedule.EffectiveFrom,
+		Lines:         make([]LineTax, 0, len(lines)),
+		Components:    make([]ComponentAmount, len(schedule.Components)),
+	}
+	for i, c := range schedule.Components {
+		out.Components[i] = ComponentAmount{Kind: c.Kind, Name: c.Name, Rate: c.Rate}
+	}
+
+	for _, l := range lines {
+		lt := LineTax{
+			LineID:      l.ID,
+			Description: l.Description,
+			AmountCents: l.AmountCents,
+			Exempt:      l.Exempt,
+			Taxes:       make([]ComponentAmount, 0, len(schedule.Components)),
+		}
+		if !l.Exempt {
+			for i, c := range schedule.Components {
+				amt := applyRate(l.AmountCents, c.Rate)
+				lt.Taxes = append(lt.Taxes, ComponentAmount{
+					Kind: c.Kind, Name: c.Name, Rate: c.Rate,
+					TaxableCents: l.AmountCents,
+					TaxCents:     amt,
+				})
+				lt.TaxCents += amt
+				out.Components[i].TaxableCents += l.AmountCents
+				out.Components[i].TaxCents += amt
+			}
+		}
+		lt.TotalCents = lt.AmountCents + lt.TaxCents
+
+		out.Lines = append(out.Lines, lt)
+		out.SubtotalCents += lt.AmountCents
+		out.TaxCents += lt.TaxCents
+	}
+	out.TotalCents = out.SubtotalCents + out.TaxCents
+	return out, nil
+}
+
+// Calculate taxes the lines using the built-in default table.
+func Calculate(province string, on time.Time, lines []Line) (*Breakdown, error) {
+	return defaultTable.Calculate(province, on, lines)
+}
+
+// applyRate returns amount * rate rounded half away from zero to the nearest cent.
+// Negative amounts (discounts, credits) produce negative tax.
+func applyRate(amountCents int64, rate Rate) int64 {
+	n := amountCents * int64(rate)
+	if n >= 0 {
+		return (n + RateDenominator/2) / RateDenominator
+	}
+	return -((-n + RateDenominator/2) / RateDenominator)
+}
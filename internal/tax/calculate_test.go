@@ -0,0 +1,187 @@
+package tax
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var asOf = date(2025, time.June, 1)
+
+// Test: current rates for every province/territory on a $1,000.00 repair
+func TestCalculatePerProvince(t *testing.T) {
+	tests := []struct {
+		province   string
+		components map[string]int64 // component name -> tax cents
+		taxCents   int64
+	}{
+		{"AB", map[string]int64{"GST": 5000}, 5000},
+		{"BC", map[string]int64{"GST": 5000, "PST": 7000}, 12000},
+		{"MB", map[string]int64{"GST": 5000, "RST": 7000}, 12000},
+		{"NB", map[string]int64{"HST": 15000}, 15000},
+		{"NL", map[string]int64{"HST": 15000}, 15000},
+		{"NS", map[string]int64{"HST": 14000}, 14000},
+		{"NT", map[string]int64{"GST": 5000}, 5000},
+		{"NU", map[string]int64{"GST": 5000}, 5000},
+		{"ON", map[string]int64{"HST": 13000}, 13000},
+		{"PE", map[string]int64{"HST": 15000}, 15000},
+		{"QC", map[string]int64{"GST": 5000, "QST": 9975}, 14975},
+		{"SK", map[string]int64{"GST": 5000, "PST": 6000}, 11000},
+		{"YT", map[string]int64{"GST": 5000}, 5000},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.province, func(t *testing.T) {
+			b, err := Calculate(tc.province, asOf, []Line{{ID: "1", Description: "Repair", AmountCents: 100000}})
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.province, b.Province)
+			assert.Equal(t, int64(100000), b.SubtotalCents)
+			assert.Equal(t, tc.taxCents, b.TaxCents)
+			assert.Equal(t, 100000+tc.taxCents, b.TotalCents)
+
+			require.Len(t, b.Components, len(tc.components))
+			for _, c := range b.Components {
+				want, ok := tc.components[c.Name]
+				require.True(t, ok, "unexpected component %s", c.Name)
+				assert.Equal(t, want, c.TaxCents, c.Name)
+				assert.Equal(t, int64(100000), c.TaxableCents, c.Name)
+			}
+		})
+	}
+}
+
+// Test: rate changes are effective-dated and do not rewrite history
+func TestCalculateEffectiveDated(t *testing.T) {
+	tests := []struct {
+		name     string
+		province string
+		on       time.Time
+		taxCents int64
+	}{
+		{"NS before HST cut", "NS", date(2025, time.March, 31), 15000},
+		{"NS on HST cut", "NS", date(2025, time.April, 1), 14000},
+		{"MB before RST cut", "MB", date(2019, time.June, 30), 13000},
+		{"MB after RST cut", "MB", date(2019, time.July, 1), 12000},
+		{"SK before PST increase", "SK", date(2017, time.March, 22), 10000},
+		{"SK after PST increase", "SK", date(2017, time.March, 23), 11000},
+		{"PE before HST increase", "PE", date(2016, time.September, 30), 14000},
+		{"NB after HST increase", "NB", date(2016, time.July, 1), 15000},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := Calculate(tc.province, tc.on, []Line{{ID: "1", AmountCents: 100000}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.taxCents, b.TaxCents)
+		})
+	}
+}
+
+// Test: the date is taken from the caller's location, not UTC
+func TestCalculateUsesLocalCalendarDate(t *testing.T) {
+	halifax, err := time.LoadLocation("America/Halifax")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+
+	// 2025-03-31 22:00 in Halifax is already April 1st in UTC.
+	b, err := Calculate("NS", time.Date(2025, time.March, 31, 22, 0, 0, 0, halifax), []Line{{AmountCents: 100000}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(15000), b.TaxCents)
+}
+
+// Test: line-level breakdown with rounding and exempt lines
+func TestCalculateLineBreakdown(t *testing.T) {
+	lines := []Line{
+		{ID: "labour", Description: "PDR labour", AmountCents: 33333},
+		{ID: "parts", Description: "Clips", AmountCents: 1999},
+		{ID: "fee", Description: "Tire levy", AmountCents: 400, Exempt: true},
+	}
+
+	b, err := Calculate("QC", asOf, lines)
+	require.NoError(t, err)
+	require.Len(t, b.Lines, 3)
+
+	// labour: GST 33333*5% = 1666.65 -> 1667, QST 33333*9.975% = 3324.97 -> 3325
+	labour := b.Lines[0]
+	require.Len(t, labour.Taxes, 2)
+	assert.Equal(t, int64(1667), labour.Taxes[0].TaxCents)
+	assert.Equal(t, int64(3325), labour.Taxes[1].TaxCents)
+	assert.Equal(t, int64(4992), labour.TaxCents)
+	assert.Equal(t, int64(38325), labour.TotalCents)
+
+	// parts: GST 99.95 -> 100, QST 199.40 -> 199
+	parts := b.Lines[1]
+	assert.Equal(t, int64(100), parts.Taxes[0].TaxCents)
+	assert.Equal(t, int64(199), parts.Taxes[1].TaxCents)
+
+	// exempt line carries no tax
+	fee := b.Lines[2]
+	assert.Empty(t, fee.Taxes)
+	assert.Equal(t, int64(0), fee.TaxCents)
+	assert.Equal(t, int64(400), fee.TotalCents)
+
+	// document totals are the sum of the lines
+	assert.Equal(t, int64(35732), b.SubtotalCents)
+	assert.Equal(t, int64(1667+3325+100+199), b.TaxCents)
+	assert.Equal(t, b.SubtotalCents+b.TaxCents, b.TotalCents)
+	assert.Equal(t, int64(33333+1999), b.Components[0].TaxableCents)
+	assert.Equal(t, int64(1767), b.Components[0].TaxCents)
+	assert.Equal(t, int64(3524), b.Components[1].TaxCents)
+}
+
+// Test: credits round symmetrically
+func TestCalculateNegativeAmounts(t *testing.T) {
+	b, err := Calculate("ON", asOf, []Line{{ID: "credit", AmountCents: -1050}})
+	require.NoError(t, err)
+	// -10.50 * 13% = -1.365 -> -1.37
+	assert.Equal(t, int64(-137), b.TaxCents)
+}
+
+// Test: lookup errors
+func TestCalculateErrors(t *testing.T) {
+	_, err := Calculate("XX", asOf, nil)
+	assert.True(t, errors.Is(err, ErrUnknownProvince))
+
+	_, err = Calculate("ON", date(2001, time.January, 1), nil)
+	assert.True(t, errors.Is(err, ErrNoRateInEffect))
+
+	// lower case / whitespace is accepted like the shop service normalizes it
+	b, err := Calculate(" on ", asOf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ON", b.Province)
+}
+
+// Test: a custom table can add a future rate without touching past documents
+func TestNewTableAddsFutureRate(t *testing.T) {
+	table, err := NewTable(
+		Schedule{Province: "AB", EffectiveFrom: date(2008, time.January, 1), Components: []Component{gst()}},
+		Schedule{Province: "AB", EffectiveFrom: date(2030, time.January, 1), Components: []Component{gst(), {Kind: PST, Name: "PST", Rate: 3000}}},
+	)
+	require.NoError(t, err)
+
+	before, err := table.Calculate("AB", date(2029, time.December, 31), []Line{{AmountCents: 10000}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), before.TaxCents)
+
+	after, err := table.Calculate("AB", date(2030, time.January, 1), []Line{{AmountCents: 10000}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(800), after.TaxCents)
+
+	_, err = NewTable(
+		Schedule{Province: "AB", EffectiveFrom: date(2008, time.January, 1), Components: []Component{gst()}},
+		Schedule{Province: "AB", EffectiveFrom: date(2008, time.January, 1), Components: []Component{gst()}},
+	)
+	assert.Error(t, err)
+}
+
+// Test: rate formatting
+func TestRatePercent(t *testing.T) {
+	assert.Equal(t, "5%", Rate(5000).Percent())
+	assert.Equal(t, "9.975%", Rate(9975).Percent())
+	assert.Equal(t, "14.5%", Rate(14500).Percent())
+}
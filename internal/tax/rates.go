@@ -0,0 +1,201 @@
+// Package tax computes Canadian sales tax (GST/HST/PST/QST) for estimates and invoices.
+// It is a pure calculation package: it knows nothing about HTTP or the database.
+// Callers pass the shop's province, the document date and the taxable lines.
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnknownProvince indicates the province code is not a Canadian province/territory.
+	ErrUnknownProvince = errors.New("unknown province code")
+
+	// ErrNoRateInEffect indicates no rate schedule covers the requested date.
+	ErrNoRateInEffect = errors.New("no tax rate in effect for date")
+)
+
+// Kind identifies the type of a sales tax component.
+type Kind string
+
+const (
+	GST Kind = "GST" // federal Goods and Services Tax
+	HST Kind = "HST" // Harmonized Sales Tax (replaces GST + PST in participating provinces)
+	PST Kind = "PST" // provincial sales tax (BC, SK; called RST in MB)
+	QST Kind = "QST" // Québec Sales Tax
+)
+
+// Rate is a tax rate expressed in hundred-thousandths (1/100000).
+// e.g. 5% = 5000, 9.975% = 9975. Integer rates keep calculations exact.
+type Rate int64
+
+// RateDenominator is the scale of Rate (100% = 100000).
+const RateDenominator = 100000
+
+// Percent renders the rate as a human-readable percentage (e.g. "9.975%").
+func (r Rate) Percent() string {
+	whole := int64(r) / 1000
+	frac := int64(r) % 1000
+	if frac == 0 {
+		return fmt.Sprintf("%d%%", whole)
+	}
+	return strings.TrimRight(fmt.Sprintf("%d.%03d", whole, frac), "0") + "%"
+}
+
+// Component is one tax levied on a sale (e.g. GST 5%).
+type Component struct {
+	Kind Kind   `json:"kind"`
+	Name string `json:"name"` // display label, e.g. "RST" for Manitoba's PST
+	Rate Rate   `json:"rate"`
+}
+
+// Schedule is the set of taxes in a province from EffectiveFrom until the next schedule starts.
+type Schedule struct {
+	Province      string      `json:"province"`
+	EffectiveFrom time.Time   `json:"effectiveFrom"`
+	Components    []Component `json:"components"`
+}
+
+// CombinedRate returns the sum of all component rates.
+func (s Schedule) CombinedRate() Rate {
+	var total Rate
+	for _, c := range s.Components {
+		total += c.Rate
+	}
+	return total
+}
+
+// Table holds effective-dated schedules per province.
+// Adding a new schedule never rewrites history: documents dated before its
+// EffectiveFrom keep resolving to the previous schedule.
+type Table struct {
+	schedules map[string][]Schedule // sorted by EffectiveFrom ascending
+}
+
+// validProvinces mirrors ck_shop_province_whitelist / ck_customers_province_ca.
+var validProvinces = map[string]bool{
+	"AB": true, "BC": true, "MB": true, "NB": true,
+	"NL": true, "NT": true, "NS": true, "NU": true,
+	"ON": true, "PE": true, "QC": true, "SK": true, "YT": true,
+}
+
+// NewTable builds a rate table from the given schedules.
+// Returns an error if a province is unknown, a schedule has no components,
+// or two schedules for the same province share an effective date.
+func NewTable(schedules ...Schedule) (*Table, error) {
+	t := &Table{schedules: make(map[string][]Schedule)}
+	for _, s := range schedules {
+		s.Province = normalizeProvince(s.Province)
+		if !validProvinces[s.Province] {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownProvince, s.Province)
+		}
+		if len(s.Components) == 0 {
+			return nil, fmt.Errorf("tax schedule for %s effective %s has no components", s.Province, s.EffectiveFrom.Format(time.DateOnly))
+		}
+		for _, c := range s.Components {
+			if c.Rate < 0 || c.Rate > RateDenominator {
+				return nil, fmt.Errorf("tax schedule for %s: invalid %s rate %d", s.Province, c.Kind, c.Rate)
+			}
+		}
+		s.EffectiveFrom = civilDate(s.EffectiveFrom)
+		t.schedules[s.Province] = append(t.schedules[s.Province], s)
+	}
+
+	for province, list := range t.schedules {
+		sort.Slice(list, func(i, j int) bool { return list[i].EffectiveFrom.Before(list[j].EffectiveFrom) })
+		for i := 1; i < len(list); i++ {
+			if list[i].EffectiveFrom.Equal(list[i-1].EffectiveFrom) {
+				return nil, fmt.Errorf("tax schedule for %s has duplicate effective date %s", province, list[i].EffectiveFrom.Format(time.DateOnly))
+			}
+		}
+	}
+	return t, nil
+}
+
+// Lookup returns the schedule in effect for the province on the given date.
+func (t *Table) Lookup(province string, on time.Time) (Schedule, error) {
+	province = normalizeProvince(province)
+	if !validProvinces[province] {
+		return Schedule{}, fmt.Errorf("%w: %q", ErrUnknownProvince, province)
+	}
+
+	day := civilDate(on)
+	list := t.schedules[province]
+	for i := len(list) - 1; i >= 0; i-- {
+		if !list[i].EffectiveFrom.After(day) {
+			return list[i], nil
+		}
+	}
+	return Schedule{}, fmt.Errorf("%w: %s on %s", ErrNoRateInEffect, province, day.Format(time.DateOnly))
+}
+
+// Schedules returns all schedules for a province, oldest first.
+func (t *Table) Schedules(province string) []Schedule {
+	list := t.schedules[normalizeProvince(province)]
+	out := make([]Schedule, len(list))
+	copy(out, list)
+	return out
+}
+
+// ---- default Canadian rates ----
+
+func date(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+func gst() Component { return Component{Kind: GST, Name: "GST", Rate: 5000} }
+
+func hst(rate Rate) Component { return Component{Kind: HST, Name: "HST", Rate: rate} }
+
+// defaultSchedules lists the historical and current rates we bill with.
+// When a province announces a change, append a new schedule with its effective date.
+var defaultSchedules = []Schedule{
+	// GST-only provinces and territories
+	{Province: "AB", EffectiveFrom: date(2008, time.January, 1), Components: []Component{gst()}},
+	{Province: "NT", EffectiveFrom: date(2008, time.January, 1), Components: []Component{gst()}},
+	{Province: "NU", EffectiveFrom: date(2008, time.January, 1), Components: []Component{gst()}},
+	{Province: "YT", EffectiveFrom: date(2008, time.January, 1), Components: []Component{gst()}},
+
+	// GST + PST provinces
+	{Province: "BC", EffectiveFrom: date(2013, time.April, 1), Components: []Component{gst(), {Kind: PST, Name: "PST", Rate: 7000}}},
+	{Province: "MB", EffectiveFrom: date(2013, time.July, 1), Components: []Component{gst(), {Kind: PST, Name: "RST", Rate: 8000}}},
+	{Province: "MB", EffectiveFrom: date(2019, time.July, 1), Components: []Component{gst(), {Kind: PST, Name: "RST", Rate: 7000}}},
+	{Province: "SK", EffectiveFrom: date(2013, time.January, 1), Components: []Component{gst(), {Kind: PST, Name: "PST", Rate: 5000}}},
+	{Province: "SK", EffectiveFrom: date(2017, time.March, 23), Components: []Component{gst(), {Kind: PST, Name: "PST", Rate: 6000}}},
+
+	// GST + QST
+	{Province: "QC", EffectiveFrom: date(2013, time.January, 1), Components: []Component{gst(), {Kind: QST, Name: "QST", Rate: 9975}}},
+
+	// HST provinces
+	{Province: "ON", EffectiveFrom: date(2010, time.July, 1), Components: []Component{hst(13000)}},
+	{Province: "NB", EffectiveFrom: date(2010, time.July, 1), Components: []Component{hst(13000)}},
+	{Province: "NB", EffectiveFrom: date(2016, time.July, 1), Components: []Component{hst(15000)}},
+	{Province: "NL", EffectiveFrom: date(2010, time.July, 1), Components: []Component{hst(13000)}},
+	{Province: "NL", EffectiveFrom: date(2016, time.July, 1), Components: []Component{hst(15000)}},
+	{Province: "NS", EffectiveFrom: date(2010, time.July, 1), Components: []Component{hst(15000)}},
+	{Province: "NS", EffectiveFrom: date(2025, time.April, 1), Components: []Component{hst(14000)}},
+	{Province: "PE", EffectiveFrom: date(2013, time.April, 1), Components: []Component{hst(14000)}},
+	{Province: "PE", EffectiveFrom: date(2016, time.October, 1), Components: []Component{hst(15000)}},
+}
+
+var defaultTable = func() *Table {
+	t, err := NewTable(defaultSchedules...)
+	if err != nil {
+		panic(fmt.Sprintf("tax: invalid default schedules: %v", err))
+	}
+	return t
+}()
+
+// DefaultTable returns the built-in Canadian rate table.
+func DefaultTable() *Table { return defaultTable }
+
+// ---- helpers ----
+
+func normalizeProvince(p string) string { return strings.ToUpper(strings.TrimSpace(p)) }
+
+// civilDate drops the time of day, keeping the calendar date as seen in t's location.
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,225 @@
+package user
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/cache"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// userChangedChannel is the Postgres NOTIFY channel used to tell other
+// instances that a cached user row is stale. The payload is the user ID, or
+// purgeAllPayload to drop every cached user.
+const (
+	userChangedChannel = "app_user_changed"
+	purgeAllPayload    = "*"
+)
+
+// CacheConfig controls the user lookup cache.
+type CacheConfig struct {
+	Size int           // maximum number of cached users
+	TTL  time.Duration // how long a row is trusted without invalidation
+	// Notify enables cross-instance invalidation through LISTEN/NOTIFY.
+	Notify bool
+}
+
+// CachedRepository wraps a Repository and caches GetByExternalID, the lookup
+// the auth middleware makes on every request. Writes that change what the
+// middleware relies on (role, shop, active state, token version, email
+// verification) drop the user from the cache, and from other instances' caches
+// when Notify is enabled.
+type CachedRepository struct {
+	Repository
+
+	db     *pgxpool.Pool // for NOTIFY/LISTEN; nil disables it
+	notify bool
+
+	users *cache.LRU[string, *User]     // by external ID
+	ids   *cache.LRU[uuid.UUID, string] // user ID -> external ID
+
+	// A load that overlaps an invalidation of the same user must not be
+	// cached. seq numbers invalidations; invalidated keeps each user's latest
+	// one and purgedAt the latest purge. mu orders the check-and-store in
+	// GetByExternalID against invalidations.
+	mu          sync.Mutex
+	seq         atomic.Uint64
+	purgedAt    uint64
+	invalidated *cache.LRU[uuid.UUID, uint64]
+}
+
+var _ Repository = (*CachedRepository)(nil)
+
+// NewCachedRepository wraps repo with a lookup cache. db is only used when
+// cfg.Notify is set.
+func NewCachedRepository(repo Repository, db *pgxpool.Pool, cfg CacheConfig) *CachedRepository {
+	return &CachedRepository{
+		Repository: repo,
+		db:         db,
+		notify:     cfg.Notify && db != nil,
+		users:      cache.New[string, *User](cfg.Size, cfg.TTL),
+		ids:        cache.New[uuid.UUID, string](cfg.Size, cfg.TTL),
+		// marks only matter while a load is in flight
+		invalidated: cache.New[uuid.UUID, uint64](cfg.Size, time.Minute),
+	}
+}
+
+// GetByExternalID returns the cached user or loads and caches it. A load that
+// raced an invalidation of the user is returned but not cached, since it may
+// predate the write. Callers must not modify the returned user.
+func (r *CachedRepository) GetByExternalID(ctx context.Context, externalID string) (*User, error) {
+	if u, ok := r.users.Get(externalID); ok {
+		r.ids.Get(u.ID) // keep both caches' recency in step so Invalidate finds it
+		return u, nil
+	}
+	start := r.seq.Load()
+	u, err := r.Repository.GetByExternalID(ctx, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if inv, ok := r.invalidated.Get(u.ID); (ok && inv > start) || r.purgedAt > start {
+		return u, nil
+	}
+	r.users.Set(externalID, u)
+	r.ids.Set(u.ID, externalID)
+	return u, nil
+}
+
+func (r *CachedRepository) Update(ctx context.Context, id uuid.UUID, in *UpdateUserInput) (*User, error) {
+	u, err := r.Repository.Update(ctx, id, in)
+	r.changed(ctx, id)
+	return u, err
+}
+
+func (r *CachedRepository) Deactivate(ctx context.Context, id uuid.UUID, byUserID *uuid.UUID) error {
+	err := r.Repository.Deactivate(ctx, id, byUserID)
+	r.changed(ctx, id)
+	return err
+}
+
+func (r *CachedRepository) Reactivate(ctx context.Context, id uuid.UUID) error {
+	err := r.Repository.Reactivate(ctx, id)
+	r.changed(ctx, id)
+	return err
+}
+
+func (r *CachedRepository) IncrementTokenVersion(ctx context.Context, id uuid.UUID) error {
+	err := r.Repository.IncrementTokenVersion(ctx, id)
+	r.changed(ctx, id)
+	return err
+}
+
+func (r *CachedRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID) error {
+	err := r.Repository.MarkEmailVerified(ctx, id)
+	r.changed(ctx, id)
+	return err
+}
+
+// Invalidate drops a user from the local cache, and keeps a load of the user
+// that is already in flight from caching what it read.
+func (r *CachedRepository) Invalidate(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invalidated.Set(id, r.seq.Add(1))
+	if externalID, ok := r.ids.Get(id); ok {
+		r.users.Delete(externalID)
+		r.ids.Delete(id)
+	}
+}
+
+// purge drops every user from the local cache, including loads in flight.
+func (r *CachedRepository) purge() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.purgedAt = r.seq.Add(1)
+	r.users.Purge()
+	r.ids.Purge()
+}
+
+// PurgeAll drops every cached user here and on the other instances. Call it
+// after changes that affect many users at once, such as a role's permissions.
+func (r *CachedRepository) PurgeAll(ctx context.Context) {
+	r.purge()
+	if !r.notify {
+		return
+	}
+	if _, err := r.db.Exec(context.WithoutCancel(ctx), `SELECT pg_notify($1, $2)`, userChangedChannel, purgeAllPayload); err != nil {
+		log.Printf("WARNING: failed to publish user cache purge: %v", err)
+	}
+}
+
+// changed invalidates the user locally and tells the other instances.
+// It runs even when the write failed, since a failed write may still have
+// committed (e.g. a timeout after the update).
+func (r *CachedRepository) changed(ctx context.Context, id uuid.UUID) {
+	r.Invalidate(id)
+	if !r.notify {
+		return
+	}
+	if _, err := r.db.Exec(context.WithoutCancel(ctx), `SELECT pg_notify($1, $2)`, userChangedChannel, id.String()); err != nil {
+		log.Printf("WARNING: failed to publish user cache invalidation for %s: %v", id, err)
+	}
+}
+
+// Listen applies invalidations published by other instances until ctx is
+// cancelled. It holds one pooled connection and reconnects after errors,
+// purging the cache each time since notifications may have been missed.
+// It returns immediately when Notify is disabled.
+func (r *CachedRepository) Listen(ctx context.Context) {
+	if !r.notify {
+		return
+	}
+	backoff := time.Second
+	for {
+		err := r.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("WARNING: user cache listener stopped: %v; retrying in %s", err, backoff)
+		r.purge()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+func (r *CachedRepository) listen(ctx context.Context) error {
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection stays in LISTEN mode, so it is never handed back to the pool.
+	pgConn := conn.Hijack()
+	defer pgConn.Close(context.Background())
+
+	if _, err := pgConn.Exec(ctx, "LISTEN "+userChangedChannel); err != nil {
+		return err
+	}
+	for {
+		n, err := pgConn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		if n.Payload == purgeAllPayload {
+			r.purge()
+			continue
+		}
+		id, err := uuid.Parse(n.Payload)
+		if err != nil {
+			log.Printf("WARNING: ignoring user cache notification %q: %v", n.Payload, err)
+			continue
+		}
+		r.Invalidate(id)
+	}
+}
@@ -0,0 +1,87 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowRepo serves one user. When gate is set, a load reads the row, signals
+// read and waits on gate before returning it.
+type slowRepo struct {
+	Repository // unimplemented methods panic
+
+	user *User
+	gate chan struct{}
+	read chan struct{}
+	gets int
+}
+
+func (r *slowRepo) GetByExternalID(context.Context, string) (*User, error) {
+	r.gets++
+	u := *r.user
+	if r.gate != nil {
+		r.read <- struct{}{}
+		<-r.gate
+	}
+	return &u, nil
+}
+
+func newCachedFixture() (*CachedRepository, *slowRepo) {
+	repo := &slowRepo{user: &User{ID: uuid.New(), ExternalID: "uid-1", IsActive: true}}
+	return NewCachedRepository(repo, nil, CacheConfig{Size: 10, TTL: time.Minute}), repo
+}
+
+func TestCachedRepositoryCachesLoads(t *testing.T) {
+	cached, repo := newCachedFixture()
+	ctx := context.Background()
+
+	for range 3 {
+		_, err := cached.GetByExternalID(ctx, "uid-1")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, repo.gets)
+
+	cached.Invalidate(repo.user.ID)
+	_, err := cached.GetByExternalID(ctx, "uid-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.gets)
+}
+
+// Test: a load that overlaps an invalidation is not cached, so the stale row
+// it may have read is not served afterwards
+func TestCachedRepositorySkipsLoadsThatRaceInvalidation(t *testing.T) {
+	for name, invalidate := range map[string]func(*CachedRepository, uuid.UUID){
+		"invalidate": func(c *CachedRepository, id uuid.UUID) { c.Invalidate(id) },
+		"purge":      func(c *CachedRepository, _ uuid.UUID) { c.PurgeAll(context.Background()) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cached, repo := newCachedFixture()
+			ctx := context.Background()
+			repo.gate, repo.read = make(chan struct{}), make(chan struct{})
+
+			done := make(chan *User)
+			go func() {
+				u, _ := cached.GetByExternalID(ctx, "uid-1")
+				done <- u
+			}()
+			// the load has read the old row; the write and its invalidation land
+			// before it returns
+			<-repo.read
+			id := repo.user.ID
+			repo.user = &User{ID: id, ExternalID: "uid-1", IsActive: false}
+			invalidate(cached, id)
+			close(repo.gate)
+			<-done
+
+			repo.gate = nil
+			u, err := cached.GetByExternalID(ctx, "uid-1")
+			require.NoError(t, err)
+			assert.False(t, u.IsActive, "served a row loaded before the invalidation")
+		})
+	}
+}
@@ -7,11 +7,16 @@ package user
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/smtp"
-	"os"
 	"strconv"
 	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/metrics"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // SMTPSender is a generic SMTP-based implementation of EmailSender.
@@ -45,39 +50,6 @@ func NewSMTPSender(host string, port int, username, password, from string) (*SMT
 	}, nil
 }
 
-// NewSMTPSenderFromEnv creates a SMTPSender using environment variables.
-// This is convenient for local dev (e.g. Gmail App Password).
-//
-//   - SMTP_HOST      (default: smtp.gmail.com)
-//   - SMTP_PORT      (default: 587)
-//   - SMTP_USERNAME  (required, e.g. your Gmail address)
-//   - SMTP_PASSWORD  (required, e.g. Gmail App Password)
-//   - SMTP_FROM      (optional; if empty, defaults to SMTP_USERNAME)
-func NewSMTPSenderFromEnv() (*SMTPSender, error) {
-	host := os.Getenv("SMTP_HOST")
-	if host == "" {
-		host = "smtp.gmail.com"
-	}
-
-	port := 587
-	if portStr := os.Getenv("SMTP_PORT"); portStr != "" {
-		p, err := strconv.Atoi(portStr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid SMTP_PORT %q: %w", portStr, err)
-		}
-		port = p
-	}
-
-	username := os.Getenv("SMTP_USERNAME")
-	password := os.Getenv("SMTP_PASSWORD")
-	from := os.Getenv("SMTP_FROM")
-	if from == "" {
-		from = username
-	}
-
-	return NewSMTPSender(host, port, username, password, from)
-}
-
 // SendWelcomePasswordSetup implements EmailSender for the welcome flow.
 func (s *SMTPSender) SendWelcomePasswordSetup(ctx context.Context, email, firstName, link string) error {
 	subject := "Welcome to HavenzSure – Set your password"
@@ -98,7 +70,9 @@ Best regards,
 Havenz Tech Team
 `, firstName, link)
 
-	return s.sendPlainText(email, subject, body)
+	err := s.sendPlainText(ctx, email, subject, body)
+	metrics.EmailSent("welcome", err)
+	return err
 }
 
 // SendPasswordSetupReminder implements EmailSender for the reminder flow.
@@ -121,11 +95,37 @@ Best regards,
 Havenz Tech Team
 `, firstName, link)
 
-	return s.sendPlainText(email, subject, body)
+	err := s.sendPlainText(ctx, email, subject, body)
+	metrics.EmailSent("reminder", err)
+	return err
+}
+
+// Ping checks that the SMTP server accepts connections and greets, without
+// authenticating or sending anything.
+func (s *SMTPSender) Ping(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(s.host, strconv.Itoa(s.port)))
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return c.Quit()
 }
 
 // sendPlainText builds a standard plaintext email with basic headers and sends it via SMTP.
-func (s *SMTPSender) sendPlainText(to, subject, body string) error {
+func (s *SMTPSender) sendPlainText(ctx context.Context, to, subject, body string) (err error) {
+	ctx, span := tracing.Start(ctx, "smtp.SendMail", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("server.address", s.host), attribute.Int("server.port", s.port)))
+	defer func() { tracing.End(span, err) }()
+
 	if strings.TrimSpace(to) == "" {
 		return fmt.Errorf("smtp: recipient email is empty")
 	}
@@ -148,6 +148,6 @@ func (s *SMTPSender) sendPlainText(to, subject, body string) error {
 		return fmt.Errorf("smtp send to %s failed: %w", to, err)
 	}
 
-	log.Printf("SMTP email sent to %s with subject %q", to, subject)
+	slog.InfoContext(ctx, "email sent", "to", to, "subject", subject)
 	return nil
 }
@@ -3,7 +3,7 @@ package user
 import (
 	"encoding/json"
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -31,6 +31,7 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Put("/{id}/deactivate", h.deactivate)
 	r.Put("/{id}/reactivate", h.reactivate)
 	r.Post("/{id}/resend-password-link", h.resendPasswordSetupLink)
+	r.Post("/{id}/revoke-sessions", h.revokeSessions)
 }
 
 /* -------------------- CRUD Handlers -------------------- */
@@ -38,19 +39,19 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
 	actor, err := auth.GetAuthUser(r.Context())
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
 	var in CreateUserInput
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		writeError(w, ErrInvalidInput)
+		writeError(w, r, ErrInvalidInput)
 		return
 	}
 
 	user, err := h.svc.CreateUser(r.Context(), actor, &in)
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
@@ -60,20 +61,20 @@ func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
 	actor, err := auth.GetAuthUser(r.Context())
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, ErrInvalidInput)
+		writeError(w, r, ErrInvalidInput)
 		return
 	}
 
 	user, err := h.svc.GetUserByID(r.Context(), actor, id)
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
@@ -83,7 +84,7 @@ func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
 	actor, err := auth.GetAuthUser(r.Context())
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 	limit := atoiDefault(r.URL.Query().Get("limit"), 50)
@@ -97,7 +98,7 @@ func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
 
 	users, err := h.svc.ListUsers(r.Context(), actor, limit, offset)
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
@@ -112,26 +113,26 @@ func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
 	actor, err := auth.GetAuthUser(r.Context())
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
 	idStr := strings.TrimSpace(chi.URLParam(r, "id"))
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, ErrInvalidInput)
+		writeError(w, r, ErrInvalidInput)
 		return
 	}
 
 	var in UpdateUserInput
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		writeError(w, ErrInvalidInput)
+		writeError(w, r, ErrInvalidInput)
 		return
 	}
 
 	user, err := h.svc.UpdateUser(r.Context(), actor, id, &in)
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
@@ -141,19 +142,19 @@ func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) deactivate(w http.ResponseWriter, r *http.Request) {
 	actor, err := auth.GetAuthUser(r.Context())
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
 	idStr := strings.TrimSpace(chi.URLParam(r, "id"))
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, ErrInvalidInput)
+		writeError(w, r, ErrInvalidInput)
 		return
 	}
 
 	if err := h.svc.DeactivateUser(r.Context(), actor, id); err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
@@ -163,18 +164,18 @@ func (h *Handler) deactivate(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) reactivate(w http.ResponseWriter, r *http.Request) {
 	actor, err := auth.GetAuthUser(r.Context())
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 	idStr := strings.TrimSpace(chi.URLParam(r, "id"))
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, ErrInvalidInput)
+		writeError(w, r, ErrInvalidInput)
 		return
 	}
 
 	if err := h.svc.ReactivateUser(r.Context(), actor, id); err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
@@ -186,19 +187,19 @@ func (h *Handler) reactivate(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) resendPasswordSetupLink(w http.ResponseWriter, r *http.Request) {
 	actor, err := auth.GetAuthUser(r.Context())
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
 	idStr := strings.TrimSpace(chi.URLParam(r, "id"))
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		writeError(w, ErrInvalidInput)
+		writeError(w, r, ErrInvalidInput)
 		return
 	}
 
 	if err := h.svc.ResendPasswordSetupLink(r.Context(), actor, id); err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
@@ -208,6 +209,30 @@ func (h *Handler) resendPasswordSetupLink(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// revokeSessions signs the user out of every device
+// POST /users/{id}/revoke-sessions
+func (h *Handler) revokeSessions(w http.ResponseWriter, r *http.Request) {
+	actor, err := auth.GetAuthUser(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	idStr := strings.TrimSpace(chi.URLParam(r, "id"))
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		writeError(w, r, ErrInvalidInput)
+		return
+	}
+
+	if err := h.svc.RevokeSessions(r.Context(), actor, id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 /* -------------------- Helpers -------------------- */
 
 // atoiDefault parses an int or returns a default value if parsing fails or s is empty.
@@ -236,9 +261,9 @@ func httpError(w http.ResponseWriter, status int, msg string) {
 }
 
 // writeError classifies known domain errors and delegates to httpError.
-func writeError(w http.ResponseWriter, err error) {
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
 	// Log the error for server-side diagnostics
-	log.Printf("[ERROR] %v", err)
+	slog.ErrorContext(r.Context(), "request failed", "error", err)
 
 	// Map domain errors to HTTP status codes
 	switch {
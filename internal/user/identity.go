@@ -0,0 +1,103 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	firabaseAuth "firebase.google.com/go/v4/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+)
+
+// Identity is an account as the identity provider sees it.
+type Identity struct {
+	UID           string
+	Email         string
+	Disabled      bool
+	EmailVerified bool
+	CreatedAt     time.Time // zero if unknown
+}
+
+// IdentityProvider manages the sign-in side of a user (GCIP in production).
+// The database stays the source of truth; the service keeps the two in step.
+type IdentityProvider interface {
+	// CreateUser creates a passwordless account and returns its UID (external_id).
+	// Returns ErrConflict if the email is already registered.
+	CreateUser(ctx context.Context, email, firstName, lastName string) (uid string, err error)
+	// DeleteUser removes the account; used to roll back a failed CreateUser.
+	DeleteUser(ctx context.Context, uid string) error
+	// PasswordSetupLink returns a link the user follows to set their password.
+	PasswordSetupLink(ctx context.Context, email string) (string, error)
+	DisableUser(ctx context.Context, uid string) error
+	EnableUser(ctx context.Context, uid string) error
+	// SetTokenVersion writes the token_version custom claim (auth.TokenVersionClaim).
+	SetTokenVersion(ctx context.Context, uid string, version int) error
+	// RevokeSessions invalidates the user's refresh tokens.
+	RevokeSessions(ctx context.Context, uid string) error
+	SetEmailVerified(ctx context.Context, uid string, verified bool) error
+	// ListUsers calls fn for every account, stopping at the first error.
+	ListUsers(ctx context.Context, fn func(Identity) error) error
+}
+
+// gcipProvider is the IdentityProvider backed by Firebase/GCIP through the
+// auth package. auth.InitFirebase must have been called.
+type gcipProvider struct{}
+
+// NewGCIPIdentityProvider returns the Firebase/GCIP IdentityProvider.
+func NewGCIPIdentityProvider() IdentityProvider {
+	return gcipProvider{}
+}
+
+func (gcipProvider) CreateUser(ctx context.Context, email, firstName, lastName string) (string, error) {
+	uid, err := auth.CreateFirebaseUserPasswordless(ctx, email, firstName, lastName)
+	if err != nil {
+		if firabaseAuth.IsEmailAlreadyExists(err) {
+			return "", ErrConflict
+		}
+		return "", fmt.Errorf("failed to create user in Firebase: %w", err)
+	}
+	return uid, nil
+}
+
+func (gcipProvider) DeleteUser(ctx context.Context, uid string) error {
+	return auth.DeleteFirebaseUser(ctx, uid)
+}
+
+func (gcipProvider) PasswordSetupLink(ctx context.Context, email string) (string, error) {
+	return auth.GeneratePasswordResetLink(ctx, email)
+}
+
+func (gcipProvider) DisableUser(ctx context.Context, uid string) error {
+	return auth.DisableFirebaseUser(ctx, uid)
+}
+
+func (gcipProvider) EnableUser(ctx context.Context, uid string) error {
+	return auth.EnableFirebaseUser(ctx, uid)
+}
+
+func (gcipProvider) SetTokenVersion(ctx context.Context, uid string, version int) error {
+	return auth.SetTokenVersionClaim(ctx, uid, version)
+}
+
+func (gcipProvider) RevokeSessions(ctx context.Context, uid string) error {
+	return auth.RevokeRefreshTokens(ctx, uid)
+}
+
+func (gcipProvider) SetEmailVerified(ctx context.Context, uid string, verified bool) error {
+	return auth.SetEmailVerified(ctx, uid, verified)
+}
+
+func (gcipProvider) ListUsers(ctx context.Context, fn func(Identity) error) error {
+	return auth.ListFirebaseUsers(ctx, func(u *firabaseAuth.ExportedUserRecord) error {
+		id := Identity{
+			UID:           u.UID,
+			Email:         u.Email,
+			Disabled:      u.Disabled,
+			EmailVerified: u.EmailVerified,
+		}
+		if u.UserMetadata != nil && u.UserMetadata.CreationTimestamp > 0 {
+			id.CreatedAt = time.UnixMilli(u.UserMetadata.CreationTimestamp)
+		}
+		return fn(id)
+	})
+}
@@ -0,0 +1,168 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryIdentity is an account held by MemoryIdentityProvider.
+type MemoryIdentity struct {
+	Identity
+	DisplayName  string
+	TokenVersion int
+	// Revocations counts RevokeSessions calls.
+	Revocations int
+}
+
+// MemoryIdentityProvider is an in-memory IdentityProvider for tests and for
+// running the API offline (AUTH_MODE=local). Failures can be injected per
+// method through FailOn.
+type MemoryIdentityProvider struct {
+	mu       sync.Mutex
+	accounts map[string]*MemoryIdentity // by UID
+
+	// FailOn makes the named method ("CreateUser", "DeleteUser", ...) return
+	// the given error instead of doing anything.
+	FailOn map[string]error
+}
+
+var _ IdentityProvider = (*MemoryIdentityProvider)(nil)
+
+// NewMemoryIdentityProvider creates an empty in-memory provider.
+func NewMemoryIdentityProvider() *MemoryIdentityProvider {
+	return &MemoryIdentityProvider{
+		accounts: make(map[string]*MemoryIdentity),
+		FailOn:   make(map[string]error),
+	}
+}
+
+// Get returns a copy of the account with the given UID.
+func (p *MemoryIdentityProvider) Get(uid string) (MemoryIdentity, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	a, ok := p.accounts[uid]
+	if !ok {
+		return MemoryIdentity{}, false
+	}
+	return *a, true
+}
+
+// Add stores an account as-is, replacing any account with the same UID.
+func (p *MemoryIdentityProvider) Add(a MemoryIdentity) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.accounts[a.UID] = &a
+}
+
+// Len returns the number of accounts.
+func (p *MemoryIdentityProvider) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.accounts)
+}
+
+func (p *MemoryIdentityProvider) CreateUser(_ context.Context, email, firstName, lastName string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.FailOn["CreateUser"]; err != nil {
+		return "", err
+	}
+	for _, a := range p.accounts {
+		if strings.EqualFold(a.Email, email) {
+			return "", ErrConflict
+		}
+	}
+	uid := "mem-" + uuid.NewString()
+	p.accounts[uid] = &MemoryIdentity{
+		Identity:    Identity{UID: uid, Email: email, CreatedAt: time.Now()},
+		DisplayName: strings.TrimSpace(firstName + " " + lastName),
+	}
+	return uid, nil
+}
+
+func (p *MemoryIdentityProvider) DeleteUser(_ context.Context, uid string) error {
+	return p.update("DeleteUser", uid, func(*MemoryIdentity) {
+		delete(p.accounts, uid)
+	})
+}
+
+func (p *MemoryIdentityProvider) PasswordSetupLink(_ context.Context, email string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.FailOn["PasswordSetupLink"]; err != nil {
+		return "", err
+	}
+	for _, a := range p.accounts {
+		if strings.EqualFold(a.Email, email) {
+			return "http://localhost/set-password?email=" + url.QueryEscape(a.Email), nil
+		}
+	}
+	return "", fmt.Errorf("no account for %s", email)
+}
+
+func (p *MemoryIdentityProvider) DisableUser(_ context.Context, uid string) error {
+	return p.update("DisableUser", uid, func(a *MemoryIdentity) { a.Disabled = true })
+}
+
+func (p *MemoryIdentityProvider) EnableUser(_ context.Context, uid string) error {
+	return p.update("EnableUser", uid, func(a *MemoryIdentity) { a.Disabled = false })
+}
+
+func (p *MemoryIdentityProvider) SetTokenVersion(_ context.Context, uid string, version int) error {
+	return p.update("SetTokenVersion", uid, func(a *MemoryIdentity) { a.TokenVersion = version })
+}
+
+func (p *MemoryIdentityProvider) RevokeSessions(_ context.Context, uid string) error {
+	return p.update("RevokeSessions", uid, func(a *MemoryIdentity) { a.Revocations++ })
+}
+
+func (p *MemoryIdentityProvider) SetEmailVerified(_ context.Context, uid string, verified bool) error {
+	return p.update("SetEmailVerified", uid, func(a *MemoryIdentity) { a.EmailVerified = verified })
+}
+
+// ListUsers visits accounts in UID order.
+func (p *MemoryIdentityProvider) ListUsers(_ context.Context, fn func(Identity) error) error {
+	p.mu.Lock()
+	if err := p.FailOn["ListUsers"]; err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	list := make([]Identity, 0, len(p.accounts))
+	for _, a := range p.accounts {
+		list = append(list, a.Identity)
+	}
+	p.mu.Unlock()
+
+	slices.SortFunc(list, func(a, b Identity) int { return strings.Compare(a.UID, b.UID) })
+	for _, id := range list {
+		if err := fn(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errNoAccount is returned for UIDs the provider doesn't know.
+var errNoAccount = errors.New("identity provider: no such account")
+
+func (p *MemoryIdentityProvider) update(method, uid string, fn func(*MemoryIdentity)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.FailOn[method]; err != nil {
+		return err
+	}
+	a, ok := p.accounts[uid]
+	if !ok {
+		return fmt.Errorf("%w: %s", errNoAccount, uid)
+	}
+	fn(a)
+	return nil
+}
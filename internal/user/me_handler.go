@@ -28,14 +28,14 @@ func (h *MeHandler) getCurrentUser(w http.ResponseWriter, r *http.Request) {
 	// 1) Get GCIP / Firebase auth user from context
 	authUser, err := auth.GetAuthUser(r.Context())
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
 	// 2) Use externalID to query DB user
 	user, err := h.svc.GetCurrentUser(r.Context(), authUser.ExternalID)
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
@@ -47,19 +47,19 @@ func (h *MeHandler) getCurrentUser(w http.ResponseWriter, r *http.Request) {
 func (h *MeHandler) updateProfile(w http.ResponseWriter, r *http.Request) {
 	var in UpdateMeProfileInput
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		writeError(w, ErrInvalidInput)
+		writeError(w, r, ErrInvalidInput)
 		return
 	}
 	// 1) Get GCIP / Firebase auth user from context
 	authUser, err := auth.GetAuthUser(r.Context())
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 	// 2) Delegate to MeService.UpdateProfile
 	updatedUser, err := h.svc.UpdateProfile(r.Context(), authUser, &in)
 	if err != nil {
-		writeError(w, err)
+		writeError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, updatedUser.ToMeResponse())
@@ -8,11 +8,14 @@ import (
 
 // Role represents a role in the system
 type Role struct {
-	Code      string    `json:"code"`
-	Name      string    `json:"name"`
-	IsSystem  bool      `json:"-"` // backend-only: protect system roles
-	CreatedAt time.Time `json:"-"` // backend-only
-	UpdatedAt time.Time `json:"-"` // backend-only
+	ID       uuid.UUID `json:"-"`
+	Code     string    `json:"code"`
+	Name     string    `json:"name"`
+	IsSystem bool      `json:"-"` // backend-only: protect system roles
+	// Permissions granted to the role (app.role_permissions)
+	Permissions []string  `json:"-"`
+	CreatedAt   time.Time `json:"-"` // backend-only
+	UpdatedAt   time.Time `json:"-"` // backend-only
 }
 
 // Shop represents a shop
@@ -37,10 +40,13 @@ type User struct {
 	DeactivatedBy *uuid.UUID `json:"-"`
 	TokenVersion  int        `json:"-"`
 	ShopID        *uuid.UUID `json:"-"`
-	RoleID        uuid.UUID  `json:"-"`
-	CreatedAt     time.Time  `json:"-"`
-	UpdatedAt     time.Time  `json:"-"`
-	LastSignInAt  *time.Time `json:"-"`
+	// OrganizationID is the shop's organization, or for org-level users (no
+	// shop) their own; nil only for superadmins.
+	OrganizationID *uuid.UUID `json:"-"`
+	RoleID         uuid.UUID  `json:"-"`
+	CreatedAt      time.Time  `json:"-"`
+	UpdatedAt      time.Time  `json:"-"`
+	LastSignInAt   *time.Time `json:"-"`
 
 	// Role - each user must have exactly ONE role (enforced by role_id NOT NULL)
 	Role Role `json:"-"`
@@ -63,7 +69,10 @@ type CreateUserInput struct {
 	EmailVerified bool       `json:"-"` // Always set to false for new users
 	ExternalID    string     `json:"-"` // Generated by Firebase Admin SDK
 	ShopID        *uuid.UUID `json:"-"`
-	RoleID        uuid.UUID  `json:"-"`
+	// OrganizationID is only needed for users without a shop; otherwise the
+	// database takes it from the shop.
+	OrganizationID *uuid.UUID `json:"-"`
+	RoleID         uuid.UUID  `json:"-"`
 }
 
 // UpdateUserInput represents the fields that can be updated for a user
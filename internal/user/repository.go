@@ -3,8 +3,10 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
@@ -25,6 +27,7 @@ type Repository interface {
 	IncrementTokenVersion(ctx context.Context, id uuid.UUID) error
 	TouchLastSignInNowByExternalID(ctx context.Context, externalID string) error
 	GetRoleIDByCode(ctx context.Context, code string) (uuid.UUID, error)
+	GetRoleByCode(ctx context.Context, code string) (*Role, error)
 	MarkEmailVerified(ctx context.Context, id uuid.UUID) error
 }
 
@@ -53,6 +56,7 @@ type userRow struct {
 	DeactivatedBy *uuid.UUID `db:"deactivated_by"`
 	TokenVersion  int        `db:"token_version"`
 	ShopID        *uuid.UUID `db:"shop_id"`
+	OrgID         *uuid.UUID `db:"organization_id"`
 	RoleID        uuid.UUID  `db:"role_id"`
 	CreatedAt     time.Time  `db:"created_at"`
 	UpdatedAt     time.Time  `db:"updated_at"`
@@ -64,6 +68,7 @@ type userRow struct {
 	RoleIsSystem *bool      `db:"role_is_system"`
 	RoleCreated  *time.Time `db:"role_created_at"`
 	RoleUpdated  *time.Time `db:"role_updated_at"`
+	RolePerms    []string   `db:"role_permissions"`
 
 	// Shop join fields
 	ShopCode *string `db:"shop_code"`
@@ -72,30 +77,33 @@ type userRow struct {
 
 func (r userRow) toDomain() *User {
 	user := &User{
-		ID:            r.ID,
-		Code:          r.Code,
-		Email:         r.Email,
-		FirstName:     r.FirstName,
-		LastName:      r.LastName,
-		Phone:         r.Phone,
-		ImageURL:      r.ImageURL,
-		ExternalID:    r.ExternalID,
-		EmailVerified: r.EmailVerified,
-		IsActive:      r.IsActive,
-		DeactivatedAt: r.DeactivatedAt,
-		DeactivatedBy: r.DeactivatedBy,
-		TokenVersion:  r.TokenVersion,
-		ShopID:        r.ShopID,
-		RoleID:        r.RoleID,
-		CreatedAt:     r.CreatedAt,
-		UpdatedAt:     r.UpdatedAt,
-		LastSignInAt:  r.LastSignInAt,
+		ID:             r.ID,
+		Code:           r.Code,
+		Email:          r.Email,
+		FirstName:      r.FirstName,
+		LastName:       r.LastName,
+		Phone:          r.Phone,
+		ImageURL:       r.ImageURL,
+		ExternalID:     r.ExternalID,
+		EmailVerified:  r.EmailVerified,
+		IsActive:       r.IsActive,
+		DeactivatedAt:  r.DeactivatedAt,
+		DeactivatedBy:  r.DeactivatedBy,
+		TokenVersion:   r.TokenVersion,
+		ShopID:         r.ShopID,
+		OrganizationID: r.OrgID,
+		RoleID:         r.RoleID,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+		LastSignInAt:   r.LastSignInAt,
 		Role: Role{
-			Code:      *r.RoleCode,
-			Name:      *r.RoleName,
-			IsSystem:  *r.RoleIsSystem,
-			CreatedAt: *r.RoleCreated,
-			UpdatedAt: *r.RoleUpdated,
+			ID:          r.RoleID,
+			Code:        *r.RoleCode,
+			Name:        *r.RoleName,
+			IsSystem:    *r.RoleIsSystem,
+			Permissions: r.RolePerms,
+			CreatedAt:   *r.RoleCreated,
+			UpdatedAt:   *r.RoleUpdated,
 		},
 	}
 	if r.ShopCode != nil && r.ShopName != nil {
@@ -134,12 +142,14 @@ const baseSelect = `
 SELECT 
   u.id, u.code, u.email, u.first_name, u.last_name, u.phone, u.image_url,
   u.external_id, u.email_verified, u.is_active, u.deactivated_at, u.deactivated_by,
-  u.token_version, u.shop_id, u.role_id, u.created_at, u.updated_at, u.last_sign_in_at,
+  u.token_version, u.shop_id, u.organization_id, u.role_id, u.created_at, u.updated_at, u.last_sign_in_at,
   r.code        AS role_code,
   r.name        AS role_name,
   r.is_system   AS role_is_system,
   r.created_at  AS role_created_at,
   r.updated_at  AS role_updated_at,
+  ARRAY(SELECT rp.permission_code FROM app.role_permissions rp
+        WHERE rp.role_id = r.id ORDER BY rp.permission_code) AS role_permissions,
   s.code        AS shop_code,
   s.shop_name        AS shop_name
 FROM app.users u
@@ -147,6 +157,16 @@ INNER JOIN app.roles r ON r.id = u.role_id
 LEFT JOIN app.shop s ON s.id = u.shop_id
 `
 
+/* ---------- organization scope ---------- */
+
+// inOrg restricts u to the request's organization (auth.OrganizationScope);
+// the placeholder is filled with the parameter number. A NULL scope matches
+// everything. Superadmins belong to no organization and stay visible in every
+// scope; the service decides who may see them.
+const inOrg = ` AND ($%[1]d::uuid IS NULL OR u.organization_id = $%[1]d OR u.organization_id IS NULL)`
+
+func scoped(n int) string { return fmt.Sprintf(inOrg, n) }
+
 /* ---------- queries ---------- */
 
 func (r *pgRepo) Create(ctx context.Context, in *CreateUserInput) (*User, error) {
@@ -157,14 +177,14 @@ func (r *pgRepo) Create(ctx context.Context, in *CreateUserInput) (*User, error)
 	const q = `
 INSERT INTO app.users (
   email, first_name, last_name, phone, image_url,
-  external_id, email_verified, shop_id, role_id
-) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+  external_id, email_verified, shop_id, organization_id, role_id
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 RETURNING id
 `
 	var id uuid.UUID
 	if err := r.db.QueryRow(ctx, q,
 		in.Email, in.FirstName, in.LastName, in.Phone, in.ImageURL,
-		in.ExternalID, in.EmailVerified, in.ShopID, in.RoleID,
+		in.ExternalID, in.EmailVerified, in.ShopID, in.OrganizationID, in.RoleID,
 	).Scan(&id); err != nil {
 		return nil, mapPgError(err)
 	}
@@ -172,7 +192,7 @@ RETURNING id
 }
 
 func (r *pgRepo) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
-	rows, err := r.db.Query(ctx, baseSelect+` WHERE u.id=$1`, id)
+	rows, err := r.db.Query(ctx, baseSelect+` WHERE u.id=$1`+scoped(2), id, auth.OrganizationScope(ctx))
 	if err != nil {
 		return nil, mapPgError(err)
 	}
@@ -186,7 +206,7 @@ func (r *pgRepo) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 }
 
 func (r *pgRepo) GetByCode(ctx context.Context, code string) (*User, error) {
-	rows, err := r.db.Query(ctx, baseSelect+` WHERE u.code=$1`, code)
+	rows, err := r.db.Query(ctx, baseSelect+` WHERE u.code=$1`+scoped(2), code, auth.OrganizationScope(ctx))
 	if err != nil {
 		return nil, mapPgError(err)
 	}
@@ -199,6 +219,8 @@ func (r *pgRepo) GetByCode(ctx context.Context, code string) (*User, error) {
 	return row.toDomain(), nil
 }
 
+// GetByExternalID is not scoped: the auth middleware calls it to find out
+// which organization the request belongs to.
 func (r *pgRepo) GetByExternalID(ctx context.Context, externalID string) (*User, error) {
 	rows, err := r.db.Query(ctx, baseSelect+` WHERE u.external_id=$1`, externalID)
 	if err != nil {
@@ -213,9 +235,11 @@ func (r *pgRepo) GetByExternalID(ctx context.Context, externalID string) (*User,
 	return row.toDomain(), nil
 }
 
+// List pages users newest first; u.id breaks created_at ties so that OFFSET
+// pages neither skip nor repeat users (reconcile walks every page).
 func (r *pgRepo) List(ctx context.Context, limit, offset int) ([]*User, error) {
-
-	rows, err := r.db.Query(ctx, baseSelect+` ORDER BY u.created_at DESC LIMIT $1 OFFSET $2`, limit, offset)
+	rows, err := r.db.Query(ctx, baseSelect+` WHERE TRUE`+scoped(3)+` ORDER BY u.created_at DESC, u.id LIMIT $1 OFFSET $2`,
+		limit, offset, auth.OrganizationScope(ctx))
 	if err != nil {
 		return nil, mapPgError(err)
 	}
@@ -238,7 +262,7 @@ func (r *pgRepo) Update(ctx context.Context, id uuid.UUID, in *UpdateUserInput)
 		return nil, ErrInvalidInput
 	}
 
-	const q = `
+	q := `
 UPDATE app.users AS u
 SET
   first_name     = COALESCE($2, u.first_name),
@@ -248,7 +272,7 @@ SET
   email_verified = COALESCE($6, u.email_verified),
   shop_id        = COALESCE($7, u.shop_id),
   role_id        = COALESCE($8, u.role_id)
-WHERE u.id = $1
+WHERE u.id = $1` + scoped(9) + `
 RETURNING u.id
 `
 	var ret uuid.UUID
@@ -261,6 +285,7 @@ RETURNING u.id
 		in.EmailVerified,
 		in.ShopID,
 		in.RoleID,
+		auth.OrganizationScope(ctx),
 	).Scan(&ret); err != nil {
 		return nil, mapPgError(err)
 	}
@@ -268,13 +293,12 @@ RETURNING u.id
 }
 
 func (r *pgRepo) Deactivate(ctx context.Context, id uuid.UUID, byUserID *uuid.UUID) error {
-	const q = `
-UPDATE app.users
+	q := `
+UPDATE app.users u
 SET is_active = FALSE,
     deactivated_by = $2
-WHERE id = $1 AND is_active = TRUE
-`
-	ct, err := r.db.Exec(ctx, q, id, byUserID)
+WHERE u.id = $1 AND u.is_active = TRUE` + scoped(3)
+	ct, err := r.db.Exec(ctx, q, id, byUserID, auth.OrganizationScope(ctx))
 	if err != nil {
 		return mapPgError(err)
 	}
@@ -288,12 +312,11 @@ WHERE id = $1 AND is_active = TRUE
 }
 
 func (r *pgRepo) Reactivate(ctx context.Context, id uuid.UUID) error {
-	const q = `
-UPDATE app.users
+	q := `
+UPDATE app.users u
 SET is_active = TRUE
-WHERE id = $1 AND is_active = FALSE
-`
-	ct, err := r.db.Exec(ctx, q, id)
+WHERE u.id = $1 AND u.is_active = FALSE` + scoped(2)
+	ct, err := r.db.Exec(ctx, q, id, auth.OrganizationScope(ctx))
 	if err != nil {
 		return mapPgError(err)
 	}
@@ -307,7 +330,8 @@ WHERE id = $1 AND is_active = FALSE
 }
 
 func (r *pgRepo) IncrementTokenVersion(ctx context.Context, id uuid.UUID) error {
-	ct, err := r.db.Exec(ctx, `UPDATE app.users SET token_version = token_version + 1 WHERE id=$1`, id)
+	ct, err := r.db.Exec(ctx, `UPDATE app.users u SET token_version = u.token_version + 1 WHERE u.id=$1`+scoped(2),
+		id, auth.OrganizationScope(ctx))
 	if err != nil {
 		return mapPgError(err)
 	}
@@ -339,14 +363,28 @@ func (r *pgRepo) GetRoleIDByCode(ctx context.Context, code string) (uuid.UUID, e
 	return id, nil
 }
 
+func (r *pgRepo) GetRoleByCode(ctx context.Context, code string) (*Role, error) {
+	var role Role
+	err := r.db.QueryRow(ctx, `
+SELECT r.id, r.code, r.name, r.is_system, r.created_at, r.updated_at,
+       ARRAY(SELECT rp.permission_code FROM app.role_permissions rp
+             WHERE rp.role_id = r.id ORDER BY rp.permission_code)
+FROM app.roles r
+WHERE r.code = $1`, code).Scan(
+		&role.ID, &role.Code, &role.Name, &role.IsSystem, &role.CreatedAt, &role.UpdatedAt, &role.Permissions)
+	if err != nil {
+		return nil, mapPgError(err)
+	}
+	return &role, nil
+}
+
 func (r *pgRepo) MarkEmailVerified(ctx context.Context, id uuid.UUID) error {
-	const q = `
-UPDATE app.users
+	q := `
+UPDATE app.users u
 SET email_verified = TRUE,
     updated_at     = NOW()
-WHERE id = $1 AND email_verified = FALSE
-`
-	ct, err := r.db.Exec(ctx, q, id)
+WHERE u.id = $1 AND u.email_verified = FALSE` + scoped(2)
+	ct, err := r.db.Exec(ctx, q, id, auth.OrganizationScope(ctx))
 	if err != nil {
 		return mapPgError(err)
 	}
@@ -24,6 +24,9 @@ var (
 //  1. Start database: docker-compose -f compose.test.yml up -d and run migrations
 //  2. Set environment variables in .env:
 //  3. Run tests: go test -v ./internal/user
+//
+// Without those variables the repository tests are skipped (requireTestDB) and
+// the unit tests in this package still run.
 func TestMain(m *testing.M) {
 	ctx := context.Background()
 
@@ -41,10 +44,7 @@ func TestMain(m *testing.M) {
 	dbSchema := os.Getenv("DB_SCHEMA")
 
 	if dbHost == "" || dbPort == "" || dbName == "" || dbUser == "" || dbPassword == "" {
-		fmt.Fprintf(os.Stderr, "Missing required environment variables\n")
-		fmt.Fprintf(os.Stderr, "Required: DB_HOST, DB_TEST_PORT, DB_NAME, DB_APP_USER, DB_APP_PASSWORD\n")
-		fmt.Fprintf(os.Stderr, "Optional: DB_SCHEMA (default: app)\n")
-		os.Exit(1)
+		os.Exit(m.Run())
 	}
 
 	if dbSchema == "" {
@@ -88,8 +88,17 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+// requireTestDB skips the test when TestMain found no test database.
+func requireTestDB(t *testing.T) {
+	t.Helper()
+	if testDB == nil {
+		t.Skip("DB_HOST, DB_TEST_PORT, DB_NAME, DB_APP_USER and DB_APP_PASSWORD are required")
+	}
+}
+
 // cleanupTestData removes all test data between tests
 func cleanupTestData(t *testing.T) {
+	requireTestDB(t)
 	ctx := context.Background()
 
 	// Delete all test users (avoid deleting seeded roles)
@@ -677,6 +686,7 @@ func TestTouchLastSignIn(t *testing.T) {
 
 // Test: Get role ID by role code
 func TestGetRoleIDByCode(t *testing.T) {
+	requireTestDB(t)
 	ctx := context.Background()
 
 	roleID, err := testRepo.GetRoleIDByCode(ctx, "admin")
@@ -687,6 +697,7 @@ func TestGetRoleIDByCode(t *testing.T) {
 
 // Test: Attempt to get role ID with non-existent code
 func TestGetRoleIDByCodeNotFound(t *testing.T) {
+	requireTestDB(t)
 	ctx := context.Background()
 
 	roleID, err := testRepo.GetRoleIDByCode(ctx, "NONEXISTENT")
@@ -4,11 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"regexp"
+	"slices"
 	"strings"
 
-	firabaseAuth "firebase.google.com/go/v4/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/shop"
 	"github.com/google/uuid"
@@ -37,12 +38,12 @@ type EmailSender interface {
 type logEmailSender struct{}
 
 func (s *logEmailSender) SendWelcomePasswordSetup(ctx context.Context, email, firstName, link string) error {
-	log.Printf("[DEV] Would send WELCOME email to %s (name=%s) with link: %s", email, firstName, link)
+	slog.InfoContext(ctx, "email not sent: no sender configured", "kind", "welcome", "to", email, "link", link)
 	return nil
 }
 
 func (s *logEmailSender) SendPasswordSetupReminder(ctx context.Context, email, firstName, link string) error {
-	log.Printf("[DEV] Would send REMINDER email to %s (name=%s) with link: %s", email, firstName, link)
+	slog.InfoContext(ctx, "email not sent: no sender configured", "kind", "reminder", "to", email, "link", link)
 	return nil
 }
 
@@ -56,28 +57,29 @@ type UserService interface {
 	DeactivateUser(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) error
 	ReactivateUser(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) error
 	ResendPasswordSetupLink(ctx context.Context, actor *auth.AuthUser, userID uuid.UUID) error
+	RevokeSessions(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) error
 }
 
 type service struct {
 	repo        Repository
 	shopService shop.ShopService
+	idp         IdentityProvider
 	emailSender EmailSender
+	audit       audit.Recorder
 }
 
 // -------------------- Service Constructors -------------------- //
 // NewService constructs a UserService with default email sender (logs emails).
-func NewService(repo Repository, shopSvc shop.ShopService,
-) UserService {
-	return &service{
-		repo:        repo,
-		shopService: shopSvc,
-		emailSender: &logEmailSender{},
-	}
+// idp is the sign-in side of each user: NewGCIPIdentityProvider() in production,
+// a MemoryIdentityProvider in tests and offline mode. Changes are reported to
+// rec (nil records nothing).
+func NewService(repo Repository, shopSvc shop.ShopService, idp IdentityProvider, rec audit.Recorder) UserService {
+	return NewServiceWithEmailSender(repo, shopSvc, idp, nil, rec)
 }
 
 // NewServiceWithEmailSender lets the caller inject a concrete EmailSender
 // (e.g. Gmail SMTP, SendGrid, etc.)
-func NewServiceWithEmailSender(repo Repository, shopSvc shop.ShopService, sender EmailSender) UserService {
+func NewServiceWithEmailSender(repo Repository, shopSvc shop.ShopService, idp IdentityProvider, sender EmailSender, rec audit.Recorder) UserService {
 	if sender == nil {
 		sender = &logEmailSender{}
 	}
@@ -85,7 +87,9 @@ func NewServiceWithEmailSender(repo Repository, shopSvc shop.ShopService, sender
 	return &service{
 		repo:        repo,
 		shopService: shopSvc,
+		idp:         idp,
 		emailSender: sender,
+		audit:       audit.OrNop(rec),
 	}
 }
 
@@ -93,11 +97,12 @@ func NewServiceWithEmailSender(repo Repository, shopSvc shop.ShopService, sender
 // CreateUser creates a new user using PASSWORDLESS flow
 // Flow:
 //  1. Validate input and permissions
-//  2. Create user in Firebase/GCIP (without password)
-//  3. Get Firebase UID (external_id)
+//  2. Create user in the identity provider (without password)
+//  3. Get the provider UID (external_id)
 //  4. Save to database
-//  5. Generate password reset link
-//  6. Send welcome email with password setup link (async)
+//  5. Write the token version custom claim
+//  6. Generate password reset link
+//  7. Send welcome email with password setup link (async)
 func (s *service) CreateUser(ctx context.Context, actor *auth.AuthUser, in *CreateUserInput) (*User, error) {
 	if in == nil {
 		return nil, ErrInvalidInput
@@ -112,24 +117,22 @@ func (s *service) CreateUser(ctx context.Context, actor *auth.AuthUser, in *Crea
 	// 2. Normalize input
 	normalizeUser(in)
 
-	// 3. Convert roleCode to roleID
+	// 3. Resolve roleCode (with the role's permissions)
 	if in.RoleCode == "" {
 		return nil, NewValidationError("roleCode", "cannot be blank")
 	}
-	roleID, err := s.repo.GetRoleIDByCode(ctx, in.RoleCode)
+	role, err := s.repo.GetRoleByCode(ctx, in.RoleCode)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			return nil, NewValidationError("roleCode", "invalid role code")
 		}
 		return nil, fmt.Errorf("lookup roleCode: %w", err)
 	}
-	in.RoleID = roleID
+	in.RoleID = role.ID
 
-	// 4. Permission check: Admin cannot create admin or superadmin and shop enforced
-	if currentUser.IsAdminOnly() {
-		if in.RoleCode == auth.RoleAdmin || in.RoleCode == auth.RoleSuperAdmin {
-			return nil, NewValidationError("roleCode", "admin cannot create admin or superadmin users")
-		}
+	// 4. Permission check: only roles strictly below the creator's own can be assigned
+	if !currentUser.IsSuperAdmin() && !outranks(currentUser, role) {
+		return nil, NewValidationError("roleCode", "cannot create users with a role at or above your own")
 	}
 
 	// 5. Handle shop assignment based on current user's role
@@ -148,17 +151,44 @@ func (s *service) CreateUser(ctx context.Context, actor *auth.AuthUser, in *Crea
 			in.ShopID = &shopID
 		}
 
-		// Non-superadmin users must be assigned to a shop
-		if in.RoleCode != auth.RoleSuperAdmin && in.ShopID == nil {
-			return nil, NewValidationError("shopCode", "non-superadmin users must be assigned to a shop")
+		// Non-superadmin users must be assigned to a shop, except org-level
+		// users, who belong to the organization the request is scoped to
+		if !isSuperAdminRole(role) && in.ShopID == nil {
+			if !isOrgLevel(role) {
+				return nil, NewValidationError("shopCode", "non-superadmin users must be assigned to a shop")
+			}
+			in.OrganizationID = auth.OrganizationScope(ctx)
+			if in.OrganizationID == nil {
+				return nil, NewValidationError("organization", "choose an organization with the "+auth.OrganizationHeader+" header")
+			}
+		}
+
+	case currentUser.IsOrgWide() && currentUser.Can(auth.PermUsersManage):
+		// Org-level managers (e.g. orgadmin): any shop of their organization
+		// (the lookup is scoped to it), or none for org-level roles
+		if currentUser.OrganizationID == nil {
+			return nil, fmt.Errorf("org admin user is missing organization assignment")
+		}
+		if in.ShopCode != nil && *in.ShopCode != "" {
+			shopID, err := s.shopService.GetShopIDByCode(ctx, *in.ShopCode)
+			if err != nil {
+				if errors.Is(err, ErrNotFound) {
+					return nil, NewValidationError("shopCode", "invalid shop code")
+				}
+				return nil, fmt.Errorf("lookup shopCode: %w", err)
+			}
+			in.ShopID = &shopID
+		} else if !isOrgLevel(role) {
+			return nil, NewValidationError("shopCode", "shop-level users must be assigned to a shop")
 		}
+		in.OrganizationID = currentUser.OrganizationID
 
-	case currentUser.IsAdminOnly():
+	case currentUser.Can(auth.PermUsersManage):
 		if !currentUser.HasShop() {
 			return nil, fmt.Errorf("admin user is missing shop assignment")
 		}
 
-		// Admin: Can only assign users to their own shop
+		// Shop-level managers (e.g. admin): Can only assign users to their own shop
 		// If shopCode is provided, must match their own shop
 		if in.ShopCode != nil && *in.ShopCode != "" {
 			shopID, err := s.shopService.GetShopIDByCode(ctx, *in.ShopCode)
@@ -189,56 +219,59 @@ func (s *service) CreateUser(ctx context.Context, actor *auth.AuthUser, in *Crea
 		return nil, err
 	}
 
-	// 7. Create user in Firebase/GCIP WITHOUT password (passwordless flow)
-	firebaseUID, err := auth.CreateFirebaseUserPasswordless(
-		ctx,
-		in.Email,
-		in.FirstName,
-		in.LastName,
-	)
+	// 7. Create user in the identity provider WITHOUT password (passwordless flow)
+	externalID, err := s.idp.CreateUser(ctx, in.Email, in.FirstName, in.LastName)
 	if err != nil {
-		if firabaseAuth.IsEmailAlreadyExists(err) {
+		if errors.Is(err, ErrConflict) {
 			return nil, ErrConflict
 		}
-		return nil, fmt.Errorf("failed to create user in Firebase: %w", err)
+		return nil, fmt.Errorf("create identity: %w", err)
 	}
 
-	// 8. Set ExternalID from Firebase (this is the Firebase UID)
-	in.ExternalID = firebaseUID
+	// 8. Set ExternalID from the identity provider (the GCIP UID)
+	in.ExternalID = externalID
 	in.EmailVerified = false // This will be set to true when user first logs in
 
 	// 9. Create user in database
 	user, err := s.repo.Create(ctx, in)
 	if err != nil {
-		// IMPORTANT: Rollback - Delete Firebase user if DB creation fails
-		if deleteErr := auth.DeleteFirebaseUser(ctx, firebaseUID); deleteErr != nil {
-			log.Printf("ERROR: Failed to rollback Firebase user creation for UID %s: %v", firebaseUID, deleteErr)
+		// IMPORTANT: Rollback - Delete the identity if DB creation fails
+		if deleteErr := s.idp.DeleteUser(ctx, externalID); deleteErr != nil {
+			slog.ErrorContext(ctx, "failed to roll back identity creation", "external_id", externalID, "error", deleteErr)
 		}
 		return nil, fmt.Errorf("service create user: %w", err)
 	}
 
-	// 10. Generate password setup link
-	passwordSetupLink, err := auth.GeneratePasswordResetLink(ctx, user.Email)
+	// 10. Write the token version claim so the first ID token already carries it
+	if err := s.idp.SetTokenVersion(ctx, externalID, user.TokenVersion); err != nil {
+		slog.WarnContext(ctx, "failed to set token version claim", "external_id", externalID, "error", err)
+		// Not fatal: the auth middleware backfills a missing claim on first sign-in
+	}
+
+	// 11. Generate password setup link
+	passwordSetupLink, err := s.idp.PasswordSetupLink(ctx, user.Email)
 	if err != nil {
-		log.Printf("WARNING: Failed to generate password setup link for %s: %v", user.Email, err)
+		slog.WarnContext(ctx, "failed to generate password setup link", "target_user_id", user.ID, "error", err)
 		// Don't fail user creation, just log the error
 		// Admin can resend the link later
 	}
 
-	// 11. Send welcome email with password setup link (async, non-blocking)
+	// 12. Send welcome email with password setup link (async, non-blocking)
 	if s.emailSender != nil && passwordSetupLink != "" {
 		go func(email, firstName, link string) {
-			// Use background context to prevent cancellation from HTTP request.
-			emailCtx := context.Background()
+			// Detach from the HTTP request's cancellation but keep its log fields.
+			emailCtx := context.WithoutCancel(ctx)
 			if err := s.emailSender.SendWelcomePasswordSetup(emailCtx, email, firstName, link); err != nil {
-				log.Printf("Failed to send welcome email to %s: %v", email, err)
+				slog.ErrorContext(emailCtx, "failed to send welcome email", "to", email, "error", err)
 			}
 		}(user.Email, user.FirstName, passwordSetupLink)
 	} else {
-		log.Printf("No email sender configured, skipping welcome email for %s", user.Email)
+		slog.InfoContext(ctx, "no email sender configured, skipping welcome email", "target_user_id", user.ID)
 	}
 
-	log.Printf("Successfully created user: %s (ID: %s, Firebase UID: %s)", user.Email, user.ID, firebaseUID)
+	s.record(ctx, "user.create", nil, user)
+
+	slog.InfoContext(ctx, "user created", "target_user_id", user.ID, "external_id", externalID)
 	return user, nil
 }
 
@@ -291,7 +324,7 @@ func (s *service) ListUsers(ctx context.Context, actor *auth.AuthUser, limit, of
 	}
 
 	// Apply visibility rules
-	if currentUser.IsAdminOnly() {
+	if !currentUser.IsSuperAdmin() {
 		filtered := make([]*User, 0, len(list))
 		for _, u := range list {
 			if !s.canViewUser(currentUser, u) {
@@ -331,7 +364,7 @@ func (s *service) UpdateUser(ctx context.Context, actor *auth.AuthUser, id uuid.
 	}
 
 	// 2. Check field update permission (can these specific fields be modified?)
-	if err := s.checkFieldUpdatePermission(currentUser, targetUser, in); err != nil {
+	if err := s.checkFieldUpdatePermission(ctx, currentUser, targetUser, in); err != nil {
 		return nil, err
 	}
 
@@ -369,6 +402,13 @@ func (s *service) UpdateUser(ctx context.Context, actor *auth.AuthUser, id uuid.
 	if err != nil {
 		return nil, fmt.Errorf("service update user: %w", err)
 	}
+
+	// A role change bumps token_version in the DB, which revokes the user's
+	// current tokens; new tokens need the new version in their claims.
+	if user.TokenVersion != targetUser.TokenVersion {
+		s.syncTokenVersionClaim(ctx, user)
+	}
+	s.record(ctx, "user.update", targetUser, user)
 	return user, nil
 }
 
@@ -401,12 +441,22 @@ func (s *service) DeactivateUser(ctx context.Context, actor *auth.AuthUser, id u
 		return fmt.Errorf("service deactivate user: %w", err)
 	}
 
-	// Also disable in Firebase/GCIP
-	if err := auth.DisableFirebaseUser(ctx, targetUser.ExternalID); err != nil {
-		log.Printf("WARNING: Failed to disable Firebase user %s: %v", targetUser.ExternalID, err)
+	// Also disable in the identity provider
+	if err := s.idp.DisableUser(ctx, targetUser.ExternalID); err != nil {
+		slog.WarnContext(ctx, "failed to disable identity", "external_id", targetUser.ExternalID, "error", err)
 		// Continue anyway - DB is source of truth
 	}
 
+	// Deactivation bumps token_version; keep the claim in step so the user can
+	// sign in again if they are reactivated later
+	updated, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to reload user after deactivation", "target_user_id", id, "error", err)
+	} else {
+		s.syncTokenVersionClaim(ctx, updated)
+	}
+
+	s.record(ctx, "user.deactivate", targetUser, updated)
 	return nil
 }
 
@@ -438,12 +488,21 @@ func (s *service) ReactivateUser(ctx context.Context, actor *auth.AuthUser, id u
 		return fmt.Errorf("service reactivate user: %w", err)
 	}
 
-	// Also enable in Firebase/GCIP
-	if err := auth.EnableFirebaseUser(ctx, targetUser.ExternalID); err != nil {
-		log.Printf("WARNING: Failed to enable Firebase user %s: %v", targetUser.ExternalID, err)
+	// Also enable in the identity provider
+	if err := s.idp.EnableUser(ctx, targetUser.ExternalID); err != nil {
+		slog.WarnContext(ctx, "failed to enable identity", "external_id", targetUser.ExternalID, "error", err)
 		// Continue anyway - DB is source of truth
 	}
 
+	// Reactivation bumps token_version as well
+	updated, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to reload user after reactivation", "target_user_id", id, "error", err)
+	} else {
+		s.syncTokenVersionClaim(ctx, updated)
+	}
+
+	s.record(ctx, "user.reactivate", targetUser, updated)
 	return nil
 }
 
@@ -468,7 +527,7 @@ func (s *service) ResendPasswordSetupLink(ctx context.Context, actor *auth.AuthU
 	}
 
 	// Generate new password reset link
-	passwordSetupLink, err := auth.ResendPasswordResetLink(ctx, targetUser.Email)
+	passwordSetupLink, err := s.idp.PasswordSetupLink(ctx, targetUser.Email)
 	if err != nil {
 		return fmt.Errorf("failed to generate password setup link: %w", err)
 	}
@@ -476,20 +535,155 @@ func (s *service) ResendPasswordSetupLink(ctx context.Context, actor *auth.AuthU
 	// Send reminder email (async, non-blocking)
 	if s.emailSender != nil && passwordSetupLink != "" {
 		go func(email, firstName, link string) {
-			emailCtx := context.Background()
+			emailCtx := context.WithoutCancel(ctx)
 			if err := s.emailSender.SendPasswordSetupReminder(emailCtx, email, firstName, link); err != nil {
-				log.Printf("Failed to send password setup reminder to %s: %v", email, err)
+				slog.ErrorContext(emailCtx, "failed to send password setup reminder", "to", email, "error", err)
 			}
 		}(targetUser.Email, targetUser.FirstName, passwordSetupLink)
 	} else {
-		log.Printf("No email sender configured, skipping password setup reminder for %s", targetUser.Email)
+		slog.InfoContext(ctx, "no email sender configured, skipping password setup reminder", "target_user_id", userID)
 	}
 
-	log.Printf("Password setup link resent for user: %s (ID: %s)", targetUser.Email, userID)
+	slog.InfoContext(ctx, "password setup link resent", "target_user_id", userID)
 	return nil
 }
 
+// RevokeSessions signs a user out everywhere
+// Flow:
+//  1. Bump token_version in the database (rejects every existing ID token)
+//  2. Write the new version into the identity provider's custom claims
+//  3. Revoke refresh tokens so the client must sign in again
+func (s *service) RevokeSessions(ctx context.Context, actor *auth.AuthUser, id uuid.UUID) error {
+	// Get current user
+	currentUser := actor
+	if currentUser == nil {
+		return fmt.Errorf("unauthorized: no auth user in context")
+	}
+
+	// Get target user
+	targetUser, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service get user: %w", err)
+	}
+
+	// Permission check - only need to verify basic management permission
+	if err := s.canManageUser(currentUser, targetUser); err != nil {
+		return err
+	}
+
+	if err := s.repo.IncrementTokenVersion(ctx, id); err != nil {
+		return fmt.Errorf("service revoke sessions: %w", err)
+	}
+	updated, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service revoke sessions: %w", err)
+	}
+
+	// Unlike the other flows these failures are returned: the request can simply
+	// be retried, and each retry bumps the version and rewrites the claim.
+	if err := s.idp.SetTokenVersion(ctx, updated.ExternalID, updated.TokenVersion); err != nil {
+		return fmt.Errorf("service revoke sessions: %w", err)
+	}
+	if err := s.idp.RevokeSessions(ctx, updated.ExternalID); err != nil {
+		return fmt.Errorf("service revoke sessions: %w", err)
+	}
+
+	s.record(ctx, "user.revoke_sessions", targetUser, updated)
+
+	slog.InfoContext(ctx, "sessions revoked", "target_user_id", id, "token_version", updated.TokenVersion)
+	return nil
+}
+
+// record reports a change to u to the audit log. after is nil when the user
+// could not be reloaded; the entry still names them.
+func (s *service) record(ctx context.Context, action string, before, after *User) {
+	u := after
+	if u == nil {
+		u = before
+	}
+	e := audit.Entry{
+		Action:         action,
+		EntityType:     audit.EntityUser,
+		EntityID:       u.ID.String(),
+		ShopID:         u.ShopID,
+		OrganizationID: u.OrganizationID,
+		After:          after.ToResponse(),
+	}
+	if before != nil {
+		e.Before = before.ToResponse()
+	}
+	s.audit.Record(ctx, e)
+}
+
+// -------------------- Token Version Helpers -------------------- //
+// syncTokenVersionClaim writes the user's current token_version into their
+// custom claims at the identity provider. The DB bumps the version itself on role and
+// active-state changes (trg_user_status_and_role_guard).
+// Failures are logged only: until the claim is written the user's new tokens
+// are rejected, and POST /users/{id}/revoke-sessions rewrites it.
+func (s *service) syncTokenVersionClaim(ctx context.Context, u *User) {
+	if err := s.idp.SetTokenVersion(ctx, u.ExternalID, u.TokenVersion); err != nil {
+		slog.WarnContext(ctx, "failed to set token version claim", "external_id", u.ExternalID, "error", err)
+	}
+}
+
 // -------------------- Permission Helpers -------------------- //
+// Roles are ranked by their permissions (app.role_permissions): a role is below
+// the actor's when the actor holds every permission it grants and at least one
+// more. The system superadmin role is above all others.
+
+// outranks reports whether role sits strictly below the actor's role.
+func outranks(actor *auth.AuthUser, role *Role) bool {
+	if isSuperAdminRole(role) {
+		return false
+	}
+	if actor.IsSuperAdmin() {
+		return true
+	}
+	return holdsAll(actor, role) && len(actor.Permissions) > len(role.Permissions)
+}
+
+// sameRank reports whether role grants exactly the actor's permissions.
+func sameRank(actor *auth.AuthUser, role *Role) bool {
+	if isSuperAdminRole(role) || actor.IsSuperAdmin() {
+		return isSuperAdminRole(role) && actor.IsSuperAdmin()
+	}
+	return holdsAll(actor, role) && len(actor.Permissions) == len(role.Permissions)
+}
+
+func holdsAll(actor *auth.AuthUser, role *Role) bool {
+	for _, p := range role.Permissions {
+		if !actor.Can(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameShop(currentUser *auth.AuthUser, targetUser *User) bool {
+	return targetUser.ShopID != nil && currentUser.ShopID != nil &&
+		*targetUser.ShopID == *currentUser.ShopID
+}
+
+// inReach reports whether targetUser is in a shop the actor manages: their own
+// shop, or for org-wide actors any user the (organization-scoped) repository
+// returned.
+func inReach(currentUser *auth.AuthUser, targetUser *User) bool {
+	return currentUser.IsOrgWide() || sameShop(currentUser, targetUser)
+}
+
+// isSuperAdminRole reports whether role is the superadmin role. IsSystem is not
+// enough: it marks every role the /roles endpoints may not change.
+func isSuperAdminRole(role *Role) bool {
+	return role.Code == auth.RoleSuperAdmin
+}
+
+// isOrgLevel reports whether role is held above the shop level (org.shops), so
+// users with it need an organization but no shop.
+func isOrgLevel(role *Role) bool {
+	return slices.Contains(role.Permissions, auth.PermOrgShops)
+}
+
 // canViewUser decides whether the actor is allowed to SEE the target user.
 // It controls visibility rules (e.g. hide superadmins from admins).
 func (s *service) canViewUser(currentUser *auth.AuthUser, targetUser *User) bool {
@@ -498,27 +692,19 @@ func (s *service) canViewUser(currentUser *auth.AuthUser, targetUser *User) bool
 		return true
 	}
 
-	// Admin visibility rules
-	if currentUser.IsAdminOnly() {
-		// Admin CANNOT see SuperAdmins
-		if targetUser.Role.Code == auth.RoleSuperAdmin {
-			return false
-		}
-		// Admin CAN see:
-		//  - themselves
-		//  - other admins (read-only handled elsewhere)
-		//  - adjusters / bodymen in the same shop
-		if targetUser.Role.Code == auth.RoleAdmin {
-			return true
-		}
-		// For adjuster/bodyman, must be in the same shop
-		return targetUser.ShopID != nil && currentUser.ShopID != nil &&
-			*targetUser.ShopID == *currentUser.ShopID
+	switch {
+	case sameRank(currentUser, &targetUser.Role):
+		// Peers (e.g. other admins, and themselves) are visible in any shop;
+		// managing them is refused in canManageUser
+		return true
+	case outranks(currentUser, &targetUser.Role):
+		// Lower roles (e.g. adjusters / bodymen) must be in the same shop, or
+		// the same organization for org-wide actors
+		return inReach(currentUser, targetUser)
+	default:
+		// Higher roles (e.g. superadmins) are hidden
+		return false
 	}
-
-	// For now, other roles should not normally hit this service-level method.
-	// If they do, default to false to be safe.
-	return false
 }
 
 // canManageUser checks basic management permissions (whether the current user can manage the target user)
@@ -529,28 +715,33 @@ func (s *service) canManageUser(currentUser *auth.AuthUser, targetUser *User) er
 		return nil
 	}
 
-	// Admin restrictions
-	if currentUser.RoleCode == auth.RoleAdmin {
+	// Everyone may manage themselves (field and deactivation rules still apply)
+	if targetUser.ID == currentUser.ID {
+		return nil
+	}
 
-		// Cannot manage superadmins
-		if targetUser.Role.Code == auth.RoleSuperAdmin {
-			return NewValidationError("permissions", "cannot manage superadmin users")
-		}
+	// Cannot manage superadmins
+	if isSuperAdminRole(&targetUser.Role) {
+		return NewValidationError("permissions", "cannot manage superadmin users")
+	}
 
-		// Cannot manage other admins (except self)
-		if targetUser.Role.Code == auth.RoleAdmin && targetUser.ID != currentUser.ID {
-			return NewValidationError("permissions", "cannot manage other admin users")
-		}
+	// Cannot manage peers (e.g. other admins) or higher roles
+	if !outranks(currentUser, &targetUser.Role) {
+		return NewValidationError("permissions", "cannot manage users whose role is not below your own")
+	}
 
-		// Admin can manage staff only within own shop
-		if targetUser.Role.Code != auth.RoleAdmin { // staff or lower
-			if currentUser.ShopID == nil || targetUser.ShopID == nil {
-				return NewValidationError("permissions", "shop assignment missing")
-			}
-			if *currentUser.ShopID != *targetUser.ShopID {
-				return NewValidationError("permissions", "cannot manage users from another shop")
-			}
-		}
+	// Org-wide actors: lower roles anywhere in the organization (the
+	// repository only returns users from it)
+	if currentUser.IsOrgWide() {
+		return nil
+	}
+
+	// Lower roles only within own shop
+	if currentUser.ShopID == nil || targetUser.ShopID == nil {
+		return NewValidationError("permissions", "shop assignment missing")
+	}
+	if *currentUser.ShopID != *targetUser.ShopID {
+		return NewValidationError("permissions", "cannot manage users from another shop")
 	}
 
 	return nil
@@ -558,15 +749,15 @@ func (s *service) canManageUser(currentUser *auth.AuthUser, targetUser *User) er
 
 // checkFieldUpdatePermission checks field update permissions (role-related restrictions)
 // This function only cares about "what fields are being modified", ensuring no rules are violated
-func (s *service) checkFieldUpdatePermission(currentUser *auth.AuthUser, targetUser *User, updates *UpdateUserInput) error {
+func (s *service) checkFieldUpdatePermission(ctx context.Context, currentUser *auth.AuthUser, targetUser *User, updates *UpdateUserInput) error {
 	// SuperAdmin: No restrictions
 	if currentUser.IsSuperAdmin() {
 		return nil
 	}
 
-	// Admin restrictions
-	if currentUser.IsAdminOnly() && updates.ShopCode != nil {
-		// Cannot change shop assignment
+	// Cannot change shop assignment, except org-wide actors moving users
+	// between shops of their organization (the shop lookup is scoped to it)
+	if updates.ShopCode != nil && !currentUser.IsOrgWide() {
 		newCode := strings.TrimSpace(*updates.ShopCode)
 		oldCode := ""
 		if targetUser.Shop != nil {
@@ -575,29 +766,26 @@ func (s *service) checkFieldUpdatePermission(currentUser *auth.AuthUser, targetU
 		if !strings.EqualFold(newCode, oldCode) {
 			return NewValidationError("shopCode", "cannot change shop assignment")
 		}
-		// Cannot promote anyone to admin
-		if updates.RoleCode != nil {
-			newRole := strings.TrimSpace(*updates.RoleCode)
-			oldRole := strings.TrimSpace(targetUser.Role.Code)
-
-			// If role is unchanged, skip further checks
-			if strings.EqualFold(newRole, oldRole) {
-			} else {
-				// Cannot promote anyone to admin
-				if newRole == auth.RoleAdmin {
-					return NewValidationError("roleCode", "cannot promote users to admin role")
-				}
-
-				// Cannot promote anyone to superadmin
-				if newRole == auth.RoleSuperAdmin {
-					return NewValidationError("roleCode", "cannot promote users to superadmin role")
-				}
+	}
 
-				// Cannot change own role
-				if targetUser.ID == currentUser.ID {
-					return NewValidationError("roleCode", "cannot change your own role")
-				}
+	// Role changes: never your own, and only to roles below your own
+	if updates.RoleCode != nil {
+		newRole := strings.TrimSpace(*updates.RoleCode)
+		if strings.EqualFold(newRole, strings.TrimSpace(targetUser.Role.Code)) {
+			return nil
+		}
+		if targetUser.ID == currentUser.ID {
+			return NewValidationError("roleCode", "cannot change your own role")
+		}
+		role, err := s.repo.GetRoleByCode(ctx, newRole)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return NewValidationError("roleCode", "invalid role code")
 			}
+			return fmt.Errorf("lookup roleCode: %w", err)
+		}
+		if !outranks(currentUser, role) {
+			return NewValidationError("roleCode", "cannot assign a role at or above your own")
 		}
 	}
 
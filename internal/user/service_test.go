@@ -0,0 +1,469 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/shop"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Service tests run against in-memory fakes: memRepo for the database and
+// MemoryIdentityProvider for GCIP. memRepo mimics the DB triggers that bump
+// token_version on role and active-state changes.
+
+type memRepo struct {
+	Repository // unimplemented methods panic
+
+	mu         sync.Mutex
+	users      map[uuid.UUID]*User
+	roles      map[string]*Role
+	shopOrgs   map[uuid.UUID]uuid.UUID // shop → organization, as app.shop records it
+	failCreate error
+}
+
+// seedPermissions mirrors the grants in the create_permissions and
+// create_organizations migrations.
+var seedPermissions = map[string][]string{
+	auth.RoleSuperAdmin: {
+		auth.PermShopsManage, auth.PermUsersManage, auth.PermWorkOrdersTransfer, auth.PermWorkOrdersImport,
+		auth.PermEstimatesWrite, auth.PermEstimatesApprove, auth.PermInvoicesManage, auth.PermAPIKeysManage, auth.PermRolesManage,
+		auth.PermOrgShops,
+	},
+	auth.RoleOrgAdmin: {
+		auth.PermShopsManage, auth.PermUsersManage, auth.PermWorkOrdersTransfer, auth.PermWorkOrdersImport,
+		auth.PermEstimatesWrite, auth.PermEstimatesApprove, auth.PermInvoicesManage, auth.PermOrgShops,
+	},
+	auth.RoleAdmin: {
+		auth.PermShopsManage, auth.PermUsersManage, auth.PermWorkOrdersTransfer, auth.PermWorkOrdersImport,
+		auth.PermEstimatesWrite, auth.PermEstimatesApprove, auth.PermInvoicesManage,
+	},
+	auth.RoleBodyman:  {auth.PermEstimatesWrite},
+	auth.RoleAdjuster: {},
+}
+
+func newMemRepo() *memRepo {
+	r := &memRepo{users: make(map[uuid.UUID]*User), roles: make(map[string]*Role), shopOrgs: make(map[uuid.UUID]uuid.UUID)}
+	for code, perms := range seedPermissions {
+		r.roles[code] = &Role{ID: uuid.New(), Code: code, Permissions: perms}
+	}
+	return r
+}
+
+func (r *memRepo) role(id uuid.UUID) Role {
+	for _, role := range r.roles {
+		if role.ID == id {
+			return *role
+		}
+	}
+	return Role{}
+}
+
+func (r *memRepo) Create(_ context.Context, in *CreateUserInput) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failCreate != nil {
+		return nil, r.failCreate
+	}
+	for _, u := range r.users {
+		if strings.EqualFold(u.Email, in.Email) {
+			return nil, ErrConflict
+		}
+	}
+	u := &User{
+		ID:             uuid.New(),
+		Email:          in.Email,
+		FirstName:      in.FirstName,
+		LastName:       in.LastName,
+		ExternalID:     in.ExternalID,
+		IsActive:       true,
+		TokenVersion:   1,
+		ShopID:         in.ShopID,
+		OrganizationID: in.OrganizationID,
+		RoleID:         in.RoleID,
+		Role:           r.role(in.RoleID),
+		CreatedAt:      time.Now(),
+	}
+	// trg_user_sync_organization: the shop decides the organization
+	if in.ShopID != nil {
+		org := r.shopOrgs[*in.ShopID]
+		u.OrganizationID = &org
+	}
+	r.users[u.ID] = u
+	cp := *u
+	return &cp, nil
+}
+
+// GetByID is scoped like the PG repository: users of other organizations are
+// not found, users without one (superadmins) always are.
+func (r *memRepo) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if org := auth.OrganizationScope(ctx); !ok || (org != nil && u.OrganizationID != nil && *u.OrganizationID != *org) {
+		return nil, ErrNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *memRepo) GetRoleIDByCode(_ context.Context, code string) (uuid.UUID, error) {
+	role, ok := r.roles[code]
+	if !ok {
+		return uuid.Nil, ErrNotFound
+	}
+	return role.ID, nil
+}
+
+func (r *memRepo) GetRoleByCode(_ context.Context, code string) (*Role, error) {
+	role, ok := r.roles[code]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *role
+	return &cp, nil
+}
+
+func (r *memRepo) Update(ctx context.Context, id uuid.UUID, in *UpdateUserInput) (*User, error) {
+	r.mu.Lock()
+	u, ok := r.users[id]
+	if !ok {
+		r.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	if in.FirstName != nil {
+		u.FirstName = *in.FirstName
+	}
+	if in.RoleID != nil && *in.RoleID != u.RoleID {
+		u.RoleID = *in.RoleID
+		u.Role = r.role(*in.RoleID)
+		u.TokenVersion++
+	}
+	r.mu.Unlock()
+	return r.GetByID(ctx, id)
+}
+
+func (r *memRepo) setActive(id uuid.UUID, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if u.IsActive != active {
+		u.IsActive = active
+		u.TokenVersion++
+	}
+	return nil
+}
+
+func (r *memRepo) Deactivate(_ context.Context, id uuid.UUID, _ *uuid.UUID) error {
+	return r.setActive(id, false)
+}
+
+func (r *memRepo) Reactivate(_ context.Context, id uuid.UUID) error {
+	return r.setActive(id, true)
+}
+
+func (r *memRepo) IncrementTokenVersion(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	u.TokenVersion++
+	return nil
+}
+
+type memShopService struct {
+	shop.ShopService
+	codes map[string]uuid.UUID
+	orgs  map[uuid.UUID]uuid.UUID
+}
+
+func (s *memShopService) GetShopIDByCode(ctx context.Context, code string) (uuid.UUID, error) {
+	id, ok := s.codes[strings.ToUpper(code)]
+	if org := auth.OrganizationScope(ctx); !ok || (org != nil && s.orgs[id] != *org) {
+		return uuid.Nil, ErrNotFound
+	}
+	return id, nil
+}
+
+// recordingSender captures welcome emails (sent from a goroutine).
+type recordingSender struct {
+	welcome chan string
+}
+
+func (s *recordingSender) SendWelcomePasswordSetup(_ context.Context, email, _, link string) error {
+	s.welcome <- email + " " + link
+	return nil
+}
+
+func (s *recordingSender) SendPasswordSetupReminder(context.Context, string, string, string) error {
+	return nil
+}
+
+type serviceFixture struct {
+	svc    UserService
+	repo   *memRepo
+	idp    *MemoryIdentityProvider
+	sender *recordingSender
+	shopA  uuid.UUID
+	shopB  uuid.UUID
+	shopX  uuid.UUID // in orgX
+	org    uuid.UUID // owns shopA and shopB
+	orgX   uuid.UUID
+}
+
+func newServiceFixture() *serviceFixture {
+	f := &serviceFixture{
+		repo:   newMemRepo(),
+		idp:    NewMemoryIdentityProvider(),
+		sender: &recordingSender{welcome: make(chan string, 10)},
+		shopA:  uuid.New(),
+		shopB:  uuid.New(),
+		shopX:  uuid.New(),
+		org:    uuid.New(),
+		orgX:   uuid.New(),
+	}
+	f.repo.shopOrgs = map[uuid.UUID]uuid.UUID{f.shopA: f.org, f.shopB: f.org, f.shopX: f.orgX}
+	shops := &memShopService{codes: map[string]uuid.UUID{"SHOPA": f.shopA, "SHOPB": f.shopB, "SHOPX": f.shopX}, orgs: f.repo.shopOrgs}
+	f.svc = NewServiceWithEmailSender(f.repo, shops, f.idp, f.sender, nil)
+	return f
+}
+
+func superAdmin() *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleSuperAdmin, Permissions: seedPermissions[auth.RoleSuperAdmin], IsActive: true}
+}
+
+func adminOf(shopID uuid.UUID) *auth.AuthUser {
+	return &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleAdmin, Permissions: seedPermissions[auth.RoleAdmin], ShopID: &shopID, IsActive: true}
+}
+
+// orgAdminOf returns an org admin of org, with the request ctx scoped the way
+// the auth middleware scopes it.
+func orgAdminOf(org uuid.UUID) (*auth.AuthUser, context.Context) {
+	u := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleOrgAdmin, Permissions: seedPermissions[auth.RoleOrgAdmin], OrganizationID: &org, IsActive: true}
+	return u, auth.WithOrganization(context.Background(), org)
+}
+
+// seed creates a user through the service, so the identity exists too.
+func (f *serviceFixture) seed(t *testing.T, email, role, shopCode string) *User {
+	t.Helper()
+	u, err := f.svc.CreateUser(context.Background(), superAdmin(), &CreateUserInput{
+		Email: email, FirstName: "Test", LastName: "User", RoleCode: role, ShopCode: &shopCode,
+	})
+	require.NoError(t, err)
+	return u
+}
+
+func TestCreateUserProvisionsIdentity(t *testing.T) {
+	f := newServiceFixture()
+	code := "shopa"
+
+	u, err := f.svc.CreateUser(context.Background(), adminOf(f.shopA), &CreateUserInput{
+		Email: " New.Tech@Example.com ", FirstName: "New", LastName: "Tech", RoleCode: auth.RoleBodyman, ShopCode: &code,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "new.tech@example.com", u.Email)
+	assert.Equal(t, f.shopA, *u.ShopID)
+	acct, ok := f.idp.Get(u.ExternalID)
+	require.True(t, ok, "identity should exist under the user's external ID")
+	assert.Equal(t, "new.tech@example.com", acct.Email)
+	assert.Equal(t, "New Tech", acct.DisplayName)
+	assert.Equal(t, u.TokenVersion, acct.TokenVersion, "token version claim written at creation")
+
+	select {
+	case got := <-f.sender.welcome:
+		assert.True(t, strings.HasPrefix(got, "new.tech@example.com http://"), got)
+	case <-time.After(time.Second):
+		t.Fatal("welcome email not sent")
+	}
+}
+
+func TestCreateUserRollsBackIdentityWhenInsertFails(t *testing.T) {
+	f := newServiceFixture()
+	f.repo.failCreate = errors.New("connection reset")
+
+	_, err := f.seedErr("rollback@example.com")
+	require.Error(t, err)
+	assert.Equal(t, 0, f.idp.Len(), "identity should be deleted when the DB insert fails")
+}
+
+func TestCreateUserReturnsInsertErrorWhenRollbackFails(t *testing.T) {
+	f := newServiceFixture()
+	f.repo.failCreate = ErrConflict
+	f.idp.FailOn["DeleteUser"] = errors.New("gcip unavailable")
+
+	_, err := f.seedErr("orphan@example.com")
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, 1, f.idp.Len(), "the orphaned identity is left behind (and logged)")
+}
+
+func TestCreateUserIdentityErrors(t *testing.T) {
+	t.Run("email already registered", func(t *testing.T) {
+		f := newServiceFixture()
+		_, err := f.idp.CreateUser(context.Background(), "taken@example.com", "A", "B")
+		require.NoError(t, err)
+
+		_, err = f.seedErr("taken@example.com")
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.Empty(t, f.repo.users)
+	})
+	t.Run("provider failure", func(t *testing.T) {
+		f := newServiceFixture()
+		f.idp.FailOn["CreateUser"] = errors.New("quota exceeded")
+
+		_, err := f.seedErr("quota@example.com")
+		assert.ErrorContains(t, err, "quota exceeded")
+		assert.Empty(t, f.repo.users)
+	})
+	t.Run("validation happens before provisioning", func(t *testing.T) {
+		f := newServiceFixture()
+		_, err := f.seedErr("not-an-email")
+		assert.ErrorIs(t, err, ErrInvalidInput)
+		assert.Equal(t, 0, f.idp.Len())
+	})
+}
+
+func (f *serviceFixture) seedErr(email string) (*User, error) {
+	code := "SHOPA"
+	return f.svc.CreateUser(context.Background(), superAdmin(), &CreateUserInput{
+		Email: email, FirstName: "Test", LastName: "User", RoleCode: auth.RoleAdjuster, ShopCode: &code,
+	})
+}
+
+func TestDeactivateAndReactivateUser(t *testing.T) {
+	f := newServiceFixture()
+	ctx := context.Background()
+	u := f.seed(t, "tech@example.com", auth.RoleBodyman, "SHOPA")
+	admin := adminOf(f.shopA)
+
+	require.NoError(t, f.svc.DeactivateUser(ctx, admin, u.ID))
+	stored, _ := f.repo.GetByID(ctx, u.ID)
+	acct, _ := f.idp.Get(u.ExternalID)
+	assert.False(t, stored.IsActive)
+	assert.True(t, acct.Disabled)
+	assert.Equal(t, stored.TokenVersion, acct.TokenVersion, "claim follows the bumped version")
+
+	require.NoError(t, f.svc.ReactivateUser(ctx, admin, u.ID))
+	stored, _ = f.repo.GetByID(ctx, u.ID)
+	acct, _ = f.idp.Get(u.ExternalID)
+	assert.True(t, stored.IsActive)
+	assert.False(t, acct.Disabled)
+	assert.Equal(t, u.TokenVersion+2, acct.TokenVersion)
+}
+
+func TestDeactivateUserKeepsDBAsSourceOfTruth(t *testing.T) {
+	f := newServiceFixture()
+	ctx := context.Background()
+	u := f.seed(t, "tech@example.com", auth.RoleAdjuster, "SHOPA")
+	f.idp.FailOn["DisableUser"] = errors.New("gcip unavailable")
+
+	require.NoError(t, f.svc.DeactivateUser(ctx, superAdmin(), u.ID))
+	stored, _ := f.repo.GetByID(ctx, u.ID)
+	assert.False(t, stored.IsActive)
+}
+
+func TestDeactivateUserPermissions(t *testing.T) {
+	f := newServiceFixture()
+	ctx := context.Background()
+	other := f.seed(t, "other@example.com", auth.RoleBodyman, "SHOPB")
+	admin := f.seed(t, "admin@example.com", auth.RoleAdmin, "SHOPA")
+	adminActor := &auth.AuthUser{ID: admin.ID, RoleCode: auth.RoleAdmin, Permissions: admin.Role.Permissions, ShopID: admin.ShopID, IsActive: true}
+
+	err := f.svc.DeactivateUser(ctx, adminActor, other.ID)
+	assert.ErrorIs(t, err, ErrInvalidInput, "admin cannot manage another shop's staff")
+	err = f.svc.DeactivateUser(ctx, adminActor, admin.ID)
+	assert.ErrorIs(t, err, ErrInvalidInput, "cannot deactivate yourself")
+
+	for _, u := range []*User{other, admin} {
+		acct, _ := f.idp.Get(u.ExternalID)
+		assert.False(t, acct.Disabled)
+	}
+}
+
+func TestRevokeSessions(t *testing.T) {
+	f := newServiceFixture()
+	ctx := context.Background()
+	u := f.seed(t, "tech@example.com", auth.RoleAdjuster, "SHOPA")
+
+	require.NoError(t, f.svc.RevokeSessions(ctx, adminOf(f.shopA), u.ID))
+	acct, _ := f.idp.Get(u.ExternalID)
+	assert.Equal(t, u.TokenVersion+1, acct.TokenVersion)
+	assert.Equal(t, 1, acct.Revocations)
+
+	f.idp.FailOn["SetTokenVersion"] = errors.New("gcip unavailable")
+	assert.Error(t, f.svc.RevokeSessions(ctx, superAdmin(), u.ID), "claim failures are returned so the call can be retried")
+	assert.ErrorIs(t, f.svc.RevokeSessions(ctx, adminOf(f.shopB), u.ID), ErrInvalidInput)
+}
+
+func TestUpdateUserRoleChangeSyncsClaim(t *testing.T) {
+	f := newServiceFixture()
+	ctx := context.Background()
+	u := f.seed(t, "tech@example.com", auth.RoleAdjuster, "SHOPA")
+
+	role := auth.RoleBodyman
+	updated, err := f.svc.UpdateUser(ctx, superAdmin(), u.ID, &UpdateUserInput{RoleCode: &role})
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleBodyman, updated.Role.Code)
+	acct, _ := f.idp.Get(u.ExternalID)
+	assert.Equal(t, updated.TokenVersion, acct.TokenVersion)
+	assert.Greater(t, acct.TokenVersion, u.TokenVersion)
+}
+
+/* ---------- organizations ---------- */
+
+// Test: org admins manage staff across their organization's shops, not beyond
+func TestOrgAdminManagesOrganizationUsers(t *testing.T) {
+	f := newServiceFixture()
+	orgAdmin, ctx := orgAdminOf(f.org)
+	inB := f.seed(t, "b@example.com", auth.RoleBodyman, "SHOPB")
+	inX := f.seed(t, "x@example.com", auth.RoleBodyman, "SHOPX")
+	shopB, shopX := "shopb", "SHOPX"
+
+	require.NoError(t, f.svc.DeactivateUser(ctx, orgAdmin, inB.ID))
+	assert.ErrorIs(t, f.svc.DeactivateUser(ctx, orgAdmin, inX.ID), ErrNotFound)
+
+	u, err := f.svc.CreateUser(ctx, orgAdmin, &CreateUserInput{
+		Email: "new@example.com", FirstName: "New", LastName: "Admin", RoleCode: auth.RoleAdmin, ShopCode: &shopB,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, f.shopB, *u.ShopID)
+	assert.Equal(t, f.org, *u.OrganizationID)
+
+	_, err = f.svc.CreateUser(ctx, orgAdmin, &CreateUserInput{
+		Email: "far@example.com", FirstName: "Far", LastName: "Away", RoleCode: auth.RoleBodyman, ShopCode: &shopX,
+	})
+	assert.ErrorIs(t, err, ErrInvalidInput, "shops of other organizations are unknown")
+
+	_, err = f.svc.CreateUser(ctx, orgAdmin, &CreateUserInput{
+		Email: "peer@example.com", FirstName: "Peer", LastName: "Admin", RoleCode: auth.RoleOrgAdmin,
+	})
+	assert.ErrorIs(t, err, ErrInvalidInput, "cannot create a peer org admin")
+}
+
+// Test: org admins have an organization but no shop
+func TestSuperAdminCreatesOrgAdmin(t *testing.T) {
+	f := newServiceFixture()
+	in := func() *CreateUserInput {
+		return &CreateUserInput{Email: "org@example.com", FirstName: "Org", LastName: "Admin", RoleCode: auth.RoleOrgAdmin}
+	}
+
+	_, err := f.svc.CreateUser(context.Background(), superAdmin(), in())
+	assert.ErrorIs(t, err, ErrInvalidInput, "an organization is required")
+
+	u, err := f.svc.CreateUser(auth.WithOrganization(context.Background(), f.orgX), superAdmin(), in())
+	require.NoError(t, err)
+	assert.Nil(t, u.ShopID)
+	assert.Equal(t, f.orgX, *u.OrganizationID)
+}
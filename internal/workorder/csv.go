@@ -0,0 +1,129 @@
+package workorder
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/csvsafe"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+)
+
+// exportColumn is one CSV column of the work order export.
+type exportColumn struct {
+	header string
+	value  func(*dto.WorkOrderExportRow) string
+}
+
+// exportGroups are the column groups selectable with ?columns=.
+// The work order code is always the first column.
+var exportGroups = map[string][]exportColumn{
+	"status": {
+		{"status", func(r *dto.WorkOrderExportRow) string { return string(r.Status) }},
+	},
+	"dates": {
+		{"date_received", func(r *dto.WorkOrderExportRow) string { return formatTime(r.CreatedAt) }},
+		{"date_updated", func(r *dto.WorkOrderExportRow) string { return formatTime(r.UpdatedAt) }},
+	},
+	"customer": {
+		{"customer_first_name", func(r *dto.WorkOrderExportRow) string { return r.CustomerFirstName }},
+		{"customer_last_name", func(r *dto.WorkOrderExportRow) string { return r.CustomerLastName }},
+		{"customer_email", func(r *dto.WorkOrderExportRow) string { return r.CustomerEmail }},
+		{"customer_phone", func(r *dto.WorkOrderExportRow) string { return r.CustomerPhone }},
+		{"customer_address", func(r *dto.WorkOrderExportRow) string { return r.CustomerAddress }},
+		{"customer_city", func(r *dto.WorkOrderExportRow) string { return r.CustomerCity }},
+		{"customer_province", func(r *dto.WorkOrderExportRow) string { return r.CustomerProvince }},
+		{"customer_postal_code", func(r *dto.WorkOrderExportRow) string { return r.CustomerPostalCode }},
+	},
+	"vehicle": {
+		{"vehicle_year", func(r *dto.WorkOrderExportRow) string { return fmt.Sprint(r.VehicleYear) }},
+		{"vehicle_make", func(r *dto.WorkOrderExportRow) string { return r.VehicleMake }},
+		{"vehicle_model", func(r *dto.WorkOrderExportRow) string { return r.VehicleModel }},
+		{"vehicle_body_style", func(r *dto.WorkOrderExportRow) string { return r.VehicleBody }},
+		{"vehicle_color", func(r *dto.WorkOrderExportRow) string { return r.VehicleColor }},
+		{"vehicle_vin", func(r *dto.WorkOrderExportRow) string { return r.VehicleVIN }},
+		{"vehicle_plate", func(r *dto.WorkOrderExportRow) string { return r.VehiclePlate }},
+	},
+	"insurance": {
+		{"insurance_company", func(r *dto.WorkOrderExportRow) string { return r.InsuranceCompany }},
+		{"insurance_agent", func(r *dto.WorkOrderExportRow) string { return r.InsuranceAgent }},
+		{"insurance_agent_phone", func(r *dto.WorkOrderExportRow) string { return r.AgentPhone }},
+		{"policy_number", func(r *dto.WorkOrderExportRow) string { return r.PolicyNumber }},
+		{"claim_number", func(r *dto.WorkOrderExportRow) string { return r.ClaimNumber }},
+	},
+	"shop": {
+		{"shop_code", func(r *dto.WorkOrderExportRow) string { return r.ShopCode }},
+		{"shop_name", func(r *dto.WorkOrderExportRow) string { return r.ShopName }},
+	},
+	"totals": {
+		{"estimate_subtotal", func(r *dto.WorkOrderExportRow) string { return formatCents(r.EstimateSubtotalCents) }},
+		{"invoice_number", func(r *dto.WorkOrderExportRow) string { return r.InvoiceNumber }},
+		{"invoice_status", func(r *dto.WorkOrderExportRow) string { return r.InvoiceStatus }},
+		{"invoice_total", func(r *dto.WorkOrderExportRow) string { return formatCents(r.InvoiceTotalCents) }},
+		{"invoice_paid", func(r *dto.WorkOrderExportRow) string { return formatCents(r.InvoicePaidCents) }},
+	},
+}
+
+// defaultGroups is the export column order when ?columns= is absent.
+var defaultGroups = []string{"status", "dates", "customer", "vehicle", "insurance", "shop", "totals"}
+
+var codeColumn = exportColumn{"work_order_code", func(r *dto.WorkOrderExportRow) string { return r.Code }}
+
+// exportColumns resolves a comma-separated group list into CSV columns, in the
+// order given. An empty list selects every group.
+func exportColumns(raw string) ([]exportColumn, error) {
+	groups := defaultGroups
+	if strings.TrimSpace(raw) != "" {
+		groups = strings.Split(raw, ",")
+	}
+
+	cols := []exportColumn{codeColumn}
+	seen := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		g = strings.ToLower(strings.TrimSpace(g))
+		if g == "" || seen[g] {
+			continue
+		}
+		group, ok := exportGroups[g]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown column group %q (use %s)", ErrInvalidInput, g, strings.Join(defaultGroups, ", "))
+		}
+		seen[g] = true
+		cols = append(cols, group...)
+	}
+	return cols, nil
+}
+
+func exportHeader(cols []exportColumn) []string {
+	out := make([]string, len(cols))
+	for i, c := range cols {
+		out[i] = c.header
+	}
+	return out
+}
+
+// exportRecord renders one row. record is reused between rows.
+func exportRecord(cols []exportColumn, row *dto.WorkOrderExportRow, record []string) []string {
+	record = record[:0]
+	for _, c := range cols {
+		record = append(record, csvsafe.Cell(c.value(row)))
+	}
+	return record
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatCents renders an optional amount as a plain decimal for spreadsheets.
+func formatCents(c *int64) string {
+	if c == nil {
+		return ""
+	}
+	v := *c
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
@@ -0,0 +1,111 @@
+package workorder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test: column groups resolve in the requested order, code first, no duplicates
+func TestExportColumns(t *testing.T) {
+	cols, err := exportColumns("shop, status,shop")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work_order_code", "shop_code", "shop_name", "status"}, exportHeader(cols))
+
+	all, err := exportColumns("")
+	require.NoError(t, err)
+	assert.Equal(t, "work_order_code", all[0].header)
+	assert.Greater(t, len(all), 20)
+
+	_, err = exportColumns("customer,assignees")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+// Test: rows render money as decimals, blanks for missing totals, and defuse formulas
+func TestExportRecord(t *testing.T) {
+	cols, err := exportColumns("customer,totals")
+	require.NoError(t, err)
+
+	total, paid := int64(123405), int64(5)
+	row := &dto.WorkOrderExportRow{
+		Code:              "WO-1",
+		CustomerFirstName: "=HYPERLINK(\"x\")",
+		InvoiceTotalCents: &total,
+		InvoicePaidCents:  &paid,
+	}
+	rec := exportRecord(cols, row, nil)
+	byHeader := map[string]string{}
+	for i, h := range exportHeader(cols) {
+		byHeader[h] = rec[i]
+	}
+	assert.Equal(t, "'=HYPERLINK(\"x\")", byHeader["customer_first_name"])
+	assert.Equal(t, "1234.05", byHeader["invoice_total"])
+	assert.Equal(t, "0.05", byHeader["invoice_paid"])
+	assert.Equal(t, "", byHeader["estimate_subtotal"])
+}
+
+// Test: list/export query-string filters
+func TestParseFilter(t *testing.T) {
+	r := httptest.NewRequest("GET", "/workorders?status=completed,in_progress&status=awaiting_info&from=2025-01-01&to=2025-01-31&shopCode=cal01&q=%20civic%20", nil)
+	f, err := parseFilter(r)
+	require.NoError(t, err)
+	assert.Equal(t, []dto.WorkOrderStatus{dto.WOStatusCompleted, dto.WOStatusInProgress, dto.WOStatusAwaitingInfo}, f.Statuses)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *f.From)
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), *f.To, "a plain to-date includes the whole day")
+	assert.Equal(t, "cal01", f.ShopCode)
+	assert.Equal(t, "civic", f.Query)
+
+	for _, q := range []string{"status=done", "from=yesterday", "from=2025-02-01&to=2025-01-01"} {
+		_, err := parseFilter(httptest.NewRequest("GET", "/workorders?"+q, nil))
+		assert.ErrorIs(t, err, ErrInvalidInput, q)
+	}
+}
+
+// Test: the shared WHERE clause numbers its placeholders in order
+func TestFilterClause(t *testing.T) {
+	where, args := filterClause(dto.WorkOrderFilter{})
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	where, args = filterClause(dto.WorkOrderFilter{
+		ShopCode: "cal01",
+		Statuses: []dto.WorkOrderStatus{dto.WOStatusCompleted},
+		From:     &from,
+		Query:    "50%_off",
+	})
+	assert.Contains(t, where, "s.code = $1")
+	assert.Contains(t, where, "ANY($2)")
+	assert.Contains(t, where, "wo.created_at >= $3")
+	assert.Contains(t, where, "wo.code ILIKE $4")
+	require.Len(t, args, 4)
+	assert.Equal(t, "CAL01", args[0])
+	assert.Equal(t, `%50\%\_off%`, args[3])
+}
+
+// failingExport fails every export before the first row.
+type failingExport struct {
+	Service
+	err error
+}
+
+func (f failingExport) ExportWorkOrders(context.Context, dto.WorkOrderFilter, func(*dto.WorkOrderExportRow) error) error {
+	return f.err
+}
+
+// Test: a failing query is a plain 500; the database error stays in the log
+func TestExportCSVHidesQueryErrors(t *testing.T) {
+	h := NewHandler(failingExport{err: errors.New(`ERROR: column "secret" does not exist (SQLSTATE 42703)`)})
+	rec := httptest.NewRecorder()
+	h.ExportCSV(rec, httptest.NewRequest(http.MethodGet, "/workorders/export.csv", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "internal server error\n", rec.Body.String())
+}
@@ -16,6 +16,7 @@ type WorkOrderDetail struct {
 	Vehicle      VehicleDetail    `json:"vehicle"`
 	Shop         ShopSummary      `json:"shop"`
 	Insurance    *InsuranceDetail `json:"insurance,omitempty"`
+	Hold         *HoldDetail      `json:"hold,omitempty"`
 }
 
 type CustomerDetail struct {
@@ -38,6 +39,13 @@ type VehicleDetail struct {
 	Color     string `json:"color"`
 }
 
+// HoldDetail is set while a work order is on hold, e.g. after its shop was
+// deactivated without a transfer target.
+type HoldDetail struct {
+	HeldAt time.Time `json:"heldAt"`
+	Reason string    `json:"reason,omitempty"`
+}
+
 type InsuranceDetail struct {
 	InsuranceCompany string `json:"insuranceCompany"`
 	AgentFullName    string `json:"agentFullName"`
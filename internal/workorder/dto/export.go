@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkOrderExportRow is one flattened work order for spreadsheet export.
+// Optional text columns are empty strings rather than nil.
+type WorkOrderExportRow struct {
+	ID        uuid.UUID
+	Code      string
+	Status    WorkOrderStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	CustomerFirstName  string
+	CustomerLastName   string
+	CustomerEmail      string
+	CustomerPhone      string
+	CustomerAddress    string
+	CustomerCity       string
+	CustomerProvince   string
+	CustomerPostalCode string
+
+	VehicleYear  int
+	VehicleMake  string
+	VehicleModel string
+	VehicleBody  string
+	VehicleColor string
+	VehicleVIN   string
+	VehiclePlate string
+
+	InsuranceCompany string
+	InsuranceAgent   string
+	AgentPhone       string
+	PolicyNumber     string
+	ClaimNumber      string
+
+	ShopCode string
+	ShopName string
+
+	// Totals come from the approved estimate (pre-tax) and the active invoice.
+	EstimateSubtotalCents *int64
+	InvoiceNumber         string
+	InvoiceStatus         string
+	InvoiceTotalCents     *int64
+	InvoicePaidCents      *int64
+}
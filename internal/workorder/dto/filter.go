@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkOrderFilter narrows GET /workorders and GET /workorders/export.csv.
+// Zero values mean "no restriction".
+type WorkOrderFilter struct {
+	OrganizationID *uuid.UUID        // set by the repository from the request's organization scope
+	ShopID         *uuid.UUID        // set from the caller's shop scope; overrides ShopCode
+	ShopCode       string            // superadmin and org admins only
+	Statuses       []WorkOrderStatus // any of
+	From           *time.Time        // created_at >= From
+	To             *time.Time        // created_at < To
+	Query          string            // matches code, customer name/email, VIN, plate or claim number
+}
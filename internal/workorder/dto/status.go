@@ -9,3 +9,13 @@ const (
 	WOStatusFollowUpNeeded       WorkOrderStatus = "follow_up_needed"
 	WOStatusAwaitingInfo         WorkOrderStatus = "awaiting_info"
 )
+
+// IsValid reports whether s is one of the known statuses.
+func (s WorkOrderStatus) IsValid() bool {
+	switch s {
+	case WOStatusWaitingForInspection, WOStatusInProgress, WOStatusCompleted,
+		WOStatusFollowUpNeeded, WOStatusAwaitingInfo:
+		return true
+	}
+	return false
+}
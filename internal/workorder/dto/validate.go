@@ -0,0 +1,136 @@
+package dto
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FieldError describes one invalid intake field, e.g. {"customer.phone", "must have 10 or 11 digits"}.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors lists every invalid field of an intake payload.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, len(v))
+	for i, e := range v {
+		msgs[i] = e.Field + ": " + e.Message
+	}
+	return "invalid intake: " + strings.Join(msgs, "; ")
+}
+
+// These mirror the CHECK constraints and normalisation triggers on
+// app.customers, app.vehicles and app.insurance, so bad input is reported
+// per field instead of as a failed insert.
+var (
+	vinPattern    = regexp.MustCompile(`^[A-HJ-NPR-Z0-9]{17}$`)
+	postalPattern = regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY][0-9][ABCEGHJ-NPRSTV-Z] ?[0-9][ABCEGHJ-NPRSTV-Z][0-9]$`)
+	emailPattern  = regexp.MustCompile(`(?i)^[A-Z0-9._%+-]+@[A-Z0-9.-]+\.[A-Z]{2,}$`)
+	provinces     = map[string]bool{
+		"AB": true, "BC": true, "MB": true, "NB": true, "NL": true, "NT": true, "NS": true,
+		"NU": true, "ON": true, "PE": true, "QC": true, "SK": true, "YT": true,
+	}
+)
+
+// Normalize trims every field and applies the same casing and digit-stripping
+// the database triggers do.
+func (p *IntakePayload) Normalize() {
+	c := &p.Customer
+	c.FirstName = strings.TrimSpace(c.FirstName)
+	c.LastName = strings.TrimSpace(c.LastName)
+	c.Address = strings.TrimSpace(c.Address)
+	c.City = strings.TrimSpace(c.City)
+	c.PostalCode = strings.ToUpper(strings.TrimSpace(c.PostalCode))
+	c.Province = strings.ToUpper(strings.TrimSpace(c.Province))
+	c.Email = strings.TrimSpace(c.Email)
+	c.Phone = digitsOnly(c.Phone)
+
+	v := &p.Vehicle
+	v.PlateNo = strings.ToUpper(strings.TrimSpace(v.PlateNo))
+	v.Make = strings.TrimSpace(v.Make)
+	v.Model = strings.TrimSpace(v.Model)
+	v.BodyStyle = strings.TrimSpace(v.BodyStyle)
+	v.VIN = strings.ToUpper(strings.TrimSpace(v.VIN))
+	v.Color = strings.TrimSpace(v.Color)
+
+	if i := p.Insurance; i != nil {
+		i.InsuranceCompany = strings.TrimSpace(i.InsuranceCompany)
+		i.AgentFirstName = strings.TrimSpace(i.AgentFirstName)
+		i.AgentLastName = strings.TrimSpace(i.AgentLastName)
+		i.AgentPhone = digitsOnly(i.AgentPhone)
+		i.PolicyNumber = strings.TrimSpace(i.PolicyNumber)
+		i.ClaimNumber = strings.TrimSpace(i.ClaimNumber)
+	}
+
+	p.Shop.ShopCode = strings.ToUpper(strings.TrimSpace(p.Shop.ShopCode))
+}
+
+// Validate checks a normalised payload and reports every invalid field.
+// It returns nil or a ValidationErrors.
+func (p *IntakePayload) Validate() error {
+	var errs ValidationErrors
+	add := func(field, msg string) { errs = append(errs, FieldError{Field: field, Message: msg}) }
+	required := func(field, value string) bool {
+		if value == "" {
+			add(field, "is required")
+			return false
+		}
+		return true
+	}
+
+	c := p.Customer
+	required("customer.firstName", c.FirstName)
+	required("customer.lastName", c.LastName)
+	required("customer.address", c.Address)
+	required("customer.city", c.City)
+	if required("customer.postalCode", c.PostalCode) && !postalPattern.MatchString(c.PostalCode) {
+		add("customer.postalCode", "must be a Canadian postal code")
+	}
+	if required("customer.province", c.Province) && !provinces[c.Province] {
+		add("customer.province", "must be a Canadian province code")
+	}
+	if required("customer.email", c.Email) && !emailPattern.MatchString(c.Email) {
+		add("customer.email", "must be an email address")
+	}
+	if required("customer.phone", c.Phone) && (len(c.Phone) < 10 || len(c.Phone) > 11) {
+		add("customer.phone", "must have 10 or 11 digits")
+	}
+
+	v := p.Vehicle
+	required("vehicle.make", v.Make)
+	required("vehicle.model", v.Model)
+	if required("vehicle.vin", v.VIN) && !vinPattern.MatchString(v.VIN) {
+		add("vehicle.vin", "must be a 17-character VIN")
+	}
+	if v.ModelYear < 1900 || v.ModelYear > time.Now().Year()+1 {
+		add("vehicle.modelYear", "must be a model year")
+	}
+
+	if i := p.Insurance; !i.IsEmpty() {
+		if err := i.Validate(); err != nil {
+			add("insurance.insuranceCompany", "is required when insurance information is provided")
+		}
+		if i.AgentPhone != "" && (len(i.AgentPhone) < 10 || len(i.AgentPhone) > 11) {
+			add("insurance.agentPhone", "must have 10 or 11 digits")
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -6,5 +6,7 @@ package workorder
 import "errors"
 
 var (
-	ErrNotFound = errors.New("work order not found")
+	ErrNotFound     = errors.New("work order not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrShopInactive = errors.New("shop is inactive and not accepting new work orders")
 )
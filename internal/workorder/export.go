@@ -0,0 +1,192 @@
+package workorder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/jackc/pgx/v5"
+)
+
+// filterClause builds the WHERE clause shared by the list and export queries.
+// It expects the aliases wo (work_orders), c (customers) and s (shop).
+func filterClause(f dto.WorkOrderFilter) (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.OrganizationID != nil {
+		conds = append(conds, "s.organization_id = "+arg(*f.OrganizationID))
+	}
+	switch {
+	case f.ShopID != nil:
+		conds = append(conds, "wo.shop_id = "+arg(*f.ShopID))
+	case f.ShopCode != "":
+		conds = append(conds, "s.code = "+arg(strings.ToUpper(f.ShopCode)))
+	}
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, len(f.Statuses))
+		for i, st := range f.Statuses {
+			statuses[i] = string(st)
+		}
+		conds = append(conds, "wo.status::text = ANY("+arg(statuses)+")")
+	}
+	if f.From != nil {
+		conds = append(conds, "wo.created_at >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		conds = append(conds, "wo.created_at < "+arg(*f.To))
+	}
+	if q := strings.TrimSpace(f.Query); q != "" {
+		p := arg("%" + escapeLike(q) + "%")
+		conds = append(conds, `(wo.code ILIKE `+p+
+			` OR (c.first_name || ' ' || c.last_name) ILIKE `+p+
+			` OR c.email ILIKE `+p+
+			` OR EXISTS (SELECT 1 FROM app.vehicles fv WHERE fv.id = wo.vehicle_id AND (fv.vin ILIKE `+p+` OR fv.plate_number ILIKE `+p+`))`+
+			` OR EXISTS (SELECT 1 FROM app.insurance fi WHERE fi.work_order_id = wo.id AND fi.claim_number ILIKE `+p+`))`)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *repository) StreamExport(ctx context.Context, filter dto.WorkOrderFilter, fn func(*dto.WorkOrderExportRow) error) error {
+	filter.OrganizationID = auth.OrganizationScope(ctx)
+	return database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		return streamExport(ctx, tx, filter, fn)
+	})
+}
+
+func streamExport(ctx context.Context, q querier, filter dto.WorkOrderFilter, fn func(*dto.WorkOrderExportRow) error) error {
+	where, args := filterClause(filter)
+
+	// pgx reads rows off the connection as Next is called, so the export is
+	// streamed end to end; nothing here collects the result set.
+	rows, err := q.Query(ctx, `
+		SELECT
+			wo.id,
+			wo.code,
+			wo.status,
+			wo.created_at,
+			wo.updated_at,
+
+			c.first_name,
+			c.last_name,
+			COALESCE(c.email::text, ''),
+			COALESCE(c.phone, ''),
+			c.address,
+			c.city,
+			c.province,
+			c.postal_code,
+
+			v.model_year,
+			v.make,
+			v.model,
+			COALESCE(v.body_style, ''),
+			COALESCE(v.color, ''),
+			v.vin::text,
+			COALESCE(v.plate_number, ''),
+
+			COALESCE(i.insurance_company, ''),
+			TRIM(COALESCE(i.agent_first_name, '') || ' ' || COALESCE(i.agent_last_name, '')),
+			COALESCE(i.agent_phone, ''),
+			COALESCE(i.policy_number, ''),
+			COALESCE(i.claim_number, ''),
+
+			s.code,
+			s.shop_name,
+
+			est.subtotal_cents,
+			COALESCE(inv.number, ''),
+			COALESCE(inv.status::text, ''),
+			inv.total_cents,
+			inv.paid_cents
+		FROM app.work_orders wo
+		JOIN app.customers c ON wo.customer_id = c.id
+		JOIN app.vehicles  v ON wo.vehicle_id  = v.id
+		JOIN app.shop      s ON wo.shop_id     = s.id
+		LEFT JOIN app.insurance i ON i.work_order_id = wo.id
+		LEFT JOIN LATERAL (
+			SELECT SUM(el.amount_cents)::bigint AS subtotal_cents
+			FROM app.estimates e
+			JOIN app.estimate_lines el ON el.estimate_id = e.id
+			WHERE e.work_order_id = wo.id AND e.status = 'approved'
+		) est ON true
+		LEFT JOIN LATERAL (
+			SELECT iv.number, iv.status, iv.total_cents,
+			       (SELECT COALESCE(SUM(p.amount_cents), 0)::bigint FROM app.payments p WHERE p.invoice_id = iv.id) AS paid_cents
+			FROM app.invoices iv
+			WHERE iv.work_order_id = wo.id AND iv.status <> 'void'
+		) inv ON true
+		`+where+`
+		ORDER BY wo.created_at DESC
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var row dto.WorkOrderExportRow
+	for rows.Next() {
+		err := rows.Scan(
+			&row.ID,
+			&row.Code,
+			&row.Status,
+			&row.CreatedAt,
+			&row.UpdatedAt,
+
+			&row.CustomerFirstName,
+			&row.CustomerLastName,
+			&row.CustomerEmail,
+			&row.CustomerPhone,
+			&row.CustomerAddress,
+			&row.CustomerCity,
+			&row.CustomerProvince,
+			&row.CustomerPostalCode,
+
+			&row.VehicleYear,
+			&row.VehicleMake,
+			&row.VehicleModel,
+			&row.VehicleBody,
+			&row.VehicleColor,
+			&row.VehicleVIN,
+			&row.VehiclePlate,
+
+			&row.InsuranceCompany,
+			&row.InsuranceAgent,
+			&row.AgentPhone,
+			&row.PolicyNumber,
+			&row.ClaimNumber,
+
+			&row.ShopCode,
+			&row.ShopName,
+
+			&row.EstimateSubtotalCents,
+			&row.InvoiceNumber,
+			&row.InvoiceStatus,
+			&row.InvoiceTotalCents,
+			&row.InvoicePaidCents,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
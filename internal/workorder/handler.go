@@ -2,6 +2,7 @@ package workorder
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
@@ -25,10 +26,15 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 
 }
 
-// GET /workorders
+// GET /workorders?status=&shopCode=&from=&to=&q=
 func (h *Handler) ListWorkOrder(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	items, err := h.service.ListWorkOrder(ctx)
+	filter, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	items, err := h.service.ListWorkOrder(ctx, filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -67,6 +73,17 @@ func (h *Handler) CreateWorkOrder(w http.ResponseWriter, r *http.Request) {
 
 	wo, err := h.service.CreateWorkOrder(ctx, payload)
 	if err != nil {
+		var verrs dto.ValidationErrors
+		if errors.As(err, &verrs) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]any{"error": "invalid intake", "fields": verrs})
+			return
+		}
+		if errors.Is(err, ErrShopInactive) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
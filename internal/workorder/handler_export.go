@@ -0,0 +1,174 @@
+package workorder
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/middleware"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+)
+
+const (
+	// exportFlushRows is how many rows are buffered before pushing them to the client.
+	exportFlushRows = 500
+	// exportWriteWindow is how long the client gets to accept the first rows and
+	// each flushed batch after them. The server-wide WriteTimeout would
+	// otherwise cut off long exports, or slow queries before their first row.
+	exportWriteWindow = 30 * time.Second
+)
+
+// GET /workorders/export.csv?columns=customer,vehicle,...&status=&shopCode=&from=&to=&q=
+// Takes the same filters as GET /workorders. Rows are written as they are read
+// from the database, so this route is mounted outside the request timeout.
+func (h *Handler) ExportCSV(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cols, err := exportColumns(r.URL.Query().Get("columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	cw := csv.NewWriter(w)
+	record := make([]string, 0, len(cols))
+	started, count := false, 0
+
+	// Headers go out with the first row so a failing query still gets a proper error status.
+	start := func() error {
+		started = true
+		// The WriteTimeout runs from the request, not from the first row.
+		_ = rc.SetWriteDeadline(time.Now().Add(exportWriteWindow))
+		filename := fmt.Sprintf("workorders-%s.csv", time.Now().Format("20060102"))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.WriteHeader(http.StatusOK)
+		// BOM so Excel opens accented names as UTF-8.
+		if _, err := w.Write([]byte("\uFEFF")); err != nil {
+			return err
+		}
+		return cw.Write(exportHeader(cols))
+	}
+	flush := func() error {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		_ = rc.SetWriteDeadline(time.Now().Add(exportWriteWindow))
+		return rc.Flush()
+	}
+
+	err = h.service.ExportWorkOrders(ctx, filter, func(row *dto.WorkOrderExportRow) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(exportRecord(cols, row, record)); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushRows == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			slog.ErrorContext(ctx, "work order export failed", "error", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		// Too late for an error status; abort so the client sees a broken
+		// download instead of a silently truncated file.
+		slog.ErrorContext(ctx, "work order export aborted", "rows", count, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			slog.ErrorContext(ctx, "work order export failed", "error", err)
+			return
+		}
+	}
+	if err := flush(); err != nil {
+		slog.ErrorContext(ctx, "work order export failed", "error", err)
+	}
+}
+
+// parseFilter reads the list/export filters from the query string. Non-superadmins
+// are always limited to their own shop (see middleware.EnforceShopScope).
+func parseFilter(r *http.Request) (dto.WorkOrderFilter, error) {
+	q := r.URL.Query()
+	var f dto.WorkOrderFilter
+
+	if shopID, ok := middleware.GetShopIDFromContext(r.Context()); ok {
+		f.ShopID = &shopID
+	} else {
+		f.ShopCode = strings.TrimSpace(q.Get("shopCode"))
+	}
+
+	for _, raw := range splitList(q, "status") {
+		st := dto.WorkOrderStatus(raw)
+		if !st.IsValid() {
+			return f, fmt.Errorf("%w: unknown status %q", ErrInvalidInput, raw)
+		}
+		f.Statuses = append(f.Statuses, st)
+	}
+
+	var err error
+	if f.From, err = parseDate(q.Get("from"), false); err != nil {
+		return f, fmt.Errorf("%w: from: %v", ErrInvalidInput, err)
+	}
+	if f.To, err = parseDate(q.Get("to"), true); err != nil {
+		return f, fmt.Errorf("%w: to: %v", ErrInvalidInput, err)
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return f, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+
+	f.Query = strings.TrimSpace(q.Get("q"))
+	return f, nil
+}
+
+// splitList accepts both ?status=a,b and ?status=a&status=b.
+func splitList(q url.Values, key string) []string {
+	var out []string
+	for _, v := range q[key] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// parseDate accepts YYYY-MM-DD or RFC 3339. A plain date used as an upper bound
+// includes the whole day.
+func parseDate(raw string, endOfDay bool) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a date (use YYYY-MM-DD)", raw)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -15,7 +17,10 @@ import (
 )
 
 type Repository interface {
-	ListWorkOrder(ctx context.Context) ([]dto.WorkOrderListItem, error)
+	ListWorkOrder(ctx context.Context, filter dto.WorkOrderFilter) ([]dto.WorkOrderListItem, error)
+	// StreamExport calls fn for each matching work order as rows arrive from the
+	// database, so the result set is never held in memory.
+	StreamExport(ctx context.Context, filter dto.WorkOrderFilter, fn func(*dto.WorkOrderExportRow) error) error
 	GetWorkOrderByID(ctx context.Context, id uuid.UUID) (dto.WorkOrderDetail, error)
 	CreateWorkOrder(ctx context.Context, payload dto.IntakePayload) (dto.WorkOrderDetail, error)
 	//EditorIntake(ctx context.Context, code string, payload dto.IntakeEditPayload) (dto.WorkOrderDetail, error)
@@ -25,12 +30,30 @@ type repository struct {
 	db *pgxpool.Pool
 }
 
+// querier is satisfied by *pgxpool.Pool and pgx.Tx. Reads run in a
+// transaction from database.InScope, so the RLS policies on work order data
+// apply to them.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 func NewRepository(db *pgxpool.Pool) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) ListWorkOrder(ctx context.Context) ([]dto.WorkOrderListItem, error) {
-	rows, err := r.db.Query(ctx, `
+func (r *repository) ListWorkOrder(ctx context.Context, filter dto.WorkOrderFilter) (list []dto.WorkOrderListItem, err error) {
+	filter.OrganizationID = auth.OrganizationScope(ctx)
+	err = database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		list, err = listWorkOrder(ctx, tx, filter)
+		return err
+	})
+	return list, err
+}
+
+func listWorkOrder(ctx context.Context, q querier, filter dto.WorkOrderFilter) ([]dto.WorkOrderListItem, error) {
+	where, args := filterClause(filter)
+	rows, err := q.Query(ctx, `
 	SELECT 
 		wo.id,
 		wo.code,
@@ -49,8 +72,9 @@ func (r *repository) ListWorkOrder(ctx context.Context) ([]dto.WorkOrderListItem
 		ON wo.customer_id = c.id
 	JOIN app.shop AS s
 		ON wo.shop_id = s.id
+	`+where+`
 	ORDER BY wo.created_at DESC
-	`)
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -79,9 +103,17 @@ func (r *repository) ListWorkOrder(ctx context.Context) ([]dto.WorkOrderListItem
 	return result, rows.Err()
 }
 
-func (r *repository) GetWorkOrderByID(ctx context.Context, id uuid.UUID) (dto.WorkOrderDetail, error) {
+func (r *repository) GetWorkOrderByID(ctx context.Context, id uuid.UUID) (detail dto.WorkOrderDetail, err error) {
+	err = database.InScope(ctx, r.db, func(tx pgx.Tx) error {
+		detail, err = getWorkOrderByID(ctx, tx, id)
+		return err
+	})
+	return detail, err
+}
+
+func getWorkOrderByID(ctx context.Context, q querier, id uuid.UUID) (dto.WorkOrderDetail, error) {
 	var detail dto.WorkOrderDetail
-	row := r.db.QueryRow(ctx, `
+	row := q.QueryRow(ctx, `
 		SELECT
 			wo.id,
 			wo.code,
@@ -113,7 +145,10 @@ func (r *repository) GetWorkOrderByID(ctx context.Context, id uuid.UUID) (dto.Wo
 
 			s.id,
 			s.code,
-			s.shop_name
+			s.shop_name,
+
+			wo.held_at,
+			wo.hold_reason
 
 		FROM app.work_orders wo
 		JOIN app.customers c ON wo.customer_id = c.id
@@ -121,7 +156,8 @@ func (r *repository) GetWorkOrderByID(ctx context.Context, id uuid.UUID) (dto.Wo
 		LEFT JOIN app.insurance i ON wo.id = i.work_order_id
 		JOIN app.shop s ON wo.shop_id = s.id
 		WHERE wo.id = $1
-	`, id)
+		  AND ($2::uuid IS NULL OR s.organization_id = $2)
+	`, id, auth.OrganizationScope(ctx))
 
 	var (
 		insCompany    sql.NullString
@@ -129,6 +165,8 @@ func (r *repository) GetWorkOrderByID(ctx context.Context, id uuid.UUID) (dto.Wo
 		agentPhone    sql.NullString
 		policyNumber  sql.NullString
 		claimNumber   sql.NullString
+		heldAt        sql.NullTime
+		holdReason    sql.NullString
 	)
 
 	err := row.Scan(
@@ -163,11 +201,21 @@ func (r *repository) GetWorkOrderByID(ctx context.Context, id uuid.UUID) (dto.Wo
 		&detail.Shop.ShopID,
 		&detail.Shop.ShopCode,
 		&detail.Shop.ShopName,
+
+		&heldAt,
+		&holdReason,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return detail, ErrNotFound
+		}
 		return detail, err
 	}
 
+	if heldAt.Valid {
+		detail.Hold = &dto.HoldDetail{HeldAt: heldAt.Time, Reason: holdReason.String}
+	}
+
 	// if any of the insurance fields is not null, set insurance info as non-nil
 	if insCompany.Valid || agentFullName.Valid ||
 		agentPhone.Valid || policyNumber.Valid || claimNumber.Valid {
@@ -192,10 +240,27 @@ func (r *repository) CreateWorkOrder(ctx context.Context, payload dto.IntakePayl
 	}
 
 	defer tx.Rollback(ctx)
+	if err := database.SetScope(ctx, tx); err != nil {
+		return dto.WorkOrderDetail{}, err
+	}
 	var shopID uuid.UUID
-	//0. resolve shopID from payload.Shop
+	var shopStatus string
+	org := auth.OrganizationScope(ctx)
+	//0. resolve shopID from payload.Shop, within the request's organization
 	if payload.Shop.ShopID != uuid.Nil {
-		shopID = payload.Shop.ShopID
+		err = tx.QueryRow(ctx, `
+            SELECT id, status
+            FROM app.shop
+            WHERE id = $1
+              AND ($2::uuid IS NULL OR organization_id = $2)
+            FOR SHARE
+        `, payload.Shop.ShopID, org).Scan(&shopID, &shopStatus)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return dto.WorkOrderDetail{}, fmt.Errorf("shop not found for id %s", payload.Shop.ShopID)
+			}
+			return dto.WorkOrderDetail{}, fmt.Errorf("lookup shop by id %s: %w", payload.Shop.ShopID, err)
+		}
 	} else {
 		code := strings.TrimSpace(payload.Shop.ShopCode)
 		if code == "" {
@@ -203,12 +268,18 @@ func (r *repository) CreateWorkOrder(ctx context.Context, payload dto.IntakePayl
 			return dto.WorkOrderDetail{}, fmt.Errorf("shop identifier missing: either shop_id or shop_code must be provided")
 		}
 
-		// look up shopID by shop code
+		// look up shopID by shop code; codes are only unique per organization
+		var matches int
 		err = tx.QueryRow(ctx, `
-            SELECT id
+            SELECT id, status,
+                   (SELECT count(*) FROM app.shop m
+                     WHERE m.code = $1 AND ($2::uuid IS NULL OR m.organization_id = $2))
             FROM app.shop
             WHERE code = $1
-        `, code).Scan(&shopID)
+              AND ($2::uuid IS NULL OR organization_id = $2)
+            LIMIT 1
+            FOR SHARE
+        `, code, org).Scan(&shopID, &shopStatus, &matches)
 		if err != nil {
 			// handle not found
 			if errors.Is(err, pgx.ErrNoRows) {
@@ -216,33 +287,39 @@ func (r *repository) CreateWorkOrder(ctx context.Context, payload dto.IntakePayl
 			}
 			return dto.WorkOrderDetail{}, fmt.Errorf("lookup shop by code %s: %w", code, err)
 		}
+		if matches > 1 {
+			return dto.WorkOrderDetail{}, fmt.Errorf("shop code %s exists in several organizations; set the %s header", code, auth.OrganizationHeader)
+		}
+	}
+	// inactive shops take no new intake; FOR SHARE holds off a concurrent
+	// deactivation until this work order is committed (and so gets moved)
+	if shopStatus != "active" {
+		return dto.WorkOrderDetail{}, ErrShopInactive
 	}
 
-	//define customerID to capture inserted customer ID
-	var customerID uuid.UUID
-	//Execute insert statement and capture the returned ID
-	//1. Insert customer and capture customer ID
-	err = tx.QueryRow(ctx, `
+	// Customer and vehicle IDs are generated here rather than RETURNING'd: under
+	// RLS a customer or vehicle is only readable once a work order points at it.
+	//1. Insert customer
+	customerID := uuid.New()
+	_, err = tx.Exec(ctx, `
 		INSERT INTO app.customers
-		(first_name, last_name, address, city, postal_code, province, email, phone)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id
-		`, payload.Customer.FirstName, payload.Customer.LastName, payload.Customer.Address, payload.Customer.City, payload.Customer.PostalCode, payload.Customer.Province, payload.Customer.Email,
+		(id, first_name, last_name, address, city, postal_code, province, email, phone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, customerID, payload.Customer.FirstName, payload.Customer.LastName, payload.Customer.Address, payload.Customer.City, payload.Customer.PostalCode, payload.Customer.Province, payload.Customer.Email,
 		payload.Customer.Phone,
-	).Scan(&customerID)
+	)
 	if err != nil {
 		return dto.WorkOrderDetail{}, fmt.Errorf("insert customer: %w", err)
 	}
-	//2. Insert vehicle and capture vehicle ID
-	var vehicleID uuid.UUID
-	err = tx.QueryRow(ctx, `
+	//2. Insert vehicle
+	vehicleID := uuid.New()
+	_, err = tx.Exec(ctx, `
 		INSERT INTO app.vehicles
-		(plate_number, make, model, body_style, model_year, vin, color)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id
-		`, payload.Vehicle.PlateNo, payload.Vehicle.Make, payload.Vehicle.Model, payload.Vehicle.BodyStyle, payload.Vehicle.ModelYear, payload.Vehicle.VIN,
+		(id, plate_number, make, model, body_style, model_year, vin, color)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, vehicleID, payload.Vehicle.PlateNo, payload.Vehicle.Make, payload.Vehicle.Model, payload.Vehicle.BodyStyle, payload.Vehicle.ModelYear, payload.Vehicle.VIN,
 		payload.Vehicle.Color,
-	).Scan(&vehicleID)
+	)
 	if err != nil {
 		return dto.WorkOrderDetail{}, fmt.Errorf("insert vehicle: %w", err)
 	}
@@ -0,0 +1,190 @@
+package workorder
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/auth"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/platform/database"
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests prove the row-level security policies hold on their own: the
+// queries below carry no shop filter, so only the database keeps shops apart.
+// They connect as DB_APP_USER (RLS does not bind the table owner) and need a
+// migrated test database:
+//  1. docker-compose -f compose.test.yml up -d and run migrations
+//  2. set DB_HOST, DB_TEST_PORT, DB_NAME, DB_APP_USER, DB_APP_PASSWORD in .env
+//  3. go test -v -run RLS ./internal/workorder
+// Without those variables the tests are skipped.
+
+func rlsTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	_ = godotenv.Load("../../.env")
+	host, port, name := os.Getenv("DB_HOST"), os.Getenv("DB_TEST_PORT"), os.Getenv("DB_NAME")
+	user, password := os.Getenv("DB_APP_USER"), os.Getenv("DB_APP_PASSWORD")
+	if host == "" || port == "" || name == "" || user == "" || password == "" {
+		t.Skip("DB_HOST, DB_TEST_PORT, DB_NAME, DB_APP_USER and DB_APP_PASSWORD are required")
+	}
+	schema := os.Getenv("DB_SCHEMA")
+	if schema == "" {
+		schema = "app"
+	}
+
+	db, err := pgxpool.New(context.Background(), fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=disable&search_path=%s",
+		user, password, host, port, name, schema))
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+	require.NoError(t, db.Ping(context.Background()))
+	return db
+}
+
+// rlsShop creates a shop in the DEFAULT organization and removes it (with its
+// work orders) after the test. Cleanup runs in the system scope.
+func rlsShop(t *testing.T, db *pgxpool.Pool) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+	code := fmt.Sprintf("RLS%04d", rand.IntN(10000))
+	var id uuid.UUID
+	require.NoError(t, db.QueryRow(ctx, `
+INSERT INTO app.shop (organization_id, code, shop_name, status, address, city, province, postal_code, contact_name, phone, email)
+SELECT id, $1, 'RLS test ' || $1, 'active', '1 Main St', 'Calgary', 'AB', 'T2P2B5', 'Pat', '403-555-1234', 'rls@example.com'
+FROM app.organizations WHERE code = 'DEFAULT'
+RETURNING id`, code).Scan(&id))
+
+	t.Cleanup(func() {
+		ctx := database.WithSystemScope(context.Background())
+		_ = database.InScope(ctx, db, func(tx pgx.Tx) error {
+			_, _ = tx.Exec(ctx, `DELETE FROM app.insurance WHERE work_order_id IN (SELECT id FROM app.work_orders WHERE shop_id = $1)`, id)
+			_, err := tx.Exec(ctx, `
+WITH gone AS (DELETE FROM app.work_orders WHERE shop_id = $1 RETURNING customer_id, vehicle_id),
+     c AS (DELETE FROM app.customers WHERE id IN (SELECT customer_id FROM gone))
+DELETE FROM app.vehicles WHERE id IN (SELECT vehicle_id FROM gone)`, id)
+			return err
+		})
+		_, _ = db.Exec(ctx, `DELETE FROM app.shop WHERE id = $1`, id)
+	})
+	return id
+}
+
+// actorCtx is a request context for an admin of shopID.
+func actorCtx(shopID uuid.UUID) context.Context {
+	u := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleAdmin, ShopID: &shopID, IsActive: true}
+	return auth.SetAuthUser(context.Background(), u)
+}
+
+func rlsIntake(shopID uuid.UUID) dto.IntakePayload {
+	vin := make([]byte, 17)
+	for i := range vin {
+		vin[i] = "ABCDEFGHJKLMNPRSTUVWXYZ0123456789"[rand.IntN(33)]
+	}
+	return dto.IntakePayload{
+		Customer: dto.CustomerIntake{
+			FirstName: "Rls", LastName: "Test", Address: "1 Main St", City: "Calgary", PostalCode: "T2P2B5", Province: "AB",
+			Email: strings.ToLower(string(vin)) + "@example.com", Phone: "4035551234",
+		},
+		Vehicle:   dto.VehicleIntake{Make: "Honda", Model: "Civic", ModelYear: 2020, VIN: string(vin)},
+		Insurance: &dto.InsuranceIntake{InsuranceCompany: "Acme Mutual", ClaimNumber: "CLM-1"},
+		Shop:      dto.ShopRef{ShopID: shopID},
+	}
+}
+
+func TestRLSHidesOtherShopsWorkOrders(t *testing.T) {
+	db := rlsTestDB(t)
+	repo := NewRepository(db)
+	shopA, shopB := rlsShop(t, db), rlsShop(t, db)
+	ctxA, ctxB := actorCtx(shopA), actorCtx(shopB)
+
+	woA, err := repo.CreateWorkOrder(ctxA, rlsIntake(shopA))
+	require.NoError(t, err, "intake works under RLS")
+	woB, err := repo.CreateWorkOrder(ctxB, rlsIntake(shopB))
+	require.NoError(t, err)
+
+	t.Run("repository", func(t *testing.T) {
+		_, err := repo.GetWorkOrderByID(ctxA, woB.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		got, err := repo.GetWorkOrderByID(ctxA, woA.ID)
+		require.NoError(t, err)
+		require.NotNil(t, got.Insurance)
+
+		// no ShopID in the filter: the database does the scoping
+		list, err := repo.ListWorkOrder(ctxA, dto.WorkOrderFilter{})
+		require.NoError(t, err)
+		for _, wo := range list {
+			assert.Equal(t, shopA, wo.Shop.ShopID)
+		}
+	})
+
+	t.Run("raw queries", func(t *testing.T) {
+		err := database.InScope(ctxA, db, func(tx pgx.Tx) error {
+			var n int
+			for table, q := range map[string]string{
+				"work_orders": `SELECT count(*) FROM app.work_orders WHERE id = $1`,
+				"customers":   `SELECT count(*) FROM app.customers c JOIN app.work_orders wo ON wo.customer_id = c.id WHERE wo.id = $1`,
+				"insurance":   `SELECT count(*) FROM app.insurance WHERE work_order_id = $1`,
+			} {
+				require.NoError(t, tx.QueryRow(ctxA, q, woB.ID).Scan(&n))
+				assert.Zero(t, n, "%s of another shop are visible", table)
+			}
+
+			tag, err := tx.Exec(ctxA, `UPDATE app.work_orders SET updated_at = now() WHERE id = $1`, woB.ID)
+			require.NoError(t, err)
+			assert.Zero(t, tag.RowsAffected(), "another shop's work order was updated")
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("writes into another shop", func(t *testing.T) {
+		err := database.InScope(ctxA, db, func(tx pgx.Tx) error {
+			_, err := tx.Exec(ctxA, `UPDATE app.work_orders SET shop_id = $2 WHERE id = $1`, woA.ID, shopB)
+			return err
+		})
+		assert.ErrorContains(t, err, "row-level security")
+	})
+
+	t.Run("unscoped connections see nothing", func(t *testing.T) {
+		var n int
+		require.NoError(t, db.QueryRow(context.Background(),
+			`SELECT count(*) FROM app.work_orders WHERE id = ANY($1)`, []uuid.UUID{woA.ID, woB.ID}).Scan(&n))
+		assert.Zero(t, n)
+	})
+
+	t.Run("system scope sees everything", func(t *testing.T) {
+		ctx := database.WithSystemScope(context.Background())
+		var n int
+		require.NoError(t, database.InScope(ctx, db, func(tx pgx.Tx) error {
+			return tx.QueryRow(ctx, `SELECT count(*) FROM app.work_orders WHERE id = ANY($1)`, []uuid.UUID{woA.ID, woB.ID}).Scan(&n)
+		}))
+		assert.Equal(t, 2, n)
+	})
+}
+
+func TestRLSSuperAdminSeesEveryShop(t *testing.T) {
+	db := rlsTestDB(t)
+	repo := NewRepository(db)
+	shopA, shopB := rlsShop(t, db), rlsShop(t, db)
+
+	woB, err := repo.CreateWorkOrder(actorCtx(shopB), rlsIntake(shopB))
+	require.NoError(t, err)
+
+	superAdmin := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleSuperAdmin, ShopID: &shopA, IsActive: true}
+	_, err = repo.GetWorkOrderByID(auth.SetAuthUser(context.Background(), superAdmin), woB.ID)
+	assert.NoError(t, err)
+
+	// staff without a shop see nothing
+	stray := &auth.AuthUser{ID: uuid.New(), RoleCode: auth.RoleBodyman, IsActive: true}
+	_, err = repo.GetWorkOrderByID(auth.SetAuthUser(context.Background(), stray), woB.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"strings"
 
+	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/audit"
 	"github.com/DashboardDivas/havenzsure-dashboard-backend/internal/workorder/dto"
 	"github.com/google/uuid"
 )
 
 type Service interface {
-	ListWorkOrder(ctx context.Context) ([]dto.WorkOrderListItem, error)
+	ListWorkOrder(ctx context.Context, filter dto.WorkOrderFilter) ([]dto.WorkOrderListItem, error)
+	ExportWorkOrders(ctx context.Context, filter dto.WorkOrderFilter, fn func(*dto.WorkOrderExportRow) error) error
 	GetWorkOrderByID(ctx context.Context, id uuid.UUID) (dto.WorkOrderDetail, error)
 	CreateWorkOrder(ctx context.Context, payload dto.IntakePayload) (dto.WorkOrderDetail, error)
 	//UpsertInsurance(ctx context.Context, workOrderID string, payload dto.InsuranceIntake) (dto.WorkOrderDetail, error)
@@ -17,23 +19,41 @@ type Service interface {
 }
 
 type service struct {
-	repo Repository
+	repo  Repository
+	audit audit.Recorder
 }
 
 var _ Service = (*service)(nil)
 
-func NewService(r Repository) Service {
-	return &service{repo: r}
+// NewService creates the work order service. Intakes are reported to rec (nil
+// records nothing).
+func NewService(r Repository, rec audit.Recorder) Service {
+	return &service{repo: r, audit: audit.OrNop(rec)}
 }
 
-func (s *service) ListWorkOrder(ctx context.Context) ([]dto.WorkOrderListItem, error) {
-	return s.repo.ListWorkOrder(ctx)
+func (s *service) ListWorkOrder(ctx context.Context, filter dto.WorkOrderFilter) ([]dto.WorkOrderListItem, error) {
+	return s.repo.ListWorkOrder(ctx, filter)
+}
+func (s *service) ExportWorkOrders(ctx context.Context, filter dto.WorkOrderFilter, fn func(*dto.WorkOrderExportRow) error) error {
+	return s.repo.StreamExport(ctx, filter, fn)
 }
 func (s *service) GetWorkOrderByID(ctx context.Context, id uuid.UUID) (dto.WorkOrderDetail, error) {
 	return s.repo.GetWorkOrderByID(ctx, id)
 }
 func (s *service) CreateWorkOrder(ctx context.Context, payload dto.IntakePayload) (dto.WorkOrderDetail, error) {
-	return s.repo.CreateWorkOrder(ctx, payload)
+	payload.Normalize()
+	if err := payload.Validate(); err != nil {
+		return dto.WorkOrderDetail{}, err
+	}
+	wo, err := s.repo.CreateWorkOrder(ctx, payload)
+	if err != nil {
+		return dto.WorkOrderDetail{}, err
+	}
+	s.audit.Record(ctx, audit.Entry{
+		Action: "workorder.create", EntityType: audit.EntityWorkOrder, EntityID: wo.ID.String(),
+		ShopID: &wo.Shop.ShopID, After: wo,
+	})
+	return wo, nil
 }
 
 // func (s *service) UpsertInsurance(ctx context.Context, workOrderID string, payload dto.InsuranceIntake) error {
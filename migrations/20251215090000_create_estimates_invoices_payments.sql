@@ -0,0 +1,247 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Billing Module
+-- - estimates / estimate_lines: versioned repair estimates per work order
+-- - invoices / invoice_lines: billed from the approved estimate once the WO is completed
+-- - invoice_number_counter: per-shop sequential invoice numbers (INV-<SHOP>-0001)
+-- - payments: cash / card / e-transfer / insurer cheque
+-- All money is stored as integer cents.
+------------------------------------------------------------
+
+------------------------------------------------------------
+-- 1) Enum types
+------------------------------------------------------------
+CREATE TYPE app.estimate_status AS ENUM ('draft', 'approved', 'rejected');
+
+CREATE TYPE app.billing_line_category AS ENUM ('pdr', 'r_and_i', 'paint', 'parts', 'sublet', 'other');
+
+CREATE TYPE app.invoice_status AS ENUM ('issued', 'partially_paid', 'paid', 'void');
+
+CREATE TYPE app.payment_method AS ENUM ('cash', 'card', 'e_transfer', 'insurer_cheque');
+
+CREATE TYPE app.payment_payer AS ENUM ('customer', 'insurer');
+
+------------------------------------------------------------
+-- 2) estimates
+------------------------------------------------------------
+CREATE TABLE app.estimates (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    work_order_id uuid NOT NULL
+        REFERENCES app.work_orders(id) ON DELETE CASCADE,
+    version integer NOT NULL,
+    status app.estimate_status NOT NULL DEFAULT 'draft',
+    deductible_cents bigint NOT NULL DEFAULT 0,
+    notes text,
+    created_by_user_id uuid
+        REFERENCES app.users(id),
+    approved_by_user_id uuid
+        REFERENCES app.users(id),
+    approved_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+
+    CONSTRAINT uq_estimates_work_order_version UNIQUE (work_order_id, version),
+    CONSTRAINT ck_estimates_deductible_non_negative CHECK (deductible_cents >= 0),
+    CONSTRAINT ck_estimates_approved_consistency CHECK (
+        (status = 'approved' AND approved_at IS NOT NULL) OR (status <> 'approved')
+    )
+);
+
+-- at most one approved estimate per work order
+CREATE UNIQUE INDEX uq_estimates_one_approved_per_work_order
+    ON app.estimates(work_order_id)
+    WHERE status = 'approved';
+
+CREATE TABLE app.estimate_lines (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    estimate_id uuid NOT NULL
+        REFERENCES app.estimates(id) ON DELETE CASCADE,
+    line_no integer NOT NULL,
+    category app.billing_line_category NOT NULL,
+    description text NOT NULL,
+    quantity numeric(10,2) NOT NULL DEFAULT 1,
+    unit_price_cents bigint NOT NULL,
+    amount_cents bigint NOT NULL,
+    taxable boolean NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT now(),
+
+    CONSTRAINT uq_estimate_lines_line_no UNIQUE (estimate_id, line_no),
+    CONSTRAINT ck_estimate_lines_description_not_blank CHECK (char_length(trim(description)) > 0),
+    CONSTRAINT ck_estimate_lines_quantity_positive CHECK (quantity > 0)
+);
+
+CREATE INDEX idx_estimate_lines_estimate_id
+    ON app.estimate_lines(estimate_id);
+
+------------------------------------------------------------
+-- 3) invoice numbers: per-shop counter + generator
+------------------------------------------------------------
+CREATE TABLE app.invoice_number_counter (
+    shop_id uuid PRIMARY KEY
+        REFERENCES app.shop(id) ON DELETE CASCADE,
+    last_value integer NOT NULL DEFAULT 0
+);
+
+-- Generate sequential per-shop invoice numbers like INV-CAL01-0001.
+-- The UPSERT row-locks the shop's counter, so concurrent inserts never share a number.
+CREATE OR REPLACE FUNCTION app.generate_invoice_number(p_shop_id uuid)
+RETURNS varchar AS $$
+DECLARE
+  next_num int;
+  shop_code text;
+BEGIN
+  INSERT INTO app.invoice_number_counter AS c (shop_id, last_value)
+  VALUES (p_shop_id, 1)
+  ON CONFLICT (shop_id) DO UPDATE SET last_value = c.last_value + 1
+  RETURNING c.last_value INTO next_num;
+
+  SELECT s.code INTO shop_code FROM app.shop s WHERE s.id = p_shop_id;
+
+  RETURN 'INV-' || shop_code || '-' || lpad(next_num::text, 4, '0');
+END;
+$$ LANGUAGE plpgsql;
+
+------------------------------------------------------------
+-- 4) invoices
+------------------------------------------------------------
+CREATE TABLE app.invoices (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    number text NOT NULL,
+    shop_id uuid NOT NULL
+        REFERENCES app.shop(id),
+    work_order_id uuid NOT NULL
+        REFERENCES app.work_orders(id),
+    estimate_id uuid NOT NULL
+        REFERENCES app.estimates(id),
+    status app.invoice_status NOT NULL DEFAULT 'issued',
+    province char(2) NOT NULL,
+    tax_date date NOT NULL,
+    subtotal_cents bigint NOT NULL,
+    tax_cents bigint NOT NULL,
+    total_cents bigint NOT NULL,
+    deductible_cents bigint NOT NULL DEFAULT 0,
+    customer_portion_cents bigint NOT NULL,
+    insurer_portion_cents bigint NOT NULL,
+    tax_components jsonb NOT NULL DEFAULT '[]'::jsonb,
+    issued_at timestamptz NOT NULL DEFAULT now(),
+    voided_at timestamptz,
+    voided_by_user_id uuid
+        REFERENCES app.users(id),
+    created_by_user_id uuid
+        REFERENCES app.users(id),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+
+    CONSTRAINT uq_invoices_shop_number UNIQUE (shop_id, number),
+    CONSTRAINT ck_invoices_totals CHECK (total_cents = subtotal_cents + tax_cents),
+    CONSTRAINT ck_invoices_portions CHECK (customer_portion_cents + insurer_portion_cents = total_cents),
+    CONSTRAINT ck_invoices_portions_non_negative CHECK (customer_portion_cents >= 0 AND insurer_portion_cents >= 0),
+    CONSTRAINT ck_invoices_void_consistency CHECK (
+        (status = 'void' AND voided_at IS NOT NULL) OR (status <> 'void' AND voided_at IS NULL)
+    )
+);
+
+-- at most one live (non-void) invoice per work order
+CREATE UNIQUE INDEX uq_invoices_one_active_per_work_order
+    ON app.invoices(work_order_id)
+    WHERE status <> 'void';
+
+CREATE INDEX idx_invoices_shop_id
+    ON app.invoices(shop_id);
+
+CREATE OR REPLACE FUNCTION app.invoice_set_number()
+RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  IF coalesce(btrim(NEW.number), '') = '' THEN
+    NEW.number := app.generate_invoice_number(NEW.shop_id);
+  END IF;
+  RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER trg_invoice_set_number
+BEFORE INSERT ON app.invoices
+FOR EACH ROW
+EXECUTE FUNCTION app.invoice_set_number();
+
+CREATE TABLE app.invoice_lines (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_id uuid NOT NULL
+        REFERENCES app.invoices(id) ON DELETE CASCADE,
+    line_no integer NOT NULL,
+    category app.billing_line_category NOT NULL,
+    description text NOT NULL,
+    quantity numeric(10,2) NOT NULL,
+    unit_price_cents bigint NOT NULL,
+    amount_cents bigint NOT NULL,
+    taxable boolean NOT NULL,
+    tax_cents bigint NOT NULL DEFAULT 0,
+
+    CONSTRAINT uq_invoice_lines_line_no UNIQUE (invoice_id, line_no)
+);
+
+CREATE INDEX idx_invoice_lines_invoice_id
+    ON app.invoice_lines(invoice_id);
+
+------------------------------------------------------------
+-- 5) payments
+------------------------------------------------------------
+CREATE TABLE app.payments (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_id uuid NOT NULL
+        REFERENCES app.invoices(id),
+    method app.payment_method NOT NULL,
+    payer app.payment_payer NOT NULL,
+    amount_cents bigint NOT NULL,
+    reference text,                      -- card auth code, e-transfer ref, cheque number
+    notes text,
+    received_at timestamptz NOT NULL DEFAULT now(),
+    recorded_by_user_id uuid
+        REFERENCES app.users(id),
+    created_at timestamptz NOT NULL DEFAULT now(),
+
+    CONSTRAINT ck_payments_amount_positive CHECK (amount_cents > 0),
+    CONSTRAINT ck_payments_insurer_cheque_payer CHECK (method <> 'insurer_cheque' OR payer = 'insurer')
+);
+
+CREATE INDEX idx_payments_invoice_id
+    ON app.payments(invoice_id);
+
+------------------------------------------------------------
+-- 6) updated_at triggers (using app.set_updated_at)
+------------------------------------------------------------
+CREATE TRIGGER trg_set_updated_at_estimates
+BEFORE UPDATE ON app.estimates
+FOR EACH ROW
+EXECUTE FUNCTION app.set_updated_at();
+
+CREATE TRIGGER trg_set_updated_at_invoices
+BEFORE UPDATE ON app.invoices
+FOR EACH ROW
+EXECUTE FUNCTION app.set_updated_at();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_set_updated_at_invoices ON app.invoices;
+DROP TRIGGER IF EXISTS trg_set_updated_at_estimates ON app.estimates;
+DROP TRIGGER IF EXISTS trg_invoice_set_number ON app.invoices;
+
+DROP TABLE IF EXISTS app.payments;
+DROP TABLE IF EXISTS app.invoice_lines;
+DROP TABLE IF EXISTS app.invoices;
+DROP TABLE IF EXISTS app.invoice_number_counter;
+DROP TABLE IF EXISTS app.estimate_lines;
+DROP TABLE IF EXISTS app.estimates;
+
+DROP FUNCTION IF EXISTS app.invoice_set_number();
+DROP FUNCTION IF EXISTS app.generate_invoice_number(uuid);
+
+DROP TYPE IF EXISTS app.payment_payer;
+DROP TYPE IF EXISTS app.payment_method;
+DROP TYPE IF EXISTS app.invoice_status;
+DROP TYPE IF EXISTS app.billing_line_category;
+DROP TYPE IF EXISTS app.estimate_status;
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Bulk work order import jobs (CSV / XLSX)
+-- - one row per committed import; progress is updated after each batch
+-- - rejected_rows keeps the original cell values so the error file can be
+--   rebuilt in the uploaded column layout
+------------------------------------------------------------
+CREATE TYPE app.import_job_status AS ENUM ('queued', 'running', 'completed', 'failed');
+
+CREATE TABLE app.import_jobs (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    shop_id uuid                            -- NULL when a superadmin imports into several shops
+        REFERENCES app.shop(id),
+    created_by_user_id uuid
+        REFERENCES app.users(id),
+    filename text NOT NULL,
+    status app.import_job_status NOT NULL DEFAULT 'queued',
+    total_rows integer NOT NULL DEFAULT 0,
+    processed_rows integer NOT NULL DEFAULT 0,
+    created_rows integer NOT NULL DEFAULT 0,
+    failed_rows integer NOT NULL DEFAULT 0,
+    header jsonb NOT NULL DEFAULT '[]'::jsonb,
+    rejected_rows jsonb NOT NULL DEFAULT '[]'::jsonb,  -- [{row, values, errors}]
+    created_work_orders jsonb NOT NULL DEFAULT '[]'::jsonb, -- [{row, workOrderId, code}]
+    error text,
+    started_at timestamptz,
+    finished_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+
+    CONSTRAINT ck_import_jobs_counts CHECK (
+        processed_rows <= total_rows AND created_rows + failed_rows = processed_rows
+    )
+);
+
+CREATE INDEX idx_import_jobs_shop_id
+    ON app.import_jobs(shop_id);
+
+CREATE TRIGGER trg_set_updated_at_import_jobs
+BEFORE UPDATE ON app.import_jobs
+FOR EACH ROW
+EXECUTE FUNCTION app.set_updated_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_set_updated_at_import_jobs ON app.import_jobs;
+DROP TABLE IF EXISTS app.import_jobs;
+DROP TYPE IF EXISTS app.import_job_status;
+-- +goose StatementEnd
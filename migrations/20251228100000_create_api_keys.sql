@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Service-to-service API keys
+-- - the key is shown once at creation: hz_<prefix>_<secret>
+-- - only the SHA-256 of the full key is stored; prefix finds the row
+-- - shop_id NULL means a system-wide key (not tied to one shop)
+-- - revoked keys are kept for auditing
+------------------------------------------------------------
+CREATE TABLE app.api_keys (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name text NOT NULL,
+    prefix text NOT NULL,
+    key_hash bytea NOT NULL,
+    scopes text[] NOT NULL DEFAULT '{}',
+    shop_id uuid
+        REFERENCES app.shop(id),
+    created_by_user_id uuid
+        REFERENCES app.users(id),
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    revoked_at timestamptz,
+    revoked_by_user_id uuid
+        REFERENCES app.users(id),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+
+    CONSTRAINT uq_api_keys_prefix UNIQUE (prefix),
+    CONSTRAINT ck_api_keys_name_not_blank CHECK (btrim(name) <> ''),
+    CONSTRAINT ck_api_keys_key_hash_len CHECK (octet_length(key_hash) = 32),
+    CONSTRAINT ck_api_keys_scopes_not_empty CHECK (cardinality(scopes) > 0)
+);
+
+CREATE INDEX idx_api_keys_shop_id
+    ON app.api_keys(shop_id);
+
+CREATE TRIGGER trg_set_updated_at_api_keys
+BEFORE UPDATE ON app.api_keys
+FOR EACH ROW
+EXECUTE FUNCTION app.set_updated_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_set_updated_at_api_keys ON app.api_keys;
+DROP TABLE IF EXISTS app.api_keys;
+-- +goose StatementEnd
@@ -0,0 +1,75 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Role permissions
+-- - permissions: the catalogue of permission codes checked by the API
+-- - role_permissions: which roles hold which permissions
+-- - the seed reproduces the previous hard-coded role matrix; custom roles
+--   (is_system = false) are added through the /roles endpoints
+------------------------------------------------------------
+CREATE TABLE app.permissions (
+    code text PRIMARY KEY,
+    description text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+
+    CONSTRAINT ck_permissions_code CHECK (code ~ '^[a-z]+(\.[a-z_]+)+$')
+);
+
+CREATE TABLE app.role_permissions (
+    role_id uuid NOT NULL
+        REFERENCES app.roles(id) ON DELETE CASCADE,
+    permission_code text NOT NULL
+        REFERENCES app.permissions(code) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (role_id, permission_code)
+);
+
+CREATE INDEX idx_role_permissions_permission_code
+    ON app.role_permissions(permission_code);
+
+INSERT INTO app.permissions (code, description) VALUES
+    ('shops.manage',        'Create, update and list shops'),
+    ('users.manage',        'Manage users with a lower role in own shop'),
+    ('workorders.transfer', 'Move work orders between shops'),
+    ('workorders.import',   'Bulk import work orders from CSV/XLSX'),
+    ('estimates.write',     'Create and edit draft estimates'),
+    ('estimates.approve',   'Approve estimates'),
+    ('invoices.manage',     'Issue invoices and record payments'),
+    ('apikeys.manage',      'Create and revoke service API keys'),
+    ('roles.manage',        'Create custom roles and change role permissions')
+ON CONFLICT (code) DO NOTHING;
+
+-- superadmin: everything (the API also treats it as holding any future permission)
+INSERT INTO app.role_permissions (role_id, permission_code)
+SELECT r.id, p.code
+FROM app.roles r CROSS JOIN app.permissions p
+WHERE r.code = 'superadmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO app.role_permissions (role_id, permission_code)
+SELECT r.id, g.permission_code
+FROM (VALUES
+    ('admin',   'shops.manage'),
+    ('admin',   'users.manage'),
+    ('admin',   'workorders.transfer'),
+    ('admin',   'workorders.import'),
+    ('admin',   'estimates.write'),
+    ('admin',   'estimates.approve'),
+    ('admin',   'invoices.manage'),
+    ('bodyman', 'estimates.write')
+) AS g(role_code, permission_code)
+JOIN app.roles r ON r.code = g.role_code
+ON CONFLICT DO NOTHING;
+
+-- Custom role codes follow the same shape as the seeded ones
+ALTER TABLE app.roles
+    ADD CONSTRAINT ck_roles_code CHECK (code ~ '^[a-z][a-z0-9_]{1,31}$');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE app.roles DROP CONSTRAINT IF EXISTS ck_roles_code;
+DROP TABLE IF EXISTS app.role_permissions;
+DROP TABLE IF EXISTS app.permissions;
+-- +goose StatementEnd
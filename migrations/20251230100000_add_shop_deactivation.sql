@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Shop deactivation
+-- - open work orders of a deactivated shop are either moved to another
+--   shop or put on hold (held_at/hold_reason) in the inactive shop
+-- - the shop records who deactivated it and when
+------------------------------------------------------------
+ALTER TABLE app.work_orders
+    ADD COLUMN held_at timestamptz,
+    ADD COLUMN hold_reason text;
+
+ALTER TABLE app.work_orders
+    ADD CONSTRAINT ck_work_orders_hold_reason
+        CHECK (hold_reason IS NULL OR held_at IS NOT NULL);
+
+CREATE INDEX idx_work_orders_shop_open
+    ON app.work_orders(shop_id)
+    WHERE status <> 'completed';
+
+ALTER TABLE app.shop
+    ADD COLUMN deactivated_at timestamptz,
+    ADD COLUMN deactivated_by uuid
+        REFERENCES app.users(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE app.shop
+    DROP COLUMN IF EXISTS deactivated_by,
+    DROP COLUMN IF EXISTS deactivated_at;
+
+DROP INDEX IF EXISTS app.idx_work_orders_shop_open;
+
+ALTER TABLE app.work_orders
+    DROP CONSTRAINT IF EXISTS ck_work_orders_hold_reason,
+    DROP COLUMN IF EXISTS hold_reason,
+    DROP COLUMN IF EXISTS held_at;
+-- +goose StatementEnd
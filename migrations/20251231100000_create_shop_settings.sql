@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Shop profile settings (one row per shop, created on first save)
+-- - business_hours: [{"day":"mon","open":"08:00","close":"17:00"}, ...]
+--   days not listed are closed
+-- - holidays: [{"date":"2025-12-25","name":"Christmas Day"}, ...]
+-- - labour rates are hourly, in cents; NULL means "no default"
+-- - tax_province overrides app.shop.province for sales tax
+-- - the logo is small enough to keep in the row (capped by the service)
+------------------------------------------------------------
+CREATE TABLE app.shop_settings (
+    shop_id uuid PRIMARY KEY
+        REFERENCES app.shop(id) ON DELETE CASCADE,
+    business_hours jsonb NOT NULL DEFAULT '[]',
+    holidays jsonb NOT NULL DEFAULT '[]',
+    pdr_rate_cents bigint,
+    r_and_i_rate_cents bigint,
+    paint_rate_cents bigint,
+    tax_province text,
+    invoice_footer text,
+    logo bytea,
+    logo_content_type text,
+    logo_updated_at timestamptz,
+    updated_by uuid
+        REFERENCES app.users(id),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+
+    CONSTRAINT ck_shop_settings_rates CHECK (
+        coalesce(pdr_rate_cents, 0) >= 0
+        AND coalesce(r_and_i_rate_cents, 0) >= 0
+        AND coalesce(paint_rate_cents, 0) >= 0
+    ),
+    CONSTRAINT ck_shop_settings_tax_province CHECK (
+        tax_province IS NULL
+        OR tax_province IN ('AB','BC','MB','NB','NL','NT','NS','NU','ON','PE','QC','SK','YT')
+    ),
+    CONSTRAINT ck_shop_settings_logo CHECK (
+        (logo IS NULL) = (logo_content_type IS NULL)
+    )
+);
+
+CREATE TRIGGER trg_set_updated_at_shop_settings
+BEFORE UPDATE ON app.shop_settings
+FOR EACH ROW
+EXECUTE FUNCTION app.set_updated_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_set_updated_at_shop_settings ON app.shop_settings;
+DROP TABLE IF EXISTS app.shop_settings;
+-- +goose StatementEnd
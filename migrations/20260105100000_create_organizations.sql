@@ -0,0 +1,119 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Organizations (franchise brands) above shops
+-- - every shop belongs to exactly one organization; existing shops are moved
+--   into a seeded DEFAULT organization
+-- - users belong to their shop's organization (kept in step by a trigger);
+--   org-level users (orgadmin) have an organization but no shop, and
+--   superadmins have neither
+-- - shop codes and user codes are unique per organization
+-- - the orgadmin role holds every admin permission plus org.shops, which
+--   extends shop-level rights to all shops in the organization
+------------------------------------------------------------
+CREATE TABLE app.organizations (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    code varchar(10) NOT NULL,
+    name text NOT NULL,
+    status app.shop_status NOT NULL DEFAULT 'active',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+
+    CONSTRAINT uq_organizations_code UNIQUE (code),
+    CONSTRAINT ck_organizations_code CHECK (code ~ '^[A-Z0-9][A-Z0-9_-]{1,9}$'),
+    CONSTRAINT ck_organizations_name_not_blank CHECK (char_length(trim(name)) > 0)
+);
+
+CREATE TRIGGER trg_set_updated_at_organizations
+BEFORE UPDATE ON app.organizations
+FOR EACH ROW
+EXECUTE FUNCTION app.set_updated_at();
+
+INSERT INTO app.organizations (code, name) VALUES ('DEFAULT', 'Default organization');
+
+-- Shops: owned by an organization, codes unique within it
+ALTER TABLE app.shop ADD COLUMN organization_id uuid
+    REFERENCES app.organizations(id) ON DELETE RESTRICT;
+UPDATE app.shop SET organization_id = (SELECT id FROM app.organizations WHERE code = 'DEFAULT');
+ALTER TABLE app.shop ALTER COLUMN organization_id SET NOT NULL;
+
+ALTER TABLE app.shop DROP CONSTRAINT uq_shop_code;
+ALTER TABLE app.shop ADD CONSTRAINT uq_shop_organization_code UNIQUE (organization_id, code);
+CREATE INDEX idx_shop_organization_id ON app.shop(organization_id);
+
+-- Users: organization follows the shop; codes unique within the organization
+ALTER TABLE app.users ADD COLUMN organization_id uuid
+    REFERENCES app.organizations(id) ON DELETE RESTRICT;
+UPDATE app.users u SET organization_id = s.organization_id
+FROM app.shop s WHERE s.id = u.shop_id;
+-- shopless staff would otherwise be unscoped; only superadmins stay without one
+UPDATE app.users u SET organization_id = (SELECT id FROM app.organizations WHERE code = 'DEFAULT')
+FROM app.roles r
+WHERE r.id = u.role_id AND u.organization_id IS NULL AND r.code <> 'superadmin';
+
+CREATE OR REPLACE FUNCTION app.user_sync_organization()
+RETURNS trigger AS $$
+BEGIN
+  IF NEW.shop_id IS NOT NULL THEN
+    SELECT organization_id INTO NEW.organization_id FROM app.shop WHERE id = NEW.shop_id;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_user_sync_organization
+BEFORE INSERT OR UPDATE OF shop_id, organization_id ON app.users
+FOR EACH ROW
+EXECUTE FUNCTION app.user_sync_organization();
+
+ALTER TABLE app.users DROP CONSTRAINT users_code_key;
+CREATE UNIQUE INDEX uq_users_organization_code
+    ON app.users (COALESCE(organization_id, '00000000-0000-0000-0000-000000000000'::uuid), code);
+CREATE INDEX idx_users_organization_id ON app.users(organization_id);
+
+-- Org admin role (made a system role by 20260118100000_orgadmin_system_role)
+INSERT INTO app.permissions (code, description) VALUES
+    ('org.shops', 'Act on every shop in own organization')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO app.roles (code, name, is_system) VALUES ('orgadmin', 'Organization Administrator', FALSE)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO app.role_permissions (role_id, permission_code)
+SELECT r.id, p.permission_code
+FROM app.roles r
+CROSS JOIN (
+    SELECT permission_code FROM app.role_permissions rp
+    JOIN app.roles a ON a.id = rp.role_id AND a.code = 'admin'
+    UNION
+    SELECT 'org.shops'
+) p
+WHERE r.code = 'orgadmin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO app.role_permissions (role_id, permission_code)
+SELECT id, 'org.shops' FROM app.roles WHERE code = 'superadmin'
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM app.role_permissions WHERE permission_code = 'org.shops';
+DELETE FROM app.roles WHERE code = 'orgadmin';
+DELETE FROM app.permissions WHERE code = 'org.shops';
+
+DROP INDEX IF EXISTS app.idx_users_organization_id;
+DROP INDEX IF EXISTS app.uq_users_organization_code;
+ALTER TABLE app.users ADD CONSTRAINT users_code_key UNIQUE (code);
+DROP TRIGGER IF EXISTS trg_user_sync_organization ON app.users;
+DROP FUNCTION IF EXISTS app.user_sync_organization();
+ALTER TABLE app.users DROP COLUMN IF EXISTS organization_id;
+
+DROP INDEX IF EXISTS app.idx_shop_organization_id;
+ALTER TABLE app.shop DROP CONSTRAINT IF EXISTS uq_shop_organization_code;
+ALTER TABLE app.shop ADD CONSTRAINT uq_shop_code UNIQUE (code);
+ALTER TABLE app.shop DROP COLUMN IF EXISTS organization_id;
+
+DROP TRIGGER IF EXISTS trg_set_updated_at_organizations ON app.organizations;
+DROP TABLE IF EXISTS app.organizations;
+-- +goose StatementEnd
@@ -0,0 +1,127 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Row-level security on work order data
+-- A second line of shop isolation behind the Go checks. Request-scoped
+-- transactions (database.InScope / SetScope) run
+--   SET LOCAL app.current_role            -- role code, 'system' for system-wide API keys
+--   SET LOCAL app.current_shop_id         -- set for shop-bound callers
+--   SET LOCAL app.current_organization_id -- set for superadmins with an
+--                                            organization header and org admins
+-- and the policies below only let those transactions see their own shops'
+-- rows. Connections that never set app.current_role (migrations, background
+-- jobs, code paths not yet moved onto the helper) are unscoped.
+-- The policies bind app_user; app_owner owns the tables and bypasses them.
+------------------------------------------------------------
+
+-- app.rls_unscoped: true when the transaction carries no request scope
+CREATE OR REPLACE FUNCTION app.rls_unscoped()
+RETURNS boolean AS $$
+  SELECT COALESCE(current_setting('app.current_role', true), '') = '';
+$$ LANGUAGE sql STABLE;
+
+-- app.rls_shop_visible: whether rows of shop p_shop are visible to the scope
+CREATE OR REPLACE FUNCTION app.rls_shop_visible(p_shop uuid)
+RETURNS boolean AS $$
+  SELECT CASE
+    WHEN app.rls_unscoped() THEN true
+    WHEN NULLIF(current_setting('app.current_shop_id', true), '') IS NOT NULL
+      THEN p_shop = current_setting('app.current_shop_id', true)::uuid
+    WHEN NULLIF(current_setting('app.current_organization_id', true), '') IS NOT NULL
+      THEN EXISTS (
+        SELECT 1 FROM app.shop s
+        WHERE s.id = p_shop
+          AND s.organization_id = current_setting('app.current_organization_id', true)::uuid)
+    ELSE current_setting('app.current_role', true) IN ('superadmin', 'system')
+  END;
+$$ LANGUAGE sql STABLE;
+
+-- Work orders carry the shop; everything else hangs off a work order, so its
+-- policy only has to look the work order up (which work_orders' own policy
+-- then filters).
+ALTER TABLE app.work_orders ENABLE ROW LEVEL SECURITY;
+CREATE POLICY work_orders_shop_scope ON app.work_orders
+    USING (app.rls_shop_visible(shop_id))
+    WITH CHECK (app.rls_shop_visible(shop_id));
+
+ALTER TABLE app.insurance ENABLE ROW LEVEL SECURITY;
+CREATE POLICY insurance_shop_scope ON app.insurance
+    USING (EXISTS (SELECT 1 FROM app.work_orders wo WHERE wo.id = work_order_id));
+
+ALTER TABLE app.work_order_image ENABLE ROW LEVEL SECURITY;
+CREATE POLICY work_order_image_shop_scope ON app.work_order_image
+    USING (EXISTS (SELECT 1 FROM app.work_orders wo WHERE wo.id = work_order_id));
+
+ALTER TABLE app.ai_scan_job ENABLE ROW LEVEL SECURITY;
+CREATE POLICY ai_scan_job_shop_scope ON app.ai_scan_job
+    USING (EXISTS (SELECT 1 FROM app.work_orders wo WHERE wo.id = work_order_id));
+
+ALTER TABLE app.ai_scan_job_image ENABLE ROW LEVEL SECURITY;
+CREATE POLICY ai_scan_job_image_shop_scope ON app.ai_scan_job_image
+    USING (EXISTS (SELECT 1 FROM app.ai_scan_job j WHERE j.id = ai_scan_job_id));
+
+ALTER TABLE app.ai_detection_raw ENABLE ROW LEVEL SECURITY;
+CREATE POLICY ai_detection_raw_shop_scope ON app.ai_detection_raw
+    USING (EXISTS (SELECT 1 FROM app.ai_scan_job_image ji WHERE ji.id = ai_scan_job_image_id));
+
+ALTER TABLE app.ai_detection ENABLE ROW LEVEL SECURITY;
+CREATE POLICY ai_detection_shop_scope ON app.ai_detection
+    USING (EXISTS (SELECT 1 FROM app.work_orders wo WHERE wo.id = work_order_id));
+
+-- Customers and vehicles have no shop of their own: they are visible through
+-- a visible work order. Intake inserts them before the work order exists, so
+-- inserts are open (and must not use RETURNING, which would apply the read
+-- policy to a row no work order points at yet).
+ALTER TABLE app.customers ENABLE ROW LEVEL SECURITY;
+CREATE POLICY customers_shop_read ON app.customers FOR SELECT
+    USING (app.rls_unscoped() OR EXISTS (SELECT 1 FROM app.work_orders wo WHERE wo.customer_id = id));
+CREATE POLICY customers_shop_update ON app.customers FOR UPDATE
+    USING (app.rls_unscoped() OR EXISTS (SELECT 1 FROM app.work_orders wo WHERE wo.customer_id = id));
+CREATE POLICY customers_shop_delete ON app.customers FOR DELETE
+    USING (app.rls_unscoped() OR EXISTS (SELECT 1 FROM app.work_orders wo WHERE wo.customer_id = id));
+CREATE POLICY customers_insert ON app.customers FOR INSERT
+    WITH CHECK (true);
+
+ALTER TABLE app.vehicles ENABLE ROW LEVEL SECURITY;
+CREATE POLICY vehicles_shop_read ON app.vehicles FOR SELECT
+    USING (app.rls_unscoped() OR EXISTS (SELECT 1 FROM app.work_orders wo WHERE wo.vehicle_id = id));
+CREATE POLICY vehicles_shop_update ON app.vehicles FOR UPDATE
+    USING (app.rls_unscoped() OR EXISTS (SELECT 1 FROM app.work_orders wo WHERE wo.vehicle_id = id));
+CREATE POLICY vehicles_shop_delete ON app.vehicles FOR DELETE
+    USING (app.rls_unscoped() OR EXISTS (SELECT 1 FROM app.work_orders wo WHERE wo.vehicle_id = id));
+CREATE POLICY vehicles_insert ON app.vehicles FOR INSERT
+    WITH CHECK (true);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP POLICY IF EXISTS vehicles_insert ON app.vehicles;
+DROP POLICY IF EXISTS vehicles_shop_delete ON app.vehicles;
+DROP POLICY IF EXISTS vehicles_shop_update ON app.vehicles;
+DROP POLICY IF EXISTS vehicles_shop_read ON app.vehicles;
+ALTER TABLE app.vehicles DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS customers_insert ON app.customers;
+DROP POLICY IF EXISTS customers_shop_delete ON app.customers;
+DROP POLICY IF EXISTS customers_shop_update ON app.customers;
+DROP POLICY IF EXISTS customers_shop_read ON app.customers;
+ALTER TABLE app.customers DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS ai_detection_shop_scope ON app.ai_detection;
+ALTER TABLE app.ai_detection DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS ai_detection_raw_shop_scope ON app.ai_detection_raw;
+ALTER TABLE app.ai_detection_raw DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS ai_scan_job_image_shop_scope ON app.ai_scan_job_image;
+ALTER TABLE app.ai_scan_job_image DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS ai_scan_job_shop_scope ON app.ai_scan_job;
+ALTER TABLE app.ai_scan_job DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS work_order_image_shop_scope ON app.work_order_image;
+ALTER TABLE app.work_order_image DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS insurance_shop_scope ON app.insurance;
+ALTER TABLE app.insurance DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS work_orders_shop_scope ON app.work_orders;
+ALTER TABLE app.work_orders DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS app.rls_shop_visible(uuid);
+DROP FUNCTION IF EXISTS app.rls_unscoped();
+-- +goose StatementEnd
@@ -0,0 +1,81 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Audit log
+-- - one row per mutating operation: who (user or API key), what (action on
+--   an entity), the entity before and after, the changed fields, and where
+--   the request came from
+-- - shop_id / organization_id scope the history shown to admins
+-- - append-only: app_user may only insert and read, and a trigger refuses
+--   updates and deletes from anyone
+------------------------------------------------------------
+CREATE TABLE app.audit_log (
+    id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    occurred_at timestamptz NOT NULL DEFAULT now(),
+
+    actor_user_id uuid,          -- no FK: entries outlive users
+    actor_api_key_id uuid,
+    actor_email text,
+    actor_role text,
+
+    action text NOT NULL,        -- e.g. 'user.update', 'shop.deactivate'
+    entity_type text NOT NULL,   -- 'user', 'shop', 'workorder'
+    entity_id text NOT NULL,
+    shop_id uuid,
+    organization_id uuid,
+
+    before jsonb,
+    after jsonb,
+    changes jsonb,               -- {"field": {"from": ..., "to": ...}}
+
+    ip inet,
+    user_agent text,
+    request_id text,
+
+    CONSTRAINT ck_audit_log_action_not_blank CHECK (char_length(trim(action)) > 0)
+);
+
+CREATE INDEX idx_audit_log_entity ON app.audit_log(entity_type, entity_id, occurred_at DESC);
+CREATE INDEX idx_audit_log_occurred_at ON app.audit_log(occurred_at DESC);
+CREATE INDEX idx_audit_log_actor_user_id ON app.audit_log(actor_user_id);
+CREATE INDEX idx_audit_log_shop_id ON app.audit_log(shop_id);
+
+CREATE OR REPLACE FUNCTION app.audit_log_append_only()
+RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'app.audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_log_append_only
+BEFORE UPDATE OR DELETE ON app.audit_log
+FOR EACH ROW
+EXECUTE FUNCTION app.audit_log_append_only();
+
+CREATE TRIGGER trg_audit_log_no_truncate
+BEFORE TRUNCATE ON app.audit_log
+FOR EACH STATEMENT
+EXECUTE FUNCTION app.audit_log_append_only();
+
+REVOKE UPDATE, DELETE, TRUNCATE ON app.audit_log FROM app_user;
+
+-- Per-entity history for admins
+INSERT INTO app.permissions (code, description) VALUES
+    ('audit.read', 'Read the change history of users, shops and work orders')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO app.role_permissions (role_id, permission_code)
+SELECT id, 'audit.read' FROM app.roles WHERE code IN ('admin', 'orgadmin')
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM app.role_permissions WHERE permission_code = 'audit.read';
+DELETE FROM app.permissions WHERE code = 'audit.read';
+
+DROP TRIGGER IF EXISTS trg_audit_log_no_truncate ON app.audit_log;
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON app.audit_log;
+DROP FUNCTION IF EXISTS app.audit_log_append_only();
+DROP TABLE IF EXISTS app.audit_log;
+-- +goose StatementEnd
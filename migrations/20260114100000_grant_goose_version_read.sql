@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Readiness check (GET /readyz) compares the applied schema version with the
+-- migrations the server was built with, so app_user needs to read goose's
+-- version table. goose created it in public before the app schema existed.
+------------------------------------------------------------
+GRANT SELECT ON public.goose_db_version TO app_user;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+REVOKE SELECT ON public.goose_db_version FROM app_user;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- Row-level security fails closed for the application
+-- A transaction that never set app.current_role used to be unscoped, so any
+-- query that missed database.InScope / SetScope saw every shop. For app_user
+-- (and roles that inherit it) an unset role now sees nothing; background
+-- jobs scope themselves as 'system' instead (database.WithSystemScope).
+-- Other roles the policies bind, such as maintenance logins, stay unscoped.
+------------------------------------------------------------
+CREATE OR REPLACE FUNCTION app.rls_unscoped()
+RETURNS boolean AS $$
+  SELECT NOT pg_has_role(current_user, 'app_user', 'MEMBER');
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION app.rls_unscoped()
+RETURNS boolean AS $$
+  SELECT COALESCE(current_setting('app.current_role', true), '') = '';
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+------------------------------------------------------------
+-- orgadmin is a built-in role like superadmin: the /roles endpoints must not
+-- change or delete it. It was seeded as a custom role because the roles check
+-- only allowed superadmin to be a system role.
+------------------------------------------------------------
+ALTER TABLE app.roles DROP CONSTRAINT ck_roles_system_code;
+ALTER TABLE app.roles ADD CONSTRAINT ck_roles_system_code
+    CHECK (is_system = FALSE OR code IN ('superadmin', 'orgadmin'));
+
+UPDATE app.roles SET is_system = TRUE WHERE code = 'orgadmin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE app.roles SET is_system = FALSE WHERE code = 'orgadmin';
+
+ALTER TABLE app.roles DROP CONSTRAINT ck_roles_system_code;
+ALTER TABLE app.roles ADD CONSTRAINT ck_roles_system_code
+    CHECK ((is_system = TRUE AND code IN ('superadmin')) OR (is_system = FALSE));
+-- +goose StatementEnd